@@ -2,12 +2,19 @@ package backend
 
 import (
 	"context"
+	"io/fs"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/fsnotify/fsnotify"
 	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+	"weld/backend/compare"
 	"weld/backend/diff"
+	"weld/backend/history"
+	"weld/backend/session"
+	"weld/backend/settings"
+	"weld/backend/watch"
 )
 
 // DiffLine is now imported from the diff package
@@ -21,6 +28,12 @@ type App struct {
 	ctx               context.Context
 	InitialLeftFile   string
 	InitialRightFile  string
+	InitialPairs      []ComparisonPair // pairs from a manifest, compared one after another
+	SampleFiles       fs.FS
+	Storage           Storage // where settings, the session, and history persist; nil keeps them in memory only
+	Notifier          Notifier
+	Runtime           Runtime        // window, menu, and event calls; the Wails runtime unless set before Startup
+	Dialogs           DialogProvider // file and message dialogs; the runtime's native dialogs when nil
 	minimapVisible    bool
 	minimapMenuItem   *menu.MenuItem
 	undoMenuItem      *menu.MenuItem
@@ -37,36 +50,179 @@ type App struct {
 	copyRightMenuItem *menu.MenuItem
 	lastUsedDirectory string
 
-	// File watching
-	fileWatcher     *fsnotify.Watcher
-	watcherMutex    sync.Mutex
-	leftWatchPath   string
-	rightWatchPath  string
-	changeDebouncer map[string]time.Time
+	// Radio items of the View > Diff Algorithm menu, keyed by algorithm name
+	diffAlgorithmMenuItems map[string]*menu.MenuItem
+	focusModeMenuItem      *menu.MenuItem
+	// menuMutex guards the menu item references above, the state of the
+	// items, and minimapVisible
+	menuMutex sync.Mutex
+
+	// Persisted user settings
+	settings     settings.Store[Settings]
+	settingsOnce sync.Once
+	settingsErr  error
+	history      history.History
+	session      session.Store[Session]
+
+	// operationMutex serializes line operations and operation groups, so the
+	// operations of a batch are never interleaved with those of other calls
+	operationMutex sync.Mutex
+
+	// Watches the compared files for external changes
+	watcher watch.Watcher
+
+	// Whether the window has focus, as reported by the frontend
+	windowFocused atomic.Bool
 
 	// Diff algorithm
 	diffAlgorithm diff.Algorithm
+
+	// Unsaved edits of the compared files and the most recent comparison,
+	// used by hunk-based APIs
+	compare compare.State[CompareOptions]
+
+	// Change counts of repeated comparisons, keyed by file pair
+	trends     map[string][]ComparisonRun
+	trendMutex sync.Mutex
+
+	// Changes made since the current pair was compared or last saved
+	merge            *mergeSession
+	lastMergeSummary *MergeSummary
+	mergeMutex       sync.Mutex
+
+	// Cached file hashes for directory comparisons
+	dirIndex      *directoryIndex
+	dirIndexMutex sync.Mutex
+
+	// Temporary directory the sample files were extracted to
+	sampleDirectory string
+	sampleMutex     sync.Mutex
+
+	// Pairs to compare one after another
+	queue      []ComparisonPair
+	queueIndex int
+	queueMutex sync.Mutex
+
+	// Pane in edit mode, and the menu accelerators disabled while editing
+	editingPane           string
+	suspendedAccelerators map[*menu.MenuItem]*keys.Accelerator
+	editingMutex          sync.Mutex
+
+	// Resources released after a period without interaction
+	idle         bool
+	idleTimer    *time.Timer
+	lastActivity time.Time
+	pausedWatch  *pausedWatch
+	idleMutex    sync.Mutex
+	// watchPausedFlag mirrors pausedWatch != nil for watch status reports,
+	// which are made while idleMutex is held as watching resumes
+	watchPausedFlag atomic.Bool
+
+	// Throttled re-diffs after edits
+	rediffThrottle  *rediffThrottle
+	rediffMutex     sync.Mutex
+	rediffLeftPath  string
+	rediffRightPath string
+	rediffFull      bool
+
+	// Comparison running in the background, cancelled when another starts
+	asyncCompareID     int
+	asyncCompareCancel context.CancelFunc
+	asyncCompareMutex  sync.Mutex
+
+	// Two growing files followed by StartTailComparison
+	tail      *tailSession
+	tailMutex sync.Mutex
+
+	// Spell-check dictionaries loaded so far, keyed by language
+	dictionaries    map[string]*diff.Dictionary
+	dictionaryMutex sync.Mutex
+
+	// Recent diff results keyed by content and settings
+	diffCache *diffCache
+
+	// Saved text snippets, loaded from storage on first use
+	snippets      []Snippet
+	snippetsMutex sync.Mutex
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
-	return &App{
-		changeDebouncer: make(map[string]time.Time),
-		minimapVisible:  true, // Default to showing minimap
-		diffAlgorithm:   diff.NewLCSDefault(),
+	app := &App{
+		minimapVisible: true, // Default to showing minimap
+		diffAlgorithm:  diff.NewLCSDefault(),
+		compare:        compare.New[CompareOptions](),
+		diffCache:      newDiffCache(maxDiffCacheBytes),
 	}
+	app.settings = app.newSettingsStore()
+	app.session = app.newSessionStore()
+	app.history = app.newHistory()
+	app.watcher = app.newWatcher()
+	// The window has focus when it first opens
+	app.windowFocused.Store(true)
+	return app
 }
 
 // Startup is called when the app starts. The context is saved
 // so we can call the runtime methods
 func (a *App) Startup(ctx context.Context) {
 	a.ctx = ctx
+	if a.Runtime == nil {
+		a.Runtime = NewWailsRuntime(ctx)
+	}
+
+	if a.Notifier == nil {
+		a.Notifier = systemNotifier{}
+	}
+
+	// Load persisted settings, falling back to defaults on error
+	if err := a.LoadSettings(); err != nil {
+		a.runtime().LogErrorf("Failed to load settings: %v", err)
+	}
+	if err := a.session.Load(); err != nil {
+		a.runtime().LogErrorf("Failed to load session: %v", err)
+	}
+	a.restoreUndoHistory()
+	a.updateDiffAlgorithmMenu()
+	// A focus mode left on last time is restored, and otherwise nothing changes
+	if a.GetFocusMode() {
+		a.applyFocusMode()
+	}
+
+	// Queue the pairs of a manifest given on the command line
+	if len(a.InitialPairs) > 0 {
+		a.setComparisonQueue(a.InitialPairs)
+	}
+
+	// Start counting down to idle
+	a.RecordActivity()
 }
 
 // Shutdown is called when the app is shutting down
 func (a *App) Shutdown(ctx context.Context) {
 	// Stop file watching
 	a.StopFileWatching()
+	a.stopIdleTimer()
+
+	// Cancel any pending re-diff
+	a.rediffMutex.Lock()
+	if a.rediffThrottle != nil {
+		a.rediffThrottle.Stop()
+	}
+	a.rediffMutex.Unlock()
+	a.CancelCompare()
+	a.StopTailComparison()
+
+	// Keep the undo history for next time when it is persisted
+	if err := a.saveUndoHistory(); err != nil {
+		a.runtime().LogErrorf("Failed to save undo history: %v", err)
+	}
+
+	// Release the directory comparison index
+	a.closeDirectoryIndex()
+
+	// Clean up any extracted sample files
+	a.removeSampleFiles()
 }
 
 // GetContext returns the app's context
@@ -82,6 +238,15 @@ type InitialFiles struct {
 
 // GetInitialFiles returns the initial file paths passed via command line
 func (a *App) GetInitialFiles() InitialFiles {
+	// Without files of its own, a queue starts with its first pair
+	if a.InitialLeftFile == "" && a.InitialRightFile == "" {
+		a.queueMutex.Lock()
+		defer a.queueMutex.Unlock()
+		if len(a.queue) > 0 {
+			a.queueIndex = 0
+			return InitialFiles{LeftFile: a.queue[0].LeftFile, RightFile: a.queue[0].RightFile}
+		}
+	}
 	return InitialFiles{
 		LeftFile:  a.InitialLeftFile,
 		RightFile: a.InitialRightFile,
@@ -90,5 +255,7 @@ func (a *App) GetInitialFiles() InitialFiles {
 
 // GetMinimapVisible returns the current minimap visibility state
 func (a *App) GetMinimapVisible() bool {
+	a.menuMutex.Lock()
+	defer a.menuMutex.Unlock()
 	return a.minimapVisible
 }
@@ -1,18 +1,16 @@
 package backend
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
-	"weld/backend/diff"
 )
 
 func TestApp_ReadFileContent(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Test reading empty file path
 	t.Run("empty file path", func(t *testing.T) {
@@ -60,9 +58,7 @@ func TestApp_ReadFileContent(t *testing.T) {
 }
 
 func TestApp_ReadFileContentWithCache(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Create a temporary file
 	tempDir := t.TempDir()
@@ -108,9 +104,7 @@ func TestApp_ReadFileContentWithCache(t *testing.T) {
 }
 
 func TestApp_CompareFiles(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	// Ensure file watchers are stopped to prevent memory leaks
 	t.Cleanup(func() { app.StopFileWatching() })
 
@@ -213,9 +207,7 @@ func TestApp_CompareFiles(t *testing.T) {
 }
 
 func TestApp_CopyToFile(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Create temporary files
 	tempDir := t.TempDir()
@@ -256,7 +248,7 @@ func TestApp_CopyToFile(t *testing.T) {
 	// Test copying line to middle
 	t.Run("copy to middle", func(t *testing.T) {
 		// Reset the cache
-		delete(fileCache, targetFile)
+		app.compare.Discard(targetFile)
 
 		err := app.CopyToFile(sourceFile, targetFile, 2, "middle line")
 		if err != nil {
@@ -277,7 +269,7 @@ func TestApp_CopyToFile(t *testing.T) {
 	// Test copying line to end
 	t.Run("copy to end", func(t *testing.T) {
 		// Reset the cache
-		delete(fileCache, targetFile)
+		app.compare.Discard(targetFile)
 
 		err := app.CopyToFile(sourceFile, targetFile, 3, "end line")
 		if err != nil {
@@ -307,9 +299,7 @@ func TestApp_CopyToFile(t *testing.T) {
 }
 
 func TestApp_RemoveLineFromFile(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Create temporary file
 	tempDir := t.TempDir()
@@ -342,7 +332,7 @@ func TestApp_RemoveLineFromFile(t *testing.T) {
 	// Test removing middle line
 	t.Run("remove middle line", func(t *testing.T) {
 		// Reset the cache
-		delete(fileCache, testFile)
+		app.compare.Discard(testFile)
 
 		err := app.RemoveLineFromFile(testFile, 2)
 		if err != nil {
@@ -363,7 +353,7 @@ func TestApp_RemoveLineFromFile(t *testing.T) {
 	// Test removing last line
 	t.Run("remove last line", func(t *testing.T) {
 		// Reset the cache
-		delete(fileCache, testFile)
+		app.compare.Discard(testFile)
 
 		err := app.RemoveLineFromFile(testFile, 4)
 		if err != nil {
@@ -384,7 +374,7 @@ func TestApp_RemoveLineFromFile(t *testing.T) {
 	// Test removing out-of-bounds line
 	t.Run("remove out-of-bounds line", func(t *testing.T) {
 		// Reset the cache
-		delete(fileCache, testFile)
+		app.compare.Discard(testFile)
 
 		err := app.RemoveLineFromFile(testFile, 10)
 		if err == nil {
@@ -404,9 +394,7 @@ func TestApp_RemoveLineFromFile(t *testing.T) {
 }
 
 func TestApp_SaveChanges(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Create temporary file
 	tempDir := t.TempDir()
@@ -445,7 +433,7 @@ func TestApp_SaveChanges(t *testing.T) {
 		}
 
 		// Verify cache was cleared
-		if _, exists := fileCache[testFile]; exists {
+		if _, exists := app.compare.Edited(testFile); exists {
 			t.Error("Cache should be cleared after saving")
 		}
 	})
@@ -475,9 +463,7 @@ func TestApp_SaveChanges(t *testing.T) {
 }
 
 func TestApp_storeFileInMemory(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	testFile := "/test/file.txt"
 	testContent := []string{"line1", "line2", "line3"}
@@ -490,7 +476,7 @@ func TestApp_storeFileInMemory(t *testing.T) {
 		}
 
 		// Check that content was stored
-		if cachedContent, exists := fileCache[testFile]; !exists {
+		if cachedContent, exists := app.compare.Edited(testFile); !exists {
 			t.Error("Content was not stored in cache")
 		} else if !reflect.DeepEqual(cachedContent, testContent) {
 			t.Errorf("Cached content is %v, expected %v", cachedContent, testContent)
@@ -506,7 +492,7 @@ func TestApp_storeFileInMemory(t *testing.T) {
 		}
 
 		// Check that content was overwritten
-		if cachedContent, exists := fileCache[testFile]; !exists {
+		if cachedContent, exists := app.compare.Edited(testFile); !exists {
 			t.Error("Content was not stored in cache")
 		} else if !reflect.DeepEqual(cachedContent, newContent) {
 			t.Errorf("Cached content is %v, expected %v", cachedContent, newContent)
@@ -515,12 +501,10 @@ func TestApp_storeFileInMemory(t *testing.T) {
 }
 
 func TestApp_HasUnsavedChanges(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	// Clear cache first to ensure clean state
-	fileCache = make(map[string][]string)
+	app.compare.DiscardAll()
 
 	t.Run("no changes for non-cached file", func(t *testing.T) {
 		result := app.HasUnsavedChanges("/test/file.txt")
@@ -531,7 +515,7 @@ func TestApp_HasUnsavedChanges(t *testing.T) {
 
 	t.Run("has changes for cached file", func(t *testing.T) {
 		// Add to cache
-		fileCache["/test/file.txt"] = []string{"content"}
+		app.compare.SetEdited("/test/file.txt", []string{"content"})
 
 		result := app.HasUnsavedChanges("/test/file.txt")
 		if !result {
@@ -548,13 +532,11 @@ func TestApp_HasUnsavedChanges(t *testing.T) {
 }
 
 func TestApp_GetUnsavedFilesList(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	t.Run("empty list when no cache", func(t *testing.T) {
 		// Clear cache
-		fileCache = make(map[string][]string)
+		app.compare.DiscardAll()
 
 		files := app.GetUnsavedFilesList()
 		if len(files) != 0 {
@@ -564,9 +546,9 @@ func TestApp_GetUnsavedFilesList(t *testing.T) {
 
 	t.Run("returns cached files", func(t *testing.T) {
 		// Clear and add files
-		fileCache = make(map[string][]string)
-		fileCache["/file1.txt"] = []string{"content1"}
-		fileCache["/file2.txt"] = []string{"content2"}
+		app.compare.DiscardAll()
+		app.compare.SetEdited("/file1.txt", []string{"content1"})
+		app.compare.SetEdited("/file2.txt", []string{"content2"})
 
 		files := app.GetUnsavedFilesList()
 		if len(files) != 2 {
@@ -595,37 +577,35 @@ func TestApp_GetUnsavedFilesList(t *testing.T) {
 }
 
 func TestApp_DiscardAllChanges(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	t.Run("discard with cached files", func(t *testing.T) {
 		// Add files to cache
-		fileCache = make(map[string][]string)
-		fileCache["/file1.txt"] = []string{"content1"}
-		fileCache["/file2.txt"] = []string{"content2"}
+		app.compare.DiscardAll()
+		app.compare.SetEdited("/file1.txt", []string{"content1"})
+		app.compare.SetEdited("/file2.txt", []string{"content2"})
 
 		err := app.DiscardAllChanges()
 		if err != nil {
 			t.Errorf("DiscardAllChanges returned error: %v", err)
 		}
 
-		if len(fileCache) != 0 {
-			t.Error("fileCache should be empty after DiscardAllChanges")
+		if len(app.compare.EditedPaths()) != 0 {
+			t.Error("Expected no unsaved changes after DiscardAllChanges")
 		}
 	})
 
 	t.Run("discard with empty cache", func(t *testing.T) {
 		// Start with empty cache
-		fileCache = make(map[string][]string)
+		app.compare.DiscardAll()
 
 		err := app.DiscardAllChanges()
 		if err != nil {
 			t.Errorf("DiscardAllChanges returned error: %v", err)
 		}
 
-		if len(fileCache) != 0 {
-			t.Error("fileCache should remain empty after DiscardAllChanges")
+		if len(app.compare.EditedPaths()) != 0 {
+			t.Error("Expected still no unsaved changes after DiscardAllChanges")
 		}
 	})
 }
@@ -664,9 +644,7 @@ func TestApp_GetInitialFiles(t *testing.T) {
 }
 
 func TestApp_CompareFiles_ErrorHandling(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	// Ensure file watchers are stopped to prevent memory leaks
 	t.Cleanup(func() { app.StopFileWatching() })
 
@@ -693,9 +671,7 @@ func TestApp_CompareFiles_ErrorHandling(t *testing.T) {
 }
 
 func TestApp_CopyToFile_ErrorHandling(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	t.Run("copy to non-existent directory", func(t *testing.T) {
 		// Create source file
@@ -740,16 +716,14 @@ func TestApp_CopyToFile_ErrorHandling(t *testing.T) {
 }
 
 func TestApp_SaveChanges_ErrorHandling(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	t.Run("save to non-existent directory", func(t *testing.T) {
 		tempDir := t.TempDir()
 		// Add content to cache for non-existent directory
 		nonExistentFile := filepath.Join(tempDir, "nonexistent", "directory", "file.txt")
-		fileCache = make(map[string][]string)
-		fileCache[nonExistentFile] = []string{"content"}
+		app.compare.DiscardAll()
+		app.compare.SetEdited(nonExistentFile, []string{"content"})
 
 		err := app.SaveChanges(nonExistentFile)
 		if err == nil {
@@ -759,7 +733,7 @@ func TestApp_SaveChanges_ErrorHandling(t *testing.T) {
 
 	t.Run("save file not in cache", func(t *testing.T) {
 		// Clear cache
-		fileCache = make(map[string][]string)
+		app.compare.DiscardAll()
 
 		tempDir := t.TempDir()
 		testFile := filepath.Join(tempDir, "test.txt")
@@ -772,9 +746,7 @@ func TestApp_SaveChanges_ErrorHandling(t *testing.T) {
 }
 
 func TestApp_RemoveLineFromFile_ErrorHandling(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 
 	t.Run("remove from non-existent file", func(t *testing.T) {
 		tempDir := t.TempDir()
@@ -860,9 +832,7 @@ func TestIsBinaryFile(t *testing.T) {
 }
 
 func TestApp_ReadFileContent_BinaryRejection(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	testDir := t.TempDir()
 
 	// Create a binary file
@@ -901,9 +871,7 @@ func TestApp_ReadFileContent_BinaryRejection(t *testing.T) {
 }
 
 func TestApp_CompareFiles_BinaryRejection(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	// Ensure file watchers are stopped to prevent memory leaks
 	t.Cleanup(func() { app.StopFileWatching() })
 	testDir := t.TempDir()
@@ -953,9 +921,7 @@ func TestApp_CompareFiles_BinaryRejection(t *testing.T) {
 }
 
 func TestApp_EndToEndDiffWorkflow(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	// Ensure file watchers are stopped to prevent memory leaks
 	t.Cleanup(func() { app.StopFileWatching() })
 	tempDir := t.TempDir()
@@ -1040,9 +1006,7 @@ func main() {
 }
 
 func TestApp_CompareFiles_TextAndHTML(t *testing.T) {
-	app := &App{
-		diffAlgorithm: diff.NewLCSDefault(),
-	}
+	app := NewApp()
 	// Ensure file watchers are stopped to prevent memory leaks
 	t.Cleanup(func() { app.StopFileWatching() })
 	tempDir := t.TempDir()
@@ -1146,3 +1110,184 @@ func TestApp_RemembersLastUsedDirectory(t *testing.T) {
 		t.Errorf("Expected lastUsedDirectory to be %s, got: %s", expectedDir2, app.lastUsedDirectory)
 	}
 }
+
+func TestApp_CompareFiles_Reordered(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	tempDir := t.TempDir()
+	file1 := filepath.Join(tempDir, "file1.txt")
+	file2 := filepath.Join(tempDir, "file2.txt")
+	if err := os.WriteFile(file1, []byte("gamma\nalpha\nbeta"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(file2, []byte("alpha\nbeta\ngamma"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	result, err := app.CompareFiles(file1, file2)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if !result.Reordered {
+		t.Error("Expected reordered files to be flagged")
+	}
+
+	sorted, err := app.CompareFilesSorted(file1, file2)
+	if err != nil {
+		t.Fatalf("CompareFilesSorted returned error: %v", err)
+	}
+	for _, line := range sorted.Lines {
+		if line.Type != "same" {
+			t.Errorf("Expected only same lines in the sorted comparison, got %+v", line)
+		}
+	}
+	if sorted.Lines[0].LeftLine != "alpha" || sorted.Lines[0].LeftNumber != 2 {
+		t.Errorf("Expected alpha from left line 2 first, got %+v", sorted.Lines[0])
+	}
+}
+
+func TestApp_CompareFiles_Identical(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	tempDir := t.TempDir()
+	file1 := filepath.Join(tempDir, "file1.txt")
+	file2 := filepath.Join(tempDir, "file2.txt")
+	file3 := filepath.Join(tempDir, "file3.txt")
+	for path, content := range map[string]string{file1: "alpha\nbeta", file2: "alpha\nbeta", file3: "alpha\ngamma"} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	result, err := app.CompareFiles(file1, file2)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if !result.Identical || result.ContentHash == "" {
+		t.Errorf("Expected identical files to be flagged with a hash, got %v %q", result.Identical, result.ContentHash)
+	}
+
+	result, err = app.CompareFiles(file1, file3)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if result.Identical || result.ContentHash != "" {
+		t.Errorf("Expected differing files not to be flagged, got %v %q", result.Identical, result.ContentHash)
+	}
+
+	if identical, err := app.FilesIdentical(file1, file2); err != nil || !identical {
+		t.Errorf("FilesIdentical(same) = %v, %v; expected true", identical, err)
+	}
+	if identical, err := app.FilesIdentical(file1, file3); err != nil || identical {
+		t.Errorf("FilesIdentical(different) = %v, %v; expected false", identical, err)
+	}
+}
+
+func TestApp_FilesIdentical_AlignImports(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	if app.GetAlignImports() {
+		t.Error("Expected import alignment to be off by default")
+	}
+	if err := app.SetAlignImports(true); err != nil {
+		t.Fatalf("SetAlignImports returned error: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	left := filepath.Join(tempDir, "left.go")
+	right := filepath.Join(tempDir, "right.go")
+	for path, content := range map[string]string{
+		left:  "package main\n\nimport (\n\t\"fmt\"\n\t\"os\"\n)\n",
+		right: "package main\n\nimport (\n\t\"os\"\n\t\"fmt\"\n)\n",
+	} {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	if identical, err := app.FilesIdentical(left, right); err != nil || identical {
+		t.Errorf("FilesIdentical(reordered imports) = %v, %v; expected false", identical, err)
+	}
+}
+
+func TestApp_LoadSettings(t *testing.T) {
+	storage := NewMemoryStorage()
+	saved := NewApp()
+	saved.Storage = storage
+	if err := saved.SetAlignImports(true); err != nil {
+		t.Fatalf("SetAlignImports returned error: %v", err)
+	}
+
+	// Settings are available before Startup, as for command line comparisons
+	app := NewApp()
+	app.Storage = storage
+	if err := app.LoadSettings(); err != nil {
+		t.Fatalf("LoadSettings returned error: %v", err)
+	}
+	if !app.GetAlignImports() {
+		t.Error("Expected the stored settings to be loaded")
+	}
+
+	// Loading again, as Startup does, keeps the settings already loaded
+	if err := app.SetAlignImports(false); err != nil {
+		t.Fatalf("SetAlignImports returned error: %v", err)
+	}
+	if err := saved.SetAlignImports(true); err != nil {
+		t.Fatalf("SetAlignImports returned error: %v", err)
+	}
+	if err := app.LoadSettings(); err != nil {
+		t.Fatalf("LoadSettings returned error: %v", err)
+	}
+	if app.GetAlignImports() {
+		t.Error("Expected settings to be loaded only once")
+	}
+}
+
+func TestApp_CompareFiles_Minified(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	statements := make([]string, 300)
+	for i := range statements {
+		statements[i] = fmt.Sprintf("a%d=%d;", i, i)
+	}
+	tempDir := t.TempDir()
+	file1 := filepath.Join(tempDir, "app.min.js")
+	file2 := filepath.Join(tempDir, "app2.min.js")
+	if err := os.WriteFile(file1, []byte(strings.Join(statements, "")), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	statements[150] = "a150=0;"
+	if err := os.WriteFile(file2, []byte(strings.Join(statements, "")), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	result, err := app.CompareFiles(file1, file2)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if !result.Minified {
+		t.Error("Expected minified files to be flagged")
+	}
+
+	tokenized, err := app.CompareFilesTokenized(file1, file2)
+	if err != nil {
+		t.Fatalf("CompareFilesTokenized returned error: %v", err)
+	}
+	var changed []DiffLine
+	for _, line := range tokenized.Lines {
+		if line.Type != "same" {
+			changed = append(changed, line)
+		}
+	}
+	// Statements this short are below the length checked for similarity
+	if len(changed) != 2 || changed[0].LeftLine != "a150=150;" || changed[1].RightLine != "a150=0;" || changed[0].LeftNumber != 1 || changed[1].RightNumber != 1 {
+		t.Errorf("Expected only the changed statement of line 1, got %+v", changed)
+	}
+}
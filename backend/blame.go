@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// blameTimeout bounds how long git may take to blame one file
+const blameTimeout = 10 * time.Second
+
+// uncommittedHash is the commit git blame reports for lines not yet committed
+const uncommittedHash = "0000000000000000000000000000000000000000"
+
+// GetShowLineAges returns whether diff lines carry the time they were last
+// changed according to git, for an age heatmap
+func (a *App) GetShowLineAges() bool {
+	return a.settings.Get().ShowLineAges
+}
+
+// SetShowLineAges sets whether diff lines carry the time they were last
+// changed according to git, and re-compares the current files. The new
+// result is delivered through the "diff-updated" event.
+func (a *App) SetShowLineAges(show bool) error {
+	err := a.settings.Update(func(s *Settings) error {
+		s.ShowLineAges = show
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// annotateLineTimes fills in the commit times of each side's lines. Files
+// outside a git repository, and virtual files, are left without times.
+func (a *App) annotateLineTimes(result *DiffResult, leftPath, rightPath string, leftLines, rightLines []string) {
+	leftTimes, err := blameTimes(leftPath, leftLines)
+	if err != nil {
+		a.runtime().LogDebugf("No line ages for %s: %v", leftPath, err)
+	}
+	rightTimes, err := blameTimes(rightPath, rightLines)
+	if err != nil {
+		a.runtime().LogDebugf("No line ages for %s: %v", rightPath, err)
+	}
+
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		if line.LeftNumber > 0 && line.LeftNumber <= len(leftTimes) {
+			line.LeftTime = leftTimes[line.LeftNumber-1]
+		}
+		if line.RightNumber > 0 && line.RightNumber <= len(rightTimes) {
+			line.RightTime = rightTimes[line.RightNumber-1]
+		}
+	}
+}
+
+// blameTimes returns the Unix time of the commit that last changed each
+// line, blaming the given content so unsaved edits line up. Lines that are
+// not committed have time 0.
+func blameTimes(path string, lines []string) ([]int64, error) {
+	if IsVirtualPath(path) {
+		return nil, fmt.Errorf("virtual files have no history")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), blameTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "git", "blame", "--line-porcelain", "--contents", "-", "--", filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	cmd.Stdin = strings.NewReader(strings.Join(lines, "\n") + "\n")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git blame failed: %w", err)
+	}
+	return parseBlameTimes(string(output), len(lines))
+}
+
+// parseBlameTimes reads the author times of git blame --line-porcelain output
+func parseBlameTimes(output string, lineCount int) ([]int64, error) {
+	times := make([]int64, lineCount)
+	line, hash := 0, ""
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	for scanner.Scan() {
+		text := scanner.Text()
+		switch {
+		case strings.HasPrefix(text, "\t"):
+			// The line's content ends its entry
+			hash = ""
+		case hash == "":
+			// Each entry starts with "<hash> <original line> <final line>"
+			fields := strings.Fields(text)
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("unexpected blame header: %q", text)
+			}
+			number, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("unexpected blame header: %q", text)
+			}
+			hash, line = fields[0], number
+		case strings.HasPrefix(text, "author-time "):
+			seconds, err := strconv.ParseInt(strings.TrimPrefix(text, "author-time "), 10, 64)
+			if err == nil && hash != uncommittedHash && line >= 1 && line <= lineCount {
+				times[line-1] = seconds
+			}
+		}
+	}
+	return times, scanner.Err()
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// commitFile writes a file and commits it to the git repository in its
+// directory with the given author date
+func commitFile(t *testing.T, path, content, date string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	for _, args := range [][]string{
+		{"add", filepath.Base(path)},
+		{"commit", "-q", "-m", "update"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = filepath.Dir(path)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=Test", "GIT_AUTHOR_EMAIL=test@example.com", "GIT_AUTHOR_DATE="+date,
+			"GIT_COMMITTER_NAME=Test", "GIT_COMMITTER_EMAIL=test@example.com", "GIT_COMMITTER_DATE="+date)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, output)
+		}
+	}
+}
+
+func TestApp_ShowLineAges(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	if output, err := exec.Command("git", "init", "-q", dir).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v\n%s", err, output)
+	}
+	leftPath := filepath.Join(dir, "left.txt")
+	commitFile(t, leftPath, "old\nshared\n", "2020-01-01T00:00:00Z")
+	commitFile(t, leftPath, "old\nshared\nnewer\n", "2024-01-01T00:00:00Z")
+	rightPath := filepath.Join(t.TempDir(), "right.txt")
+	if err := os.WriteFile(rightPath, []byte("old\nshared\nchanged\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if result.Lines[0].LeftTime != 0 {
+		t.Error("Expected no line ages while they are hidden")
+	}
+
+	if err := app.SetShowLineAges(true); err != nil {
+		t.Fatalf("SetShowLineAges returned error: %v", err)
+	}
+	// An unsaved edit is blamed as not yet committed
+	if err := app.CopyToFile("", leftPath, 4, "unsaved"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	result, err = app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+
+	times := make(map[string]int64)
+	for _, line := range result.Lines {
+		if line.LeftNumber > 0 {
+			times[line.LeftLine] = line.LeftTime
+		}
+		if line.RightTime != 0 {
+			t.Errorf("Expected no ages for a file outside git, got %+v", line)
+		}
+	}
+	if times["old"] != 1577836800 || times["newer"] != 1704067200 || times["unsaved"] != 0 {
+		t.Errorf("Unexpected line times: %v", times)
+	}
+}
@@ -0,0 +1,62 @@
+package backend
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ExportChangesCSV writes the changed lines of the current comparison to path
+// as CSV, one row per line with its type, line numbers, and text. Numbers are
+// left empty for the side a line is missing from.
+func (a *App) ExportChangesCSV(path string) error {
+	if path == "" {
+		return fmt.Errorf("export path cannot be empty")
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return err
+	}
+
+	data, err := changesCSV(current.Result)
+	if err != nil {
+		return fmt.Errorf("failed to encode changes: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write changes: %w", err)
+	}
+	return nil
+}
+
+// changesCSV lists every line of a result other than "same" lines as CSV
+func changesCSV(result *DiffResult) ([]byte, error) {
+	var builder strings.Builder
+	w := csv.NewWriter(&builder)
+	rows := [][]string{{"type", "left_number", "right_number", "left_text", "right_text"}}
+	for _, line := range result.Lines {
+		if line.Type == "same" {
+			continue
+		}
+		rows = append(rows, []string{
+			line.Type,
+			lineNumberField(line.LeftNumber),
+			lineNumberField(line.RightNumber),
+			line.LeftLine,
+			line.RightLine,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return []byte(builder.String()), nil
+}
+
+// lineNumberField formats a 1-based line number, or nothing for 0
+func lineNumberField(number int) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.Itoa(number)
+}
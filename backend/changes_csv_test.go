@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_ExportChangesCSV(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	if err := app.ExportChangesCSV(filepath.Join(t.TempDir(), "none.csv")); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+
+	left := []string{"keep", "the old value of the setting", "middle", "gone", "tail"}
+	right := []string{"keep", "the new value of the setting", "middle", "tail", "extra, with comma"}
+	compareTempFiles(t, app, left, right)
+
+	out := filepath.Join(t.TempDir(), "changes.csv")
+	if err := app.ExportChangesCSV(out); err != nil {
+		t.Fatalf("ExportChangesCSV returned error: %v", err)
+	}
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("Failed to read exported changes: %v", err)
+	}
+
+	expected := "type,left_number,right_number,left_text,right_text\n" +
+		"modified,2,2,the old value of the setting,the new value of the setting\n" +
+		"removed,4,,gone,\n" +
+		"added,,5,,\"extra, with comma\"\n"
+	if string(content) != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, string(content))
+	}
+
+	if err := app.ExportChangesCSV(""); err == nil {
+		t.Error("Expected error for empty path")
+	}
+}
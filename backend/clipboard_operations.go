@@ -0,0 +1,49 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+
+	"weld/backend/diff"
+)
+
+// CompareClipboardWithRange diffs the system clipboard against lines
+// startLine..endLine (1-based, inclusive) of a file in a transient comparison.
+// The clipboard is the left side; right line numbers refer to the file.
+func (a *App) CompareClipboardWithRange(path string, startLine, endLine int) (*DiffResult, error) {
+	text, err := a.runtime().ClipboardGetText()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clipboard: %w", err)
+	}
+
+	return a.compareTextWithRange(text, path, startLine, endLine)
+}
+
+// compareTextWithRange diffs text against a range of lines from a file
+func (a *App) compareTextWithRange(text, path string, startLine, endLine int) (*DiffResult, error) {
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if startLine < 1 || endLine < startLine || endLine > len(lines) {
+		return nil, fmt.Errorf("invalid line range %d-%d", startLine, endLine)
+	}
+
+	algorithm := a.algorithmFor(a.resolveCompareOptions(CompareOptions{}))
+	result := algorithm.ComputeDiff(splitTextLines(text), lines[startLine-1:endLine])
+	diff.OffsetLineNumbers(result, 0, startLine-1)
+	return result, nil
+}
+
+// splitTextLines splits text into lines, accepting any line ending and
+// ignoring a single trailing newline
+func splitTextLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return []string{}
+	}
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApp_CompareTextWithRange(t *testing.T) {
+
+	app := NewApp()
+	app.compare.SetEdited("pane.txt", []string{"zero", "one", "two", "three", "four"})
+
+	t.Run("identical selection", func(t *testing.T) {
+		result, err := app.compareTextWithRange("one\r\ntwo\r\n", "pane.txt", 2, 3)
+		if err != nil {
+			t.Fatalf("compareTextWithRange returned error: %v", err)
+		}
+		if len(result.Lines) != 2 {
+			t.Fatalf("Expected 2 lines, got %d", len(result.Lines))
+		}
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				t.Errorf("Expected same line, got %+v", line)
+			}
+		}
+		if result.Lines[0].LeftNumber != 1 || result.Lines[0].RightNumber != 2 {
+			t.Errorf("Expected clipboard line 1 to match file line 2, got %+v", result.Lines[0])
+		}
+	})
+
+	t.Run("different selection", func(t *testing.T) {
+		result, err := app.compareTextWithRange("one\nTWO", "pane.txt", 2, 4)
+		if err != nil {
+			t.Fatalf("compareTextWithRange returned error: %v", err)
+		}
+		hasChange := false
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				hasChange = true
+			}
+		}
+		if !hasChange {
+			t.Error("Expected differences between clipboard and selection")
+		}
+	})
+
+	t.Run("invalid range", func(t *testing.T) {
+		if _, err := app.compareTextWithRange("x", "pane.txt", 4, 2); err == nil {
+			t.Error("Expected error for reversed range")
+		}
+		if _, err := app.compareTextWithRange("x", "pane.txt", 1, 10); err == nil {
+			t.Error("Expected error for range past end of file")
+		}
+	})
+
+	t.Run("requires a window", func(t *testing.T) {
+		if _, err := app.CompareClipboardWithRange("pane.txt", 1, 1); err == nil {
+			t.Error("Expected error without a window")
+		}
+	})
+}
+
+func TestSplitTextLines(t *testing.T) {
+	tests := map[string][]string{
+		"":            {},
+		"a":           {"a"},
+		"a\n":         {"a"},
+		"a\r\nb\rc\n": {"a", "b", "c"},
+		"a\n\nb":      {"a", "", "b"},
+	}
+	for input, expected := range tests {
+		if got := splitTextLines(input); !reflect.DeepEqual(got, expected) {
+			t.Errorf("splitTextLines(%q) = %v, expected %v", input, got, expected)
+		}
+	}
+}
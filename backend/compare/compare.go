@@ -0,0 +1,198 @@
+// Package compare holds the state of the files being compared: the unsaved
+// edits made to them and the latest comparison of a pair
+package compare
+
+import (
+	"errors"
+	"sync"
+
+	"weld/backend/diff"
+)
+
+// ErrNotEdited is returned by Save for a file without unsaved lines
+var ErrNotEdited = errors.New("file has no unsaved lines")
+
+// Comparison is the latest comparison of two files, made with options of
+// type O
+type Comparison[O any] struct {
+	LeftPath  string
+	RightPath string
+	Options   O
+	// Result is nil once it was dropped to free memory, and is recomputed
+	// on next use
+	Result *diff.DiffResult
+	Hunks  []diff.Hunk
+}
+
+// State holds the unsaved edits of files and the latest comparison
+type State[O any] interface {
+	// Current returns the latest comparison, or nil when none was made
+	Current() *Comparison[O]
+	// SetCurrent replaces the latest comparison
+	SetCurrent(current *Comparison[O])
+	// ReplaceCurrent replaces the latest comparison with current only while
+	// it is still old, reporting whether it was replaced
+	ReplaceCurrent(old, current *Comparison[O]) bool
+
+	// Edited returns the unsaved lines of a file, if it has any
+	Edited(path string) ([]string, bool)
+	// SetEdited replaces the unsaved lines of a file
+	SetEdited(path string, lines []string)
+	// Edit replaces the lines of a file, its unsaved lines or else those
+	// read returns, with the lines edit returns. Edits run one at a time,
+	// so concurrent edits to one file are not lost.
+	Edit(path string, read func(path string) ([]string, error), edit func(lines []string) ([]string, error)) error
+	// Save passes the unsaved lines of a file to write and, once written,
+	// drops them. Edits wait until it is done, so none made while writing
+	// are lost. It returns ErrNotEdited for a file without unsaved lines.
+	Save(path string, write func(lines []string) error) error
+	// Discard drops the unsaved lines of a file
+	Discard(path string)
+	// DiscardAll drops the unsaved lines of every file
+	DiscardAll()
+	// EditedPaths returns the files with unsaved lines
+	EditedPaths() []string
+	// Compact releases the spare capacity that edits leave in unsaved lines
+	Compact()
+}
+
+// state is the State returned by New
+type state[O any] struct {
+	// currentMu guards current
+	currentMu sync.RWMutex
+	current   *Comparison[O]
+
+	// editsMu guards edits, and editMu serializes changes to them, so each
+	// Edit's read, change, and store of a file's lines, and each Save's
+	// write and drop, are atomic
+	editsMu sync.RWMutex
+	edits   map[string][]string
+	editMu  sync.Mutex
+}
+
+// New returns a State without edits or a comparison
+func New[O any]() State[O] {
+	return &state[O]{edits: make(map[string][]string)}
+}
+
+// Current returns the latest comparison
+func (s *state[O]) Current() *Comparison[O] {
+	s.currentMu.RLock()
+	defer s.currentMu.RUnlock()
+	return s.current
+}
+
+// SetCurrent replaces the latest comparison
+func (s *state[O]) SetCurrent(current *Comparison[O]) {
+	s.currentMu.Lock()
+	defer s.currentMu.Unlock()
+	s.current = current
+}
+
+// ReplaceCurrent replaces the latest comparison if it is still old
+func (s *state[O]) ReplaceCurrent(old, current *Comparison[O]) bool {
+	s.currentMu.Lock()
+	defer s.currentMu.Unlock()
+	if s.current != old {
+		return false
+	}
+	s.current = current
+	return true
+}
+
+// Edited returns the unsaved lines of a file
+func (s *state[O]) Edited(path string) ([]string, bool) {
+	s.editsMu.RLock()
+	defer s.editsMu.RUnlock()
+	lines, ok := s.edits[path]
+	return lines, ok
+}
+
+// SetEdited replaces the unsaved lines of a file
+func (s *state[O]) SetEdited(path string, lines []string) {
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+	s.setEditedLocked(path, lines)
+}
+
+// setEditedLocked replaces the unsaved lines of a file (must be called with
+// editMu held)
+func (s *state[O]) setEditedLocked(path string, lines []string) {
+	s.editsMu.Lock()
+	defer s.editsMu.Unlock()
+	s.edits[path] = lines
+}
+
+// Edit replaces the lines of a file with the lines edit returns
+func (s *state[O]) Edit(path string, read func(path string) ([]string, error), edit func(lines []string) ([]string, error)) error {
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+
+	lines, ok := s.Edited(path)
+	if !ok {
+		var err error
+		if lines, err = read(path); err != nil {
+			return err
+		}
+	}
+	edited, err := edit(lines)
+	if err != nil {
+		return err
+	}
+	s.setEditedLocked(path, edited)
+	return nil
+}
+
+// Save writes the unsaved lines of a file and drops them
+func (s *state[O]) Save(path string, write func(lines []string) error) error {
+	s.editMu.Lock()
+	defer s.editMu.Unlock()
+
+	lines, ok := s.Edited(path)
+	if !ok {
+		return ErrNotEdited
+	}
+	if err := write(lines); err != nil {
+		return err
+	}
+	s.Discard(path)
+	return nil
+}
+
+// Discard drops the unsaved lines of a file
+func (s *state[O]) Discard(path string) {
+	s.editsMu.Lock()
+	defer s.editsMu.Unlock()
+	delete(s.edits, path)
+}
+
+// DiscardAll drops the unsaved lines of every file
+func (s *state[O]) DiscardAll() {
+	s.editsMu.Lock()
+	defer s.editsMu.Unlock()
+	s.edits = make(map[string][]string)
+}
+
+// EditedPaths returns the files with unsaved lines
+func (s *state[O]) EditedPaths() []string {
+	s.editsMu.RLock()
+	defer s.editsMu.RUnlock()
+	paths := make([]string, 0, len(s.edits))
+	for path := range s.edits {
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// Compact releases the spare capacity of unsaved lines
+func (s *state[O]) Compact() {
+	s.editsMu.Lock()
+	defer s.editsMu.Unlock()
+	for path, lines := range s.edits {
+		if cap(lines) > len(lines) {
+			compacted := make([]string, len(lines))
+			copy(compacted, lines)
+			s.edits[path] = compacted
+		}
+	}
+}
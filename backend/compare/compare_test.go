@@ -0,0 +1,159 @@
+package compare
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type testOptions struct {
+	IgnoreCase bool
+}
+
+func TestState_Current(t *testing.T) {
+	s := New[testOptions]()
+	if s.Current() != nil {
+		t.Fatal("Expected no comparison")
+	}
+
+	first := &Comparison[testOptions]{LeftPath: "a", RightPath: "b", Options: testOptions{IgnoreCase: true}}
+	s.SetCurrent(first)
+	if got := s.Current(); got != first || !got.Options.IgnoreCase {
+		t.Errorf("Expected the comparison that was set, got %+v", got)
+	}
+
+	second := &Comparison[testOptions]{LeftPath: "c", RightPath: "d"}
+	if s.ReplaceCurrent(second, &Comparison[testOptions]{}) {
+		t.Error("Expected a comparison that is not current not to be replaced")
+	}
+	if !s.ReplaceCurrent(first, second) || s.Current() != second {
+		t.Errorf("Expected the current comparison to be replaced, got %+v", s.Current())
+	}
+}
+
+func TestState_Edits(t *testing.T) {
+	readDisk := func(path string) ([]string, error) { return []string{"disk"}, nil }
+
+	t.Run("edits start from the file and then from the unsaved lines", func(t *testing.T) {
+		s := New[testOptions]()
+		appendLine := func(lines []string) ([]string, error) { return append(lines, "edit"), nil }
+		if err := s.Edit("a.txt", readDisk, appendLine); err != nil {
+			t.Fatalf("Edit returned error: %v", err)
+		}
+		if err := s.Edit("a.txt", readDisk, appendLine); err != nil {
+			t.Fatalf("Edit returned error: %v", err)
+		}
+		if lines, ok := s.Edited("a.txt"); !ok || !reflect.DeepEqual(lines, []string{"disk", "edit", "edit"}) {
+			t.Errorf("Unexpected unsaved lines %v", lines)
+		}
+	})
+
+	t.Run("failed edits change nothing", func(t *testing.T) {
+		s := New[testOptions]()
+		failure := errors.New("failed")
+		if err := s.Edit("a.txt", readDisk, func([]string) ([]string, error) { return nil, failure }); err != failure {
+			t.Errorf("Expected the edit's error, got %v", err)
+		}
+		if err := s.Edit("b.txt", func(string) ([]string, error) { return nil, failure }, nil); err != failure {
+			t.Errorf("Expected the read error, got %v", err)
+		}
+		if paths := s.EditedPaths(); len(paths) != 0 {
+			t.Errorf("Expected no unsaved files, got %v", paths)
+		}
+	})
+
+	t.Run("concurrent edits are not lost", func(t *testing.T) {
+		s := New[testOptions]()
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				s.Edit("a.txt", readDisk, func(lines []string) ([]string, error) {
+					return append(append([]string{}, lines...), "edit"), nil
+				})
+			}()
+		}
+		wg.Wait()
+		if lines, _ := s.Edited("a.txt"); len(lines) != 51 {
+			t.Errorf("Expected every edit to be kept, got %d lines", len(lines))
+		}
+	})
+
+	t.Run("save drops the written lines", func(t *testing.T) {
+		s := New[testOptions]()
+		if err := s.Save("a.txt", nil); err != ErrNotEdited {
+			t.Errorf("Expected ErrNotEdited, got %v", err)
+		}
+
+		failure := errors.New("failed")
+		s.SetEdited("a.txt", []string{"a"})
+		if err := s.Save("a.txt", func([]string) error { return failure }); err != failure {
+			t.Errorf("Expected the write error, got %v", err)
+		}
+		var written []string
+		if err := s.Save("a.txt", func(lines []string) error { written = lines; return nil }); err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+		if !reflect.DeepEqual(written, []string{"a"}) {
+			t.Errorf("Expected the unsaved lines to be written, got %v", written)
+		}
+		if _, ok := s.Edited("a.txt"); ok {
+			t.Error("Expected the written lines to be dropped")
+		}
+	})
+
+	t.Run("edits made while saving are kept", func(t *testing.T) {
+		s := New[testOptions]()
+		s.SetEdited("a.txt", []string{"a"})
+		writing := make(chan struct{})
+		edited := make(chan error)
+		err := s.Save("a.txt", func([]string) error {
+			close(writing)
+			go func() {
+				edited <- s.Edit("a.txt", readDisk, func(lines []string) ([]string, error) {
+					return append(lines, "edit"), nil
+				})
+			}()
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Save returned error: %v", err)
+		}
+		<-writing
+		if err := <-edited; err != nil {
+			t.Fatalf("Edit returned error: %v", err)
+		}
+		if lines, ok := s.Edited("a.txt"); !ok || !reflect.DeepEqual(lines, []string{"disk", "edit"}) {
+			t.Errorf("Expected the edit to be kept after the save, got %v", lines)
+		}
+	})
+
+	t.Run("discard", func(t *testing.T) {
+		s := New[testOptions]()
+		s.SetEdited("a.txt", []string{"a"})
+		s.SetEdited("b.txt", []string{"b"})
+		s.Discard("a.txt")
+		if paths := s.EditedPaths(); !reflect.DeepEqual(paths, []string{"b.txt"}) {
+			t.Errorf("Expected only b.txt to be unsaved, got %v", paths)
+		}
+		s.DiscardAll()
+		if paths := s.EditedPaths(); len(paths) != 0 {
+			t.Errorf("Expected no unsaved files, got %v", paths)
+		}
+	})
+
+	t.Run("compact keeps the lines", func(t *testing.T) {
+		s := New[testOptions]()
+		lines := make([]string, 1, 100)
+		lines[0] = "a"
+		s.SetEdited("a.txt", lines)
+		s.Compact()
+		if compacted, _ := s.Edited("a.txt"); cap(compacted) != 1 || compacted[0] != "a" {
+			t.Errorf("Expected the lines without spare capacity, got %v (cap %d)", compacted, cap(compacted))
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// Stages of a comparison reported by "diff-progress" events
+const (
+	CompareStageReading   = "reading"
+	CompareStageComparing = "comparing"
+	CompareStageFinishing = "finishing"
+)
+
+// compareStagePercent is the rough share of the work done when each stage starts
+var compareStagePercent = map[string]int{
+	CompareStageReading:   0,
+	CompareStageComparing: 20,
+	CompareStageFinishing: 90,
+}
+
+// CompareFilesAsync starts comparing two files in the background and returns
+// an ID identifying the comparison in the events it emits: "diff-progress" as
+// each stage starts, then "diff-complete" with the result, "diff-error", or
+// "diff-cancelled". Starting another comparison cancels this one.
+func (a *App) CompareFilesAsync(leftPath, rightPath string, options CompareOptions) (int, error) {
+	a.RecordActivity()
+	if !diff.IsWhitespaceMode(options.Whitespace) {
+		return 0, fmt.Errorf("unknown whitespace mode: %s", options.Whitespace)
+	}
+	options = a.resolveCompareOptions(options)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.asyncCompareMutex.Lock()
+	if a.asyncCompareCancel != nil {
+		a.asyncCompareCancel()
+	}
+	a.asyncCompareID++
+	id := a.asyncCompareID
+	a.asyncCompareCancel = cancel
+	a.asyncCompareMutex.Unlock()
+
+	go a.runAsyncCompare(ctx, id, leftPath, rightPath, options)
+	return id, nil
+}
+
+// CancelCompare cancels the comparison running in the background, if any
+func (a *App) CancelCompare() {
+	a.asyncCompareMutex.Lock()
+	defer a.asyncCompareMutex.Unlock()
+	if a.asyncCompareCancel != nil {
+		a.asyncCompareCancel()
+		a.asyncCompareCancel = nil
+	}
+}
+
+// runAsyncCompare computes a background comparison and, unless it was
+// cancelled meanwhile, makes it the current comparison
+func (a *App) runAsyncCompare(ctx context.Context, id int, leftPath, rightPath string, options CompareOptions) {
+	result, err := a.computeDiffContext(ctx, leftPath, rightPath, options, func(stage string) {
+		a.emitCompareEvent("diff-progress", map[string]interface{}{
+			"id":      id,
+			"stage":   stage,
+			"percent": compareStagePercent[stage],
+		})
+	})
+
+	// Holding the lock keeps a newer comparison from starting in between the
+	// check and taking over as the current comparison
+	a.asyncCompareMutex.Lock()
+	defer a.asyncCompareMutex.Unlock()
+	if ctx.Err() != nil {
+		a.emitCompareEvent("diff-cancelled", map[string]interface{}{"id": id})
+		return
+	}
+	a.asyncCompareCancel = nil
+
+	if err != nil {
+		a.emitCompareEvent("diff-error", map[string]interface{}{
+			"id":        id,
+			"leftPath":  leftPath,
+			"rightPath": rightPath,
+			"error":     err.Error(),
+		})
+		return
+	}
+	a.emitCompareEvent("diff-complete", map[string]interface{}{
+		"id":        id,
+		"leftPath":  leftPath,
+		"rightPath": rightPath,
+		"result":    a.startComparison(leftPath, rightPath, options, result),
+	})
+}
+
+// emitCompareEvent emits an event about a background comparison once the
+// window exists
+func (a *App) emitCompareEvent(name string, data map[string]interface{}) {
+	a.runtime().EventsEmit(name, data)
+}
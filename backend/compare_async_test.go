@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_CompareFilesAsync(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	paths := map[string]string{}
+	for name, content := range map[string]string{"a": "one\ntwo\n", "b": "one\nthree\n", "c": "x\n", "d": "y\n"} {
+		paths[name] = filepath.Join(dir, name+".txt")
+		if err := os.WriteFile(paths[name], []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	first, err := app.CompareFilesAsync(paths["a"], paths["b"], CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesAsync returned error: %v", err)
+	}
+	second, err := app.CompareFilesAsync(paths["c"], paths["d"], CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesAsync returned error: %v", err)
+	}
+	if second <= first {
+		t.Errorf("Expected increasing IDs, got %d then %d", first, second)
+	}
+
+	// Only the latest comparison becomes current
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		current := app.compare.Current()
+		if current != nil && current.LeftPath == paths["c"] {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the second comparison to become current, got %+v", current)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if _, err := app.CompareFilesAsync(paths["a"], paths["b"], CompareOptions{Whitespace: "sideways"}); err == nil {
+		t.Error("Expected error for an unknown whitespace mode")
+	}
+}
+
+func TestApp_ComputeDiffContext_Cancelled(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	for _, path := range []string{leftPath, rightPath} {
+		if err := os.WriteFile(path, []byte("line\n"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var stages []string
+	_, err := app.computeDiffContext(ctx, leftPath, rightPath, CompareOptions{}, func(stage string) {
+		stages = append(stages, stage)
+		if stage == CompareStageComparing {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("Expected the comparison to stop when cancelled, got %v", err)
+	}
+	if len(stages) != 2 || stages[0] != CompareStageReading {
+		t.Errorf("Expected to stop after the comparing stage, got %v", stages)
+	}
+}
+
+func TestApp_CompareFiles_CancelsAsync(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	app.asyncCompareMutex.Lock()
+	app.asyncCompareCancel = cancel
+	app.asyncCompareMutex.Unlock()
+
+	compareTempFiles(t, app, []string{"one"}, []string{"two"})
+	if ctx.Err() == nil {
+		t.Error("Expected a synchronous comparison to cancel the one in the background")
+	}
+}
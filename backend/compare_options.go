@@ -0,0 +1,139 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"weld/backend/diff"
+)
+
+// maxTabWidth is the largest accepted tab width
+const maxTabWidth = 16
+
+// CompareOptions overrides settings for a single comparison. Zero values use
+// the corresponding setting.
+type CompareOptions struct {
+	TabWidth int `json:"tabWidth"`
+	// Whitespace selects which whitespace differences to ignore, one of the
+	// diff.Whitespace modes. Empty compares whitespace exactly.
+	Whitespace string `json:"whitespace"`
+	// IgnoreCase treats lines differing only in letter case as the same
+	IgnoreCase bool `json:"ignoreCase"`
+	// IgnoreBlankLines hides changes that only add or remove blank lines
+	IgnoreBlankLines bool `json:"ignoreBlankLines"`
+	// NormalizeUnicode matches lines that differ only in how accented or
+	// combined characters are encoded
+	NormalizeUnicode bool `json:"normalizeUnicode"`
+	// Semantic aligns Go declarations by name, so moved functions are not
+	// shown as removed and added, JSON and YAML values by key, so reordered
+	// keys and reformatting are not shown at all, XML elements with
+	// attribute order and formatting normalized, and INI and TOML keys by
+	// section and name. Other files are compared line by line.
+	Semantic bool `json:"semantic"`
+	// IgnoreComments leaves comments out of semantic YAML, XML, INI, and
+	// TOML comparisons
+	IgnoreComments bool `json:"ignoreComments"`
+	// KeyColumns, given as 0-based column indexes, compares CSV and TSV
+	// files record by record, matching rows by the values of these columns
+	// instead of by position
+	KeyColumns []int `json:"keyColumns"`
+	// Prose compares files as paragraphs of text, such as Markdown, with
+	// hard line wraps undone, so re-flowed paragraphs are not shown as
+	// changed and edited ones show only the sentences that changed
+	Prose bool `json:"prose"`
+}
+
+// Formats semanticFormat reports besides diff.LanguageGo
+const (
+	semanticJSON   = "json"
+	semanticYAML   = "yaml"
+	semanticXML    = "xml"
+	semanticConfig = "config"
+	semanticCSV    = "csv"
+	semanticProse  = "prose"
+)
+
+// semanticFormat returns the format both files are compared structurally
+// as, diff.LanguageGo, semanticJSON, semanticYAML, semanticXML,
+// semanticConfig, semanticCSV, or semanticProse, or an empty string when
+// they are compared line by line. CSV files are compared by record whenever
+// key columns are given, and any files as prose in prose mode, in semantic
+// mode or not.
+func semanticFormat(options CompareOptions, leftPath, rightPath string) string {
+	switch {
+	case len(options.KeyColumns) > 0 && diff.CSVDelimiter(leftPath) != 0 && diff.CSVDelimiter(leftPath) == diff.CSVDelimiter(rightPath):
+		return semanticCSV
+	case options.Prose:
+		return semanticProse
+	case !options.Semantic:
+		return ""
+	case diff.LanguageForPath(leftPath) == diff.LanguageGo && diff.LanguageForPath(rightPath) == diff.LanguageGo:
+		return diff.LanguageGo
+	case diff.IsJSONPath(leftPath) && diff.IsJSONPath(rightPath):
+		return semanticJSON
+	case diff.IsYAMLPath(leftPath) && diff.IsYAMLPath(rightPath):
+		return semanticYAML
+	case diff.IsXMLPath(leftPath) && diff.IsXMLPath(rightPath):
+		return semanticXML
+	case diff.IsConfigPath(leftPath) && diff.IsConfigPath(rightPath):
+		return semanticConfig
+	}
+	return ""
+}
+
+// resolveCompareOptions fills unset options from the settings
+func (a *App) resolveCompareOptions(options CompareOptions) CompareOptions {
+	if options.TabWidth <= 0 {
+		options.TabWidth = a.GetTabWidth()
+	}
+	return options
+}
+
+// algorithmFor returns the diff algorithm configured for a comparison
+func (a *App) algorithmFor(options CompareOptions) diff.Algorithm {
+	configurable, ok := a.diffAlgorithm.(diff.Configurable)
+	if !ok {
+		return a.diffAlgorithm
+	}
+	config := configurable.Config()
+	config.TabWidth = options.TabWidth
+	config.Whitespace = options.Whitespace
+	config.IgnoreCase = options.IgnoreCase
+	config.IgnoreBlankLines = options.IgnoreBlankLines
+	config.NormalizeUnicode = options.NormalizeUnicode
+	config.IgnorePatterns = a.ignorePatterns()
+	config.Algorithm = a.GetDiffAlgorithm()
+	config.TimeBudget = time.Duration(a.GetDiffTimeBudget()) * time.Millisecond
+	return configurable.WithConfig(config)
+}
+
+// comparisonOptions returns the options of the current comparison when it is
+// of the given pair, so re-diffs keep any per-comparison overrides
+func (a *App) comparisonOptions(leftPath, rightPath string) CompareOptions {
+	current := a.compare.Current()
+	if current != nil && current.LeftPath == leftPath && current.RightPath == rightPath {
+		return current.Options
+	}
+	return CompareOptions{}
+}
+
+// GetTabWidth returns the number of columns a tab advances to
+func (a *App) GetTabWidth() int {
+	width := a.settings.Get().TabWidth
+	if width <= 0 {
+		return diff.DefaultConfig().TabWidth
+	}
+	return width
+}
+
+// SetTabWidth sets the number of columns a tab advances to, used for
+// similarity scoring, whitespace-insensitive comparison, and column guides
+func (a *App) SetTabWidth(width int) error {
+	if width < 1 || width > maxTabWidth {
+		return fmt.Errorf("tab width must be between 1 and %d", maxTabWidth)
+	}
+	return a.settings.Update(func(s *Settings) error {
+		s.TabWidth = width
+		return nil
+	})
+}
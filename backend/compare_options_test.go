@@ -0,0 +1,357 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"weld/backend/diff"
+)
+
+func TestApp_CompareFilesWithOptions_TabWidth(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"\t\t\tvalue := compute(a)"}
+	right := []string{"            value := compute(b)"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	t.Run("default tab width", func(t *testing.T) {
+		result, err := app.CompareFiles(leftPath, rightPath)
+		if err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+		if len(result.Lines) != 1 || result.Lines[0].Type != "modified" {
+			t.Errorf("Expected a modified line, got %+v", result.Lines)
+		}
+	})
+
+	t.Run("per-comparison override", func(t *testing.T) {
+		result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{TabWidth: 8})
+		if err != nil {
+			t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+		}
+		if len(result.Lines) != 2 {
+			t.Errorf("Expected a removed and added line, got %+v", result.Lines)
+		}
+		if options := app.comparisonOptions(leftPath, rightPath); options.TabWidth != 8 {
+			t.Errorf("Expected re-diffs to keep tab width 8, got %d", options.TabWidth)
+		}
+	})
+
+	t.Run("setting", func(t *testing.T) {
+		if err := app.SetTabWidth(0); err == nil {
+			t.Error("Expected error for tab width 0")
+		}
+		if err := app.SetTabWidth(8); err != nil {
+			t.Fatalf("SetTabWidth returned error: %v", err)
+		}
+		if app.GetTabWidth() != 8 {
+			t.Errorf("Expected tab width 8, got %d", app.GetTabWidth())
+		}
+		result, err := app.CompareFiles(leftPath, rightPath)
+		if err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+		if len(result.Lines) != 2 {
+			t.Errorf("Expected the setting to apply, got %+v", result.Lines)
+		}
+	})
+}
+
+func TestApp_CompareFilesWithOptions_Whitespace(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"if (ready) {", "  start();", "}"}
+	right := []string{"if (ready) {", "    start();  ", "}"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Whitespace: diff.WhitespaceIgnoreLeadingTrailing})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			t.Errorf("Expected reindentation to be hidden, got %+v", line)
+		}
+	}
+	if options := app.comparisonOptions(leftPath, rightPath); options.Whitespace != diff.WhitespaceIgnoreLeadingTrailing {
+		t.Errorf("Expected re-diffs to keep the whitespace mode, got %q", options.Whitespace)
+	}
+
+	if _, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Whitespace: "tabs"}); err == nil {
+		t.Error("Expected error for an unknown whitespace mode")
+	}
+}
+
+func TestApp_CompareFilesWithOptions_IgnoreCase(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	leftPath, rightPath := compareTempFiles(t, app, []string{"SELECT 1;"}, []string{"select 1;"})
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{IgnoreCase: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Lines) != 1 || result.Lines[0].Type != "same" {
+		t.Errorf("Expected the lines to match ignoring case, got %+v", result.Lines)
+	}
+	if !app.comparisonOptions(leftPath, rightPath).IgnoreCase {
+		t.Error("Expected re-diffs to keep ignoring case")
+	}
+}
+
+func TestApp_CompareOptions_FollowUpDiffs(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	leftPath, rightPath := compareTempFiles(t, app, []string{"SELECT 1;", "SELECT 2;"}, []string{"select 2;", "select 1;"})
+	if _, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{IgnoreCase: true}); err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+
+	allSame := func(t *testing.T, result *DiffResult, err error) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				t.Errorf("Expected the comparison's options to apply, got %+v", result.Lines)
+				return
+			}
+		}
+	}
+
+	t.Run("sorted", func(t *testing.T) {
+		result, err := app.CompareFilesSorted(leftPath, rightPath)
+		allSame(t, result, err)
+	})
+	t.Run("range", func(t *testing.T) {
+		result, err := app.CompareRange(leftPath, rightPath, 1, 1, 2, 2)
+		allSame(t, result, err)
+	})
+	t.Run("ignore patterns", func(t *testing.T) {
+		if err := app.SetIgnorePatterns([]string{`^select`}); err != nil {
+			t.Fatalf("SetIgnorePatterns returned error: %v", err)
+		}
+		result, err := app.compareTextWithRange("select 3;", rightPath, 1, 1)
+		allSame(t, result, err)
+	})
+}
+
+func TestApp_CompareFilesWithOptions_IgnoreBlankLines(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	leftPath, rightPath := compareTempFiles(t, app, []string{"one", "", "two"}, []string{"one", "two", ""})
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{IgnoreBlankLines: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			t.Errorf("Expected blank-line changes to be hidden, got %+v", line)
+		}
+	}
+	hunks, err := app.GetHunks()
+	if err != nil || len(hunks) != 0 {
+		t.Errorf("Expected no hunks, got %+v (%v)", hunks, err)
+	}
+}
+
+func TestApp_CompareFilesWithOptions_Semantic(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.go")
+	rightPath := filepath.Join(dir, "right.go")
+	left := "package main\n\nfunc a() {\n\tprintln(1)\n}\n\nfunc b() {\n\tprintln(2)\n}\n"
+	right := "package main\n\nfunc b() {\n\tprintln(2)\n}\n\nfunc a() {\n\tprintln(1)\n}\n"
+	if err := os.WriteFile(leftPath, []byte(left), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte(right), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			t.Errorf("Expected reordered functions to match, got %+v", line)
+		}
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) == 0 {
+		t.Error("Expected a line diff to report the move")
+	}
+}
+
+func TestApp_CompareFilesWithOptions_SemanticJSON(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.json")
+	rightPath := filepath.Join(dir, "right.json")
+	if err := os.WriteFile(leftPath, []byte("{\n  \"a\": 1,\n  \"b\": [1, 2]\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("{\n  \"b\": [\n    1,\n    2\n  ],\n  \"a\": 1\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 0 {
+		t.Errorf("Expected reordered and reformatted JSON to match, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) == 0 {
+		t.Error("Expected a line diff to report the reformatting")
+	}
+}
+
+func TestApp_CompareFilesWithOptions_SemanticYAML(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.yaml")
+	rightPath := filepath.Join(dir, "right.yml")
+	if err := os.WriteFile(leftPath, []byte("a: 1 # one\nb:\n  - x\n  - y\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("b:\n- x\n- y\na: 1 # first\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true, IgnoreComments: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 0 {
+		t.Errorf("Expected reordered and reindented YAML to match, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 1 {
+		t.Errorf("Expected only the changed comment to differ, got %+v", result.Lines)
+	}
+}
+
+func TestApp_CompareFilesWithOptions_SemanticXML(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.xml")
+	rightPath := filepath.Join(dir, "right.xml")
+	if err := os.WriteFile(leftPath, []byte("<config debug=\"false\" level=\"2\">\n  <!-- paths -->\n  <path/>\n</config>\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("<config level='2' debug='false'>\n    <!-- search paths -->\n    <path></path>\n</config>\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true, IgnoreComments: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 0 {
+		t.Errorf("Expected equivalent XML to match, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 1 {
+		t.Errorf("Expected only the changed comment to differ, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) == 0 {
+		t.Error("Expected a line comparison outside semantic mode")
+	}
+}
+
+func TestApp_CompareFilesWithOptions_SemanticConfig(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "production.ini")
+	rightPath := filepath.Join(dir, "staging.ini")
+	if err := os.WriteFile(leftPath, []byte("[server]\nhost = example.com\nport = 443\n\n[cache]\nttl = 60\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("[cache]\nttl = 60\n\n[server]\nport = 443\nhost = staging.example.com\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Semantic: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 1 {
+		t.Errorf("Expected only the host to differ, got %+v", result.Lines)
+	}
+	if result.Keys == nil || *result.Keys != (diff.RecordSummary{Changed: 1, Unchanged: 2}) {
+		t.Errorf("Expected one changed key, got %+v", result.Keys)
+	}
+}
+
+func TestApp_CompareFilesWithOptions_Prose(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.md")
+	rightPath := filepath.Join(dir, "right.md")
+	if err := os.WriteFile(leftPath, []byte("# Notes\n\nThe first sentence is wrapped\nacross two lines. The second is not.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("# Notes\n\nThe first sentence is wrapped across two lines.\nThe second is not.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{Prose: true})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) != 0 {
+		t.Errorf("Expected the re-flowed paragraph to match, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if len(result.Chunks) == 0 {
+		t.Error("Expected a line comparison outside prose mode")
+	}
+}
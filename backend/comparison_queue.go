@@ -0,0 +1,192 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ComparisonPair is a pair of files waiting to be compared
+type ComparisonPair struct {
+	LeftFile  string `json:"leftFile"`
+	RightFile string `json:"rightFile"`
+}
+
+// ComparisonQueue is the list of pairs to work through and the one being compared
+type ComparisonQueue struct {
+	Pairs   []ComparisonPair `json:"pairs"`
+	Current int              `json:"current"` // index into Pairs, or -1 before the first comparison
+}
+
+// LoadPairsManifest reads the pairs to compare from a manifest file, or from
+// stdin when path is "-". Files ending in .json hold a JSON array of
+// {"left": ..., "right": ...} objects or [left, right] arrays; anything else is
+// read as CSV with one left,right pair per row. Relative paths are resolved
+// against the manifest's directory.
+func LoadPairsManifest(path string) ([]ComparisonPair, error) {
+	var data []byte
+	var err error
+	baseDir := ""
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+		baseDir, _ = os.Getwd()
+	} else {
+		data, err = os.ReadFile(path)
+		baseDir = filepath.Dir(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pairs manifest: %w", err)
+	}
+
+	isJSON := strings.EqualFold(filepath.Ext(path), ".json")
+	if path == "-" {
+		// Stdin has no extension, so go by the first character
+		isJSON = bytes.HasPrefix(bytes.TrimSpace(data), []byte("["))
+	}
+
+	var pairs []ComparisonPair
+	if isJSON {
+		pairs, err = parseJSONPairs(data)
+	} else {
+		pairs, err = parseCSVPairs(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("pairs manifest lists no pairs")
+	}
+
+	for i := range pairs {
+		pairs[i].LeftFile = resolveManifestPath(baseDir, pairs[i].LeftFile)
+		pairs[i].RightFile = resolveManifestPath(baseDir, pairs[i].RightFile)
+	}
+	return pairs, nil
+}
+
+// parseCSVPairs reads left,right rows, skipping blank lines, # comments, and
+// an optional left,right header
+func parseCSVPairs(data []byte) ([]ComparisonPair, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	pairs := []ComparisonPair{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid pairs manifest: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if len(record) != 2 {
+			return nil, fmt.Errorf("invalid pairs manifest: line %d has %d fields, expected left,right", line, len(record))
+		}
+		left, right := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if len(pairs) == 0 && strings.EqualFold(left, "left") && strings.EqualFold(right, "right") {
+			continue
+		}
+		if left == "" || right == "" {
+			return nil, fmt.Errorf("invalid pairs manifest: line %d has an empty path", line)
+		}
+		pairs = append(pairs, ComparisonPair{LeftFile: left, RightFile: right})
+	}
+	return pairs, nil
+}
+
+// parseJSONPairs reads an array whose entries are {"left", "right"} objects
+// or two-element arrays
+func parseJSONPairs(data []byte) ([]ComparisonPair, error) {
+	var entries []json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid pairs manifest: %w", err)
+	}
+
+	pairs := make([]ComparisonPair, 0, len(entries))
+	for i, entry := range entries {
+		var object struct {
+			Left  string `json:"left"`
+			Right string `json:"right"`
+		}
+		var array []string
+		switch {
+		case json.Unmarshal(entry, &object) == nil:
+		case json.Unmarshal(entry, &array) == nil && len(array) == 2:
+			object.Left, object.Right = array[0], array[1]
+		default:
+			return nil, fmt.Errorf("invalid pairs manifest: entry %d is not a left,right pair", i+1)
+		}
+		if object.Left == "" || object.Right == "" {
+			return nil, fmt.Errorf("invalid pairs manifest: entry %d has an empty path", i+1)
+		}
+		pairs = append(pairs, ComparisonPair{LeftFile: object.Left, RightFile: object.Right})
+	}
+	return pairs, nil
+}
+
+// resolveManifestPath makes a manifest path absolute relative to the manifest
+func resolveManifestPath(baseDir, path string) string {
+	if path == "" || IsVirtualPath(path) {
+		return path
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(baseDir, path)
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// GetComparisonQueue returns the queued pairs and the one being compared
+func (a *App) GetComparisonQueue() ComparisonQueue {
+	a.queueMutex.Lock()
+	defer a.queueMutex.Unlock()
+	current := a.queueIndex
+	if len(a.queue) == 0 {
+		current = -1
+	}
+	return ComparisonQueue{
+		Pairs:   append([]ComparisonPair{}, a.queue...),
+		Current: current,
+	}
+}
+
+// CompareQueuedPair compares the pair at the given index of the queue and
+// makes it the current one
+func (a *App) CompareQueuedPair(index int) (*DiffResult, error) {
+	a.queueMutex.Lock()
+	if index < 0 || index >= len(a.queue) {
+		a.queueMutex.Unlock()
+		return nil, fmt.Errorf("no queued comparison at index %d", index)
+	}
+	pair := a.queue[index]
+	a.queueMutex.Unlock()
+
+	result, err := a.CompareFiles(pair.LeftFile, pair.RightFile)
+	if err != nil {
+		return nil, err
+	}
+
+	a.queueMutex.Lock()
+	a.queueIndex = index
+	a.queueMutex.Unlock()
+	return result, nil
+}
+
+// setComparisonQueue replaces the queue with the given pairs
+func (a *App) setComparisonQueue(pairs []ComparisonPair) {
+	a.queueMutex.Lock()
+	defer a.queueMutex.Unlock()
+	a.queue = append([]ComparisonPair{}, pairs...)
+	a.queueIndex = -1
+}
@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadPairsManifest(t *testing.T) {
+	dir := t.TempDir()
+	abs := func(name string) string { return filepath.Join(dir, name) }
+
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected []ComparisonPair
+		wantErr  bool
+	}{
+		{
+			name:    "csv with header and comments",
+			file:    "pairs.csv",
+			content: "left,right\n# flagged by lint\na.txt, b.txt\n\n/abs/c.txt,d.txt\n",
+			expected: []ComparisonPair{
+				{LeftFile: abs("a.txt"), RightFile: abs("b.txt")},
+				{LeftFile: "/abs/c.txt", RightFile: abs("d.txt")},
+			},
+		},
+		{
+			name:    "json objects and arrays",
+			file:    "pairs.json",
+			content: `[{"left": "a.txt", "right": "b.txt"}, ["c.txt", "d.txt"]]`,
+			expected: []ComparisonPair{
+				{LeftFile: abs("a.txt"), RightFile: abs("b.txt")},
+				{LeftFile: abs("c.txt"), RightFile: abs("d.txt")},
+			},
+		},
+		{name: "csv row with one path", file: "one.csv", content: "a.txt\n", wantErr: true},
+		{name: "csv row with empty path", file: "empty.csv", content: "a.txt,\n", wantErr: true},
+		{name: "json entry that is not a pair", file: "bad.json", content: `[["a.txt"]]`, wantErr: true},
+		{name: "no pairs", file: "none.csv", content: "left,right\n", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := abs(tt.file)
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("Failed to write manifest: %v", err)
+			}
+			pairs, err := LoadPairsManifest(path)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got %v", pairs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadPairsManifest returned error: %v", err)
+			}
+			if !reflect.DeepEqual(pairs, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, pairs)
+			}
+		})
+	}
+
+	if _, err := LoadPairsManifest(abs("missing.csv")); err == nil {
+		t.Error("Expected error for a missing manifest")
+	}
+}
+
+func TestApp_ComparisonQueue(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{"a1.txt": "a\n", "a2.txt": "b\n", "b1.txt": "c\n", "b2.txt": "c\n"}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+	pairs := []ComparisonPair{
+		{LeftFile: filepath.Join(dir, "a1.txt"), RightFile: filepath.Join(dir, "a2.txt")},
+		{LeftFile: filepath.Join(dir, "b1.txt"), RightFile: filepath.Join(dir, "b2.txt")},
+	}
+	app.setComparisonQueue(pairs)
+
+	if queue := app.GetComparisonQueue(); queue.Current != -1 || len(queue.Pairs) != 2 {
+		t.Errorf("Expected two pairs and no current pair, got %+v", queue)
+	}
+
+	t.Run("initial files come from the queue", func(t *testing.T) {
+		initial := app.GetInitialFiles()
+		if initial.LeftFile != pairs[0].LeftFile || initial.RightFile != pairs[0].RightFile {
+			t.Errorf("Expected the first pair, got %+v", initial)
+		}
+		if current := app.GetComparisonQueue().Current; current != 0 {
+			t.Errorf("Expected current pair 0, got %d", current)
+		}
+	})
+
+	t.Run("compare a queued pair", func(t *testing.T) {
+		result, err := app.CompareQueuedPair(1)
+		if err != nil {
+			t.Fatalf("CompareQueuedPair returned error: %v", err)
+		}
+		if len(result.Lines) != 1 || result.Lines[0].Type != "same" {
+			t.Errorf("Expected identical files, got %+v", result.Lines)
+		}
+		if current := app.GetComparisonQueue().Current; current != 1 {
+			t.Errorf("Expected current pair 1, got %d", current)
+		}
+	})
+
+	t.Run("index out of range", func(t *testing.T) {
+		if _, err := app.CompareQueuedPair(2); err == nil {
+			t.Error("Expected error for an index past the end")
+		}
+	})
+}
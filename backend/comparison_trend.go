@@ -0,0 +1,156 @@
+package backend
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// trendsKeyPrefix prefixes the storage keys of per-pair statistics
+const trendsKeyPrefix = "trends/"
+
+// maxComparisonRuns caps how many runs are kept for each pair
+const maxComparisonRuns = 365
+
+// Trend directions
+const (
+	TrendGrowing   = "growing"
+	TrendShrinking = "shrinking"
+	TrendStable    = "stable"
+)
+
+// ComparisonRun records the change counts of one comparison of a pair
+type ComparisonRun struct {
+	ComparedAt time.Time `json:"comparedAt"`
+	Added      int       `json:"added"`
+	Removed    int       `json:"removed"`
+	Modified   int       `json:"modified"`
+	Changes    int       `json:"changes"`
+}
+
+// ComparisonTrend summarizes how the differences between a pair develop over time
+type ComparisonTrend struct {
+	LeftFile  string          `json:"leftFile"`
+	RightFile string          `json:"rightFile"`
+	Runs      []ComparisonRun `json:"runs"`      // oldest first
+	Direction string          `json:"direction"` // "growing", "shrinking", "stable", or "" with fewer than two runs
+	Delta     int             `json:"delta"`     // change count of the latest run minus the earliest
+}
+
+// trendFile is the on-disk format of a pair's statistics
+type trendFile struct {
+	LeftFile  string          `json:"leftFile"`
+	RightFile string          `json:"rightFile"`
+	Runs      []ComparisonRun `json:"runs"`
+}
+
+// trendKey identifies a compared pair
+func trendKey(leftPath, rightPath string) string {
+	sum := sha256.Sum256([]byte(leftPath + "\x00" + rightPath))
+	return hex.EncodeToString(sum[:8])
+}
+
+// comparisonRunsLocked returns the recorded runs of a pair, loading them from
+// storage on first use (must be called with trendMutex held)
+func (a *App) comparisonRunsLocked(key string) ([]ComparisonRun, error) {
+	if runs, ok := a.trends[key]; ok {
+		return runs, nil
+	}
+	if a.trends == nil {
+		a.trends = make(map[string][]ComparisonRun)
+	}
+	if a.Storage == nil {
+		return nil, nil
+	}
+
+	data, err := a.Storage.Read(trendsKeyPrefix + key + ".json")
+	if errors.Is(err, ErrNotStored) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read comparison statistics: %w", err)
+	}
+
+	var file trendFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse comparison statistics: %w", err)
+	}
+	a.trends[key] = file.Runs
+	return file.Runs, nil
+}
+
+// recordComparisonRun appends the change counts of a comparison to the
+// pair's history and writes it to storage
+func (a *App) recordComparisonRun(leftPath, rightPath string, result *DiffResult) error {
+	run := ComparisonRun{ComparedAt: time.Now()}
+	if result != nil {
+		for _, line := range result.Lines {
+			switch line.Type {
+			case "added":
+				run.Added++
+			case "removed":
+				run.Removed++
+			case "modified":
+				run.Modified++
+			}
+		}
+	}
+	run.Changes = run.Added + run.Removed + run.Modified
+
+	a.trendMutex.Lock()
+	defer a.trendMutex.Unlock()
+
+	key := trendKey(leftPath, rightPath)
+	runs, err := a.comparisonRunsLocked(key)
+	if err != nil {
+		// Start over rather than failing the comparison on a corrupt file
+		runs = nil
+	}
+	runs = append(runs, run)
+	if len(runs) > maxComparisonRuns {
+		runs = runs[len(runs)-maxComparisonRuns:]
+	}
+	a.trends[key] = runs
+
+	if a.Storage == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(trendFile{LeftFile: leftPath, RightFile: rightPath, Runs: runs}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode comparison statistics: %w", err)
+	}
+	if err := a.Storage.Write(trendsKeyPrefix+key+".json", data); err != nil {
+		return fmt.Errorf("failed to write comparison statistics: %w", err)
+	}
+	return nil
+}
+
+// GetComparisonTrend returns the recorded change counts of every comparison
+// of a pair and whether the differences are growing or shrinking over time
+func (a *App) GetComparisonTrend(leftPath, rightPath string) (*ComparisonTrend, error) {
+	a.trendMutex.Lock()
+	runs, err := a.comparisonRunsLocked(trendKey(leftPath, rightPath))
+	runs = append([]ComparisonRun{}, runs...)
+	a.trendMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	trend := &ComparisonTrend{LeftFile: leftPath, RightFile: rightPath, Runs: runs}
+	if len(runs) >= 2 {
+		trend.Delta = runs[len(runs)-1].Changes - runs[0].Changes
+		switch {
+		case trend.Delta > 0:
+			trend.Direction = TrendGrowing
+		case trend.Delta < 0:
+			trend.Direction = TrendShrinking
+		default:
+			trend.Direction = TrendStable
+		}
+	}
+	return trend, nil
+}
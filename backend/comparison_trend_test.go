@@ -0,0 +1,82 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_GetComparisonTrend(t *testing.T) {
+	storage := NewFileStorage(t.TempDir())
+	app := NewApp()
+	app.Storage = storage
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	root := t.TempDir()
+	left := filepath.Join(root, "expected.conf")
+	right := filepath.Join(root, "actual.conf")
+	write := func(path, content string) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		app.compare.DiscardAll()
+	}
+
+	t.Run("no runs", func(t *testing.T) {
+		trend, err := app.GetComparisonTrend(left, right)
+		if err != nil {
+			t.Fatalf("GetComparisonTrend returned error: %v", err)
+		}
+		if len(trend.Runs) != 0 || trend.Direction != "" {
+			t.Errorf("Expected an empty trend, got %+v", trend)
+		}
+	})
+
+	t.Run("growing drift", func(t *testing.T) {
+		write(left, "a\nb\nc\n")
+		write(right, "a\nb\nc\n")
+		if _, err := app.CompareFiles(left, right); err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+
+		write(right, "a\nb\nc\nd\ne\n")
+		if _, err := app.CompareFiles(left, right); err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+
+		trend, err := app.GetComparisonTrend(left, right)
+		if err != nil {
+			t.Fatalf("GetComparisonTrend returned error: %v", err)
+		}
+		if len(trend.Runs) != 2 {
+			t.Fatalf("Expected 2 runs, got %+v", trend.Runs)
+		}
+		if trend.Runs[0].Changes != 0 || trend.Runs[1].Added != 2 {
+			t.Errorf("Unexpected run counts: %+v", trend.Runs)
+		}
+		if trend.Direction != TrendGrowing || trend.Delta != 2 {
+			t.Errorf("Expected growing trend with delta 2, got %s/%d", trend.Direction, trend.Delta)
+		}
+	})
+
+	t.Run("persists per pair", func(t *testing.T) {
+		reloaded := NewApp()
+		reloaded.Storage = storage
+
+		trend, err := reloaded.GetComparisonTrend(left, right)
+		if err != nil {
+			t.Fatalf("GetComparisonTrend returned error: %v", err)
+		}
+		if len(trend.Runs) != 2 {
+			t.Errorf("Expected 2 persisted runs, got %d", len(trend.Runs))
+		}
+
+		reversed, err := reloaded.GetComparisonTrend(right, left)
+		if err != nil {
+			t.Fatalf("GetComparisonTrend returned error: %v", err)
+		}
+		if len(reversed.Runs) != 0 {
+			t.Errorf("Expected reversed pair to have its own history, got %d runs", len(reversed.Runs))
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+)
+
+// These tests drive the App from many goroutines at once, the way the
+// frontend, file watcher, and re-diff throttle do. They are most useful
+// under the race detector: go test -race ./backend
+
+func TestApp_ConcurrentCompareAndEdit(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	var left, right []string
+	for i := 1; i <= 50; i++ {
+		left = append(left, fmt.Sprintf("line number %d of the file", i))
+		right = append(right, fmt.Sprintf("line number %d of the file", i+i%3))
+	}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	const workers, rounds = 8, 20
+	var wg sync.WaitGroup
+	errs := make(chan error, workers*rounds*4)
+	for w := 0; w < workers; w++ {
+		wg.Add(4)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if _, err := app.CompareFiles(leftPath, rightPath); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				if err := app.CopyToFile(leftPath, rightPath, 1, "copied line"); err != nil {
+					errs <- err
+				}
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				app.watcher.Changed(leftPath)
+				app.watcher.Changed(rightPath)
+				app.GetWatchStatus()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < rounds; i++ {
+				app.RequestRediff(leftPath, rightPath)
+				if _, err := app.GetDiffSummary(); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Concurrent operation failed: %v", err)
+	}
+
+	// No copy was lost to another copy reading the same content
+	lines, err := app.ReadFileContentWithCache(rightPath)
+	if err != nil {
+		t.Fatalf("ReadFileContentWithCache returned error: %v", err)
+	}
+	if len(lines) != len(right)+workers*rounds {
+		t.Errorf("Expected %d lines after every copy, got %d", len(right)+workers*rounds, len(lines))
+	}
+}
+
+func TestApp_ConcurrentMenuUpdates(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+
+	var wg sync.WaitGroup
+	wg.Add(5)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			app.SetMinimapMenuItem(menu.Text("Minimap", nil, nil))
+			app.SetMinimapVisible(i%2 == 0)
+			app.GetMinimapVisible()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			app.SetSaveLeftMenuItem(menu.Text("Save Left", nil, nil))
+			app.UpdateSaveMenuItems(i%2 == 0, i%3 == 0)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			app.SetNextDiffMenuItem(menu.Text("Next", nil, nil))
+			app.UpdateDiffNavigationMenuItems(true, i%2 == 0, true, false)
+			app.UpdateCopyMenuItems("added")
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			app.SetUndoMenuItem(menu.Text("Undo", nil, nil))
+			app.setUndoRedoMenuItems(app.CanUndo(), app.CanRedo())
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 50; i++ {
+			pane := ""
+			if i%2 == 0 {
+				pane = "left"
+			}
+			app.SetEditingPane(pane)
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxRecentComparisons caps how many compared pairs are remembered
+const maxRecentComparisons = 50
+
+// RecentComparison records a previously compared pair of files
+type RecentComparison struct {
+	LeftFile   string    `json:"leftFile"`
+	RightFile  string    `json:"rightFile"`
+	ComparedAt time.Time `json:"comparedAt"`
+}
+
+// CounterpartSuggestion is a candidate file to compare against a chosen file
+type CounterpartSuggestion struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"` // "history", "backup", "sibling"
+	Score  int    `json:"score"`
+}
+
+// Heuristic scores, highest first
+const (
+	scoreHistory        = 100
+	scoreHistoryReverse = 90
+	scoreBackup         = 80
+	scoreSibling        = 60
+)
+
+// backupSuffixes are the file name suffixes editors and tools use for backups
+var backupSuffixes = []string{"~", ".bak", ".orig", ".old", ".prev"}
+
+// SuggestCounterparts returns likely files to compare against path, ranked by
+// heuristics: counterparts from comparison history, backup copies, and files
+// with the same name in sibling directories. Only existing files are suggested.
+func (a *App) SuggestCounterparts(path string) []CounterpartSuggestion {
+	candidates := make(map[string]CounterpartSuggestion)
+	add := func(candidate, reason string, score int) {
+		if candidate == path {
+			return
+		}
+		if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+			return
+		}
+		if existing, ok := candidates[candidate]; ok && existing.Score >= score {
+			return
+		}
+		candidates[candidate] = CounterpartSuggestion{Path: candidate, Reason: reason, Score: score}
+	}
+
+	// Previously paired counterparts
+	for _, pair := range a.settings.Get().RecentComparisons {
+		if pair.LeftFile == path {
+			add(pair.RightFile, "history", scoreHistory)
+		} else if pair.RightFile == path {
+			add(pair.LeftFile, "history", scoreHistoryReverse)
+		}
+	}
+
+	// Backup copies of the file, or the original when a backup was picked
+	for _, suffix := range backupSuffixes {
+		add(path+suffix, "backup", scoreBackup)
+		if strings.HasSuffix(path, suffix) {
+			add(strings.TrimSuffix(path, suffix), "backup", scoreBackup)
+		}
+	}
+
+	// Same file name in sibling directories
+	dir := filepath.Dir(path)
+	base := filepath.Base(path)
+	parent := filepath.Dir(dir)
+	if parent != dir {
+		if entries, err := os.ReadDir(parent); err == nil {
+			for _, entry := range entries {
+				if entry.IsDir() {
+					add(filepath.Join(parent, entry.Name(), base), "sibling", scoreSibling)
+				}
+			}
+		}
+	}
+
+	suggestions := make([]CounterpartSuggestion, 0, len(candidates))
+	for _, suggestion := range candidates {
+		suggestions = append(suggestions, suggestion)
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Score != suggestions[j].Score {
+			return suggestions[i].Score > suggestions[j].Score
+		}
+		return suggestions[i].Path < suggestions[j].Path
+	})
+	return suggestions
+}
+
+// recordRecentComparison remembers a compared pair, most recent first
+func (a *App) recordRecentComparison(leftPath, rightPath string) {
+	// History is best-effort; a failed write must not fail the comparison
+	a.settings.Update(func(s *Settings) error {
+		recent := []RecentComparison{{LeftFile: leftPath, RightFile: rightPath, ComparedAt: time.Now()}}
+		for _, pair := range s.RecentComparisons {
+			if pair.LeftFile == leftPath && pair.RightFile == rightPath {
+				continue
+			}
+			recent = append(recent, pair)
+		}
+		if len(recent) > maxRecentComparisons {
+			recent = recent[:maxRecentComparisons]
+		}
+		s.RecentComparisons = recent
+		return nil
+	})
+}
+
+// GetRecentComparisons returns previously compared pairs, most recent first
+func (a *App) GetRecentComparisons() []RecentComparison {
+	return append([]RecentComparison{}, a.settings.Get().RecentComparisons...)
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_SuggestCounterparts(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	root := t.TempDir()
+	mustWrite := func(path string) string {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		return path
+	}
+
+	original := mustWrite(filepath.Join(root, "v1", "config.yaml"))
+	sibling := mustWrite(filepath.Join(root, "v2", "config.yaml"))
+	backup := mustWrite(filepath.Join(root, "v1", "config.yaml.bak"))
+	paired := mustWrite(filepath.Join(root, "other", "settings.yaml"))
+
+	t.Run("ranks backups above siblings", func(t *testing.T) {
+		suggestions := app.SuggestCounterparts(original)
+		if len(suggestions) != 2 {
+			t.Fatalf("Expected 2 suggestions, got %v", suggestions)
+		}
+		if suggestions[0].Path != backup || suggestions[0].Reason != "backup" {
+			t.Errorf("Expected backup first, got %+v", suggestions[0])
+		}
+		if suggestions[1].Path != sibling || suggestions[1].Reason != "sibling" {
+			t.Errorf("Expected sibling second, got %+v", suggestions[1])
+		}
+	})
+
+	t.Run("history counterpart ranks first", func(t *testing.T) {
+		if _, err := app.CompareFiles(original, paired); err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+
+		suggestions := app.SuggestCounterparts(original)
+		if len(suggestions) == 0 || suggestions[0].Path != paired || suggestions[0].Reason != "history" {
+			t.Errorf("Expected history counterpart first, got %v", suggestions)
+		}
+
+		// The reverse direction is suggested too
+		reverse := app.SuggestCounterparts(paired)
+		if len(reverse) == 0 || reverse[0].Path != original {
+			t.Errorf("Expected original as counterpart of paired file, got %v", reverse)
+		}
+	})
+
+	t.Run("backup suggests original", func(t *testing.T) {
+		suggestions := app.SuggestCounterparts(backup)
+		if len(suggestions) == 0 || suggestions[0].Path != original {
+			t.Errorf("Expected original for backup file, got %v", suggestions)
+		}
+	})
+
+	t.Run("recent comparisons are deduplicated", func(t *testing.T) {
+		app.recordRecentComparison(original, paired)
+		app.recordRecentComparison(sibling, original)
+
+		recent := app.GetRecentComparisons()
+		if len(recent) != 2 {
+			t.Fatalf("Expected 2 recent comparisons, got %d", len(recent))
+		}
+		if recent[0].LeftFile != sibling {
+			t.Errorf("Expected most recent comparison first, got %+v", recent[0])
+		}
+	})
+}
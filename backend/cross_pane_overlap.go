@@ -0,0 +1,97 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// lineRange is a 1-based range of lines. A range with count 0 is the gap
+// before line start.
+type lineRange struct {
+	start int
+	count int
+}
+
+// overlaps reports whether two ranges share a line, treating a gap as
+// touching the lines on either side of it
+func (r lineRange) overlaps(other lineRange) bool {
+	switch {
+	case r.count == 0 && other.count == 0:
+		return r.start == other.start
+	case r.count == 0:
+		return other.start <= r.start && r.start <= other.start+other.count
+	case other.count == 0:
+		return r.start <= other.start && other.start <= r.start+r.count
+	}
+	return r.start < other.start+other.count && other.start < r.start+r.count
+}
+
+// editedRanges returns the ranges of a file's unsaved content that differ
+// from the file on disk, or nil when the file has no unsaved changes
+func (a *App) editedRanges(path string) ([]lineRange, error) {
+	cached, exists := a.compare.Edited(path)
+	if !exists {
+		return nil, nil
+	}
+
+	saved, err := a.ReadFileContent(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read saved file: %w", err)
+	}
+
+	algorithm := a.algorithmFor(a.resolveCompareOptions(CompareOptions{}))
+	var ranges []lineRange
+	for _, hunk := range diff.GroupHunks(algorithm.ComputeDiff(saved, cached)) {
+		ranges = append(ranges, lineRange{start: hunk.RightStart, count: hunk.RightCount})
+	}
+	return ranges, nil
+}
+
+// touchesAny reports whether a range overlaps any of the given ranges
+func touchesAny(r lineRange, ranges []lineRange) bool {
+	for _, other := range ranges {
+		if r.overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectCrossPaneOverlap returns the hunks of the current comparison that
+// have unsaved edits on both sides, so the user can be warned before saving
+// both panes. It returns an empty list unless both files have unsaved changes.
+func (a *App) DetectCrossPaneOverlap() ([]Hunk, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	leftEdits, err := a.editedRanges(current.LeftPath)
+	if err != nil {
+		return nil, err
+	}
+	rightEdits, err := a.editedRanges(current.RightPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(leftEdits) == 0 || len(rightEdits) == 0 {
+		return []Hunk{}, nil
+	}
+
+	// Diff the unsaved contents so hunk positions match the edited lines
+	result, err := a.computeDiff(current.LeftPath, current.RightPath, current.Options)
+	if err != nil {
+		return nil, err
+	}
+
+	overlapping := []Hunk{}
+	for _, hunk := range diff.GroupHunks(result) {
+		left := lineRange{start: hunk.LeftStart, count: hunk.LeftCount}
+		right := lineRange{start: hunk.RightStart, count: hunk.RightCount}
+		if touchesAny(left, leftEdits) && touchesAny(right, rightEdits) {
+			overlapping = append(overlapping, hunk)
+		}
+	}
+	return overlapping, nil
+}
@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestApp_DetectCrossPaneOverlap(t *testing.T) {
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	t.Run("requires a comparison", func(t *testing.T) {
+		if _, err := NewApp().DetectCrossPaneOverlap(); err == nil {
+			t.Error("Expected error when nothing has been compared")
+		}
+	})
+
+	left := []string{"one", "two", "three", "four", "five", "six"}
+	right := []string{"one", "TWO", "three", "four", "five", "six"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	t.Run("edits on one side only", func(t *testing.T) {
+		app.compare.SetEdited(leftPath, []string{"one", "2", "three", "four", "five", "six"})
+
+		overlap, err := app.DetectCrossPaneOverlap()
+		if err != nil {
+			t.Fatalf("DetectCrossPaneOverlap returned error: %v", err)
+		}
+		if len(overlap) != 0 {
+			t.Errorf("Expected no overlap, got %+v", overlap)
+		}
+	})
+
+	t.Run("edits to the same hunk on both sides", func(t *testing.T) {
+		app.compare.SetEdited(leftPath, []string{"one", "2", "three", "four", "five", "six"})
+		app.compare.SetEdited(rightPath, []string{"one", "Two", "three", "four", "five", "six"})
+
+		overlap, err := app.DetectCrossPaneOverlap()
+		if err != nil {
+			t.Fatalf("DetectCrossPaneOverlap returned error: %v", err)
+		}
+		if len(overlap) != 1 || overlap[0].LeftStart != 2 || overlap[0].RightStart != 2 {
+			t.Errorf("Expected the line 2 hunk, got %+v", overlap)
+		}
+	})
+
+	t.Run("edits to different regions", func(t *testing.T) {
+		app.compare.SetEdited(leftPath, []string{"one", "2", "three", "four", "five", "six"})
+		app.compare.SetEdited(rightPath, []string{"one", "TWO", "three", "four", "five", "SIX"})
+
+		overlap, err := app.DetectCrossPaneOverlap()
+		if err != nil {
+			t.Fatalf("DetectCrossPaneOverlap returned error: %v", err)
+		}
+		if len(overlap) != 0 {
+			t.Errorf("Expected no overlap, got %+v", overlap)
+		}
+	})
+}
+
+func TestLineRange_Overlaps(t *testing.T) {
+	tests := []struct {
+		a, b     lineRange
+		expected bool
+	}{
+		{lineRange{1, 2}, lineRange{2, 1}, true},
+		{lineRange{1, 2}, lineRange{3, 1}, false},
+		{lineRange{3, 0}, lineRange{1, 2}, true},
+		{lineRange{4, 0}, lineRange{1, 2}, false},
+		{lineRange{2, 0}, lineRange{2, 0}, true},
+	}
+	for _, tt := range tests {
+		if got := tt.a.overlaps(tt.b); got != tt.expected {
+			t.Errorf("%+v.overlaps(%+v) = %v, expected %v", tt.a, tt.b, got, tt.expected)
+		}
+		if got := tt.b.overlaps(tt.a); got != tt.expected {
+			t.Errorf("%+v.overlaps(%+v) = %v, expected %v", tt.b, tt.a, got, tt.expected)
+		}
+	}
+}
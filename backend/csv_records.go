@@ -0,0 +1,25 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// GetCSVColumns returns the header cells of a CSV or TSV file, so the
+// columns that identify a record can be chosen as CompareOptions.KeyColumns
+func (a *App) GetCSVColumns(path string) ([]string, error) {
+	delimiter := diff.CSVDelimiter(path)
+	if delimiter == 0 {
+		return nil, fmt.Errorf("not a CSV or TSV file: %s", path)
+	}
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := diff.ParseCSVHeader(lines, delimiter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	return columns, nil
+}
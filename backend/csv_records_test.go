@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApp_GetCSVColumns(t *testing.T) {
+	app := NewApp()
+	path := filepath.Join(t.TempDir(), "people.tsv")
+	if err := os.WriteFile(path, []byte("id\tname\n1\tAda\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	columns, err := app.GetCSVColumns(path)
+	if err != nil {
+		t.Fatalf("GetCSVColumns returned error: %v", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"id", "name"}) {
+		t.Errorf("Unexpected columns %q", columns)
+	}
+
+	if _, err := app.GetCSVColumns(filepath.Join(t.TempDir(), "notes.txt")); err == nil {
+		t.Error("Expected error for a file that is not CSV")
+	}
+}
+
+func TestApp_CompareFilesWithOptions_KeyColumns(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.csv")
+	rightPath := filepath.Join(dir, "right.csv")
+	if err := os.WriteFile(leftPath, []byte("id,name\n1,Ada\n2,Grace\n3,Linus\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("id,name\n3,Linus\n2,Hopper\n1,Ada\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	if _, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{KeyColumns: []int{-1}}); err == nil {
+		t.Error("Expected error for a negative key column")
+	}
+
+	result, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{KeyColumns: []int{0}})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if result.Records == nil || result.Records.Changed != 1 || result.Records.Unchanged != 2 {
+		t.Errorf("Expected one changed record of three, got %+v", result.Records)
+	}
+	if len(result.Chunks) != 1 {
+		t.Errorf("Expected only the renamed record to differ, got %+v", result.Lines)
+	}
+
+	result, err = app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+	if err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if result.Records != nil {
+		t.Error("Expected a line comparison without key columns")
+	}
+}
@@ -0,0 +1,109 @@
+package backend
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// DialogProvider shows the dialogs the App asks the user with. Swapping it
+// lets features that prompt for files or confirmation run without a window.
+type DialogProvider interface {
+	OpenFile(options runtime.OpenDialogOptions) (string, error)
+	SaveFile(options runtime.SaveDialogOptions) (string, error)
+	Message(options runtime.MessageDialogOptions) (string, error)
+}
+
+// nativeDialogs shows dialogs through the runtime's window
+type nativeDialogs struct {
+	runtime Runtime
+}
+
+func (d nativeDialogs) OpenFile(options runtime.OpenDialogOptions) (string, error) {
+	return d.runtime.OpenFileDialog(options)
+}
+
+func (d nativeDialogs) SaveFile(options runtime.SaveDialogOptions) (string, error) {
+	return d.runtime.SaveFileDialog(options)
+}
+
+func (d nativeDialogs) Message(options runtime.MessageDialogOptions) (string, error) {
+	return d.runtime.MessageDialog(options)
+}
+
+// HeadlessDialogs refuses every dialog, for running without a window where
+// there is nobody to answer one
+type HeadlessDialogs struct{}
+
+func (HeadlessDialogs) OpenFile(options runtime.OpenDialogOptions) (string, error) {
+	return "", fmt.Errorf("cannot show %q dialog: %w", options.Title, errNoWindow)
+}
+
+func (HeadlessDialogs) SaveFile(options runtime.SaveDialogOptions) (string, error) {
+	return "", fmt.Errorf("cannot show %q dialog: %w", options.Title, errNoWindow)
+}
+
+func (HeadlessDialogs) Message(options runtime.MessageDialogOptions) (string, error) {
+	return "", fmt.Errorf("cannot show %q dialog: %w", options.Title, errNoWindow)
+}
+
+// DialogAnswer is a scripted reply to one dialog: the chosen path or button,
+// or an error
+type DialogAnswer struct {
+	Value string
+	Err   error
+}
+
+// ScriptedDialogs answers dialogs from a script, in order, for tests and
+// automation. It records the title of each dialog it is asked to show and
+// fails once the script runs out.
+type ScriptedDialogs struct {
+	mu      sync.Mutex
+	answers []DialogAnswer
+	shown   []string
+}
+
+// NewScriptedDialogs returns a provider giving the answers in order
+func NewScriptedDialogs(answers ...DialogAnswer) *ScriptedDialogs {
+	return &ScriptedDialogs{answers: answers}
+}
+
+// Shown returns the titles of the dialogs shown so far
+func (d *ScriptedDialogs) Shown() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string{}, d.shown...)
+}
+
+func (d *ScriptedDialogs) answer(title string) (string, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.shown = append(d.shown, title)
+	if len(d.answers) == 0 {
+		return "", fmt.Errorf("no scripted answer for %q dialog", title)
+	}
+	next := d.answers[0]
+	d.answers = d.answers[1:]
+	return next.Value, next.Err
+}
+
+func (d *ScriptedDialogs) OpenFile(options runtime.OpenDialogOptions) (string, error) {
+	return d.answer(options.Title)
+}
+
+func (d *ScriptedDialogs) SaveFile(options runtime.SaveDialogOptions) (string, error) {
+	return d.answer(options.Title)
+}
+
+func (d *ScriptedDialogs) Message(options runtime.MessageDialogOptions) (string, error) {
+	return d.answer(options.Title)
+}
+
+// dialogs returns the DialogProvider the App was given, or native dialogs
+func (a *App) dialogs() DialogProvider {
+	if a.Dialogs == nil {
+		return nativeDialogs{runtime: a.runtime()}
+	}
+	return a.Dialogs
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+func TestApp_ScriptedDialogs(t *testing.T) {
+	dir := t.TempDir()
+	chosen := filepath.Join(dir, "chosen.txt")
+	if err := os.WriteFile(chosen, []byte("alpha\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	destination := filepath.Join(dir, "saved.txt")
+
+	dialogs := NewScriptedDialogs(DialogAnswer{Value: chosen}, DialogAnswer{Value: destination})
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	app.Dialogs = dialogs
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	file, err := app.SelectFile()
+	if err != nil || file != chosen {
+		t.Fatalf("Expected SelectFile to return %s, got %q (%v)", chosen, file, err)
+	}
+
+	path, err := app.CreateVirtualFile("scratch.txt", []string{"beta"})
+	if err != nil {
+		t.Fatalf("CreateVirtualFile returned error: %v", err)
+	}
+	t.Cleanup(func() { app.CloseVirtualFile(path) })
+	saved, err := app.SaveFileAs(path, "")
+	if err != nil || saved != destination {
+		t.Fatalf("Expected SaveFileAs to save to %s, got %q (%v)", destination, saved, err)
+	}
+	if data, err := os.ReadFile(destination); err != nil || string(data) != "beta" {
+		t.Errorf("Unexpected saved content %q (%v)", data, err)
+	}
+
+	if _, err := app.SelectFile(); err == nil {
+		t.Error("Expected error once the script runs out")
+	}
+	expected := []string{"Select File to Compare", "Save As", "Select File to Compare"}
+	if shown := dialogs.Shown(); !reflect.DeepEqual(shown, expected) {
+		t.Errorf("Expected dialogs %v, got %v", expected, shown)
+	}
+}
+
+func TestHeadlessDialogs(t *testing.T) {
+	var dialogs DialogProvider = HeadlessDialogs{}
+	if _, err := dialogs.OpenFile(runtime.OpenDialogOptions{Title: "Open"}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from OpenFile, got %v", err)
+	}
+	if _, err := dialogs.SaveFile(runtime.SaveDialogOptions{Title: "Save"}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from SaveFile, got %v", err)
+	}
+	if _, err := dialogs.Message(runtime.MessageDialogOptions{Title: "Confirm"}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from Message, got %v", err)
+	}
+
+	// Without a provider, dialogs go to the runtime, which has no window
+	// until the App starts
+	if _, err := NewApp().dialogs().OpenFile(runtime.OpenDialogOptions{}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from native dialogs without a window, got %v", err)
+	}
+}
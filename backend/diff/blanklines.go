@@ -0,0 +1,71 @@
+package diff
+
+import "strings"
+
+// diffIgnoringBlankLines diffs the non-blank lines of both files with the
+// configured algorithm, then weaves the blank lines back in as "same" lines,
+// so blank lines that were added, removed, or moved are not differences.
+// Blank lines are paired across the sides where possible; an unpaired blank
+// line has line number 0 on the other side.
+func diffIgnoringBlankLines(leftLines, rightLines []string, config Config) *DiffResult {
+	leftIndexes, leftText := nonBlankLines(leftLines)
+	rightIndexes, rightText := nonBlankLines(rightLines)
+
+	config.IgnoreBlankLines = false
+	inner := New(config).ComputeDiff(leftText, rightText)
+
+	result := &DiffResult{Lines: make([]DiffLine, 0, len(leftLines)+len(rightLines))}
+	nextLeft, nextRight := 0, 0
+	// emitBlanks adds the blank lines before the given 0-based indexes
+	emitBlanks := func(leftEnd, rightEnd int) {
+		for nextLeft < leftEnd || nextRight < rightEnd {
+			line := DiffLine{Type: "same"}
+			if nextLeft < leftEnd {
+				line.LeftLine, line.LeftNumber = leftLines[nextLeft], nextLeft+1
+				nextLeft++
+			}
+			if nextRight < rightEnd {
+				line.RightLine, line.RightNumber = rightLines[nextRight], nextRight+1
+				nextRight++
+			}
+			result.Lines = append(result.Lines, line)
+		}
+	}
+
+	for _, line := range inner.Lines {
+		leftEnd, rightEnd := nextLeft, nextRight
+		if line.LeftNumber > 0 {
+			line.LeftNumber = leftIndexes[line.LeftNumber-1] + 1
+			leftEnd = line.LeftNumber - 1
+		}
+		if line.RightNumber > 0 {
+			line.RightNumber = rightIndexes[line.RightNumber-1] + 1
+			rightEnd = line.RightNumber - 1
+		}
+		emitBlanks(leftEnd, rightEnd)
+		if line.LeftNumber > 0 {
+			nextLeft = line.LeftNumber
+		}
+		if line.RightNumber > 0 {
+			nextRight = line.RightNumber
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	emitBlanks(len(leftLines), len(rightLines))
+	result.Chunks = GroupHunks(result)
+	return result
+}
+
+// nonBlankLines returns the 0-based indexes and text of the lines that are
+// not empty or whitespace-only
+func nonBlankLines(lines []string) ([]int, []string) {
+	var indexes []int
+	var text []string
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			indexes = append(indexes, i)
+			text = append(text, line)
+		}
+	}
+	return indexes, text
+}
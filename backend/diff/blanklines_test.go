@@ -0,0 +1,44 @@
+package diff
+
+import "testing"
+
+func TestAlgorithms_IgnoreBlankLines(t *testing.T) {
+	left := []string{"a", "", "b", "c", "d"}
+	right := []string{"a", "b", "", "  ", "c", "e"}
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+		config.IgnoreBlankLines = true
+		result := New(config).ComputeDiff(left, right)
+
+		changed := 0
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				changed++
+				if line.LeftLine != "d" && line.RightLine != "e" {
+					t.Errorf("%s: expected only d/e to differ, got %+v", name, line)
+				}
+			}
+		}
+		if changed == 0 {
+			t.Errorf("%s: expected the non-blank change to remain, got %+v", name, result.Lines)
+		}
+		if hunks := GroupHunks(result); len(hunks) != 1 {
+			t.Errorf("%s: expected one hunk, got %+v", name, hunks)
+		}
+	}
+
+	// Blank lines next to other changes are not differences either
+	config := DefaultConfig()
+	config.IgnoreBlankLines = true
+	result := NewLCS(config).ComputeDiff([]string{"x", "y"}, []string{"x", "", "z"})
+	for _, line := range result.Lines {
+		if line.RightNumber == 2 && line.Type != "same" {
+			t.Errorf("Expected the added blank line to be the same, got %+v", line)
+		}
+	}
+	if len(result.Lines) != 4 || result.Lines[1].LeftLine != "y" || result.Lines[3].RightLine != "z" {
+		t.Errorf("Expected y to be replaced by z, got %+v", result.Lines)
+	}
+}
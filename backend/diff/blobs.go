@@ -0,0 +1,48 @@
+package diff
+
+import (
+	"math"
+	"unicode"
+)
+
+// Thresholds for recognizing encoded data such as base64 blobs and
+// certificates, which are long runs of nearly random characters
+const (
+	// blobMinLength is the shortest line, in bytes, treated as a blob
+	blobMinLength = 256
+	// blobMinEntropy is the lowest Shannon entropy, in bits per byte, of a
+	// blob. Hex scores 4, base64 about 6, and prose around 4.2 but with spaces.
+	blobMinEntropy = 3.5
+	// blobMaxWhitespace is the largest fraction of whitespace in a blob
+	blobMaxWhitespace = 0.01
+)
+
+// isOpaqueBlob reports whether a line looks like encoded data rather than
+// text. Such lines are only ever equal or different: measuring how similar
+// two blobs are is quadratic in their length and meaningless to the reader.
+func isOpaqueBlob(line string) bool {
+	if len(line) < blobMinLength {
+		return false
+	}
+
+	var counts [256]int
+	whitespace := 0
+	for i := 0; i < len(line); i++ {
+		counts[line[i]]++
+		if unicode.IsSpace(rune(line[i])) {
+			whitespace++
+		}
+	}
+	if float64(whitespace) > blobMaxWhitespace*float64(len(line)) {
+		return false
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		if count > 0 {
+			p := float64(count) / float64(len(line))
+			entropy -= p * math.Log2(p)
+		}
+	}
+	return entropy >= blobMinEntropy
+}
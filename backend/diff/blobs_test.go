@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"encoding/base64"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIsOpaqueBlob(t *testing.T) {
+	data := make([]byte, 3000)
+	rand.New(rand.NewSource(1)).Read(data)
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	tests := []struct {
+		name string
+		line string
+		want bool
+	}{
+		{"base64", encoded, true},
+		{"short base64", encoded[:100], false},
+		{"prose", strings.Repeat("the quick brown fox jumps over the lazy dog ", 10), false},
+		{"repeated character", strings.Repeat("=", 400), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isOpaqueBlob(tt.line); got != tt.want {
+				t.Errorf("isOpaqueBlob() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDetectModifications_Blobs(t *testing.T) {
+	data := make([]byte, 30000)
+	rand.New(rand.NewSource(2)).Read(data)
+	left := base64.StdEncoding.EncodeToString(data)
+	data[len(data)/2] ^= 0xff
+	right := base64.StdEncoding.EncodeToString(data)
+
+	started := time.Now()
+	result := NewLCSDefault().ComputeDiff([]string{"key:", left}, []string{"key:", right})
+	if elapsed := time.Since(started); elapsed > 2*time.Second {
+		t.Errorf("Comparing blobs took %v", elapsed)
+	}
+
+	for _, line := range result.Lines {
+		if line.Type == "modified" {
+			t.Errorf("Expected differing blobs to be removed and added, got a modified line")
+		}
+	}
+	if len(result.Lines) != 3 {
+		t.Errorf("Expected 3 lines, got %d", len(result.Lines))
+	}
+}
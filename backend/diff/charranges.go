@@ -0,0 +1,68 @@
+package diff
+
+import "unicode/utf8"
+
+// CharRange is a changed region within a line, given as character (rune)
+// offsets with End exclusive
+type CharRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// CharRanges diffs two versions of a line character by character and returns
+// the changed regions of each side
+func CharRanges(left, right string) ([]CharRange, []CharRange) {
+	leftRunes, rightRunes := []rune(left), []rune(right)
+	leftIDs := make([]int, len(leftRunes))
+	for i, r := range leftRunes {
+		leftIDs[i] = int(r)
+	}
+	rightIDs := make([]int, len(rightRunes))
+	for i, r := range rightRunes {
+		rightIDs[i] = int(r)
+	}
+
+	matches := make([]int, len(leftIDs))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &myersState{left: leftIDs, right: rightIDs, matches: matches}
+	s.compare(0, len(leftIDs), 0, len(rightIDs))
+
+	leftChanged := make([]bool, len(leftIDs))
+	rightChanged := make([]bool, len(rightIDs))
+	for j := range rightChanged {
+		rightChanged[j] = true
+	}
+	for i, j := range matches {
+		if j < 0 {
+			leftChanged[i] = true
+		} else {
+			rightChanged[j] = false
+		}
+	}
+	return changedRanges(leftChanged), changedRanges(rightChanged)
+}
+
+// changedRanges collapses runs of changed characters into ranges
+func changedRanges(changed []bool) []CharRange {
+	ranges := []CharRange{}
+	for i := 0; i < len(changed); i++ {
+		if !changed[i] {
+			continue
+		}
+		start := i
+		for i < len(changed) && changed[i] {
+			i++
+		}
+		ranges = append(ranges, CharRange{Start: start, End: i})
+	}
+	return ranges
+}
+
+// withinCharDiffLength returns whether both lines are short enough for a
+// character-level diff under the configuration
+func withinCharDiffLength(left, right string, config Config) bool {
+	return utf8.RuneCountInString(left) <= config.MaxCharDiffLength &&
+		utf8.RuneCountInString(right) <= config.MaxCharDiffLength
+}
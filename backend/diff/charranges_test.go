@@ -0,0 +1,63 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCharRanges(t *testing.T) {
+	tests := []struct {
+		name  string
+		left  string
+		right string
+		wantL []CharRange
+		wantR []CharRange
+	}{
+		{"number tweak", "timeout = 30", "timeout = 35", []CharRange{{11, 12}}, []CharRange{{11, 12}}},
+		{"rename", "userId", "userID", []CharRange{{5, 6}}, []CharRange{{5, 6}}},
+		{"insertion", "f(a)", "f(a, b)", []CharRange{}, []CharRange{{3, 6}}},
+		{"deletion", "abc", "ac", []CharRange{{1, 2}}, []CharRange{}},
+		{"multibyte characters", "café = 1", "café = 2", []CharRange{{7, 8}}, []CharRange{{7, 8}}},
+		{"identical", "same", "same", []CharRange{}, []CharRange{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			left, right := CharRanges(tt.left, tt.right)
+			if !reflect.DeepEqual(left, tt.wantL) {
+				t.Errorf("Expected left ranges %v, got %v", tt.wantL, left)
+			}
+			if !reflect.DeepEqual(right, tt.wantR) {
+				t.Errorf("Expected right ranges %v, got %v", tt.wantR, right)
+			}
+		})
+	}
+}
+
+func TestModifiedLinesHaveCharRanges(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	t.Run("short line", func(t *testing.T) {
+		result := lcs.ComputeDiff([]string{"const retries = 3"}, []string{"const retries = 5"})
+		line := result.Lines[0]
+		if line.Type != "modified" {
+			t.Fatalf("Expected a modified line, got %+v", line)
+		}
+		if !reflect.DeepEqual(line.LeftChanges, []CharRange{{16, 17}}) || !reflect.DeepEqual(line.RightChanges, []CharRange{{16, 17}}) {
+			t.Errorf("Expected the digit to be the only change, got %v and %v", line.LeftChanges, line.RightChanges)
+		}
+	})
+
+	t.Run("long line", func(t *testing.T) {
+		long := strings.Repeat("x", DefaultConfig().MaxCharDiffLength)
+		result := lcs.ComputeDiff([]string{long + "a"}, []string{long + "b"})
+		line := result.Lines[0]
+		if line.Type != "modified" {
+			t.Fatalf("Expected a modified line, got %+v", line)
+		}
+		if line.LeftChanges != nil || line.RightChanges != nil {
+			t.Error("Expected no character ranges for a long line")
+		}
+	})
+}
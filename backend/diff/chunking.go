@@ -0,0 +1,227 @@
+package diff
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"sort"
+)
+
+// Content-defined chunk size limits, in bytes. Boundaries depend only on
+// nearby content, so an insertion only changes the chunks around it.
+const (
+	minChunkSize = 2 * 1024
+	maxChunkSize = 64 * 1024
+	chunkMask    = 1<<13 - 1 // about 8KB average chunks
+)
+
+// gearTable holds the per-byte values of the rolling gear hash
+var gearTable = func() [256]uint64 {
+	var table [256]uint64
+	// splitmix64 with a fixed seed so chunk boundaries are stable between runs
+	state := uint64(0x9e3779b97f4a7c15)
+	for i := range table {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		table[i] = z ^ (z >> 31)
+	}
+	return table
+}()
+
+// Chunk is a content-defined region of a file
+type Chunk struct {
+	Offset    int64  `json:"offset"`
+	Length    int64  `json:"length"`
+	StartLine int    `json:"startLine"` // 1-based line containing the first byte
+	EndLine   int    `json:"endLine"`   // 1-based line containing the last byte
+	Hash      string `json:"hash"`
+
+	nextLine int // line on which the following chunk starts
+}
+
+// ChunkRegion is a run of chunks that is either identical on both sides or
+// differs. Offsets and lengths are in bytes; line numbers are 1-based.
+type ChunkRegion struct {
+	Status         string `json:"status"` // "same", "changed", "added", "removed"
+	LeftOffset     int64  `json:"leftOffset"`
+	LeftLength     int64  `json:"leftLength"`
+	RightOffset    int64  `json:"rightOffset"`
+	RightLength    int64  `json:"rightLength"`
+	LeftStartLine  int    `json:"leftStartLine"`
+	LeftEndLine    int    `json:"leftEndLine"`
+	RightStartLine int    `json:"rightStartLine"`
+	RightEndLine   int    `json:"rightEndLine"`
+}
+
+// ChunkReader splits a stream into content-defined chunks using a gear
+// rolling hash, reading it once without holding more than one chunk in memory
+func ChunkReader(r io.Reader) ([]Chunk, error) {
+	reader := bufio.NewReaderSize(r, maxChunkSize)
+	chunks := []Chunk{}
+
+	var (
+		offset  int64
+		line    = 1
+		hash    uint64
+		current = sha256.New()
+		buffer  = make([]byte, 0, maxChunkSize)
+	)
+
+	emit := func() {
+		if len(buffer) == 0 {
+			return
+		}
+		current.Reset()
+		current.Write(buffer)
+		nextLine := line + bytes.Count(buffer, []byte{'\n'})
+		endLine := nextLine
+		if buffer[len(buffer)-1] == '\n' {
+			endLine--
+		}
+		chunks = append(chunks, Chunk{
+			Offset:    offset,
+			Length:    int64(len(buffer)),
+			StartLine: line,
+			EndLine:   endLine,
+			Hash:      hex.EncodeToString(current.Sum(nil)),
+			nextLine:  nextLine,
+		})
+		offset += int64(len(buffer))
+		line = nextLine
+		buffer = buffer[:0]
+		hash = 0
+	}
+
+	for {
+		b, err := reader.ReadByte()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		buffer = append(buffer, b)
+		hash = hash<<1 + gearTable[b]
+		if (len(buffer) >= minChunkSize && hash&chunkMask == 0) || len(buffer) >= maxChunkSize {
+			emit()
+		}
+	}
+	emit()
+	return chunks, nil
+}
+
+// CompareChunks aligns two chunk sequences and returns the regions that are
+// the same or differ, in order. Mismatches are resynchronized at the nearest
+// chunk found on both sides, which keeps the comparison close to linear for
+// the large files chunking is meant for.
+func CompareChunks(left, right []Chunk) []ChunkRegion {
+	rightPositions := chunkPositions(right)
+	leftPositions := chunkPositions(left)
+
+	regions := []ChunkRegion{}
+	add := func(status string, leftFrom, leftTo, rightFrom, rightTo int) {
+		if leftFrom == leftTo && rightFrom == rightTo {
+			return
+		}
+		region := ChunkRegion{Status: status}
+		region.LeftOffset, region.LeftLength, region.LeftStartLine, region.LeftEndLine = chunkSpan(left, leftFrom, leftTo)
+		region.RightOffset, region.RightLength, region.RightStartLine, region.RightEndLine = chunkSpan(right, rightFrom, rightTo)
+
+		// Extend the previous region when it has the same status
+		if n := len(regions); n > 0 && regions[n-1].Status == status {
+			previous := &regions[n-1]
+			previous.LeftLength += region.LeftLength
+			previous.RightLength += region.RightLength
+			previous.LeftEndLine = region.LeftEndLine
+			previous.RightEndLine = region.RightEndLine
+			return
+		}
+		regions = append(regions, region)
+	}
+
+	i, j := 0, 0
+	for i < len(left) || j < len(right) {
+		if i < len(left) && j < len(right) && left[i].Hash == right[j].Hash {
+			add("same", i, i+1, j, j+1)
+			i++
+			j++
+			continue
+		}
+
+		nextI, nextJ := resync(left, right, leftPositions, rightPositions, i, j)
+		status := "changed"
+		switch {
+		case nextI == i:
+			status = "added"
+		case nextJ == j:
+			status = "removed"
+		}
+		add(status, i, nextI, j, nextJ)
+		i, j = nextI, nextJ
+	}
+	return regions
+}
+
+// resync finds the closest pair of positions at or after i and j where the
+// two sequences share a chunk again, or the ends of both sequences
+func resync(left, right []Chunk, leftPositions, rightPositions map[string][]int, i, j int) (int, int) {
+	for d := 0; i+d < len(left) || j+d < len(right); d++ {
+		if i+d < len(left) {
+			if p := firstAtOrAfter(rightPositions[left[i+d].Hash], j); p >= 0 {
+				return i + d, p
+			}
+		}
+		if j+d < len(right) {
+			if p := firstAtOrAfter(leftPositions[right[j+d].Hash], i); p >= 0 {
+				return p, j + d
+			}
+		}
+	}
+	return len(left), len(right)
+}
+
+// chunkPositions maps each chunk hash to its sorted positions in the sequence
+func chunkPositions(chunks []Chunk) map[string][]int {
+	positions := make(map[string][]int, len(chunks))
+	for i, chunk := range chunks {
+		positions[chunk.Hash] = append(positions[chunk.Hash], i)
+	}
+	return positions
+}
+
+// firstAtOrAfter returns the first position >= from, or -1
+func firstAtOrAfter(positions []int, from int) int {
+	k := sort.SearchInts(positions, from)
+	if k == len(positions) {
+		return -1
+	}
+	return positions[k]
+}
+
+// chunkSpan returns the byte range and line range covered by chunks[from:to].
+// An empty span is positioned where the next chunk would start, with its end
+// line one before its start line.
+func chunkSpan(chunks []Chunk, from, to int) (offset, length int64, startLine, endLine int) {
+	switch {
+	case from < len(chunks):
+		offset = chunks[from].Offset
+		startLine = chunks[from].StartLine
+	case len(chunks) > 0:
+		last := chunks[len(chunks)-1]
+		offset = last.Offset + last.Length
+		startLine = last.nextLine
+	default:
+		startLine = 1
+	}
+	if to == from {
+		return offset, 0, startLine, startLine - 1
+	}
+	for _, chunk := range chunks[from:to] {
+		length += chunk.Length
+	}
+	return offset, length, startLine, chunks[to-1].EndLine
+}
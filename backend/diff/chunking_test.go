@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// chunkTestContent returns deterministic multi-line text of roughly the given size
+func chunkTestContent(lines int) []string {
+	rng := rand.New(rand.NewSource(1))
+	content := make([]string, lines)
+	for i := range content {
+		content[i] = fmt.Sprintf("%06d %x %x", i, rng.Int63(), rng.Int63())
+	}
+	return content
+}
+
+func TestChunkReader(t *testing.T) {
+	t.Run("empty input", func(t *testing.T) {
+		chunks, err := ChunkReader(strings.NewReader(""))
+		if err != nil {
+			t.Fatalf("ChunkReader returned error: %v", err)
+		}
+		if len(chunks) != 0 {
+			t.Errorf("Expected no chunks, got %d", len(chunks))
+		}
+	})
+
+	t.Run("chunks cover the input", func(t *testing.T) {
+		text := strings.Join(chunkTestContent(5000), "\n") + "\n"
+		chunks, err := ChunkReader(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("ChunkReader returned error: %v", err)
+		}
+		if len(chunks) < 2 {
+			t.Fatalf("Expected several chunks, got %d", len(chunks))
+		}
+
+		var offset int64
+		line := 1
+		for i, chunk := range chunks {
+			if chunk.Offset != offset {
+				t.Fatalf("Chunk %d starts at %d, expected %d", i, chunk.Offset, offset)
+			}
+			if chunk.StartLine != line {
+				t.Fatalf("Chunk %d starts on line %d, expected %d", i, chunk.StartLine, line)
+			}
+			if chunk.Length > maxChunkSize || (i < len(chunks)-1 && chunk.Length < minChunkSize) {
+				t.Errorf("Chunk %d has out of range length %d", i, chunk.Length)
+			}
+			offset += chunk.Length
+			line += strings.Count(text[chunk.Offset:chunk.Offset+chunk.Length], "\n")
+		}
+		if offset != int64(len(text)) {
+			t.Errorf("Chunks cover %d bytes, expected %d", offset, len(text))
+		}
+		if last := chunks[len(chunks)-1]; last.EndLine != 5000 {
+			t.Errorf("Expected last chunk to end on line 5000, got %d", last.EndLine)
+		}
+	})
+}
+
+func TestCompareChunks(t *testing.T) {
+	lines := chunkTestContent(5000)
+	original := strings.Join(lines, "\n") + "\n"
+
+	chunk := func(text string) []Chunk {
+		chunks, err := ChunkReader(strings.NewReader(text))
+		if err != nil {
+			t.Fatalf("ChunkReader returned error: %v", err)
+		}
+		return chunks
+	}
+
+	t.Run("identical", func(t *testing.T) {
+		regions := CompareChunks(chunk(original), chunk(original))
+		if len(regions) != 1 || regions[0].Status != "same" {
+			t.Fatalf("Expected a single same region, got %+v", regions)
+		}
+		if regions[0].LeftLength != int64(len(original)) || regions[0].LeftEndLine != 5000 {
+			t.Errorf("Expected the region to cover the file, got %+v", regions[0])
+		}
+	})
+
+	t.Run("insertion in the middle", func(t *testing.T) {
+		edited := append(append(append([]string{}, lines[:2500]...), "inserted line"), lines[2500:]...)
+		modified := strings.Join(edited, "\n") + "\n"
+
+		regions := CompareChunks(chunk(original), chunk(modified))
+		if len(regions) != 3 {
+			t.Fatalf("Expected same, changed, same regions, got %+v", regions)
+		}
+		changed := regions[1]
+		if regions[0].Status != "same" || changed.Status != "changed" || regions[2].Status != "same" {
+			t.Fatalf("Unexpected region statuses: %+v", regions)
+		}
+		if changed.RightStartLine > 2501 || changed.RightEndLine < 2501 {
+			t.Errorf("Expected the changed region to contain right line 2501, got %d-%d", changed.RightStartLine, changed.RightEndLine)
+		}
+		if changed.RightLength-changed.LeftLength != int64(len("inserted line\n")) {
+			t.Errorf("Expected the region sizes to differ by the inserted line, got %+v", changed)
+		}
+		if changed.LeftLength > 4*maxChunkSize {
+			t.Errorf("Expected a small changed region, got %d bytes", changed.LeftLength)
+		}
+	})
+
+	t.Run("appended content", func(t *testing.T) {
+		appended := original + strings.Repeat("tail line\n", 2000)
+		regions := CompareChunks(chunk(original), chunk(appended))
+		last := regions[len(regions)-1]
+		if last.Status != "added" && last.Status != "changed" {
+			t.Errorf("Expected the tail to differ, got %+v", regions)
+		}
+		if last.RightOffset+last.RightLength != int64(len(appended)) {
+			t.Errorf("Expected the last region to reach the end of the right file, got %+v", last)
+		}
+	})
+}
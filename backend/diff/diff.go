@@ -1,6 +1,16 @@
 // Package diff provides diff algorithms for comparing text files
 package diff
 
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/text/unicode/norm"
+)
+
 // DiffLine represents a single line in a diff result
 type DiffLine struct {
 	LeftLine    string `json:"leftLine"`
@@ -8,11 +18,66 @@ type DiffLine struct {
 	LeftNumber  int    `json:"leftNumber"`
 	RightNumber int    `json:"rightNumber"`
 	Type        string `json:"type"` // "same", "added", "removed", "modified"
+	// Segments shows which words changed within a "modified" line
+	Segments []Segment `json:"segments,omitempty"`
+	// LeftChanges and RightChanges give the changed characters of short
+	// "modified" lines
+	LeftChanges  []CharRange `json:"leftChanges,omitempty"`
+	RightChanges []CharRange `json:"rightChanges,omitempty"`
+	// LeftTime and RightTime are the Unix times of the commits that last
+	// changed each side's line, when line ages are shown. Zero means unknown
+	// or not yet committed.
+	LeftTime  int64 `json:"leftTime,omitempty"`
+	RightTime int64 `json:"rightTime,omitempty"`
 }
 
 // DiffResult contains the complete diff between two files
 type DiffResult struct {
-	Lines []DiffLine `json:"lines"`
+	Lines   []DiffLine     `json:"lines"`
+	Imports *ImportSummary `json:"imports,omitempty"` // set when import sections were compared as a set
+	// Reordered is set when both files hold the same lines in a different order
+	Reordered bool `json:"reordered,omitempty"`
+	// Minified is set when either file has lines too long to read in a line
+	// diff, such as minified scripts, which a tokenized comparison splits up
+	Minified bool `json:"minified,omitempty"`
+	// Chunks groups adjacent changed lines into navigable changes
+	Chunks []DiffChunk `json:"chunks"`
+	// Folds lists the unchanged lines left out when the result is folded
+	Folds []FoldedRegion `json:"folds,omitempty"`
+	// Metadata reports the file type and similarity settings the comparison used
+	Metadata *ComparisonMetadata `json:"metadata,omitempty"`
+	// Identical is set when the comparison found no differences, with the
+	// SHA-256 of the content in ContentHash
+	Identical   bool   `json:"identical,omitempty"`
+	ContentHash string `json:"contentHash,omitempty"`
+	// WhitespaceOnly is set when every difference found is in whitespace or
+	// blank lines, so ignoring whitespace would leave none
+	WhitespaceOnly bool `json:"whitespaceOnly,omitempty"`
+	// Approximate is set when modification detection ran out of time, so
+	// some modified lines may be shown as removed and added
+	Approximate bool `json:"approximate,omitempty"`
+	// LineEndingsDiffer is set when one file uses CRLF line endings and the
+	// other LF, a difference comparisons do not show
+	LineEndingsDiffer bool `json:"lineEndingsDiffer,omitempty"`
+	// Segmented is set when the files were too large to diff whole and were
+	// compared a window at a time, so long changes may be paired less
+	// precisely
+	Segmented bool `json:"segmented,omitempty"`
+	// Scope is set when only a range of lines of each file was compared
+	Scope *DiffScope `json:"scope,omitempty"`
+	// Records counts the records of CSV files compared by key columns
+	Records *RecordSummary `json:"records,omitempty"`
+	// Keys counts the keys of INI and TOML files compared by section
+	Keys *RecordSummary `json:"keys,omitempty"`
+}
+
+// DiffScope gives the lines (1-based, inclusive) of each file a scoped
+// comparison covers. Line numbers in the result refer to the full files.
+type DiffScope struct {
+	LeftStart  int `json:"leftStart"`
+	LeftEnd    int `json:"leftEnd"`
+	RightStart int `json:"rightStart"`
+	RightEnd   int `json:"rightEnd"`
 }
 
 // Algorithm defines the interface for diff algorithms
@@ -21,18 +86,159 @@ type Algorithm interface {
 	ComputeDiff(leftLines, rightLines []string) *DiffResult
 }
 
+// Names of the available diff algorithms
+const (
+	AlgorithmLCS       = "lcs"
+	AlgorithmMyers     = "myers"
+	AlgorithmPatience  = "patience"
+	AlgorithmHistogram = "histogram"
+)
+
 // Config holds configuration for diff algorithms
 type Config struct {
+	// Algorithm selects the diff algorithm created by New. Empty selects LCS.
+	Algorithm string
 	// SimilarityThreshold is the minimum similarity ratio (0.0-1.0) for lines to be considered modifications
 	SimilarityThreshold float64
 	// MinLineLength is the minimum line length to apply similarity checking
 	MinLineLength int
+	// TabWidth is the number of columns a tab advances to, used so tab-indented
+	// lines are measured the way they are displayed
+	TabWidth int
+	// MaxCharDiffLength is the longest line, in characters, whose changes
+	// are also located character by character
+	MaxCharDiffLength int
+	// Whitespace selects which whitespace differences are ignored when
+	// matching lines. Lines matched despite such differences are "same".
+	Whitespace string
+	// IgnoreCase matches lines that differ only in letter case
+	IgnoreCase bool
+	// IgnorePatterns match lines that are always equal to each other, such
+	// as generated timestamps, so a matching line is "same" as any other
+	// matching line
+	IgnorePatterns []*regexp.Regexp
+	// IgnoreBlankLines matches only non-blank lines, so blank lines are never
+	// differences. A blank line without a counterpart is "same" with line
+	// number 0 on the other side.
+	IgnoreBlankLines bool
+	// NormalizeUnicode compares lines in Unicode normalization form C, so
+	// composed and decomposed forms of the same characters match
+	NormalizeUnicode bool
+	// TimeBudget limits the time spent scoring line similarity to find
+	// modifications. Zero means no limit.
+	TimeBudget time.Duration
+	// Done stops the comparison early once it is closed, leaving a partial
+	// result for the caller to discard. Nil runs every comparison to the end.
+	Done <-chan struct{}
+}
+
+// Configurable is implemented by algorithms whose configuration can be
+// changed for a single comparison
+type Configurable interface {
+	Algorithm
+	// Config returns the algorithm's configuration
+	Config() Config
+	// WithConfig returns a copy of the algorithm using the given configuration
+	WithConfig(config Config) Algorithm
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() Config {
 	return Config{
+		Algorithm:           AlgorithmLCS,
 		SimilarityThreshold: 0.7,
 		MinLineLength:       10,
+		TabWidth:            4,
+		MaxCharDiffLength:   200,
+	}
+}
+
+// New creates the diff algorithm selected by the configuration
+func New(config Config) Algorithm {
+	switch config.Algorithm {
+	case AlgorithmMyers:
+		return NewMyers(config)
+	case AlgorithmPatience:
+		return NewPatience(config)
+	case AlgorithmHistogram:
+		return NewHistogram(config)
+	default:
+		return NewLCS(config)
+	}
+}
+
+// WithContext returns the algorithm configured to stop early once ctx is
+// cancelled. Algorithms without a configuration always run to the end.
+func WithContext(ctx context.Context, algorithm Algorithm) Algorithm {
+	configurable, ok := algorithm.(Configurable)
+	if !ok || ctx.Done() == nil {
+		return algorithm
+	}
+	config := configurable.Config()
+	config.Done = ctx.Done()
+	return configurable.WithConfig(config)
+}
+
+// stopped reports whether done, a Config.Done channel, has been closed
+func stopped(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
+}
+
+// ignoredLineKey is the comparison key of every line matching an ignore
+// pattern. It cannot be the key of a line read from a file.
+const ignoredLineKey = "\x00ignored\x00"
+
+// matchesAny reports whether any of the patterns matches line
+func matchesAny(patterns []*regexp.Regexp, line string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// comparisonKeys returns the lines in the form they are matched in under the
+// configuration, or the lines themselves when they are matched exactly
+func comparisonKeys(lines []string, config Config) []string {
+	if config.Whitespace == WhitespaceExact && !config.IgnoreCase && len(config.IgnorePatterns) == 0 && !config.NormalizeUnicode {
+		return lines
+	}
+	keys := make([]string, len(lines))
+	for i, line := range lines {
+		if config.NormalizeUnicode {
+			line = norm.NFC.String(line)
+		}
+		if matchesAny(config.IgnorePatterns, line) {
+			keys[i] = ignoredLineKey
+			continue
+		}
+		key := NormalizeWhitespace(line, config.Whitespace)
+		if config.IgnoreCase {
+			key = strings.ToLower(key)
+		}
+		keys[i] = key
+	}
+	return keys
+}
+
+// CompileIgnorePatterns compiles regular expressions for Config.IgnorePatterns
+func CompileIgnorePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern == "" {
+			return nil, fmt.Errorf("ignore pattern cannot be empty")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ignore pattern %q: %w", pattern, err)
+		}
+		compiled = append(compiled, re)
 	}
+	return compiled, nil
 }
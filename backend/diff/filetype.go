@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of file content, which call for different modification detection
+const (
+	FileTypeCode  = "code"
+	FileTypeProse = "prose"
+	FileTypeData  = "data"
+)
+
+// ComparisonMetadata reports how a comparison was tuned for its files
+type ComparisonMetadata struct {
+	FileType            string  `json:"fileType"` // "code", "prose", or "data"
+	SimilarityThreshold float64 `json:"similarityThreshold"`
+	MinLineLength       int     `json:"minLineLength"`
+}
+
+// fileTypeExtensions maps extensions that are not languages to file types
+var fileTypeExtensions = map[string]string{
+	".rst":        FileTypeProse,
+	".adoc":       FileTypeProse,
+	".tex":        FileTypeProse,
+	".json":       FileTypeData,
+	".yaml":       FileTypeData,
+	".yml":        FileTypeData,
+	".toml":       FileTypeData,
+	".ini":        FileTypeData,
+	".csv":        FileTypeData,
+	".tsv":        FileTypeData,
+	".xml":        FileTypeData,
+	".properties": FileTypeData,
+	".env":        FileTypeData,
+	".lock":       FileTypeData,
+}
+
+// DetectFileType classifies a file as code, prose, or data by its extension,
+// falling back to the shape of its lines for unknown extensions
+func DetectFileType(path string, lines []string) string {
+	switch LanguageForPath(path) {
+	case "":
+	case LanguageMarkdown:
+		return FileTypeProse
+	default:
+		return FileTypeCode
+	}
+	if fileType, ok := fileTypeExtensions[strings.ToLower(filepath.Ext(path))]; ok {
+		return fileType
+	}
+
+	// Prose has long lines of words; data is mostly short key-value or
+	// delimited records
+	var nonBlank, words, records int
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+		nonBlank++
+		words += len(strings.Fields(trimmed))
+		if strings.ContainsAny(trimmed, "=:,\t") && !strings.ContainsAny(trimmed, "(){};") {
+			records++
+		}
+	}
+	switch {
+	case nonBlank == 0:
+		return FileTypeCode
+	case words >= 8*nonBlank:
+		return FileTypeProse
+	case records*10 >= nonBlank*8:
+		return FileTypeData
+	}
+	return FileTypeCode
+}
+
+// TuneConfig adjusts the modification detection of a configuration for a
+// file type. Rewritten prose shares fewer characters with the original than
+// edited code, so it needs a lower threshold, and short prose lines are
+// headings and list items best matched exactly. Data lines are short, so
+// shorter lines are still compared by similarity.
+func TuneConfig(config Config, fileType string) Config {
+	switch fileType {
+	case FileTypeProse:
+		config.SimilarityThreshold = 0.5
+		config.MinLineLength = 20
+	case FileTypeData:
+		config.SimilarityThreshold = 0.6
+		config.MinLineLength = 5
+	default:
+		defaults := DefaultConfig()
+		config.SimilarityThreshold = defaults.SimilarityThreshold
+		config.MinLineLength = defaults.MinLineLength
+	}
+	return config
+}
@@ -0,0 +1,49 @@
+package diff
+
+import "testing"
+
+func TestDetectFileType(t *testing.T) {
+	prose := []string{
+		"The quick brown fox jumps over the lazy dog and keeps on running.",
+		"",
+		"Nobody knows where it is going, but everyone agrees it is in a hurry.",
+	}
+	tests := []struct {
+		name  string
+		path  string
+		lines []string
+		want  string
+	}{
+		{"go source", "main.go", nil, FileTypeCode},
+		{"markdown", "README.md", nil, FileTypeProse},
+		{"yaml", "config.yml", nil, FileTypeData},
+		{"prose text", "notes.txt", prose, FileTypeProse},
+		{"records", "hosts", []string{"web=10.0.0.1", "db=10.0.0.2", "cache=10.0.0.3"}, FileTypeData},
+		{"code text", "snippet.txt", []string{"if (x) {", "    run(x);", "}"}, FileTypeCode},
+		{"empty", "empty.txt", nil, FileTypeCode},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectFileType(tt.path, tt.lines); got != tt.want {
+				t.Errorf("DetectFileType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTuneConfig(t *testing.T) {
+	base := DefaultConfig()
+	base.TabWidth = 8
+
+	prose := TuneConfig(base, FileTypeProse)
+	if prose.SimilarityThreshold >= base.SimilarityThreshold || prose.TabWidth != 8 {
+		t.Errorf("Expected a lower threshold for prose with other settings kept, got %+v", prose)
+	}
+	data := TuneConfig(base, FileTypeData)
+	if data.MinLineLength >= base.MinLineLength {
+		t.Errorf("Expected shorter data lines to be compared by similarity, got %+v", data)
+	}
+	if code := TuneConfig(prose, FileTypeCode); code.SimilarityThreshold != base.SimilarityThreshold || code.MinLineLength != base.MinLineLength {
+		t.Errorf("Expected the defaults for code, got %+v", code)
+	}
+}
@@ -0,0 +1,105 @@
+package diff
+
+import "fmt"
+
+// minFoldedLines is the fewest unchanged lines worth hiding, since the fold
+// marker itself takes up a row
+const minFoldedLines = 2
+
+// FoldedRegion is a run of unchanged lines left out of a folded DiffResult
+type FoldedRegion struct {
+	ID string `json:"id"`
+	// Index is the position in the folded result's Lines where the region's
+	// lines belong
+	Index int `json:"index"`
+	// StartIndex and EndIndex delimit the region in the unfolded result's Lines
+	StartIndex int `json:"startIndex"`
+	EndIndex   int `json:"endIndex"`
+	LeftStart  int `json:"leftStart"`  // 1-based first left line, 0 if none
+	RightStart int `json:"rightStart"` // 1-based first right line, 0 if none
+	Count      int `json:"count"`
+}
+
+// FoldUnchanged returns a copy of result that keeps only the given number of
+// unchanged context lines around each change. Longer runs of "same" lines are
+// left out and described in Folds; Chunks are re-indexed to the kept lines.
+func FoldUnchanged(result *DiffResult, context int) *DiffResult {
+	if result == nil {
+		return nil
+	}
+	context = max(context, 0)
+
+	folded := *result
+	folded.Lines = make([]DiffLine, 0, len(result.Lines))
+	folded.Folds = []FoldedRegion{}
+	newIndex := make([]int, len(result.Lines)+1)
+
+	for i := 0; i < len(result.Lines); {
+		if result.Lines[i].Type != "same" {
+			newIndex[i] = len(folded.Lines)
+			folded.Lines = append(folded.Lines, result.Lines[i])
+			i++
+			continue
+		}
+
+		end := i
+		for end < len(result.Lines) && result.Lines[end].Type == "same" {
+			end++
+		}
+		// Context is kept only on the sides of the run that border a change
+		hideStart, hideEnd := i, end
+		if i > 0 {
+			hideStart = min(i+context, end)
+		}
+		if end < len(result.Lines) {
+			hideEnd = max(end-context, hideStart)
+		}
+		if hideEnd-hideStart < minFoldedLines {
+			hideStart, hideEnd = end, end
+		}
+
+		for j := i; j < end; j++ {
+			newIndex[j] = len(folded.Lines)
+			if j == hideStart {
+				first := result.Lines[hideStart]
+				folded.Folds = append(folded.Folds, FoldedRegion{
+					ID:         fmt.Sprintf("fold-%d-%d", hideStart, hideEnd),
+					Index:      len(folded.Lines),
+					StartIndex: hideStart,
+					EndIndex:   hideEnd,
+					LeftStart:  first.LeftNumber,
+					RightStart: first.RightNumber,
+					Count:      hideEnd - hideStart,
+				})
+			}
+			if j < hideStart || j >= hideEnd {
+				folded.Lines = append(folded.Lines, result.Lines[j])
+			}
+		}
+		i = end
+	}
+	newIndex[len(result.Lines)] = len(folded.Lines)
+
+	folded.Chunks = make([]DiffChunk, len(result.Chunks))
+	for i, chunk := range result.Chunks {
+		chunk.StartIndex = newIndex[chunk.StartIndex]
+		chunk.EndIndex = newIndex[chunk.EndIndex-1] + 1
+		folded.Chunks[i] = chunk
+	}
+	return &folded
+}
+
+// FoldedLines returns the lines of result hidden by the folded region with
+// the given ID, or false if result has no such region
+func FoldedLines(result *DiffResult, context int, id string) ([]DiffLine, bool) {
+	folded := FoldUnchanged(result, context)
+	if folded == nil {
+		return nil, false
+	}
+	for _, region := range folded.Folds {
+		if region.ID == id {
+			return append([]DiffLine(nil), result.Lines[region.StartIndex:region.EndIndex]...), true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestFoldUnchanged(t *testing.T) {
+	var left, right []string
+	for i := 1; i <= 20; i++ {
+		line := fmt.Sprintf("line %d", i)
+		left = append(left, line)
+		if i == 10 {
+			line = "changed line"
+		}
+		right = append(right, line)
+	}
+	result := NewLCSDefault().ComputeDiff(left, right)
+
+	t.Run("keeps context around changes", func(t *testing.T) {
+		folded := FoldUnchanged(result, 2)
+		// Lines 8-9 and 11-12 are context, the rest is folded
+		if len(folded.Folds) != 2 {
+			t.Fatalf("Expected 2 folds, got %+v", folded.Folds)
+		}
+		before, after := folded.Folds[0], folded.Folds[1]
+		if before.Index != 0 || before.LeftStart != 1 || before.Count != 7 {
+			t.Errorf("Unexpected leading fold: %+v", before)
+		}
+		if after.LeftStart != 13 || after.Count != 8 || after.Index != len(folded.Lines) {
+			t.Errorf("Unexpected trailing fold: %+v", after)
+		}
+		if len(folded.Lines) != len(result.Lines)-15 {
+			t.Errorf("Expected 15 lines to be hidden, got %d lines", len(folded.Lines))
+		}
+		if len(result.Lines) != 21 {
+			t.Errorf("Expected the original result to be unchanged, got %d lines", len(result.Lines))
+		}
+	})
+
+	t.Run("re-indexes chunks", func(t *testing.T) {
+		folded := FoldUnchanged(result, 1)
+		if len(folded.Chunks) != 1 {
+			t.Fatalf("Expected 1 chunk, got %+v", folded.Chunks)
+		}
+		chunk := folded.Chunks[0]
+		if chunk.ID != result.Chunks[0].ID {
+			t.Errorf("Expected the chunk ID to be kept, got %s", chunk.ID)
+		}
+		for i := chunk.StartIndex; i < chunk.EndIndex; i++ {
+			if folded.Lines[i].Type == "same" {
+				t.Errorf("Chunk covers an unchanged line: %+v", folded.Lines[i])
+			}
+		}
+	})
+
+	t.Run("short runs stay visible", func(t *testing.T) {
+		folded := FoldUnchanged(result, 9)
+		if len(folded.Folds) != 0 || !reflect.DeepEqual(folded.Lines, result.Lines) {
+			t.Errorf("Expected nothing to be folded, got %+v", folded.Folds)
+		}
+	})
+
+	t.Run("expands a fold", func(t *testing.T) {
+		folded := FoldUnchanged(result, 2)
+		lines, ok := FoldedLines(result, 2, folded.Folds[1].ID)
+		if !ok || len(lines) != 8 || lines[0].LeftLine != "line 13" || lines[7].LeftLine != "line 20" {
+			t.Errorf("Unexpected folded lines: %+v", lines)
+		}
+		if _, ok := FoldedLines(result, 2, "fold-missing"); ok {
+			t.Error("Expected an unknown fold not to be found")
+		}
+	})
+}
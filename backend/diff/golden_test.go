@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// updateGolden rewrites the golden files instead of comparing against them,
+// for changes to algorithm output that are intended:
+//
+//	go test ./backend/diff -run TestGolden -update
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata/golden")
+
+// goldenDir holds one directory per case with left.txt and right.txt, and
+// the expected result of each algorithm in <algorithm>.json
+const goldenDir = "testdata/golden"
+
+func TestGolden(t *testing.T) {
+	cases, err := os.ReadDir(goldenDir)
+	if err != nil {
+		t.Fatalf("Failed to read golden cases: %v", err)
+	}
+	if len(cases) == 0 {
+		t.Fatal("Expected golden cases in " + goldenDir)
+	}
+
+	for _, entry := range cases {
+		if !entry.IsDir() {
+			continue
+		}
+		dir := filepath.Join(goldenDir, entry.Name())
+		left := readGoldenLines(t, filepath.Join(dir, "left.txt"))
+		right := readGoldenLines(t, filepath.Join(dir, "right.txt"))
+
+		for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+			t.Run(entry.Name()+"/"+name, func(t *testing.T) {
+				config := DefaultConfig()
+				config.Algorithm = name
+				got, err := json.MarshalIndent(New(config).ComputeDiff(left, right), "", "  ")
+				if err != nil {
+					t.Fatalf("Failed to encode result: %v", err)
+				}
+				got = append(got, '\n')
+
+				path := filepath.Join(dir, name+".json")
+				if *updateGolden {
+					if err := os.WriteFile(path, got, 0644); err != nil {
+						t.Fatalf("Failed to write golden file: %v", err)
+					}
+					return
+				}
+				want, err := os.ReadFile(path)
+				if err != nil {
+					t.Fatalf("Failed to read golden file (run with -update to create it): %v", err)
+				}
+				if !bytes.Equal(got, want) {
+					t.Errorf("Result differs from %s; if the change is intended, run with -update and review the diff\n%s", path, got)
+				}
+			})
+		}
+	}
+}
+
+// readGoldenLines returns the lines of a case file, without the final newline
+func readGoldenLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read golden case: %v", err)
+	}
+	content := strings.TrimSuffix(string(data), "\n")
+	if content == "" {
+		return []string{}
+	}
+	return strings.Split(content, "\n")
+}
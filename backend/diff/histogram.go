@@ -0,0 +1,149 @@
+package diff
+
+// histogramMaxOccurrences is how often a line may occur in the left region
+// and still anchor a match. Regions whose lines all occur more often than this
+// are compared with Myers instead.
+const histogramMaxOccurrences = 64
+
+// Histogram implements histogram diff, the default algorithm of git. Like
+// patience diff it anchors on rare lines, but it accepts lines that occur more
+// than once, preferring the matching run whose rarest line occurs least often.
+// That keeps files with many repeated lines, such as logs and generated code,
+// aligned on their distinctive lines.
+type Histogram struct {
+	config Config
+}
+
+// NewHistogram creates a new histogram diff algorithm with the given configuration
+func NewHistogram(config Config) *Histogram {
+	return &Histogram{config: config}
+}
+
+// NewHistogramDefault creates a new histogram diff algorithm with default configuration
+func NewHistogramDefault() *Histogram {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmHistogram
+	return NewHistogram(config)
+}
+
+// Config returns the algorithm's configuration
+func (h *Histogram) Config() Config {
+	return h.config
+}
+
+// WithConfig returns the algorithm selected by the given configuration
+func (h *Histogram) WithConfig(config Config) Algorithm {
+	return New(config)
+}
+
+// ComputeDiff compares two sets of lines and returns the diff result
+func (h *Histogram) ComputeDiff(leftLines, rightLines []string) *DiffResult {
+	if h.config.IgnoreBlankLines {
+		return diffIgnoringBlankLines(leftLines, rightLines, h.config)
+	}
+
+	left, right := internLines(comparisonKeys(leftLines, h.config), comparisonKeys(rightLines, h.config))
+
+	matches := make([]int, len(left))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &myersState{left: left, right: right, matches: matches, done: h.config.Done}
+	histogramCompare(s, 0, len(left), 0, len(right))
+
+	result := &DiffResult{Lines: buildDiffLines(leftLines, rightLines, matches)}
+	return detectModifications(result, h.config)
+}
+
+// histogramRegion is a run of lines matching on both sides
+type histogramRegion struct {
+	leftStart  int
+	rightStart int
+	length     int
+	// occurrences is how often the rarest line of the run occurs on the left
+	occurrences int
+}
+
+// histogramCompare matches left[aLo:aHi] against right[bLo:bHi] by splitting
+// the ranges around the matching run with the rarest lines
+func histogramCompare(s *myersState, aLo, aHi, bLo, bHi int) {
+	if stopped(s.done) {
+		return
+	}
+	for aLo < aHi && bLo < bHi && s.left[aLo] == s.right[bLo] {
+		s.matches[aLo] = bLo
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && s.left[aHi-1] == s.right[bHi-1] {
+		aHi--
+		bHi--
+		s.matches[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	region, ok := findHistogramRegion(s.left, s.right, aLo, aHi, bLo, bHi)
+	if !ok {
+		s.compare(aLo, aHi, bLo, bHi)
+		return
+	}
+
+	histogramCompare(s, aLo, region.leftStart, bLo, region.rightStart)
+	for i := 0; i < region.length; i++ {
+		s.matches[region.leftStart+i] = region.rightStart + i
+	}
+	histogramCompare(s, region.leftStart+region.length, aHi, region.rightStart+region.length, bHi)
+}
+
+// findHistogramRegion finds the matching run whose rarest line occurs least
+// often on the left, preferring longer runs on ties. ok is false when no line
+// occurs on both sides at most histogramMaxOccurrences times.
+func findHistogramRegion(left, right []int, aLo, aHi, bLo, bHi int) (histogramRegion, bool) {
+	positions := make(map[int][]int)
+	for i := aLo; i < aHi; i++ {
+		positions[left[i]] = append(positions[left[i]], i)
+	}
+
+	best := histogramRegion{occurrences: histogramMaxOccurrences + 1}
+	for j := bLo; j < bHi; {
+		next := j + 1
+		candidates := positions[right[j]]
+		if len(candidates) == 0 || len(candidates) > best.occurrences {
+			j = next
+			continue
+		}
+
+		for _, i := range candidates {
+			// Extend the match in both directions
+			start, rightStart := i, j
+			for start > aLo && rightStart > bLo && left[start-1] == right[rightStart-1] {
+				start--
+				rightStart--
+			}
+			end, rightEnd := i+1, j+1
+			for end < aHi && rightEnd < bHi && left[end] == right[rightEnd] {
+				end++
+				rightEnd++
+			}
+			next = max(next, rightEnd)
+
+			occurrences := len(candidates)
+			for k := start; k < end; k++ {
+				occurrences = min(occurrences, len(positions[left[k]]))
+			}
+			length := end - start
+			if occurrences < best.occurrences || (occurrences == best.occurrences && length > best.length) {
+				best = histogramRegion{
+					leftStart:   start,
+					rightStart:  rightStart,
+					length:      length,
+					occurrences: occurrences,
+				}
+			}
+		}
+		j = next
+	}
+	return best, best.length > 0
+}
@@ -0,0 +1,85 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestHistogram_ComputeDiff(t *testing.T) {
+	histogram := NewHistogramDefault()
+
+	t.Run("anchors on the rarest line", func(t *testing.T) {
+		left := []string{"name", "}", "}", "}"}
+		right := []string{"}", "}", "}", "name"}
+
+		types := []string{}
+		for _, line := range histogram.ComputeDiff(left, right).Lines {
+			types = append(types, line.Type)
+		}
+		expected := []string{"added", "added", "added", "same", "removed", "removed", "removed"}
+		if !reflect.DeepEqual(types, expected) {
+			t.Errorf("Expected %v, got %v", expected, types)
+		}
+	})
+
+	t.Run("repeated log lines", func(t *testing.T) {
+		// Every line repeats, but the request IDs repeat least
+		var left, right []string
+		for i := 0; i < 20; i++ {
+			left = append(left, "INFO heartbeat", fmt.Sprintf("INFO request %d", i%5), "INFO heartbeat")
+		}
+		right = append(right, left[:30]...)
+		right = append(right, "ERROR timeout")
+		right = append(right, left[30:]...)
+
+		result := histogram.ComputeDiff(left, right)
+		if got := countSame(result); got != len(left) {
+			t.Errorf("Expected %d unchanged lines, got %d", len(left), got)
+		}
+		for _, line := range result.Lines {
+			if line.Type == "added" && line.RightLine != "ERROR timeout" {
+				t.Errorf("Expected only the error line to be added, got %+v", line)
+			}
+		}
+	})
+
+	t.Run("no low-occurrence lines falls back to myers", func(t *testing.T) {
+		var left, right []string
+		for i := 0; i < 2*histogramMaxOccurrences; i++ {
+			left = append(left, "x", "y")
+			right = append(right, "y", "x")
+		}
+		result := histogram.ComputeDiff(left, right)
+		gotLeft, gotRight := reconstruct(result)
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatal("Diff does not reproduce the inputs")
+		}
+		if got, want := countSame(result), countSame(NewMyersDefault().ComputeDiff(left, right)); got != want {
+			t.Errorf("Expected %d unchanged lines, got %d", want, got)
+		}
+	})
+}
+
+func TestHistogram_RandomInputs(t *testing.T) {
+	histogram := NewHistogramDefault()
+	rng := rand.New(rand.NewSource(3))
+	alphabet := []string{"a", "b", "c", "d", "e"}
+
+	for i := 0; i < 500; i++ {
+		left := make([]string, rng.Intn(30))
+		for j := range left {
+			left[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+		right := make([]string, rng.Intn(30))
+		for j := range right {
+			right[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+
+		gotLeft, gotRight := reconstruct(histogram.ComputeDiff(left, right))
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatalf("Diff of %v and %v does not reproduce the inputs", left, right)
+		}
+	}
+}
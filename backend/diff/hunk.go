@@ -0,0 +1,144 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Hunk is a run of adjacent changed lines in a DiffResult
+type Hunk struct {
+	// ID is derived from the hunk's content and surrounding context, so it
+	// stays the same when edits elsewhere shift the hunk's line numbers
+	ID         string `json:"id"`
+	Type       string `json:"type"`       // "added", "removed", or "modified"
+	StartIndex int    `json:"startIndex"` // index of the first line in DiffResult.Lines
+	EndIndex   int    `json:"endIndex"`   // index one past the last line in DiffResult.Lines
+	// LeftStart is the 1-based first left line of the hunk. When LeftCount is 0
+	// it is the line before which the right side's lines would be inserted.
+	LeftStart  int `json:"leftStart"`
+	LeftCount  int `json:"leftCount"`
+	RightStart int `json:"rightStart"`
+	RightCount int `json:"rightCount"`
+}
+
+// DiffChunk is a hunk as carried in DiffResult.Chunks
+type DiffChunk = Hunk
+
+// GroupHunks groups adjacent non-"same" lines of a diff into hunks
+func GroupHunks(result *DiffResult) []Hunk {
+	hunks := []Hunk{}
+	if result == nil {
+		return hunks
+	}
+
+	nextLeft, nextRight := 1, 1
+	var current *Hunk
+
+	for i, line := range result.Lines {
+		if line.Type == "same" {
+			if current != nil {
+				current.EndIndex = i
+				hunks = append(hunks, *current)
+				current = nil
+			}
+		} else {
+			if current == nil {
+				current = &Hunk{
+					Type:       line.Type,
+					StartIndex: i,
+					LeftStart:  nextLeft,
+					RightStart: nextRight,
+				}
+			} else if current.Type != line.Type {
+				// A hunk that both removes and adds lines is a modification
+				current.Type = "modified"
+			}
+			// Structural diffs can match reordered lines, so a hunk's lines
+			// need not follow the lines before it: start at its own lowest
+			if line.LeftNumber > 0 {
+				if current.LeftCount == 0 || line.LeftNumber < current.LeftStart {
+					current.LeftStart = line.LeftNumber
+				}
+				current.LeftCount++
+			}
+			if line.RightNumber > 0 {
+				if current.RightCount == 0 || line.RightNumber < current.RightStart {
+					current.RightStart = line.RightNumber
+				}
+				current.RightCount++
+			}
+		}
+
+		if line.LeftNumber > 0 {
+			nextLeft = line.LeftNumber + 1
+		}
+		if line.RightNumber > 0 {
+			nextRight = line.RightNumber + 1
+		}
+	}
+
+	if current != nil {
+		current.EndIndex = len(result.Lines)
+		hunks = append(hunks, *current)
+	}
+
+	// Identical hunks with identical context get an occurrence suffix
+	seen := make(map[string]int)
+	for i := range hunks {
+		id := hunkHash(result.Lines, hunks[i])
+		seen[id]++
+		if seen[id] > 1 {
+			id = fmt.Sprintf("%s-%d", id, seen[id])
+		}
+		hunks[i].ID = id
+	}
+	return hunks
+}
+
+// hunkHash hashes a hunk's lines together with the nearest unchanged line on
+// either side, which anchors otherwise identical hunks to their location
+func hunkHash(lines []DiffLine, hunk Hunk) string {
+	h := sha256.New()
+
+	if hunk.StartIndex > 0 {
+		h.Write([]byte(lines[hunk.StartIndex-1].LeftLine))
+	}
+	h.Write([]byte{0})
+	for _, line := range lines[hunk.StartIndex:hunk.EndIndex] {
+		if line.LeftNumber > 0 {
+			h.Write([]byte("-" + line.LeftLine + "\n"))
+		}
+		if line.RightNumber > 0 {
+			h.Write([]byte("+" + line.RightLine + "\n"))
+		}
+	}
+	h.Write([]byte{0})
+	if hunk.EndIndex < len(lines) {
+		h.Write([]byte(lines[hunk.EndIndex].LeftLine))
+	}
+
+	return "h" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// HashLine returns a stable identifier for a line's content, used to
+// re-anchor annotations when line numbers shift
+func HashLine(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// OffsetLineNumbers shifts the line numbers of a diff computed on a window of
+// each file so they refer to positions in the full files. Zero line numbers
+// (the missing side of an added or removed line) are left untouched.
+func OffsetLineNumbers(result *DiffResult, leftOffset, rightOffset int) {
+	for i := range result.Lines {
+		if result.Lines[i].LeftNumber > 0 {
+			result.Lines[i].LeftNumber += leftOffset
+		}
+		if result.Lines[i].RightNumber > 0 {
+			result.Lines[i].RightNumber += rightOffset
+		}
+	}
+	result.Chunks = GroupHunks(result)
+}
@@ -0,0 +1,210 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGroupHunks(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	t.Run("nil result", func(t *testing.T) {
+		if hunks := GroupHunks(nil); len(hunks) != 0 {
+			t.Errorf("Expected no hunks, got %d", len(hunks))
+		}
+	})
+
+	t.Run("identical content", func(t *testing.T) {
+		result := lcs.ComputeDiff([]string{"a", "b"}, []string{"a", "b"})
+		if hunks := GroupHunks(result); len(hunks) != 0 {
+			t.Errorf("Expected no hunks, got %d", len(hunks))
+		}
+	})
+
+	t.Run("addition, removal and modification", func(t *testing.T) {
+		left := []string{"keep", "old value here", "keep2", "gone", "end"}
+		right := []string{"new first", "keep", "new value here", "keep2", "end"}
+		result := lcs.ComputeDiff(left, right)
+		hunks := GroupHunks(result)
+
+		if len(hunks) != 3 {
+			t.Fatalf("Expected 3 hunks, got %d: %+v", len(hunks), hunks)
+		}
+
+		added := hunks[0]
+		if added.Type != "added" || added.LeftCount != 0 || added.RightCount != 1 {
+			t.Errorf("Unexpected first hunk: %+v", added)
+		}
+		if added.LeftStart != 1 || added.RightStart != 1 {
+			t.Errorf("Expected insertion before left line 1, got %+v", added)
+		}
+
+		modified := hunks[1]
+		if modified.Type != "modified" || modified.LeftStart != 2 || modified.RightStart != 3 {
+			t.Errorf("Unexpected second hunk: %+v", modified)
+		}
+
+		removed := hunks[2]
+		if removed.Type != "removed" || removed.LeftStart != 4 || removed.LeftCount != 1 {
+			t.Errorf("Unexpected third hunk: %+v", removed)
+		}
+		if removed.RightStart != 5 || removed.RightCount != 0 {
+			t.Errorf("Expected insertion point before right line 5, got %+v", removed)
+		}
+
+		for i, hunk := range hunks {
+			if hunk.ID == "" {
+				t.Errorf("Hunk %d has no ID", i)
+			}
+			for j := hunk.StartIndex; j < hunk.EndIndex; j++ {
+				if result.Lines[j].Type == "same" {
+					t.Errorf("Hunk %d includes a same line at index %d", i, j)
+				}
+			}
+		}
+	})
+}
+
+func TestGroupHunks_ReorderedLines(t *testing.T) {
+	// The semantic diffs match reordered members, so the changed c member
+	// follows the b member on the right but not on the left
+	for _, tt := range []struct {
+		name        string
+		left, right []string
+		diff        func(left, right []string) (*DiffResult, bool)
+	}{
+		{
+			name:  "JSON",
+			left:  []string{"{", `  "b": 1,`, `  "a": 2,`, `  "c": 3`, "}"},
+			right: []string{"{", `  "a": 2,`, `  "b": 1,`, `  "c": 4`, "}"},
+			diff: func(left, right []string) (*DiffResult, bool) {
+				return SemanticJSONDiff(left, right, NewLCSDefault())
+			},
+		},
+		{
+			name:  "YAML",
+			left:  []string{"x:", "  b: 1", "  a: 2", "  c: 3", "y: 0"},
+			right: []string{"x:", "  a: 2", "  b: 1", "  c: 4", "y: 0"},
+			diff: func(left, right []string) (*DiffResult, bool) {
+				return SemanticYAMLDiff(left, right, NewLCSDefault(), false)
+			},
+		},
+		{
+			name:  "TOML",
+			left:  []string{"[x]", "b = 1", "a = 2", "c = 3", "[y]"},
+			right: []string{"[x]", "a = 2", "b = 1", "c = 4", "[y]"},
+			diff: func(left, right []string) (*DiffResult, bool) {
+				return SemanticConfigDiff(left, right, NewLCSDefault(), false)
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, ok := tt.diff(tt.left, tt.right)
+			if !ok {
+				t.Fatal("Expected both files to parse")
+			}
+			hunks := GroupHunks(result)
+			if len(hunks) != 1 {
+				t.Fatalf("Expected only the c member to differ, got %+v", hunks)
+			}
+			if hunk := hunks[0]; hunk.LeftStart != 4 || hunk.RightStart != 4 || hunk.LeftCount != 1 || hunk.RightCount != 1 {
+				t.Errorf("Expected the hunk to start at the c lines, got %+v", hunk)
+			}
+		})
+	}
+}
+
+func TestOffsetLineNumbers(t *testing.T) {
+	result := &DiffResult{Lines: []DiffLine{
+		{LeftNumber: 1, RightNumber: 1, Type: "same"},
+		{LeftNumber: 0, RightNumber: 2, Type: "added"},
+		{LeftNumber: 2, RightNumber: 0, Type: "removed"},
+	}}
+
+	OffsetLineNumbers(result, 10, 20)
+
+	expected := [][2]int{{11, 21}, {0, 22}, {12, 0}}
+	for i, line := range result.Lines {
+		if line.LeftNumber != expected[i][0] || line.RightNumber != expected[i][1] {
+			t.Errorf("Line %d: expected %v, got [%d %d]", i, expected[i], line.LeftNumber, line.RightNumber)
+		}
+	}
+	if len(result.Chunks) != 1 || result.Chunks[0].RightStart != 22 || result.Chunks[0].LeftStart != 12 {
+		t.Errorf("Expected chunks regrouped at the new line numbers, got %+v", result.Chunks)
+	}
+}
+
+func TestDiffResult_Chunks(t *testing.T) {
+	left := []string{"a", "b", "c", "", "d", "e"}
+	right := []string{"a", "B", "x", "c", "d", "f"}
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		for _, ignoreBlank := range []bool{false, true} {
+			config := DefaultConfig()
+			config.Algorithm = name
+			config.IgnoreBlankLines = ignoreBlank
+			result := New(config).ComputeDiff(left, right)
+
+			if !reflect.DeepEqual(result.Chunks, GroupHunks(result)) {
+				t.Errorf("%s (ignore blank lines %v): expected chunks to match the lines, got %+v", name, ignoreBlank, result.Chunks)
+			}
+			// Adjacent changed lines form a single chunk
+			for _, chunk := range result.Chunks {
+				for i := chunk.StartIndex; i < chunk.EndIndex; i++ {
+					if result.Lines[i].Type == "same" {
+						t.Errorf("%s: chunk %s includes an unchanged line", name, chunk.ID)
+					}
+				}
+			}
+		}
+	}
+
+	sorted := SortedDiff(left, right, NewLCSDefault())
+	if !reflect.DeepEqual(sorted.Chunks, GroupHunks(sorted)) {
+		t.Errorf("Expected sorted diff chunks to match its lines, got %+v", sorted.Chunks)
+	}
+}
+
+func TestGroupHunks_StableIDs(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	left := []string{"header", "one", "two", "three", "footer"}
+	right := []string{"header", "one", "2", "three", "footer"}
+	original := GroupHunks(lcs.ComputeDiff(left, right))
+
+	// Add an unrelated change above the hunk, shifting its line numbers
+	shiftedLeft := append([]string{"new top line"}, left...)
+	shifted := GroupHunks(lcs.ComputeDiff(shiftedLeft, right))
+
+	if len(original) != 1 || len(shifted) != 2 {
+		t.Fatalf("Expected 1 and 2 hunks, got %d and %d", len(original), len(shifted))
+	}
+	if shifted[1].LeftStart == original[0].LeftStart {
+		t.Fatal("Expected the hunk's line numbers to shift")
+	}
+	if shifted[1].ID != original[0].ID {
+		t.Errorf("Expected ID %s to survive the shift, got %s", original[0].ID, shifted[1].ID)
+	}
+
+	t.Run("identical hunks get unique IDs", func(t *testing.T) {
+		left := []string{"x", "a", "x", "a", "x"}
+		right := []string{"x", "b", "x", "b", "x"}
+		hunks := GroupHunks(lcs.ComputeDiff(left, right))
+		seen := map[string]bool{}
+		for _, hunk := range hunks {
+			if seen[hunk.ID] {
+				t.Errorf("Duplicate hunk ID %s", hunk.ID)
+			}
+			seen[hunk.ID] = true
+		}
+	})
+}
+
+func TestHashLine(t *testing.T) {
+	if HashLine("same") != HashLine("same") {
+		t.Error("Expected equal content to hash equally")
+	}
+	if HashLine("one") == HashLine("two") {
+		t.Error("Expected different content to hash differently")
+	}
+}
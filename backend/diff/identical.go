@@ -0,0 +1,23 @@
+package diff
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// MarkIdentical sets Identical on a result that has no differences, along
+// with the hash of the compared content. When differences were ignored, such
+// as whitespace, the hash is of the left file's lines.
+func MarkIdentical(result *DiffResult, leftLines []string) {
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			result.Identical = false
+			result.ContentHash = ""
+			return
+		}
+	}
+	sum := sha256.Sum256([]byte(strings.Join(leftLines, "\n")))
+	result.Identical = true
+	result.ContentHash = hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,29 @@
+package diff
+
+import "testing"
+
+func TestMarkIdentical(t *testing.T) {
+	algorithm := NewLCSDefault()
+
+	t.Run("identical", func(t *testing.T) {
+		lines := []string{"a", "b"}
+		result := algorithm.ComputeDiff(lines, lines)
+		MarkIdentical(result, lines)
+		if !result.Identical {
+			t.Error("Expected identical files to be marked")
+		}
+		// SHA-256 of "a\nb"
+		if expected := "7e18f737311b2dc3b2f269dd78396b0351f14fb66efa879f768cb23181883c78"; result.ContentHash != expected {
+			t.Errorf("Expected hash %s, got %s", expected, result.ContentHash)
+		}
+	})
+
+	t.Run("different", func(t *testing.T) {
+		left, right := []string{"a", "b"}, []string{"a", "c"}
+		result := algorithm.ComputeDiff(left, right)
+		MarkIdentical(result, left)
+		if result.Identical || result.ContentHash != "" {
+			t.Errorf("Expected differing files not to be marked, got %v %q", result.Identical, result.ContentHash)
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package diff
+
+import "testing"
+
+func TestAlgorithms_IgnorePatterns(t *testing.T) {
+	patterns, err := CompileIgnorePatterns([]string{`^\s*//`, `Generated at .*`})
+	if err != nil {
+		t.Fatalf("CompileIgnorePatterns returned error: %v", err)
+	}
+	left := []string{"// Generated at 2024-01-01", "// old note", "x := 1"}
+	right := []string{"// Generated at 2025-06-30", "// new note", "x := 2"}
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+		config.IgnorePatterns = patterns
+		result := New(config).ComputeDiff(left, right)
+		if len(result.Lines) < 3 || result.Lines[0].Type != "same" || result.Lines[1].Type != "same" {
+			t.Errorf("%s: expected matched lines to be the same, got %+v", name, result.Lines)
+			continue
+		}
+		if result.Lines[1].LeftLine != left[1] || result.Lines[1].RightLine != right[1] {
+			t.Errorf("%s: expected original text on both sides, got %+v", name, result.Lines[1])
+		}
+		if result.Lines[2].Type == "same" {
+			t.Errorf("%s: expected unmatched changes to remain, got %+v", name, result.Lines[2])
+		}
+	}
+}
+
+func TestCompileIgnorePatterns(t *testing.T) {
+	if _, err := CompileIgnorePatterns([]string{"("}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+	if _, err := CompileIgnorePatterns([]string{""}); err == nil {
+		t.Error("Expected error for an empty pattern")
+	}
+}
@@ -0,0 +1,246 @@
+package diff
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ImportSummary describes how the import sections of two files differ
+type ImportSummary struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Summary string   `json:"summary"` // e.g. "imports differ: +2, -1" or "imports reordered"
+}
+
+// AlignImports diffs two files treating their import sections as a set. A
+// changed import section is reported as one change in file order, with a
+// summary of the imports genuinely added or removed, so reordering imports
+// does not scatter changes through the section. It returns nil when the
+// language is not supported or either file has no import section.
+func AlignImports(leftLines, rightLines []string, language string, algorithm Algorithm) *DiffResult {
+	leftStart, leftEnd, ok := findImportBlock(leftLines, language)
+	if !ok {
+		return nil
+	}
+	rightStart, rightEnd, ok := findImportBlock(rightLines, language)
+	if !ok {
+		return nil
+	}
+
+	result := &DiffResult{Lines: []DiffLine{}}
+
+	before := algorithm.ComputeDiff(leftLines[:leftStart], rightLines[:rightStart])
+	result.Lines = append(result.Lines, before.Lines...)
+
+	importLines, summary := alignImportBlocks(leftLines, rightLines, leftStart, leftEnd, rightStart, rightEnd)
+	result.Lines = append(result.Lines, importLines...)
+	result.Imports = summary
+
+	after := algorithm.ComputeDiff(leftLines[leftEnd:], rightLines[rightEnd:])
+	OffsetLineNumbers(after, leftEnd, rightEnd)
+	result.Lines = append(result.Lines, after.Lines...)
+	result.Chunks = GroupHunks(result)
+
+	return result
+}
+
+// alignImportBlocks diffs two import sections. Lines that match byte for byte
+// at the start and end of both sections are unchanged; the lines between them
+// are removed and added in file order, so they group into a single hunk.
+func alignImportBlocks(leftLines, rightLines []string, leftStart, leftEnd, rightStart, rightEnd int) ([]DiffLine, *ImportSummary) {
+	var lines []DiffLine
+	same := func(i, j int) {
+		lines = append(lines, DiffLine{
+			LeftLine:    leftLines[i],
+			RightLine:   rightLines[j],
+			LeftNumber:  i + 1,
+			RightNumber: j + 1,
+			Type:        "same",
+		})
+	}
+
+	prefix := 0
+	for leftStart+prefix < leftEnd && rightStart+prefix < rightEnd &&
+		leftLines[leftStart+prefix] == rightLines[rightStart+prefix] {
+		prefix++
+	}
+	suffix := 0
+	for leftEnd-suffix > leftStart+prefix && rightEnd-suffix > rightStart+prefix &&
+		leftLines[leftEnd-suffix-1] == rightLines[rightEnd-suffix-1] {
+		suffix++
+	}
+
+	for k := 0; k < prefix; k++ {
+		same(leftStart+k, rightStart+k)
+	}
+	for i := leftStart + prefix; i < leftEnd-suffix; i++ {
+		lines = append(lines, DiffLine{
+			LeftLine:   leftLines[i],
+			LeftNumber: i + 1,
+			Type:       "removed",
+		})
+	}
+	for j := rightStart + prefix; j < rightEnd-suffix; j++ {
+		lines = append(lines, DiffLine{
+			RightLine:   rightLines[j],
+			RightNumber: j + 1,
+			Type:        "added",
+		})
+	}
+	for k := suffix; k > 0; k-- {
+		same(leftEnd-k, rightEnd-k)
+	}
+
+	if leftEnd-leftStart == prefix && rightEnd-rightStart == prefix {
+		return lines, nil
+	}
+	return lines, summarizeImports(leftLines[leftStart:leftEnd], rightLines[rightStart:rightEnd])
+}
+
+// summarizeImports compares two import sections as sets of normalized imports
+func summarizeImports(left, right []string) *ImportSummary {
+	leftKeys, rightKeys := importKeys(left), importKeys(right)
+
+	// Count right imports by key so duplicates pair up
+	remaining := make(map[string]int)
+	for _, key := range rightKeys {
+		remaining[key]++
+	}
+	summary := &ImportSummary{Added: []string{}, Removed: []string{}}
+	for _, key := range leftKeys {
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		summary.Removed = append(summary.Removed, key)
+	}
+	for _, key := range rightKeys {
+		if remaining[key] > 0 {
+			remaining[key]--
+			summary.Added = append(summary.Added, key)
+		}
+	}
+
+	switch {
+	case len(summary.Added) > 0 || len(summary.Removed) > 0:
+		summary.Summary = fmt.Sprintf("imports differ: +%d, -%d", len(summary.Added), len(summary.Removed))
+	case slices.Equal(leftKeys, rightKeys):
+		summary.Summary = "imports reformatted"
+	default:
+		summary.Summary = "imports reordered"
+	}
+	return summary
+}
+
+// importKeys returns the normalized imports of a section, skipping blank lines
+func importKeys(lines []string) []string {
+	keys := []string{}
+	for _, line := range lines {
+		if key := importKey(line); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// importKey normalizes an import line for set comparison
+func importKey(line string) string {
+	key := strings.TrimSpace(line)
+	key = strings.TrimRight(key, ";,")
+	return strings.Join(strings.Fields(key), " ")
+}
+
+// findImportBlock returns the [start, end) line range of the first import
+// section of a file in the given language
+func findImportBlock(lines []string, language string) (int, int, bool) {
+	switch language {
+	case LanguageGo:
+		return findGoImportBlock(lines)
+	case LanguagePython:
+		return findPrefixedImportBlock(lines, isPythonImport, "#")
+	case LanguageJavaScript:
+		return findPrefixedImportBlock(lines, isJavaScriptImport, "//")
+	}
+	return 0, 0, false
+}
+
+// findGoImportBlock finds either a parenthesized import block or a run of single-line imports
+func findGoImportBlock(lines []string) (int, int, bool) {
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "import (" {
+			for j := i + 1; j < len(lines); j++ {
+				if strings.TrimSpace(lines[j]) == ")" {
+					return i, j + 1, true
+				}
+			}
+			return 0, 0, false
+		}
+		if strings.HasPrefix(trimmed, "import ") {
+			start, end, ok := findPrefixedImportBlock(lines[i:], func(l string) bool {
+				return strings.HasPrefix(l, "import ")
+			}, "//")
+			return start + i, end + i, ok
+		}
+	}
+	return 0, 0, false
+}
+
+// findPrefixedImportBlock finds the first run of import statements, allowing
+// blank lines, comments, and parenthesized/braced continuation lines inside it
+func findPrefixedImportBlock(lines []string, isImport func(string) bool, comment string) (int, int, bool) {
+	start, end := -1, -1
+	open := 0
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case open > 0:
+			open += strings.Count(trimmed, "(") + strings.Count(trimmed, "{")
+			open -= strings.Count(trimmed, ")") + strings.Count(trimmed, "}")
+			end = i + 1
+		case isImport(trimmed):
+			if start < 0 {
+				start = i
+			}
+			open = strings.Count(trimmed, "(") + strings.Count(trimmed, "{") -
+				strings.Count(trimmed, ")") - strings.Count(trimmed, "}")
+			end = i + 1
+		case trimmed == "" || strings.HasPrefix(trimmed, comment):
+			// Allowed inside or before the block
+		default:
+			if start >= 0 {
+				return start, end, true
+			}
+			// Code before any import means there is no leading import section
+			if !isPreamble(trimmed) {
+				return 0, 0, false
+			}
+		}
+	}
+
+	if start < 0 {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// isPreamble reports whether a line may precede an import section
+func isPreamble(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "package ") ||
+		strings.HasPrefix(trimmed, "#!") ||
+		strings.HasPrefix(trimmed, "\"use ") ||
+		strings.HasPrefix(trimmed, "'use ") ||
+		strings.HasPrefix(trimmed, "\"\"\"") ||
+		strings.HasPrefix(trimmed, "/*") ||
+		strings.HasPrefix(trimmed, "*")
+}
+
+func isPythonImport(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "import ") ||
+		(strings.HasPrefix(trimmed, "from ") && strings.Contains(trimmed, " import"))
+}
+
+func isJavaScriptImport(trimmed string) bool {
+	return strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "import{")
+}
@@ -0,0 +1,156 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlignImports(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	// checkImportRows verifies rows stay in file order on both sides, that
+	// only byte-equal lines are unchanged, and that the section is one hunk
+	checkImportRows := func(t *testing.T, result *DiffResult) {
+		t.Helper()
+		lastLeft, lastRight := 0, 0
+		for _, line := range result.Lines {
+			if line.LeftNumber > 0 {
+				if line.LeftNumber <= lastLeft {
+					t.Errorf("Expected left rows in file order, got %+v", line)
+				}
+				lastLeft = line.LeftNumber
+			}
+			if line.RightNumber > 0 {
+				if line.RightNumber <= lastRight {
+					t.Errorf("Expected right rows in file order, got %+v", line)
+				}
+				lastRight = line.RightNumber
+			}
+			if line.Type == "same" && line.LeftLine != line.RightLine {
+				t.Errorf("Expected unchanged lines to match exactly, got %+v", line)
+			}
+		}
+		if len(result.Chunks) != 1 {
+			t.Errorf("Expected one hunk, got %d", len(result.Chunks))
+		}
+		if MarkIdentical(result, nil); result.Identical {
+			t.Error("Expected files with different imports not to be identical")
+		}
+	}
+
+	t.Run("reordered Go imports", func(t *testing.T) {
+		left := []string{"package main", "", "import (", "\t\"fmt\"", "\t\"os\"", ")", "", "func main() {}"}
+		right := []string{"package main", "", "import (", "\t\"os\"", "\t\"fmt\"", ")", "", "func main() {}"}
+
+		result := AlignImports(left, right, LanguageGo, lcs)
+		if result == nil {
+			t.Fatal("Expected a result for Go imports")
+		}
+		checkImportRows(t, result)
+		if result.Imports == nil || result.Imports.Summary != "imports reordered" {
+			t.Errorf("Expected reordered summary, got %+v", result.Imports)
+		}
+		if changed := changedLines(result); len(changed) != 4 {
+			t.Errorf("Expected the two reordered imports to change, got %+v", changed)
+		}
+	})
+
+	t.Run("added and removed Python imports", func(t *testing.T) {
+		left := []string{"import os", "import sys", "from a import b", "", "x = 1"}
+		right := []string{"import os", "from a import b", "import json", "", "x = 2"}
+
+		result := AlignImports(left, right, LanguagePython, lcs)
+		if result == nil {
+			t.Fatal("Expected a result for Python imports")
+		}
+		expected := &ImportSummary{
+			Added:   []string{"import json"},
+			Removed: []string{"import sys"},
+			Summary: "imports differ: +1, -1",
+		}
+		if !reflect.DeepEqual(result.Imports, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, result.Imports)
+		}
+
+		// The unchanged first import stays paired; the rest is one change
+		var types []string
+		for _, line := range result.Lines[:5] {
+			types = append(types, line.Type)
+		}
+		if !reflect.DeepEqual(types, []string{"same", "removed", "removed", "added", "added"}) {
+			t.Errorf("Unexpected import line types: %v", types)
+		}
+
+		// Code after the import section keeps its real line numbers
+		last := result.Lines[len(result.Lines)-1]
+		if last.RightNumber != 5 {
+			t.Errorf("Expected last line to be right line 5, got %+v", last)
+		}
+	})
+
+	t.Run("JavaScript imports with trailing semicolons", func(t *testing.T) {
+		left := []string{"'use strict';", "import a from 'a';", "import { b } from 'b';", "run();"}
+		right := []string{"'use strict';", "import { b } from 'b'", "import a from 'a';", "run();"}
+
+		result := AlignImports(left, right, LanguageJavaScript, lcs)
+		if result == nil {
+			t.Fatal("Expected a result for JavaScript imports")
+		}
+		checkImportRows(t, result)
+		if result.Imports == nil || result.Imports.Summary != "imports reordered" {
+			t.Errorf("Expected reordered summary, got %+v", result.Imports)
+		}
+	})
+
+	t.Run("reformatted imports", func(t *testing.T) {
+		left := []string{"import a from 'a';", "import { b } from 'b';", "run();"}
+		right := []string{"import a from 'a'", "import { b } from 'b';", "run();"}
+
+		result := AlignImports(left, right, LanguageJavaScript, lcs)
+		if result == nil {
+			t.Fatal("Expected a result for JavaScript imports")
+		}
+		checkImportRows(t, result)
+		if result.Imports == nil || result.Imports.Summary != "imports reformatted" {
+			t.Errorf("Expected reformatted summary, got %+v", result.Imports)
+		}
+	})
+
+	t.Run("identical imports", func(t *testing.T) {
+		lines := []string{"package main", "import \"fmt\"", "func main() {}"}
+		result := AlignImports(lines, lines, LanguageGo, lcs)
+		if result == nil {
+			t.Fatal("Expected a result for Go imports")
+		}
+		if result.Imports != nil {
+			t.Errorf("Expected no import summary, got %+v", result.Imports)
+		}
+	})
+
+	t.Run("no import section", func(t *testing.T) {
+		left := []string{"package main", "func main() {}"}
+		right := []string{"package main", "import \"fmt\"", "func main() {}"}
+		if result := AlignImports(left, right, LanguageGo, lcs); result != nil {
+			t.Errorf("Expected nil when one side has no imports, got %+v", result)
+		}
+		if result := AlignImports(right, right, "", lcs); result != nil {
+			t.Errorf("Expected nil for unknown language, got %+v", result)
+		}
+	})
+}
+
+func TestLanguageForPath(t *testing.T) {
+	tests := map[string]string{
+		"main.go":   LanguageGo,
+		"script.PY": LanguagePython,
+		"app.tsx":   LanguageJavaScript,
+		"README.md": LanguageMarkdown,
+		"notes.txt": "",
+		"Makefile":  "",
+	}
+	for path, expected := range tests {
+		if got := LanguageForPath(path); got != expected {
+			t.Errorf("LanguageForPath(%q) = %q, expected %q", path, got, expected)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package diff
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Segment is a run of text within a modified line. Segments of type "same"
+// appear on both sides, "removed" only on the left, and "added" only on the
+// right, so each side can be rebuilt by joining its segments in order.
+type Segment struct {
+	Text string `json:"text"`
+	Type string `json:"type"` // "same", "removed", or "added"
+}
+
+// WordSegments diffs two versions of a line word by word. Words, runs of
+// whitespace, and individual punctuation characters are compared as units.
+func WordSegments(left, right string) []Segment {
+	leftTokens, rightTokens := tokenizeWords(left), tokenizeWords(right)
+	leftIDs, rightIDs := internLines(leftTokens, rightTokens)
+
+	matches := make([]int, len(leftIDs))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &myersState{left: leftIDs, right: rightIDs, matches: matches}
+	s.compare(0, len(leftIDs), 0, len(rightIDs))
+
+	segments := []Segment{}
+	appendSegment := func(text, segmentType string) {
+		if n := len(segments); n > 0 && segments[n-1].Type == segmentType {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, Segment{Text: text, Type: segmentType})
+	}
+
+	for _, line := range buildDiffLines(leftTokens, rightTokens, matches) {
+		switch line.Type {
+		case "same":
+			appendSegment(line.LeftLine, "same")
+		case "removed":
+			appendSegment(line.LeftLine, "removed")
+		case "added":
+			appendSegment(line.RightLine, "added")
+		}
+	}
+	return segments
+}
+
+// tokenizeWords splits a line into words, whitespace runs, and single
+// punctuation characters
+func tokenizeWords(line string) []string {
+	tokens := []string{}
+	start := 0
+	for start < len(line) {
+		r, size := utf8.DecodeRuneInString(line[start:])
+		end := start + size
+		switch {
+		case isWordRune(r):
+			for end < len(line) {
+				next, nextSize := utf8.DecodeRuneInString(line[end:])
+				if !isWordRune(next) {
+					break
+				}
+				end += nextSize
+			}
+		case unicode.IsSpace(r):
+			for end < len(line) {
+				next, nextSize := utf8.DecodeRuneInString(line[end:])
+				if !unicode.IsSpace(next) {
+					break
+				}
+				end += nextSize
+			}
+		}
+		tokens = append(tokens, line[start:end])
+		start = end
+	}
+	return tokens
+}
+
+// isWordRune returns whether a rune is part of an identifier-like word
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWordSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     string
+		right    string
+		expected []Segment
+	}{
+		{
+			name:  "renamed identifier",
+			left:  "total := count + 1",
+			right: "total := amount + 1",
+			expected: []Segment{
+				{Text: "total := ", Type: "same"},
+				{Text: "count", Type: "removed"},
+				{Text: "amount", Type: "added"},
+				{Text: " + 1", Type: "same"},
+			},
+		},
+		{
+			name:  "inserted argument",
+			left:  "call(a)",
+			right: "call(a, b)",
+			expected: []Segment{
+				{Text: "call(a", Type: "same"},
+				{Text: ", b", Type: "added"},
+				{Text: ")", Type: "same"},
+			},
+		},
+		{
+			name:     "identical",
+			left:     "same line",
+			right:    "same line",
+			expected: []Segment{{Text: "same line", Type: "same"}},
+		},
+		{
+			name:  "unicode words",
+			left:  "naïve café",
+			right: "naïve thé",
+			expected: []Segment{
+				{Text: "naïve ", Type: "same"},
+				{Text: "café", Type: "removed"},
+				{Text: "thé", Type: "added"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			segments := WordSegments(tt.left, tt.right)
+			if !reflect.DeepEqual(segments, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, segments)
+			}
+
+			// Each side can be rebuilt from its segments
+			var left, right strings.Builder
+			for _, segment := range segments {
+				if segment.Type != "added" {
+					left.WriteString(segment.Text)
+				}
+				if segment.Type != "removed" {
+					right.WriteString(segment.Text)
+				}
+			}
+			if left.String() != tt.left || right.String() != tt.right {
+				t.Errorf("Segments rebuild %q and %q", left.String(), right.String())
+			}
+		})
+	}
+}
+
+func Test_tokenizeWords(t *testing.T) {
+	expected := []string{"x", " ", "=", " ", "foo_bar", "(", "1", ",", "  ", "y2", ")"}
+	if got := tokenizeWords("x = foo_bar(1,  y2)"); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %q, got %q", expected, got)
+	}
+}
+
+func TestModifiedLinesHaveSegments(t *testing.T) {
+	result := NewLCSDefault().ComputeDiff(
+		[]string{"    return total + count"},
+		[]string{"    return total + amount"},
+	)
+	if len(result.Lines) != 1 || result.Lines[0].Type != "modified" {
+		t.Fatalf("Expected one modified line, got %+v", result.Lines)
+	}
+	if len(result.Lines[0].Segments) == 0 {
+		t.Error("Expected modified line to carry word segments")
+	}
+}
@@ -0,0 +1,206 @@
+package diff
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVDelimiter returns the field delimiter of a CSV or TSV file by its
+// extension, or 0 for other files
+func CSVDelimiter(path string) rune {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ','
+	case ".tsv", ".tab":
+		return '\t'
+	}
+	return 0
+}
+
+// RecordSummary counts the records of a keyed comparison, not counting the
+// header, or the keys of a config file comparison, by how they changed
+type RecordSummary struct {
+	Added     int `json:"added"`
+	Removed   int `json:"removed"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+}
+
+// csvRecord is a line of a CSV file split into cells
+type csvRecord struct {
+	line  int // 0-based
+	text  string
+	key   string
+	cells []string    // nil for a blank line
+	spans []CharRange // where each cell is on the line
+}
+
+// ParseCSVHeader returns the cells of a CSV file's first line
+func ParseCSVHeader(lines []string, delimiter rune) ([]string, error) {
+	if len(lines) == 0 {
+		return []string{}, nil
+	}
+	record, err := parseCSVLine(lines[0], 0, delimiter, nil)
+	if err != nil {
+		return nil, err
+	}
+	return record.cells, nil
+}
+
+// KeyedCSVDiff compares two CSV files record by record, matching rows by
+// the values of the key columns, given as 0-based indexes, instead of by
+// position, so re-sorted exports show only the records that changed. The
+// first line of each file is the header and is compared with the other.
+// Matched records whose cells differ are "modified", with the changed cells
+// as their changes; records only in one file are removed or added. Lines
+// are listed in left file order, with records only on the right placed after
+// their neighbor in the right file, and records with the same key pair up in
+// order. It reports false when a line is not a record of its own, such as
+// when a quoted cell runs over several lines, so the caller can fall back to
+// a line diff.
+func KeyedCSVDiff(leftLines, rightLines []string, delimiter rune, keyColumns []int) (*DiffResult, bool) {
+	left, ok := parseCSVLines(leftLines, delimiter, keyColumns)
+	if !ok {
+		return nil, false
+	}
+	right, ok := parseCSVLines(rightLines, delimiter, keyColumns)
+	if !ok {
+		return nil, false
+	}
+
+	result := &DiffResult{Records: &RecordSummary{}}
+	// The headers are compared as records of their own
+	if len(left) > 0 && len(right) > 0 {
+		result.Lines = append(result.Lines, compareRecords(left[0], right[0]))
+		left, right = left[1:], right[1:]
+	}
+
+	rightIndex := make(map[string]int, len(right))
+	for i, record := range right {
+		rightIndex[record.key] = i
+	}
+	leftKeys := make(map[string]bool, len(left))
+	for _, record := range left {
+		leftKeys[record.key] = true
+	}
+	// emitRightOnly adds the records only on the right, starting at index i,
+	// until the next one the left file also has
+	emitRightOnly := func(i int) {
+		for ; i < len(right) && !leftKeys[right[i].key]; i++ {
+			result.Lines = append(result.Lines, DiffLine{RightLine: right[i].text, RightNumber: right[i].line + 1, Type: "added"})
+			if right[i].cells != nil {
+				result.Records.Added++
+			}
+		}
+	}
+
+	emitRightOnly(0)
+	for _, record := range left {
+		match, ok := rightIndex[record.key]
+		if !ok {
+			result.Lines = append(result.Lines, DiffLine{LeftLine: record.text, LeftNumber: record.line + 1, Type: "removed"})
+			if record.cells != nil {
+				result.Records.Removed++
+			}
+			continue
+		}
+		line := compareRecords(record, right[match])
+		switch {
+		case record.cells == nil:
+			// Blank lines are not records
+		case line.Type == "same":
+			result.Records.Unchanged++
+		default:
+			result.Records.Changed++
+		}
+		result.Lines = append(result.Lines, line)
+		emitRightOnly(match + 1)
+	}
+
+	result.Chunks = GroupHunks(result)
+	return result, true
+}
+
+// parseCSVLines splits each line into a record keyed by its key columns.
+// Records with the same key are numbered in order, and blank lines are
+// keyed apart from any record.
+func parseCSVLines(lines []string, delimiter rune, keyColumns []int) ([]csvRecord, bool) {
+	records := make([]csvRecord, len(lines))
+	seen := make(map[string]int)
+	for i, line := range lines {
+		record, err := parseCSVLine(line, i, delimiter, keyColumns)
+		if err != nil {
+			return nil, false
+		}
+		seen[record.key]++
+		if seen[record.key] > 1 {
+			record.key = fmt.Sprintf("%s\x1e%d", record.key, seen[record.key])
+		}
+		records[i] = record
+	}
+	return records, true
+}
+
+// parseCSVLine splits a line into cells, locating each on the line
+func parseCSVLine(line string, index int, delimiter rune, keyColumns []int) (csvRecord, error) {
+	record := csvRecord{line: index, text: line}
+	if strings.TrimSpace(line) == "" {
+		record.key = "\x00blank"
+		return record, nil
+	}
+
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	cells, err := reader.Read()
+	if err != nil {
+		return record, err
+	}
+
+	record.cells = cells
+	record.spans = make([]CharRange, len(cells))
+	starts := make([]int, len(cells)+1)
+	for i := range cells {
+		_, column := reader.FieldPos(i)
+		starts[i] = column - 1
+	}
+	starts[len(cells)] = len(line) + utf8.RuneLen(delimiter)
+	for i := range cells {
+		start, end := starts[i], starts[i+1]-utf8.RuneLen(delimiter)
+		record.spans[i] = CharRange{
+			Start: utf8.RuneCountInString(line[:start]),
+			End:   utf8.RuneCountInString(line[:end]),
+		}
+	}
+
+	key := make([]string, len(keyColumns))
+	for i, column := range keyColumns {
+		if column >= 0 && column < len(cells) {
+			key[i] = cells[column]
+		}
+	}
+	record.key = strings.Join(key, "\x1f")
+	return record, nil
+}
+
+// compareRecords pairs two records, as "modified" with the changed cells as
+// their changes when any cell differs
+func compareRecords(left, right csvRecord) DiffLine {
+	line := DiffLine{LeftLine: left.text, RightLine: right.text, LeftNumber: left.line + 1, RightNumber: right.line + 1, Type: "same"}
+	for i := 0; i < max(len(left.cells), len(right.cells)); i++ {
+		if i < len(left.cells) && i < len(right.cells) && left.cells[i] == right.cells[i] {
+			continue
+		}
+		line.Type = "modified"
+		if i < len(left.cells) {
+			line.LeftChanges = append(line.LeftChanges, left.spans[i])
+		}
+		if i < len(right.cells) {
+			line.RightChanges = append(line.RightChanges, right.spans[i])
+		}
+	}
+	return line
+}
@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCSVDelimiter(t *testing.T) {
+	for path, want := range map[string]rune{
+		"export.csv":  ',',
+		"Export.TSV":  '\t',
+		"data.tab":    '\t',
+		"notes.txt":   0,
+		"config.json": 0,
+	} {
+		if got := CSVDelimiter(path); got != want {
+			t.Errorf("CSVDelimiter(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestKeyedCSVDiff(t *testing.T) {
+	left := []string{
+		"id,name,city",
+		"1,Ada,London",
+		"2,Grace,Arlington",
+		"3,Linus,Helsinki",
+	}
+
+	t.Run("re-sorted records match", func(t *testing.T) {
+		right := []string{"id,name,city", "3,Linus,Helsinki", "1,Ada,London", "2,Grace,Arlington"}
+		result, ok := KeyedCSVDiff(left, right, ',', []int{0})
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if changed := changedLines(result); len(changed) != 0 {
+			t.Errorf("Expected no changes, got %+v", changed)
+		}
+		if *result.Records != (RecordSummary{Unchanged: 3}) {
+			t.Errorf("Expected three unchanged records, got %+v", *result.Records)
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Errorf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+	})
+
+	t.Run("added, removed, and changed records", func(t *testing.T) {
+		right := []string{"id,name,city", "4,Barbara,Boston", "2,Grace,\"New York\"", "1,Ada,London"}
+		result, ok := KeyedCSVDiff(left, right, ',', []int{0})
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if *result.Records != (RecordSummary{Added: 1, Removed: 1, Changed: 1, Unchanged: 1}) {
+			t.Errorf("Unexpected record counts %+v", *result.Records)
+		}
+
+		var modified DiffLine
+		for _, line := range result.Lines {
+			if line.Type == "modified" {
+				modified = line
+			}
+		}
+		if modified.LeftNumber != 3 || modified.RightNumber != 3 {
+			t.Fatalf("Expected record 2 to be modified, got %+v", result.Lines)
+		}
+		// Only the city cell is highlighted, quotes included
+		if !reflect.DeepEqual(modified.LeftChanges, []CharRange{{Start: 8, End: 17}}) {
+			t.Errorf("Expected the left city to change, got %+v", modified.LeftChanges)
+		}
+		if !reflect.DeepEqual(modified.RightChanges, []CharRange{{Start: 8, End: 18}}) {
+			t.Errorf("Expected the right city to change, got %+v", modified.RightChanges)
+		}
+		if len(result.Chunks) == 0 {
+			t.Error("Expected the changes to be grouped into chunks")
+		}
+	})
+
+	t.Run("composite keys and duplicates", func(t *testing.T) {
+		left := []string{"region\tsku\tqty", "eu\t1\t5", "us\t1\t7", "us\t1\t8"}
+		right := []string{"region\tsku\tqty", "us\t1\t7", "us\t1\t9", "eu\t1\t5"}
+		result, ok := KeyedCSVDiff(left, right, '\t', []int{0, 1})
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if *result.Records != (RecordSummary{Changed: 1, Unchanged: 2}) {
+			t.Errorf("Expected duplicate keys to pair in order, got %+v", *result.Records)
+		}
+	})
+
+	t.Run("changed header", func(t *testing.T) {
+		right := []string{"id,name,town", "1,Ada,London", "2,Grace,Arlington", "3,Linus,Helsinki"}
+		result, _ := KeyedCSVDiff(left, right, ',', []int{0})
+		if result.Lines[0].Type != "modified" || len(changedLines(result)) != 1 {
+			t.Errorf("Expected only the header to change, got %+v", changedLines(result))
+		}
+	})
+
+	t.Run("multi-line records fall back", func(t *testing.T) {
+		if _, ok := KeyedCSVDiff(left, []string{"id,name,city", "1,\"Ada", "Lovelace\",London"}, ',', []int{0}); ok {
+			t.Error("Expected a record spanning lines not to parse")
+		}
+	})
+}
+
+func TestParseCSVHeader(t *testing.T) {
+	columns, err := ParseCSVHeader([]string{"id,\"full name\",city", "1,Ada,London"}, ',')
+	if err != nil {
+		t.Fatalf("ParseCSVHeader returned error: %v", err)
+	}
+	if !reflect.DeepEqual(columns, []string{"id", "full name", "city"}) {
+		t.Errorf("Unexpected columns %q", columns)
+	}
+}
@@ -0,0 +1,35 @@
+package diff
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Languages recognized by language-aware diff features
+const (
+	LanguageGo         = "go"
+	LanguagePython     = "python"
+	LanguageJavaScript = "javascript"
+	LanguageMarkdown   = "markdown"
+)
+
+// languageExtensions maps file extensions to languages
+var languageExtensions = map[string]string{
+	".go":       LanguageGo,
+	".py":       LanguagePython,
+	".pyi":      LanguagePython,
+	".js":       LanguageJavaScript,
+	".jsx":      LanguageJavaScript,
+	".mjs":      LanguageJavaScript,
+	".cjs":      LanguageJavaScript,
+	".ts":       LanguageJavaScript,
+	".tsx":      LanguageJavaScript,
+	".md":       LanguageMarkdown,
+	".markdown": LanguageMarkdown,
+}
+
+// LanguageForPath returns the language of a file based on its extension,
+// or an empty string when the language is not recognized
+func LanguageForPath(path string) string {
+	return languageExtensions[strings.ToLower(filepath.Ext(path))]
+}
@@ -1,7 +1,14 @@
 package diff
 
 import (
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
 )
 
 // LCS implements the Longest Common Subsequence diff algorithm.
@@ -19,74 +26,43 @@ func NewLCSDefault() *LCS {
 	return NewLCS(DefaultConfig())
 }
 
+// Config returns the algorithm's configuration
+func (l *LCS) Config() Config {
+	return l.config
+}
+
+// WithConfig returns the algorithm selected by the given configuration
+func (l *LCS) WithConfig(config Config) Algorithm {
+	return New(config)
+}
+
 // ComputeDiff compares two sets of lines and returns the diff result
 func (l *LCS) ComputeDiff(leftLines, rightLines []string) *DiffResult {
-	// Compute the LCS table
-	m, n := len(leftLines), len(rightLines)
-	lcs := make([][]int, m+1)
-	for i := range lcs {
-		lcs[i] = make([]int, n+1)
-	}
-
-	// Fill the LCS table
-	for i := 1; i <= m; i++ {
-		for j := 1; j <= n; j++ {
-			if leftLines[i-1] == rightLines[j-1] {
-				lcs[i][j] = lcs[i-1][j-1] + 1
-			} else {
-				if lcs[i-1][j] > lcs[i][j-1] {
-					lcs[i][j] = lcs[i-1][j]
-				} else {
-					lcs[i][j] = lcs[i][j-1]
-				}
-			}
-		}
+	if l.config.IgnoreBlankLines {
+		return diffIgnoringBlankLines(leftLines, rightLines, l.config)
 	}
 
-	// Backtrack to build the diff
-	result := &DiffResult{Lines: []DiffLine{}}
-	i, j := m, n
-	var diffLines []DiffLine
-
-	for i > 0 || j > 0 {
-		if i > 0 && j > 0 && leftLines[i-1] == rightLines[j-1] {
-			// Lines match
-			diffLines = append(diffLines, DiffLine{
-				LeftLine:    leftLines[i-1],
-				RightLine:   rightLines[j-1],
-				LeftNumber:  i,
-				RightNumber: j,
-				Type:        "same",
-			})
-			i--
-			j--
-		} else if j > 0 && (i == 0 || lcs[i][j-1] >= lcs[i-1][j]) {
-			// Line added in right
-			diffLines = append(diffLines, DiffLine{
-				LeftLine:    "",
-				RightLine:   rightLines[j-1],
-				LeftNumber:  0,
-				RightNumber: j,
-				Type:        "added",
-			})
-			j--
-		} else if i > 0 {
-			// Line removed from left
-			diffLines = append(diffLines, DiffLine{
-				LeftLine:    leftLines[i-1],
-				RightLine:   "",
-				LeftNumber:  i,
-				RightNumber: 0,
-				Type:        "removed",
-			})
-			i--
-		}
-	}
+	// Match lines by their form under the whitespace and case options, hashed
+	// to integers so the search compares numbers instead of strings
+	leftKeys, rightKeys := internLines(comparisonKeys(leftLines, l.config), comparisonKeys(rightLines, l.config))
 
-	// Reverse the diff lines (we built them backwards)
-	for i := len(diffLines) - 1; i >= 0; i-- {
-		result.Lines = append(result.Lines, diffLines[i])
+	// matches[i] is the right index paired with left line i, or -1
+	matches := make([]int, len(leftKeys))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &hirschbergState{
+		left:    leftKeys,
+		right:   rightKeys,
+		matches: matches,
+		done:    l.config.Done,
+		forward: make([]int, len(rightKeys)+1),
+		reverse: make([]int, len(rightKeys)+1),
+		scratch: make([]int, len(rightKeys)+1),
 	}
+	s.compare(0, len(leftKeys), 0, len(rightKeys))
+
+	result := &DiffResult{Lines: buildDiffLines(leftLines, rightLines, matches)}
 
 	// Post-process to detect modifications (removed followed by added)
 	result = l.detectModifications(result)
@@ -94,11 +70,135 @@ func (l *LCS) ComputeDiff(leftLines, rightLines []string) *DiffResult {
 	return result
 }
 
+// hirschbergState finds a longest common subsequence with Hirschberg's
+// divide and conquer, which needs memory proportional to the length of the
+// right file instead of a table of both lengths multiplied
+type hirschbergState struct {
+	left    []int
+	right   []int
+	matches []int
+	done    <-chan struct{}
+	// Rows of LCS lengths reused at every level of the recursion
+	forward []int
+	reverse []int
+	scratch []int
+}
+
+// compare matches left[leftLo:leftHi] against right[rightLo:rightHi]
+func (s *hirschbergState) compare(leftLo, leftHi, rightLo, rightHi int) {
+	if stopped(s.done) {
+		return
+	}
+	// Lines shared at either end are always part of the subsequence
+	for leftLo < leftHi && rightLo < rightHi && s.left[leftLo] == s.right[rightLo] {
+		s.matches[leftLo] = rightLo
+		leftLo++
+		rightLo++
+	}
+	for leftLo < leftHi && rightLo < rightHi && s.left[leftHi-1] == s.right[rightHi-1] {
+		leftHi--
+		rightHi--
+		s.matches[leftHi] = rightHi
+	}
+	if leftLo == leftHi || rightLo == rightHi {
+		return
+	}
+
+	if leftHi-leftLo == 1 {
+		// Pair a single line with its last occurrence
+		for j := rightHi - 1; j >= rightLo; j-- {
+			if s.right[j] == s.left[leftLo] {
+				s.matches[leftLo] = j
+				return
+			}
+		}
+		return
+	}
+
+	// Split the left range in half and find where the right range divides so
+	// the subsequences of both halves add up to the longest
+	mid := (leftLo + leftHi) / 2
+	s.forwardRow(leftLo, mid, rightLo, rightHi)
+	s.reverseRow(mid, leftHi, rightLo, rightHi)
+	split, best := rightLo, -1
+	for j := rightLo; j <= rightHi; j++ {
+		if total := s.forward[j-rightLo] + s.reverse[j-rightLo]; total >= best {
+			split, best = j, total
+		}
+	}
+
+	s.compare(leftLo, mid, rightLo, split)
+	s.compare(mid, leftHi, split, rightHi)
+}
+
+// forwardRow sets forward[j] to the length of the longest common subsequence
+// of left[leftLo:leftHi] and right[rightLo:rightLo+j]
+func (s *hirschbergState) forwardRow(leftLo, leftHi, rightLo, rightHi int) {
+	row, prev := s.forward[:rightHi-rightLo+1], s.scratch[:rightHi-rightLo+1]
+	clear(row)
+	for i := leftLo; i < leftHi && !stopped(s.done); i++ {
+		copy(prev, row)
+		for j := 1; j < len(row); j++ {
+			if s.left[i] == s.right[rightLo+j-1] {
+				row[j] = prev[j-1] + 1
+			} else {
+				row[j] = max(prev[j], row[j-1])
+			}
+		}
+	}
+}
+
+// reverseRow sets reverse[j] to the length of the longest common subsequence
+// of left[leftLo:leftHi] and right[rightLo+j:rightHi]
+func (s *hirschbergState) reverseRow(leftLo, leftHi, rightLo, rightHi int) {
+	row, prev := s.reverse[:rightHi-rightLo+1], s.scratch[:rightHi-rightLo+1]
+	clear(row)
+	for i := leftHi - 1; i >= leftLo && !stopped(s.done); i-- {
+		copy(prev, row)
+		for j := len(row) - 2; j >= 0; j-- {
+			if s.left[i] == s.right[rightLo+j] {
+				row[j] = prev[j+1] + 1
+			} else {
+				row[j] = max(prev[j], row[j+1])
+			}
+		}
+	}
+}
+
 // detectModifications post-processes diff results to find removed+added pairs that should be modifications
 func (l *LCS) detectModifications(result *DiffResult) *DiffResult {
+	return detectModifications(result, l.config)
+}
+
+// areSimilarLines checks if two lines are similar enough to be considered a modification
+func (l *LCS) areSimilarLines(left, right string) bool {
+	return areSimilarLines(left, right, l.config)
+}
+
+// detectModifications pairs runs of removed lines with the added lines that
+// follow them, marking them as modified when every pair is similar. It is
+// shared by all algorithms so they agree on what counts as a modification.
+// Once the configured time budget is spent, the remaining changes are left
+// as removed and added lines and the result is marked Approximate.
+func detectModifications(result *DiffResult, config Config) *DiffResult {
 	newLines := []DiffLine{}
 	i := 0
 
+	var deadline time.Time
+	if config.TimeBudget > 0 {
+		deadline = time.Now().Add(config.TimeBudget)
+	}
+	overBudget := func() bool {
+		// A cancelled comparison is discarded, so it skips the scoring
+		if stopped(config.Done) {
+			return true
+		}
+		if !result.Approximate && !deadline.IsZero() && time.Now().After(deadline) {
+			result.Approximate = true
+		}
+		return result.Approximate
+	}
+
 	for i < len(result.Lines) {
 		// Look for sequences of removed lines that might be followed by added lines
 		if i < len(result.Lines) && result.Lines[i].Type == "removed" {
@@ -120,24 +220,32 @@ func (l *LCS) detectModifications(result *DiffResult) *DiffResult {
 
 				// If we have matching counts and all are similar, treat as modifications
 				if len(removedLines) == len(addedLines) {
-					allSimilar := true
-					for j := 0; j < len(removedLines); j++ {
-						if !l.areSimilarLines(removedLines[j].LeftLine, addedLines[j].RightLine) {
-							allSimilar = false
-							break
+					allSimilar := false
+					if !overBudget() {
+						var overran bool
+						allSimilar, overran = allLinesSimilar(removedLines, addedLines, config, deadline)
+						if overran {
+							result.Approximate = true
 						}
 					}
 
 					if allSimilar {
 						// Create modified lines with matching line numbers
 						for j := 0; j < len(removedLines); j++ {
-							newLines = append(newLines, DiffLine{
-								LeftLine:    removedLines[j].LeftLine,
-								RightLine:   addedLines[j].RightLine,
+							left, right := removedLines[j].LeftLine, addedLines[j].RightLine
+							line := DiffLine{
+								LeftLine:    left,
+								RightLine:   right,
 								LeftNumber:  removedLines[j].LeftNumber,
 								RightNumber: addedLines[j].RightNumber,
 								Type:        "modified",
-							})
+								Segments:    WordSegments(left, right),
+							}
+							// Words are too coarse for renames and number tweaks in short lines
+							if withinCharDiffLength(left, right, config) {
+								line.LeftChanges, line.RightChanges = CharRanges(left, right)
+							}
+							newLines = append(newLines, line)
 						}
 						continue
 					}
@@ -164,16 +272,78 @@ func (l *LCS) detectModifications(result *DiffResult) *DiffResult {
 	}
 
 	result.Lines = newLines
+	result.Chunks = GroupHunks(result)
 	return result
 }
 
-// areSimilarLines checks if two lines are similar enough to be considered a modification
-func (l *LCS) areSimilarLines(left, right string) bool {
+// parallelSimilarityPairs is the number of line pairs from which a block is
+// checked for similarity by a pool of workers rather than serially
+const parallelSimilarityPairs = 64
+
+// allLinesSimilar reports whether every removed line is similar to the added
+// line at the same index, stopping at the first pair that is not. Large
+// blocks are checked by up to GOMAXPROCS workers. overran reports that the
+// deadline, if any, passed before every pair was checked.
+func allLinesSimilar(removed, added []DiffLine, config Config, deadline time.Time) (similar, overran bool) {
+	pastDeadline := func() bool {
+		return !deadline.IsZero() && time.Now().After(deadline)
+	}
+
+	workers := min(runtime.GOMAXPROCS(0), len(removed)/parallelSimilarityPairs+1)
+	if workers <= 1 {
+		for j := range removed {
+			if pastDeadline() {
+				return false, true
+			}
+			if !areSimilarLines(removed[j].LeftLine, added[j].RightLine, config) {
+				return false, false
+			}
+		}
+		return true, false
+	}
+
+	var next atomic.Int64
+	var dissimilar, late atomic.Bool
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for !dissimilar.Load() && !late.Load() {
+				j := int(next.Add(1) - 1)
+				if j >= len(removed) {
+					return
+				}
+				if pastDeadline() {
+					late.Store(true)
+				} else if !areSimilarLines(removed[j].LeftLine, added[j].RightLine, config) {
+					dissimilar.Store(true)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	// A dissimilar pair settles the answer even if the deadline also passed
+	if dissimilar.Load() {
+		return false, false
+	}
+	return !late.Load(), late.Load()
+}
+
+// areSimilarLines checks if two lines are similar enough under the given configuration
+func areSimilarLines(left, right string, config Config) bool {
 	// If either is empty (including both empty), they're not similar
 	if left == "" || right == "" {
 		return false
 	}
 
+	// Measure lines as displayed so tabs are not undercounted
+	left = ExpandTabs(left, config.TabWidth)
+	right = ExpandTabs(right, config.TabWidth)
+	if config.NormalizeUnicode {
+		left, right = norm.NFC.String(left), norm.NFC.String(right)
+	}
+
 	// For whitespace-only differences, trim and compare
 	leftTrimmed := strings.TrimSpace(left)
 	rightTrimmed := strings.TrimSpace(right)
@@ -181,52 +351,66 @@ func (l *LCS) areSimilarLines(left, right string) bool {
 		return true
 	}
 
+	// Differing blobs are replaced, not edited
+	if isOpaqueBlob(leftTrimmed) || isOpaqueBlob(rightTrimmed) {
+		return false
+	}
+
 	// For short lines, require exact match
-	if len(left) < l.config.MinLineLength || len(right) < l.config.MinLineLength {
+	leftLen, rightLen := utf8.RuneCountInString(left), utf8.RuneCountInString(right)
+	if leftLen < config.MinLineLength || rightLen < config.MinLineLength {
 		return left == right
 	}
 
+	// The distance is at least the difference in length, which rules out
+	// lines of very different lengths without computing it
+	maxLen := max(leftLen, rightLen)
+	if 1.0-float64(maxLen-min(leftLen, rightLen))/float64(maxLen) < config.SimilarityThreshold {
+		return false
+	}
+
 	// Use Levenshtein distance for similarity
 	distance := levenshteinDistance(left, right)
-	maxLen := max(len(left), len(right))
 	similarity := 1.0 - float64(distance)/float64(maxLen)
 
-	return similarity >= l.config.SimilarityThreshold
+	return similarity >= config.SimilarityThreshold
 }
 
 // levenshteinDistance calculates the Levenshtein distance between two strings
+// in runes, so a multi-byte character counts as a single edit
 func levenshteinDistance(s1, s2 string) int {
 	if s1 == s2 {
 		return 0
 	}
 
-	if len(s1) == 0 {
-		return len(s2)
+	r1, r2 := []rune(s1), []rune(s2)
+	if len(r1) == 0 {
+		return len(r2)
 	}
 
-	if len(s2) == 0 {
-		return len(s1)
+	if len(r2) == 0 {
+		return len(r1)
 	}
 
 	// Create a 2D slice for dynamic programming
-	d := make([][]int, len(s1)+1)
+	d := make([][]int, len(r1)+1)
 	for i := range d {
-		d[i] = make([]int, len(s2)+1)
+		d[i] = make([]int, len(r2)+1)
 	}
 
 	// Initialize base cases
-	for i := 0; i <= len(s1); i++ {
+	for i := 0; i <= len(r1); i++ {
 		d[i][0] = i
 	}
-	for j := 0; j <= len(s2); j++ {
+	for j := 0; j <= len(r2); j++ {
 		d[0][j] = j
 	}
 
 	// Fill the table
-	for i := 1; i <= len(s1); i++ {
-		for j := 1; j <= len(s2); j++ {
+	for i := 1; i <= len(r1); i++ {
+		for j := 1; j <= len(r2); j++ {
 			cost := 0
-			if s1[i-1] != s2[j-1] {
+			if r1[i-1] != r2[j-1] {
 				cost = 1
 			}
 			d[i][j] = min3(
@@ -237,7 +421,7 @@ func levenshteinDistance(s1, s2 string) int {
 		}
 	}
 
-	return d[len(s1)][len(s2)]
+	return d[len(r1)][len(r2)]
 }
 
 // Helper functions
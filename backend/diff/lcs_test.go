@@ -1,7 +1,12 @@
 package diff
 
 import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLCS_ComputeDiff(t *testing.T) {
@@ -279,7 +284,8 @@ func Test_levenshteinDistance(t *testing.T) {
 		{"case sensitive", "Hello", "hello", 1},
 		{"completely different", "abc", "xyz", 3},
 		{"one longer", "test", "testing", 3},
-		{"unicode", "café", "cafe", 2}, // É is 2 bytes, so distance is 2
+		{"unicode", "café", "cafe", 1},
+		{"cjk", "你好世界", "你好世间", 1},
 		{"numbers", "123", "124", 1},
 		{"special chars", "a-b", "a_b", 1},
 	}
@@ -788,6 +794,137 @@ func BenchmarkLCS_ComputeDiff(b *testing.B) {
 	}
 }
 
+func TestDetectModifications_TimeBudget(t *testing.T) {
+	left := []string{"keep", "the quick brown fox jumps"}
+	right := []string{"keep", "the quick brown fox jumped"}
+
+	config := DefaultConfig()
+	result := NewLCS(config).ComputeDiff(left, right)
+	if result.Approximate || result.Lines[1].Type != "modified" {
+		t.Fatalf("Expected a modification without a budget, got %+v", result.Lines)
+	}
+
+	config.TimeBudget = time.Nanosecond
+	result = NewLCS(config).ComputeDiff(left, right)
+	if !result.Approximate {
+		t.Error("Expected the result to be marked approximate")
+	}
+	if len(result.Lines) != 3 || result.Lines[1].Type != "removed" || result.Lines[2].Type != "added" {
+		t.Errorf("Expected similarity scoring to be skipped, got %+v", result.Lines)
+	}
+}
+
+// modifiedBlock returns n removed lines and n added lines, each an edit of
+// the removed line at the same index
+func modifiedBlock(n int) (removed, added []DiffLine) {
+	for i := 0; i < n; i++ {
+		line := fmt.Sprintf("the value of setting number %d is enabled", i)
+		removed = append(removed, DiffLine{LeftLine: line, LeftNumber: i + 1, Type: "removed"})
+		added = append(added, DiffLine{RightLine: line + "!", RightNumber: i + 1, Type: "added"})
+	}
+	return removed, added
+}
+
+func TestAllLinesSimilar(t *testing.T) {
+	config := DefaultConfig()
+	for _, n := range []int{3, parallelSimilarityPairs * 8} {
+		removed, added := modifiedBlock(n)
+		if similar, overran := allLinesSimilar(removed, added, config, time.Time{}); !similar || overran {
+			t.Errorf("%d pairs: expected every pair to be similar, got %v, %v", n, similar, overran)
+		}
+
+		added[n-1].RightLine = "something else entirely"
+		if similar, overran := allLinesSimilar(removed, added, config, time.Time{}); similar || overran {
+			t.Errorf("%d pairs: expected the last pair to be dissimilar, got %v, %v", n, similar, overran)
+		}
+
+		removed, added = modifiedBlock(n)
+		past := time.Now().Add(-time.Second)
+		if similar, overran := allLinesSimilar(removed, added, config, past); similar || !overran {
+			t.Errorf("%d pairs: expected the deadline to be reported, got %v, %v", n, similar, overran)
+		}
+	}
+}
+
+func BenchmarkDetectModifications_LargeBlock(b *testing.B) {
+	removed, added := modifiedBlock(5000)
+	lines := append(append([]DiffLine{}, removed...), added...)
+	config := DefaultConfig()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = detectModifications(&DiffResult{Lines: append([]DiffLine{}, lines...)}, config)
+	}
+}
+
+// TestLCS_LongestSubsequence checks the matches found in linear space against
+// the length of the longest common subsequence computed with a full table
+func TestLCS_LongestSubsequence(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(40))
+		for i := range lines {
+			lines[i] = string(rune('a' + rng.Intn(4)))
+		}
+		return lines
+	}
+	tableLength := func(left, right []string) int {
+		table := make([][]int, len(left)+1)
+		for i := range table {
+			table[i] = make([]int, len(right)+1)
+		}
+		for i := 1; i <= len(left); i++ {
+			for j := 1; j <= len(right); j++ {
+				if left[i-1] == right[j-1] {
+					table[i][j] = table[i-1][j-1] + 1
+				} else {
+					table[i][j] = max(table[i-1][j], table[i][j-1])
+				}
+			}
+		}
+		return table[len(left)][len(right)]
+	}
+
+	lcs := NewLCSDefault()
+	for round := 0; round < 200; round++ {
+		left, right := randomLines(), randomLines()
+		same, lastLeft, lastRight := 0, 0, 0
+		for _, line := range lcs.ComputeDiff(left, right).Lines {
+			if line.Type != "same" {
+				continue
+			}
+			if line.LeftNumber <= lastLeft || line.RightNumber <= lastRight || left[line.LeftNumber-1] != right[line.RightNumber-1] {
+				t.Fatalf("Invalid match %+v for %q and %q", line, left, right)
+			}
+			same, lastLeft, lastRight = same+1, line.LeftNumber, line.RightNumber
+		}
+		if expected := tableLength(left, right); same != expected {
+			t.Fatalf("Matched %d lines of %q and %q, expected %d", same, left, right, expected)
+		}
+	}
+}
+
+// BenchmarkLCS_ComputeDiff_LongLines diffs files whose lines share a long
+// prefix, where comparing lines as strings is most expensive
+func BenchmarkLCS_ComputeDiff_LongLines(b *testing.B) {
+	lcs := NewLCSDefault()
+	prefix := strings.Repeat("x", 2000)
+	left := make([]string, 500)
+	var right []string
+	for i := range left {
+		left[i] = fmt.Sprintf("%s%04d", prefix, i)
+		// Removals only, so the time is not spent scoring modifications
+		if i%10 != 0 {
+			right = append(right, left[i])
+		}
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = lcs.ComputeDiff(left, right)
+	}
+}
+
 func BenchmarkLevenshteinDistance(b *testing.B) {
 	s1 := "hello world this is a test"
 	s2 := "hello world this was a test"
@@ -797,3 +934,59 @@ func BenchmarkLevenshteinDistance(b *testing.B) {
 		_ = levenshteinDistance(s1, s2)
 	}
 }
+
+func TestNormalizeUnicode(t *testing.T) {
+	composed := "naïve café"
+	decomposed := "naïve café"
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+
+		result := New(config).ComputeDiff([]string{composed}, []string{decomposed})
+		if result.Lines[0].Type == "same" {
+			t.Errorf("%s: expected differently encoded lines to differ without normalization", name)
+		}
+
+		config.NormalizeUnicode = true
+		result = New(config).ComputeDiff([]string{composed}, []string{decomposed})
+		if len(result.Lines) != 1 || result.Lines[0].Type != "same" {
+			t.Errorf("%s: expected normalized lines to be the same, got %+v", name, result.Lines)
+		}
+	}
+
+	// Similarity is measured in characters, not bytes
+	config := DefaultConfig()
+	if !areSimilarLines("これは長い日本語の文章です", "これは長い日本語の文章でした", config) {
+		t.Error("Expected Japanese lines differing in one word to be similar")
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	lines := []string{"alpha", "beta", "gamma"}
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+		algorithm := New(config)
+
+		t.Run(name, func(t *testing.T) {
+			if WithContext(context.Background(), algorithm) != algorithm {
+				t.Error("Expected a context that is never cancelled to keep the algorithm")
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			running := WithContext(ctx, algorithm)
+			if changed := changedLines(running.ComputeDiff(lines, lines)); len(changed) != 0 {
+				t.Errorf("Expected a live context to compare in full, got %+v", changed)
+			}
+
+			// A cancelled comparison stops before pairing any lines
+			cancel()
+			for _, line := range running.ComputeDiff(lines, lines).Lines {
+				if line.Type == "same" {
+					t.Errorf("Expected no lines paired after cancelling, got %+v", line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package diff
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Localization file formats
+const (
+	LocalizationPO         = "po"
+	LocalizationProperties = "properties"
+	LocalizationStrings    = "strings"
+)
+
+// Statuses of a localization entry compared across two files
+const (
+	EntryUnchanged          = "same"
+	EntrySourceChanged      = "source-changed"
+	EntryTranslationChanged = "translation-changed"
+	EntryBothChanged        = "both-changed"
+	EntryFuzzyChanged       = "fuzzy-changed"
+	EntryLeftOnly           = "left-only"
+	EntryRightOnly          = "right-only"
+)
+
+// LocalizationEntry is one translatable string of a localization file
+type LocalizationEntry struct {
+	Key string `json:"key"`
+	// Source is the original text, empty when the format or file only holds
+	// translations
+	Source      string `json:"source"`
+	Translation string `json:"translation"`
+	// Fuzzy is set for gettext entries flagged as needing review
+	Fuzzy bool `json:"fuzzy,omitempty"`
+	Line  int  `json:"line"` // 1-based line where the entry starts
+}
+
+// LocalizationEntryDiff compares the entry with one key in both files
+type LocalizationEntryDiff struct {
+	Key    string             `json:"key"`
+	Status string             `json:"status"`
+	Left   *LocalizationEntry `json:"left,omitempty"`
+	Right  *LocalizationEntry `json:"right,omitempty"`
+}
+
+// LocalizationReport is the entry-by-entry comparison of two localization files
+type LocalizationReport struct {
+	Format  string                  `json:"format"`
+	Entries []LocalizationEntryDiff `json:"entries"`
+	// SourceChanges counts entries whose source text changed, which breaks a
+	// string freeze
+	SourceChanges      int `json:"sourceChanges"`
+	TranslationChanges int `json:"translationChanges"`
+	// FuzzyChanges counts entries flagged fuzzy in one file and not the other
+	FuzzyChanges int      `json:"fuzzyChanges"`
+	Warnings     []string `json:"warnings"`
+}
+
+// localizationExtensions maps file extensions to localization formats
+var localizationExtensions = map[string]string{
+	".po":         LocalizationPO,
+	".pot":        LocalizationPO,
+	".properties": LocalizationProperties,
+	".strings":    LocalizationStrings,
+}
+
+// localeSuffixPattern matches the locale suffix of translated properties
+// files, as in messages_fr.properties or messages_pt_BR.properties
+var localeSuffixPattern = regexp.MustCompile(`_[a-z]{2,3}(?:_[A-Z]{2})?\.properties$`)
+
+// stringsEntryPattern matches a "key" = "value"; line of a .strings file
+var stringsEntryPattern = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*=\s*"((?:[^"\\]|\\.)*)"\s*;`)
+
+// LocalizationFormatForPath returns the localization format of a file based
+// on its extension, or an empty string for other files
+func LocalizationFormatForPath(path string) string {
+	return localizationExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// ParseLocalization reads the entries of a localization file. For key-value
+// formats the value is the source text in source-language files (properties
+// files without a locale suffix, and .strings files under Base.lproj) and the
+// translation otherwise.
+func ParseLocalization(lines []string, path string) ([]LocalizationEntry, error) {
+	switch LocalizationFormatForPath(path) {
+	case LocalizationPO:
+		return parsePO(lines), nil
+	case LocalizationProperties:
+		return keyValueEntries(parseProperties(lines), !localeSuffixPattern.MatchString(filepath.Base(path))), nil
+	case LocalizationStrings:
+		isSource := filepath.Base(filepath.Dir(path)) == "Base.lproj"
+		return keyValueEntries(parseStrings(lines), isSource), nil
+	default:
+		return nil, fmt.Errorf("unsupported localization file: %s", filepath.Base(path))
+	}
+}
+
+// CompareLocalization aligns the entries of two files by key. Entries are
+// listed in left file order, followed by entries found only on the right.
+// Entries whose gettext fuzzy flag alone changed, as when a translator
+// confirmed a translation without editing it, are "fuzzy-changed".
+func CompareLocalization(left, right []LocalizationEntry) *LocalizationReport {
+	report := &LocalizationReport{Entries: []LocalizationEntryDiff{}, Warnings: []string{}}
+
+	rightByKey := make(map[string]*LocalizationEntry, len(right))
+	for i := range right {
+		if _, ok := rightByKey[right[i].Key]; !ok {
+			rightByKey[right[i].Key] = &right[i]
+		}
+	}
+	seen := make(map[string]bool, len(left))
+
+	for i := range left {
+		entry := &left[i]
+		if seen[entry.Key] {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("key %q is defined more than once in the left file", entry.Key))
+			continue
+		}
+		seen[entry.Key] = true
+
+		match, ok := rightByKey[entry.Key]
+		if !ok {
+			report.Entries = append(report.Entries, LocalizationEntryDiff{Key: entry.Key, Status: EntryLeftOnly, Left: entry})
+			report.Warnings = append(report.Warnings, fmt.Sprintf("key %q is only in the left file", entry.Key))
+			continue
+		}
+
+		sourceChanged := entry.Source != match.Source
+		translationChanged := entry.Translation != match.Translation
+		fuzzyChanged := entry.Fuzzy != match.Fuzzy
+		status := EntryUnchanged
+		switch {
+		case sourceChanged && translationChanged:
+			status = EntryBothChanged
+		case sourceChanged:
+			status = EntrySourceChanged
+		case translationChanged:
+			status = EntryTranslationChanged
+		case fuzzyChanged:
+			status = EntryFuzzyChanged
+		}
+		if sourceChanged {
+			report.SourceChanges++
+		}
+		if translationChanged {
+			report.TranslationChanges++
+		}
+		if fuzzyChanged {
+			report.FuzzyChanges++
+		}
+		report.Entries = append(report.Entries, LocalizationEntryDiff{Key: entry.Key, Status: status, Left: entry, Right: match})
+	}
+
+	rightSeen := make(map[string]bool, len(right))
+	for i := range right {
+		entry := &right[i]
+		if rightSeen[entry.Key] {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("key %q is defined more than once in the right file", entry.Key))
+			continue
+		}
+		rightSeen[entry.Key] = true
+		if !seen[entry.Key] {
+			report.Entries = append(report.Entries, LocalizationEntryDiff{Key: entry.Key, Status: EntryRightOnly, Right: entry})
+			report.Warnings = append(report.Warnings, fmt.Sprintf("key %q is only in the right file", entry.Key))
+		}
+	}
+	return report
+}
+
+// keyValue is an entry of a key-value localization format
+type keyValue struct {
+	key   string
+	value string
+	line  int
+}
+
+// keyValueEntries turns key-value pairs into entries, treating the values as
+// source text or translations
+func keyValueEntries(pairs []keyValue, isSource bool) []LocalizationEntry {
+	entries := make([]LocalizationEntry, 0, len(pairs))
+	for _, pair := range pairs {
+		entry := LocalizationEntry{Key: pair.key, Line: pair.line}
+		if isSource {
+			entry.Source = pair.value
+		} else {
+			entry.Translation = pair.value
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// parsePO reads gettext entries. The key is the message ID, qualified by its
+// context when it has one; the header entry with an empty ID is skipped. A
+// "#," flags comment applies to the entry that follows it.
+func parsePO(lines []string) []LocalizationEntry {
+	entries := []LocalizationEntry{}
+	var context, id, plural string
+	var translations []string
+	var field *string
+	start := 0
+	hasID := false
+	fuzzy, nextFuzzy := false, false
+
+	flush := func() {
+		if hasID && id != "" {
+			key := id
+			if context != "" {
+				key = context + "|" + id
+			}
+			source := id
+			if plural != "" {
+				source += "\n" + plural
+			}
+			entries = append(entries, LocalizationEntry{
+				Key:         key,
+				Source:      source,
+				Translation: strings.Join(translations, "\n"),
+				Fuzzy:       fuzzy,
+				Line:        start,
+			})
+		}
+		context, id, plural, translations, field, hasID = "", "", "", nil, nil, false
+		fuzzy, nextFuzzy = nextFuzzy, false
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		keyword, rest, _ := strings.Cut(trimmed, " ")
+		switch {
+		case strings.HasPrefix(trimmed, "#,"):
+			field = nil
+			nextFuzzy = nextFuzzy || hasPOFlag(trimmed[2:], "fuzzy")
+		case trimmed == "" || strings.HasPrefix(trimmed, "#"):
+			field = nil
+		case strings.HasPrefix(trimmed, `"`):
+			if field != nil {
+				*field += unquotePO(trimmed)
+			}
+		case keyword == "msgctxt":
+			flush()
+			start = i + 1
+			context = unquotePO(rest)
+			field = &context
+		case keyword == "msgid":
+			if hasID || context == "" {
+				flush()
+				start = i + 1
+			}
+			hasID = true
+			id = unquotePO(rest)
+			field = &id
+		case keyword == "msgid_plural":
+			plural = unquotePO(rest)
+			field = &plural
+		case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+			translations = append(translations, unquotePO(rest))
+			field = &translations[len(translations)-1]
+		}
+	}
+	flush()
+	return entries
+}
+
+// hasPOFlag reports whether a comma-separated list of gettext flags has flag
+func hasPOFlag(flags, flag string) bool {
+	for _, f := range strings.Split(flags, ",") {
+		if strings.TrimSpace(f) == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// unquotePO decodes a quoted PO string, keeping the text as is when it is
+// not a valid quoted string
+func unquotePO(quoted string) string {
+	quoted = strings.TrimSpace(quoted)
+	if text, err := strconv.Unquote(quoted); err == nil {
+		return text
+	}
+	return strings.Trim(quoted, `"`)
+}
+
+// parseProperties reads Java properties, joining continued lines and
+// decoding escapes
+func parseProperties(lines []string) []keyValue {
+	pairs := []keyValue{}
+	for i := 0; i < len(lines); i++ {
+		start := i
+		logical := strings.TrimLeft(lines[i], " \t\f")
+		if logical == "" || logical[0] == '#' || logical[0] == '!' {
+			continue
+		}
+		for continuesProperty(logical) && i+1 < len(lines) {
+			i++
+			logical = logical[:len(logical)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+
+		// The key ends at the first unescaped separator or whitespace
+		end := 0
+		for end < len(logical) && !strings.ContainsRune("=: \t\f", rune(logical[end])) {
+			if logical[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end, len(logical))
+		value := strings.TrimLeft(logical[end:], " \t\f")
+		if value != "" && (value[0] == '=' || value[0] == ':') {
+			value = strings.TrimLeft(value[1:], " \t\f")
+		}
+		pairs = append(pairs, keyValue{
+			key:   unescapeProperty(logical[:end]),
+			value: unescapeProperty(value),
+			line:  start + 1,
+		})
+	}
+	return pairs
+}
+
+// continuesProperty reports whether a line ends with an odd number of
+// backslashes, continuing the property on the next line
+func continuesProperty(line string) bool {
+	count := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		count++
+	}
+	return count%2 == 1
+}
+
+// unescapeProperty decodes the escapes of a properties key or value
+func unescapeProperty(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var builder strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\\' || i+1 >= len(text) {
+			builder.WriteByte(text[i])
+			continue
+		}
+		i++
+		switch text[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'u':
+			if i+4 < len(text) {
+				if code, err := strconv.ParseUint(text[i+1:i+5], 16, 32); err == nil {
+					builder.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			builder.WriteByte('u')
+		default:
+			builder.WriteByte(text[i])
+		}
+	}
+	return builder.String()
+}
+
+// parseStrings reads the "key" = "value"; entries of an Apple .strings file
+func parseStrings(lines []string) []keyValue {
+	pairs := []keyValue{}
+	for i, line := range lines {
+		if match := stringsEntryPattern.FindStringSubmatch(line); match != nil {
+			pairs = append(pairs, keyValue{
+				key:   unquotePO(`"` + match[1] + `"`),
+				value: unquotePO(`"` + match[2] + `"`),
+				line:  i + 1,
+			})
+		}
+	}
+	return pairs
+}
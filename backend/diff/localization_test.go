@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseLocalization(t *testing.T) {
+	t.Run("po", func(t *testing.T) {
+		lines := []string{
+			`msgid ""`,
+			`msgstr "Content-Type: text/plain\n"`,
+			``,
+			`#: main.c:10`,
+			`msgid "Open"`,
+			`msgstr "Ouvrir"`,
+			``,
+			`msgctxt "menu"`,
+			`msgid "Open"`,
+			`msgstr ""`,
+			`"Ouvrir..."`,
+			``,
+			`#, fuzzy, c-format`,
+			`msgid "file"`,
+			`msgid_plural "files"`,
+			`msgstr[0] "fichier"`,
+			`msgstr[1] "fichiers"`,
+		}
+		entries, err := ParseLocalization(lines, "fr.po")
+		if err != nil {
+			t.Fatalf("ParseLocalization returned error: %v", err)
+		}
+		expected := []LocalizationEntry{
+			{Key: "Open", Source: "Open", Translation: "Ouvrir", Line: 5},
+			{Key: "menu|Open", Source: "Open", Translation: "Ouvrir...", Line: 8},
+			{Key: "file", Source: "file\nfiles", Translation: "fichier\nfichiers", Fuzzy: true, Line: 14},
+		}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, entries)
+		}
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		lines := []string{
+			"# comment",
+			"greeting = Hello \\",
+			"    world",
+			"farewell:Bye",
+			"escaped\\ key=caf\\u00e9",
+		}
+		entries, err := ParseLocalization(lines, "messages_fr.properties")
+		if err != nil {
+			t.Fatalf("ParseLocalization returned error: %v", err)
+		}
+		expected := []LocalizationEntry{
+			{Key: "greeting", Translation: "Hello world", Line: 2},
+			{Key: "farewell", Translation: "Bye", Line: 4},
+			{Key: "escaped key", Translation: "café", Line: 5},
+		}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, entries)
+		}
+
+		entries, _ = ParseLocalization(lines, "messages.properties")
+		if entries[0].Source != "Hello world" || entries[0].Translation != "" {
+			t.Errorf("Expected the base file to hold source strings, got %+v", entries[0])
+		}
+	})
+
+	t.Run("strings", func(t *testing.T) {
+		lines := []string{
+			`/* Title of the window */`,
+			`"window.title" = "Fen\"être";`,
+		}
+		entries, err := ParseLocalization(lines, "fr.lproj/Localizable.strings")
+		if err != nil {
+			t.Fatalf("ParseLocalization returned error: %v", err)
+		}
+		expected := []LocalizationEntry{{Key: "window.title", Translation: `Fen"être`, Line: 2}}
+		if !reflect.DeepEqual(entries, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, entries)
+		}
+	})
+
+	if _, err := ParseLocalization(nil, "notes.txt"); err == nil {
+		t.Error("Expected error for an unsupported file")
+	}
+}
+
+func TestCompareLocalization(t *testing.T) {
+	left := []LocalizationEntry{
+		{Key: "a", Source: "Save", Translation: "Enregistrer"},
+		{Key: "b", Source: "Open", Translation: "Ouvrir"},
+		{Key: "c", Source: "Quit", Translation: "Quitter"},
+		{Key: "d", Source: "Close", Translation: "Fermer", Fuzzy: true},
+		{Key: "gone", Source: "Old", Translation: "Vieux"},
+	}
+	right := []LocalizationEntry{
+		{Key: "new", Source: "New", Translation: "Nouveau"},
+		{Key: "c", Source: "Quit", Translation: "Quitter"},
+		{Key: "d", Source: "Close", Translation: "Fermer"},
+		{Key: "b", Source: "Open", Translation: "Ouvrir…"},
+		{Key: "a", Source: "Save all", Translation: "Enregistrer"},
+	}
+
+	report := CompareLocalization(left, right)
+	var statuses []string
+	for _, entry := range report.Entries {
+		statuses = append(statuses, entry.Key+":"+entry.Status)
+	}
+	expected := []string{"a:source-changed", "b:translation-changed", "c:same", "d:fuzzy-changed", "gone:left-only", "new:right-only"}
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("Expected %v, got %v", expected, statuses)
+	}
+	if report.SourceChanges != 1 || report.TranslationChanges != 1 || report.FuzzyChanges != 1 {
+		t.Errorf("Unexpected change counts: %+v", report)
+	}
+	if len(report.Warnings) != 2 {
+		t.Errorf("Expected warnings for the one-sided keys, got %v", report.Warnings)
+	}
+}
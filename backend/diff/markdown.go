@@ -0,0 +1,286 @@
+package diff
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown block kinds
+const (
+	BlockHeading   = "heading"
+	BlockParagraph = "paragraph"
+	BlockCode      = "code"
+	BlockList      = "list"
+	BlockQuote     = "quote"
+	BlockRule      = "rule"
+)
+
+// MarkdownBlock is a block-level element of a Markdown document with the
+// source lines it came from and its rendered HTML
+type MarkdownBlock struct {
+	Kind      string `json:"kind"`
+	StartLine int    `json:"startLine"` // 1-based, inclusive
+	EndLine   int    `json:"endLine"`   // 1-based, inclusive
+	Source    string `json:"source"`
+	HTML      string `json:"html"`
+}
+
+// MarkdownBlockDiff pairs a block of the left document with a block of the
+// right one. Left is nil for added blocks and Right for removed blocks.
+type MarkdownBlockDiff struct {
+	Type  string         `json:"type"` // "same", "added", "removed", "modified"
+	Left  *MarkdownBlock `json:"left,omitempty"`
+	Right *MarkdownBlock `json:"right,omitempty"`
+}
+
+// Patterns recognizing the start of Markdown blocks and inline elements
+var (
+	mdFencePattern    = regexp.MustCompile("^\\s{0,3}(```|~~~)\\s*([\\w+-]*)")
+	mdRulePattern     = regexp.MustCompile(`^\s{0,3}(?:(?:-\s*){3,}|(?:\*\s*){3,}|(?:_\s*){3,})$`)
+	mdListPattern     = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+(.*)$`)
+	mdQuotePattern    = regexp.MustCompile(`^\s{0,3}>\s?(.*)$`)
+	mdCodeSpanPattern = regexp.MustCompile("`+[^`]*`+")
+	mdImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+	mdLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	mdStrongPattern   = regexp.MustCompile(`\*\*(.+?)\*\*|__(.+?)__`)
+	mdEmphasisPattern = regexp.MustCompile(`\*([^*\s][^*]*)\*|\b_([^_\s][^_]*)_\b`)
+	mdOrderedPattern  = regexp.MustCompile(`^\s*\d+[.)]`)
+
+	mdPlaceholderPattern = regexp.MustCompile("\x00\\d+\x00")
+)
+
+// safeURLSchemes are the URL schemes allowed in rendered links and images.
+// URLs without a scheme are relative and always allowed.
+var safeURLSchemes = map[string]bool{"http": true, "https": true, "mailto": true}
+
+// ParseMarkdown splits a Markdown document into blocks and renders each to
+// HTML. Raw HTML in the source is escaped rather than passed through, and
+// links with scripting schemes are dropped, so the output is safe to display.
+func ParseMarkdown(lines []string) []MarkdownBlock {
+	blocks := []MarkdownBlock{}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			i++
+			continue
+		}
+
+		start := i
+		kind := BlockParagraph
+		switch {
+		case mdFencePattern.MatchString(line):
+			kind = BlockCode
+			fence := mdFencePattern.FindStringSubmatch(line)[1]
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+			}
+			i = min(i+1, len(lines))
+		case markdownHeadingPattern.MatchString(line):
+			kind = BlockHeading
+			i++
+		case mdRulePattern.MatchString(line):
+			kind = BlockRule
+			i++
+		case mdQuotePattern.MatchString(line):
+			kind = BlockQuote
+			for i++; i < len(lines) && mdQuotePattern.MatchString(lines[i]); i++ {
+			}
+		case mdListPattern.MatchString(line):
+			kind = BlockList
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsMarkdownBlock(lines[i], true); i++ {
+			}
+		default:
+			for i++; i < len(lines) && strings.TrimSpace(lines[i]) != "" && !startsMarkdownBlock(lines[i], false); i++ {
+			}
+		}
+
+		source := lines[start:i]
+		blocks = append(blocks, MarkdownBlock{
+			Kind:      kind,
+			StartLine: start + 1,
+			EndLine:   i,
+			Source:    strings.Join(source, "\n"),
+			HTML:      renderMarkdownBlock(kind, source),
+		})
+	}
+	return blocks
+}
+
+// RenderMarkdown renders a Markdown document to sanitized HTML
+func RenderMarkdown(lines []string) string {
+	var parts []string
+	for _, block := range ParseMarkdown(lines) {
+		parts = append(parts, block.HTML)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// DiffMarkdown compares two Markdown documents block by block, so a changed
+// paragraph is reported as one modified block however it was rewrapped
+func DiffMarkdown(leftLines, rightLines []string, algorithm Algorithm) []MarkdownBlockDiff {
+	leftBlocks, rightBlocks := ParseMarkdown(leftLines), ParseMarkdown(rightLines)
+
+	// Compare blocks by their text with line breaks collapsed, so rewrapping
+	// a paragraph does not change it
+	result := algorithm.ComputeDiff(blockKeys(leftBlocks), blockKeys(rightBlocks))
+
+	diffs := make([]MarkdownBlockDiff, 0, len(result.Lines))
+	for _, line := range result.Lines {
+		blockDiff := MarkdownBlockDiff{Type: line.Type}
+		if line.LeftNumber > 0 {
+			blockDiff.Left = &leftBlocks[line.LeftNumber-1]
+		}
+		if line.RightNumber > 0 {
+			blockDiff.Right = &rightBlocks[line.RightNumber-1]
+		}
+		diffs = append(diffs, blockDiff)
+	}
+	return diffs
+}
+
+// blockKeys returns the text blocks are compared by
+func blockKeys(blocks []MarkdownBlock) []string {
+	keys := make([]string, len(blocks))
+	for i, block := range blocks {
+		keys[i] = block.Kind + ":" + strings.Join(strings.Fields(block.Source), " ")
+		if block.Kind == BlockCode {
+			keys[i] = block.Kind + ":" + block.Source
+		}
+	}
+	return keys
+}
+
+// startsMarkdownBlock reports whether a line interrupts the paragraph or list
+// before it. List items continue a list but interrupt a paragraph.
+func startsMarkdownBlock(line string, inList bool) bool {
+	if mdFencePattern.MatchString(line) || markdownHeadingPattern.MatchString(line) ||
+		mdRulePattern.MatchString(line) || mdQuotePattern.MatchString(line) {
+		return true
+	}
+	return !inList && mdListPattern.MatchString(line)
+}
+
+// renderMarkdownBlock renders the source lines of one block
+func renderMarkdownBlock(kind string, source []string) string {
+	switch kind {
+	case BlockCode:
+		fence := mdFencePattern.FindStringSubmatch(source[0])
+		body := source[1:]
+		if n := len(body); n > 0 && strings.HasPrefix(strings.TrimSpace(body[n-1]), fence[1]) {
+			body = body[:n-1]
+		}
+		class := ""
+		if fence[2] != "" {
+			class = ` class="language-` + html.EscapeString(fence[2]) + `"`
+		}
+		return "<pre><code" + class + ">" + html.EscapeString(strings.Join(body, "\n")) + "</code></pre>"
+	case BlockHeading:
+		match := markdownHeadingPattern.FindStringSubmatch(source[0])
+		tag := "h" + string(rune('0'+len(match[1])))
+		return "<" + tag + ">" + renderInline(match[2]) + "</" + tag + ">"
+	case BlockRule:
+		return "<hr>"
+	case BlockQuote:
+		inner := make([]string, len(source))
+		for i, line := range source {
+			inner[i] = mdQuotePattern.FindStringSubmatch(line)[1]
+		}
+		return "<blockquote>" + RenderMarkdown(inner) + "</blockquote>"
+	case BlockList:
+		tag := "ul"
+		if mdOrderedPattern.MatchString(source[0]) {
+			tag = "ol"
+		}
+		var items []string
+		for _, line := range source {
+			if match := mdListPattern.FindStringSubmatch(line); match != nil {
+				items = append(items, match[2])
+			} else if len(items) > 0 {
+				items[len(items)-1] += "\n" + strings.TrimSpace(line)
+			}
+		}
+		var builder strings.Builder
+		builder.WriteString("<" + tag + ">")
+		for _, item := range items {
+			builder.WriteString("<li>" + renderInline(item) + "</li>")
+		}
+		builder.WriteString("</" + tag + ">")
+		return builder.String()
+	default:
+		trimmed := make([]string, len(source))
+		for i, line := range source {
+			trimmed[i] = strings.TrimSpace(line)
+		}
+		return "<p>" + renderInline(strings.Join(trimmed, "\n")) + "</p>"
+	}
+}
+
+// renderInline escapes text and renders code spans, images, links, and
+// emphasis within it
+func renderInline(text string) string {
+	var builder strings.Builder
+	last := 0
+	for _, span := range mdCodeSpanPattern.FindAllStringIndex(text, -1) {
+		builder.WriteString(renderInlineText(text[last:span[0]]))
+		code := strings.Trim(text[span[0]:span[1]], "`")
+		builder.WriteString("<code>" + html.EscapeString(strings.TrimSpace(code)) + "</code>")
+		last = span[1]
+	}
+	builder.WriteString(renderInlineText(text[last:]))
+	return builder.String()
+}
+
+// renderInlineText renders the inline elements of text outside code spans.
+// Generated tags are set aside while emphasis is rendered so that link
+// targets are never rewritten.
+func renderInlineText(text string) string {
+	text = html.EscapeString(strings.ReplaceAll(text, "\x00", ""))
+
+	var tags []string
+	protect := func(tag string) string {
+		tags = append(tags, tag)
+		return "\x00" + strconv.Itoa(len(tags)-1) + "\x00"
+	}
+	text = mdImagePattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mdImagePattern.FindStringSubmatch(match)
+		if !isSafeURL(parts[2]) {
+			return parts[1]
+		}
+		return protect(`<img src="` + parts[2] + `" alt="` + parts[1] + `">`)
+	})
+	text = mdLinkPattern.ReplaceAllStringFunc(text, func(match string) string {
+		parts := mdLinkPattern.FindStringSubmatch(match)
+		if !isSafeURL(parts[2]) {
+			return parts[1]
+		}
+		return protect(`<a href="`+parts[2]+`">`) + parts[1] + protect("</a>")
+	})
+	text = mdStrongPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return "<strong>" + match[2:len(match)-2] + "</strong>"
+	})
+	text = mdEmphasisPattern.ReplaceAllStringFunc(text, func(match string) string {
+		return "<em>" + match[1:len(match)-1] + "</em>"
+	})
+	return mdPlaceholderPattern.ReplaceAllStringFunc(text, func(match string) string {
+		index, _ := strconv.Atoi(strings.Trim(match, "\x00"))
+		return tags[index]
+	})
+}
+
+// isSafeURL reports whether an HTML-escaped URL is relative or uses an
+// allowed scheme
+func isSafeURL(escaped string) bool {
+	url := strings.ToLower(html.UnescapeString(escaped))
+	url = strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, url)
+	colon := strings.Index(url, ":")
+	if colon < 0 || strings.ContainsAny(url[:colon], "/?#") {
+		return true
+	}
+	return safeURLSchemes[url[:colon]]
+}
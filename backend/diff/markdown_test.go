@@ -0,0 +1,75 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+	}{
+		{"heading", []string{"## Setup *now*"}, "<h2>Setup <em>now</em></h2>"},
+		{"paragraph", []string{"Some **bold**", "and `a<b>` code."}, "<p>Some <strong>bold</strong>\nand <code>a&lt;b&gt;</code> code.</p>"},
+		{"list", []string{"1. one", "2. two"}, "<ol><li>one</li><li>two</li></ol>"},
+		{"code", []string{"```go", "x := <y>", "```"}, "<pre><code class=\"language-go\">x := &lt;y&gt;</code></pre>"},
+		{"quote", []string{"> quoted"}, "<blockquote><p>quoted</p></blockquote>"},
+		{"rule", []string{"---"}, "<hr>"},
+		{"link", []string{"[docs](https://example.com/a_b_c)"}, "<p><a href=\"https://example.com/a_b_c\">docs</a></p>"},
+	}
+	for _, tt := range tests {
+		if got := RenderMarkdown(tt.lines); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.name, tt.expected, got)
+		}
+	}
+}
+
+func TestRenderMarkdown_Sanitizes(t *testing.T) {
+	got := RenderMarkdown([]string{
+		`<script>alert(1)</script>`,
+		``,
+		`[click](javascript:alert(1)) ![x](" onerror="alert(1))`,
+	})
+	if strings.Contains(got, "<script") || strings.Contains(got, "javascript:") || strings.Contains(got, `" onerror`) {
+		t.Errorf("Expected unsafe markup to be neutralized, got %q", got)
+	}
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	left := []string{
+		"# Title",
+		"",
+		"Dropped paragraph.",
+		"",
+		"The quick brown fox jumps",
+		"over the lazy dog.",
+		"",
+		"Reworded closing paragraph here.",
+	}
+	right := []string{
+		"# Title",
+		"",
+		"The quick brown fox jumps over the",
+		"lazy dog.",
+		"",
+		"Reworded closing paragraph there.",
+	}
+
+	diffs := DiffMarkdown(left, right, NewLCSDefault())
+	var types []string
+	for _, d := range diffs {
+		types = append(types, d.Type)
+	}
+	expected := []string{"same", "removed", "same", "modified"}
+	if strings.Join(types, ",") != strings.Join(expected, ",") {
+		t.Fatalf("Expected %v, got %v", expected, types)
+	}
+	if diffs[1].Right != nil || diffs[1].Left.HTML != "<p>Dropped paragraph.</p>" {
+		t.Errorf("Unexpected removed block: %+v", diffs[1])
+	}
+	if diffs[2].Left.StartLine != 5 || diffs[2].Right.EndLine != 4 {
+		t.Errorf("Expected the rewrapped paragraph to keep its source lines, got %+v %+v", diffs[2].Left, diffs[2].Right)
+	}
+}
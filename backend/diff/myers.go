@@ -0,0 +1,238 @@
+package diff
+
+// Myers implements Eugene Myers' O(ND) diff algorithm. Its running time grows
+// with the size of the difference rather than the product of the file sizes,
+// and the linear-space variant used here needs memory proportional to the
+// file sizes, so large files with few changes diff quickly.
+type Myers struct {
+	config Config
+}
+
+// NewMyers creates a new Myers diff algorithm with the given configuration
+func NewMyers(config Config) *Myers {
+	return &Myers{config: config}
+}
+
+// NewMyersDefault creates a new Myers diff algorithm with default configuration
+func NewMyersDefault() *Myers {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmMyers
+	return NewMyers(config)
+}
+
+// Config returns the algorithm's configuration
+func (m *Myers) Config() Config {
+	return m.config
+}
+
+// WithConfig returns the algorithm selected by the given configuration
+func (m *Myers) WithConfig(config Config) Algorithm {
+	return New(config)
+}
+
+// ComputeDiff compares two sets of lines and returns the diff result
+func (m *Myers) ComputeDiff(leftLines, rightLines []string) *DiffResult {
+	if m.config.IgnoreBlankLines {
+		return diffIgnoringBlankLines(leftLines, rightLines, m.config)
+	}
+
+	left, right := internLines(comparisonKeys(leftLines, m.config), comparisonKeys(rightLines, m.config))
+
+	// matches[i] is the right index paired with left line i, or -1
+	matches := make([]int, len(left))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &myersState{left: left, right: right, matches: matches, done: m.config.Done}
+	s.compare(0, len(left), 0, len(right))
+
+	result := &DiffResult{Lines: buildDiffLines(leftLines, rightLines, matches)}
+	return detectModifications(result, m.config)
+}
+
+// internLines maps each distinct line to a small integer so the algorithms
+// compares integers instead of strings
+func internLines(leftLines, rightLines []string) ([]int, []int) {
+	ids := make(map[string]int)
+	intern := func(lines []string) []int {
+		out := make([]int, len(lines))
+		for i, line := range lines {
+			id, ok := ids[line]
+			if !ok {
+				id = len(ids)
+				ids[line] = id
+			}
+			out[i] = id
+		}
+		return out
+	}
+	return intern(leftLines), intern(rightLines)
+}
+
+// buildDiffLines turns matched line pairs into diff lines. Between two
+// matches the removed lines come before the added ones, the order
+// detectModifications expects.
+func buildDiffLines(leftLines, rightLines []string, matches []int) []DiffLine {
+	lines := []DiffLine{}
+	i, j := 0, 0
+	for i < len(leftLines) || j < len(rightLines) {
+		for i < len(leftLines) && matches[i] < 0 {
+			lines = append(lines, DiffLine{
+				LeftLine:   leftLines[i],
+				LeftNumber: i + 1,
+				Type:       "removed",
+			})
+			i++
+		}
+
+		next := len(rightLines)
+		if i < len(leftLines) {
+			next = matches[i]
+		}
+		for j < next {
+			lines = append(lines, DiffLine{
+				RightLine:   rightLines[j],
+				RightNumber: j + 1,
+				Type:        "added",
+			})
+			j++
+		}
+
+		if i < len(leftLines) {
+			lines = append(lines, DiffLine{
+				LeftLine:    leftLines[i],
+				RightLine:   rightLines[j],
+				LeftNumber:  i + 1,
+				RightNumber: j + 1,
+				Type:        "same",
+			})
+			i++
+			j++
+		}
+	}
+	return lines
+}
+
+// myersState holds the interned lines and the matches found so far
+type myersState struct {
+	left    []int
+	right   []int
+	matches []int
+	done    <-chan struct{}
+}
+
+// compare matches the lines of left[aLo:aHi] against right[bLo:bHi] by
+// splitting the ranges at the middle snake of a shortest edit script
+func (s *myersState) compare(aLo, aHi, bLo, bHi int) {
+	if stopped(s.done) {
+		return
+	}
+	// Common prefix and suffix need no searching
+	for aLo < aHi && bLo < bHi && s.left[aLo] == s.right[bLo] {
+		s.matches[aLo] = bLo
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && s.left[aHi-1] == s.right[bHi-1] {
+		aHi--
+		bHi--
+		s.matches[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	x, y, ok := s.middleSnake(aLo, aHi, bLo, bHi)
+	if !ok {
+		// Nothing in common, so every line is removed or added
+		return
+	}
+	s.compare(aLo, aLo+x, bLo, bLo+y)
+	s.compare(aLo+x, aHi, bLo+y, bHi)
+}
+
+// middleSnake runs the search forward from the start and backward from the
+// end of both ranges until the paths overlap, returning the offsets at which
+// to split them. ok is false when the ranges share no lines.
+func (s *myersState) middleSnake(aLo, aHi, bLo, bHi int) (x, y int, ok bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	size := 2*maxD + 2
+	forward := make([]int, size)
+	backward := make([]int, size)
+	for i := range forward {
+		forward[i] = -1
+		backward[i] = -1
+	}
+	forward[offset+1] = 0
+	backward[offset+1] = 0
+
+	delta := n - m
+	// With an odd delta the paths can only meet during a forward step
+	checkForward := delta%2 != 0
+
+	// Diagonals that ran off the edit graph are skipped from then on
+	fStart, fEnd, bStart, bEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD && !stopped(s.done); d++ {
+		for k := -d + fStart; k <= d-fEnd; k += 2 {
+			i := offset + k
+			var x1 int
+			if k == -d || (k != d && forward[i-1] < forward[i+1]) {
+				x1 = forward[i+1]
+			} else {
+				x1 = forward[i-1] + 1
+			}
+			y1 := x1 - k
+			for x1 < n && y1 < m && s.left[aLo+x1] == s.right[bLo+y1] {
+				x1++
+				y1++
+			}
+			forward[i] = x1
+
+			if x1 > n {
+				fEnd += 2
+			} else if y1 > m {
+				fStart += 2
+			} else if checkForward {
+				j := offset + delta - k
+				if j >= 0 && j < size && backward[j] != -1 && x1 >= n-backward[j] {
+					return x1, y1, true
+				}
+			}
+		}
+
+		for k := -d + bStart; k <= d-bEnd; k += 2 {
+			i := offset + k
+			var x2 int
+			if k == -d || (k != d && backward[i-1] < backward[i+1]) {
+				x2 = backward[i+1]
+			} else {
+				x2 = backward[i-1] + 1
+			}
+			y2 := x2 - k
+			for x2 < n && y2 < m && s.left[aHi-x2-1] == s.right[bHi-y2-1] {
+				x2++
+				y2++
+			}
+			backward[i] = x2
+
+			if x2 > n {
+				bEnd += 2
+			} else if y2 > m {
+				bStart += 2
+			} else if !checkForward {
+				j := offset + delta - k
+				if j >= 0 && j < size && forward[j] != -1 {
+					x1 := forward[j]
+					y1 := x1 - (j - offset)
+					if x1 >= n-x2 {
+						return x1, y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
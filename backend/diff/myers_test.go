@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// countSame returns the number of unchanged lines in a diff
+func countSame(result *DiffResult) int {
+	count := 0
+	for _, line := range result.Lines {
+		if line.Type == "same" {
+			count++
+		}
+	}
+	return count
+}
+
+// reconstruct rebuilds both sides of a diff so it can be checked for completeness
+func reconstruct(result *DiffResult) ([]string, []string) {
+	left, right := []string{}, []string{}
+	for _, line := range result.Lines {
+		if line.LeftNumber > 0 {
+			left = append(left, line.LeftLine)
+		}
+		if line.RightNumber > 0 {
+			right = append(right, line.RightLine)
+		}
+	}
+	return left, right
+}
+
+func TestMyers_ComputeDiff(t *testing.T) {
+	myers := NewMyersDefault()
+
+	tests := []struct {
+		name     string
+		left     []string
+		right    []string
+		expected []string // line types
+	}{
+		{"both empty", []string{}, []string{}, []string{}},
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, []string{"same", "same"}},
+		{"addition", []string{"a", "c"}, []string{"a", "b", "c"}, []string{"same", "added", "same"}},
+		{"removal", []string{"a", "b", "c"}, []string{"a", "c"}, []string{"same", "removed", "same"}},
+		{"all new", []string{}, []string{"a", "b"}, []string{"added", "added"}},
+		{"all removed", []string{"a", "b"}, []string{}, []string{"removed", "removed"}},
+		{"nothing in common", []string{"a"}, []string{"b"}, []string{"removed", "added"}},
+		{
+			"modification",
+			[]string{"func main() {", "    return 1", "}"},
+			[]string{"func main() {", "    return 2", "}"},
+			[]string{"same", "modified", "same"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := myers.ComputeDiff(tt.left, tt.right)
+			types := []string{}
+			for _, line := range result.Lines {
+				types = append(types, line.Type)
+			}
+			if !reflect.DeepEqual(types, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, types)
+			}
+		})
+	}
+}
+
+func TestMyers_MatchesLCS(t *testing.T) {
+	myers := NewMyersDefault()
+	lcs := NewLCSDefault()
+	rng := rand.New(rand.NewSource(1))
+	alphabet := []string{"a", "b", "c", "d"}
+
+	randomLines := func() []string {
+		lines := make([]string, rng.Intn(30))
+		for i := range lines {
+			lines[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		left, right := randomLines(), randomLines()
+		result := myers.ComputeDiff(left, right)
+
+		gotLeft, gotRight := reconstruct(result)
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatalf("Diff of %v and %v does not reproduce the inputs", left, right)
+		}
+		// Both algorithms find a longest common subsequence
+		if got, want := countSame(result), countSame(lcs.ComputeDiff(left, right)); got != want {
+			t.Fatalf("Diff of %v and %v kept %d lines, expected %d", left, right, got, want)
+		}
+	}
+}
+
+func TestMyers_LargeFileFewChanges(t *testing.T) {
+	left := make([]string, 50000)
+	for i := range left {
+		left[i] = string(rune('a'+i%26)) + string(rune('a'+i/26%26)) + string(rune('a'+i/676%26))
+	}
+	right := append([]string{}, left...)
+	right[100] = "changed"
+	right = append(right[:40000], append([]string{"inserted"}, right[40000:]...)...)
+
+	result := NewMyersDefault().ComputeDiff(left, right)
+	if got := countSame(result); got != len(left)-1 {
+		t.Errorf("Expected %d unchanged lines, got %d", len(left)-1, got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	config := DefaultConfig()
+	if _, ok := New(config).(*LCS); !ok {
+		t.Error("Expected the default configuration to select LCS")
+	}
+
+	config.Algorithm = AlgorithmMyers
+	if _, ok := New(config).(*Myers); !ok {
+		t.Error("Expected Myers to be selected")
+	}
+	config.Algorithm = AlgorithmPatience
+	if _, ok := New(config).(*Patience); !ok {
+		t.Error("Expected Patience to be selected")
+	}
+
+	config.Algorithm = AlgorithmHistogram
+	if _, ok := New(config).(*Histogram); !ok {
+		t.Error("Expected Histogram to be selected")
+	}
+
+	config.Algorithm = AlgorithmMyers
+	if _, ok := NewLCSDefault().WithConfig(config).(*Myers); !ok {
+		t.Error("Expected WithConfig to switch algorithms")
+	}
+}
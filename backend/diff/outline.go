@@ -0,0 +1,106 @@
+package diff
+
+// Change statuses of an outline entry
+const (
+	OutlineUnchanged = "same"
+	OutlineModified  = "modified"
+	OutlineAdded     = "added"
+	OutlineRemoved   = "removed"
+)
+
+// OutlineNode is a symbol in a file's outline together with the symbols it
+// encloses and whether its lines changed
+type OutlineNode struct {
+	Name      string        `json:"name"`
+	Kind      string        `json:"kind"`
+	StartLine int           `json:"startLine"`
+	EndLine   int           `json:"endLine"`
+	Status    string        `json:"status,omitempty"` // empty when the file is not being compared
+	Children  []OutlineNode `json:"children"`
+}
+
+// BuildOutline nests symbols under the symbols enclosing them. When result is
+// not nil each node's status is derived from the lines of the given side
+// ("left" or "right") that fall within it.
+func BuildOutline(symbols []Symbol, result *DiffResult, side string) []OutlineNode {
+	nodes, _ := buildOutlineLevel(symbols, 0, result, side)
+	return nodes
+}
+
+// buildOutlineLevel builds the nodes at the depth of symbols[start], returning
+// them and the index of the first symbol after them
+func buildOutlineLevel(symbols []Symbol, start int, result *DiffResult, side string) ([]OutlineNode, int) {
+	nodes := []OutlineNode{}
+	if start >= len(symbols) {
+		return nodes, start
+	}
+	depth := symbols[start].Depth
+	i := start
+	for i < len(symbols) && symbols[i].Depth >= depth {
+		symbol := symbols[i]
+		node := OutlineNode{
+			Name:      symbol.Name,
+			Kind:      symbol.Kind,
+			StartLine: symbol.StartLine,
+			EndLine:   symbol.EndLine,
+			Children:  []OutlineNode{},
+		}
+		if result != nil {
+			node.Status = symbolStatus(result, symbol, side)
+		}
+		i++
+		if i < len(symbols) && symbols[i].Depth > depth {
+			node.Children, i = buildOutlineLevel(symbols, i, result, side)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, i
+}
+
+// symbolStatus classifies the diff lines within a symbol's range on one side.
+// Lines inserted on the other side between two of the symbol's lines count as
+// changes to it.
+func symbolStatus(result *DiffResult, symbol Symbol, side string) string {
+	own, other := "removed", "added"
+	number := func(line DiffLine) int { return line.LeftNumber }
+	if side == "right" {
+		own, other = "added", "removed"
+		number = func(line DiffLine) int { return line.RightNumber }
+	}
+
+	total, whollyOwn, changed := 0, 0, false
+	previous := 0
+	for _, line := range result.Lines {
+		n := number(line)
+		if n == 0 {
+			// Only insertions strictly inside the symbol change it
+			if line.Type == other && previous >= symbol.StartLine && previous < symbol.EndLine {
+				changed = true
+			}
+			continue
+		}
+		previous = n
+		if n < symbol.StartLine || n > symbol.EndLine {
+			continue
+		}
+		total++
+		switch line.Type {
+		case own:
+			whollyOwn++
+		case "modified":
+			changed = true
+		}
+	}
+
+	switch {
+	case total > 0 && whollyOwn == total:
+		if side == "right" {
+			return OutlineAdded
+		}
+		return OutlineRemoved
+	case changed || whollyOwn > 0:
+		return OutlineModified
+	default:
+		return OutlineUnchanged
+	}
+}
@@ -0,0 +1,51 @@
+package diff
+
+import "testing"
+
+func TestBuildOutline(t *testing.T) {
+	left := []string{
+		"class A:",
+		"    def keep(self):",
+		"        return 1",
+		"    def change(self):",
+		"        return 2",
+		"def gone():",
+		"    return None",
+	}
+	right := []string{
+		"class A:",
+		"    def keep(self):",
+		"        return 1",
+		"    def change(self):",
+		"        return 3",
+		"def fresh():",
+		"    x = compute()",
+		"    return x",
+	}
+	result := NewLCSDefault().ComputeDiff(left, right)
+
+	outline := BuildOutline(FindSymbols(left, LanguagePython), result, "left")
+	if len(outline) != 2 || outline[0].Name != "A" || outline[1].Name != "gone" {
+		t.Fatalf("Unexpected top level: %+v", outline)
+	}
+	if outline[0].Status != OutlineModified {
+		t.Errorf("Expected class A to be modified, got %q", outline[0].Status)
+	}
+	children := outline[0].Children
+	if len(children) != 2 || children[0].Status != OutlineUnchanged || children[1].Status != OutlineModified {
+		t.Errorf("Unexpected methods: %+v", children)
+	}
+	if outline[1].Status != OutlineRemoved {
+		t.Errorf("Expected gone to be removed, got %q", outline[1].Status)
+	}
+
+	outline = BuildOutline(FindSymbols(right, LanguagePython), result, "right")
+	if last := outline[len(outline)-1]; last.Name != "fresh" || last.Status != OutlineAdded {
+		t.Errorf("Expected fresh to be added, got %+v", last)
+	}
+
+	outline = BuildOutline(FindSymbols(left, LanguagePython), nil, "")
+	if outline[0].Status != "" || len(outline[0].Children) != 2 {
+		t.Errorf("Expected nested symbols without status, got %+v", outline[0])
+	}
+}
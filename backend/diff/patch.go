@@ -0,0 +1,125 @@
+package diff
+
+// Patch describes how PatchResult changed a result: Lines replaced the Count
+// lines starting at index Start, and the line numbers of every line after
+// them moved by LeftShift and RightShift
+type Patch struct {
+	Start      int        `json:"start"`
+	Count      int        `json:"count"`
+	Lines      []DiffLine `json:"lines"`
+	LeftShift  int        `json:"leftShift"`
+	RightShift int        `json:"rightShift"`
+}
+
+// PatchResult returns a copy of result, a line-by-line comparison, updated to
+// compare leftLines and rightLines instead. Only the region between the
+// nearest paired unchanged lines around the edits is compared again, so
+// editing a few lines of a large file does not recompute the whole diff. It
+// reports false when result does not hold every line of both sides exactly
+// once, in order, so the caller must compare the files in full.
+func PatchResult(result *DiffResult, leftLines, rightLines []string, algorithm Algorithm) (*DiffResult, Patch, bool) {
+	oldLeft, ok := sideLines(result, true)
+	if !ok {
+		return nil, Patch{}, false
+	}
+	oldRight, ok := sideLines(result, false)
+	if !ok {
+		return nil, Patch{}, false
+	}
+	leftPrefix, leftSuffix := commonEnds(oldLeft, leftLines)
+	rightPrefix, rightSuffix := commonEnds(oldRight, rightLines)
+
+	// The region starts after the last paired line preceded only by lines
+	// from the unchanged beginnings of both files
+	start, leftStart, rightStart := 0, 0, 0
+	for i, line := range result.Lines {
+		if line.LeftNumber > leftPrefix || line.RightNumber > rightPrefix {
+			break
+		}
+		if line.LeftNumber > 0 && line.RightNumber > 0 {
+			start, leftStart, rightStart = i+1, line.LeftNumber, line.RightNumber
+		}
+	}
+	// and ends at the first paired line followed only by lines from the
+	// unchanged ends
+	leftKept, rightKept := len(oldLeft)-leftSuffix, len(oldRight)-rightSuffix
+	end, leftEnd, rightEnd := len(result.Lines), len(oldLeft), len(oldRight)
+	for i := len(result.Lines) - 1; i >= start; i-- {
+		line := result.Lines[i]
+		if (line.LeftNumber > 0 && line.LeftNumber <= leftKept) || (line.RightNumber > 0 && line.RightNumber <= rightKept) {
+			break
+		}
+		if line.LeftNumber > 0 && line.RightNumber > 0 {
+			end, leftEnd, rightEnd = i, line.LeftNumber-1, line.RightNumber-1
+		}
+	}
+
+	leftShift, rightShift := len(leftLines)-len(oldLeft), len(rightLines)-len(oldRight)
+	if leftEnd+leftShift < leftStart || rightEnd+rightShift < rightStart {
+		return nil, Patch{}, false
+	}
+	part := algorithm.ComputeDiff(leftLines[leftStart:leftEnd+leftShift], rightLines[rightStart:rightEnd+rightShift])
+	OffsetLineNumbers(part, leftStart, rightStart)
+
+	patched := *result
+	patched.Lines = make([]DiffLine, 0, start+len(part.Lines)+len(result.Lines)-end)
+	patched.Lines = append(patched.Lines, result.Lines[:start]...)
+	patched.Lines = append(patched.Lines, part.Lines...)
+	for _, line := range result.Lines[end:] {
+		if line.LeftNumber > 0 {
+			line.LeftNumber += leftShift
+		}
+		if line.RightNumber > 0 {
+			line.RightNumber += rightShift
+		}
+		patched.Lines = append(patched.Lines, line)
+	}
+	patched.Chunks = GroupHunks(&patched)
+	patched.Folds = nil
+	patched.Approximate = result.Approximate || part.Approximate
+
+	return &patched, Patch{
+		Start:      start,
+		Count:      end - start,
+		Lines:      part.Lines,
+		LeftShift:  leftShift,
+		RightShift: rightShift,
+	}, true
+}
+
+// sideLines recovers one side's lines from a result, reporting false unless
+// its line numbers run from 1 without gaps or repeats
+func sideLines(result *DiffResult, left bool) ([]string, bool) {
+	lines := []string{}
+	for _, line := range result.Lines {
+		number, text := line.RightNumber, line.RightLine
+		if left {
+			number, text = line.LeftNumber, line.LeftLine
+		}
+		if number == 0 {
+			continue
+		}
+		if number != len(lines)+1 {
+			return nil, false
+		}
+		lines = append(lines, text)
+	}
+	return lines, true
+}
+
+// commonEnds returns the number of leading and trailing lines a and b have
+// in common, never counting a line as both unless a and b are equal, so an
+// unedited side does not limit the region compared again
+func commonEnds(a, b []string) (prefix, suffix int) {
+	limit := min(len(a), len(b))
+	for prefix < limit && a[prefix] == b[prefix] {
+		prefix++
+	}
+	if prefix == len(a) && prefix == len(b) {
+		return prefix, prefix
+	}
+	for suffix < limit-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	return prefix, suffix
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestPatchResult(t *testing.T) {
+	lcs := NewLCSDefault()
+	left := []string{"alpha", "beta", "gamma", "delta", "epsilon", "zeta", "eta", "theta"}
+	right := []string{"alpha", "beta", "GAMMA", "delta", "epsilon", "zeta", "iota", "eta", "theta"}
+	original := lcs.ComputeDiff(left, right)
+
+	t.Run("edit inside a change", func(t *testing.T) {
+		// Copying the left line over fixes the change at line 3
+		edited := slices.Clone(right)
+		edited[2] = "gamma"
+		patched, patch, ok := PatchResult(original, left, edited, lcs)
+		if !ok {
+			t.Fatal("Expected the result to be patched")
+		}
+		assertPatchedLike(t, patched, lcs.ComputeDiff(left, edited))
+		if patch.LeftShift != 0 || patch.RightShift != 0 {
+			t.Errorf("Expected no shift, got %d/%d", patch.LeftShift, patch.RightShift)
+		}
+		if patch.Count >= len(original.Lines) {
+			t.Errorf("Expected only part of the result to be replaced, got %d of %d lines", patch.Count, len(original.Lines))
+		}
+	})
+
+	t.Run("removed line shifts later lines", func(t *testing.T) {
+		edited := slices.Delete(slices.Clone(right), 6, 7)
+		patched, patch, ok := PatchResult(original, left, edited, lcs)
+		if !ok {
+			t.Fatal("Expected the result to be patched")
+		}
+		assertPatchedLike(t, patched, lcs.ComputeDiff(left, edited))
+		if patch.RightShift != -1 {
+			t.Errorf("Expected right lines to shift by -1, got %d", patch.RightShift)
+		}
+	})
+
+	t.Run("edit at both ends", func(t *testing.T) {
+		edited := append([]string{"new first"}, right...)
+		edited = append(edited, "new last")
+		patched, _, ok := PatchResult(original, left, edited, lcs)
+		if !ok {
+			t.Fatal("Expected the result to be patched")
+		}
+		assertPatchedLike(t, patched, lcs.ComputeDiff(left, edited))
+	})
+
+	t.Run("unchanged content", func(t *testing.T) {
+		patched, _, ok := PatchResult(original, left, right, lcs)
+		if !ok {
+			t.Fatal("Expected the result to be patched")
+		}
+		assertPatchedLike(t, patched, original)
+	})
+
+	t.Run("original is not modified", func(t *testing.T) {
+		before := slices.Clone(original.Lines)
+		PatchResult(original, left, []string{"other"}, lcs)
+		if !reflect.DeepEqual(original.Lines, before) {
+			t.Error("Expected the original result to be left unchanged")
+		}
+	})
+
+	t.Run("result missing lines", func(t *testing.T) {
+		gapped := &DiffResult{Lines: []DiffLine{
+			{LeftLine: "a", RightLine: "a", LeftNumber: 1, RightNumber: 1, Type: "same"},
+			{LeftLine: "c", RightLine: "c", LeftNumber: 3, RightNumber: 2, Type: "same"},
+		}}
+		if _, _, ok := PatchResult(gapped, []string{"a", "b", "c"}, []string{"a", "c"}, lcs); ok {
+			t.Error("Expected a result with missing lines to be rejected")
+		}
+	})
+}
+
+// assertPatchedLike checks that a patched result holds the same lines and
+// changes as a full comparison
+func assertPatchedLike(t *testing.T, patched, expected *DiffResult) {
+	t.Helper()
+	if !reflect.DeepEqual(patched.Lines, expected.Lines) {
+		t.Errorf("Expected lines %+v, got %+v", expected.Lines, patched.Lines)
+	}
+	if len(patched.Chunks) != len(expected.Chunks) {
+		t.Errorf("Expected %d chunks, got %d", len(expected.Chunks), len(patched.Chunks))
+	}
+}
@@ -0,0 +1,165 @@
+package diff
+
+import "sort"
+
+// Patience implements patience diff. It anchors the comparison on lines that
+// occur exactly once in both files, which in source code are usually
+// meaningful lines such as declarations rather than braces or blank lines, so
+// moved or reordered blocks produce far more readable output. Regions without
+// unique lines are compared with Myers.
+type Patience struct {
+	config Config
+}
+
+// NewPatience creates a new patience diff algorithm with the given configuration
+func NewPatience(config Config) *Patience {
+	return &Patience{config: config}
+}
+
+// NewPatienceDefault creates a new patience diff algorithm with default configuration
+func NewPatienceDefault() *Patience {
+	config := DefaultConfig()
+	config.Algorithm = AlgorithmPatience
+	return NewPatience(config)
+}
+
+// Config returns the algorithm's configuration
+func (p *Patience) Config() Config {
+	return p.config
+}
+
+// WithConfig returns the algorithm selected by the given configuration
+func (p *Patience) WithConfig(config Config) Algorithm {
+	return New(config)
+}
+
+// ComputeDiff compares two sets of lines and returns the diff result
+func (p *Patience) ComputeDiff(leftLines, rightLines []string) *DiffResult {
+	if p.config.IgnoreBlankLines {
+		return diffIgnoringBlankLines(leftLines, rightLines, p.config)
+	}
+
+	left, right := internLines(comparisonKeys(leftLines, p.config), comparisonKeys(rightLines, p.config))
+
+	matches := make([]int, len(left))
+	for i := range matches {
+		matches[i] = -1
+	}
+	s := &myersState{left: left, right: right, matches: matches, done: p.config.Done}
+	patienceCompare(s, 0, len(left), 0, len(right))
+
+	result := &DiffResult{Lines: buildDiffLines(leftLines, rightLines, matches)}
+	return detectModifications(result, p.config)
+}
+
+// patienceCompare matches left[aLo:aHi] against right[bLo:bHi], recursing
+// between unique anchor lines
+func patienceCompare(s *myersState, aLo, aHi, bLo, bHi int) {
+	if stopped(s.done) {
+		return
+	}
+	for aLo < aHi && bLo < bHi && s.left[aLo] == s.right[bLo] {
+		s.matches[aLo] = bLo
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && s.left[aHi-1] == s.right[bHi-1] {
+		aHi--
+		bHi--
+		s.matches[aHi] = bHi
+	}
+	if aLo == aHi || bLo == bHi {
+		return
+	}
+
+	anchors := uniqueAnchors(s.left[aLo:aHi], s.right[bLo:bHi])
+	if len(anchors) == 0 {
+		s.compare(aLo, aHi, bLo, bHi)
+		return
+	}
+
+	prevA, prevB := aLo, bLo
+	for _, anchor := range anchors {
+		a, b := aLo+anchor.left, bLo+anchor.right
+		patienceCompare(s, prevA, a, prevB, b)
+		s.matches[a] = b
+		prevA, prevB = a+1, b+1
+	}
+	patienceCompare(s, prevA, aHi, prevB, bHi)
+}
+
+// linePair is a line at the given index on each side
+type linePair struct {
+	left  int
+	right int
+}
+
+// uniqueAnchors returns the longest run of lines, in order on both sides,
+// that occur exactly once on each side
+func uniqueAnchors(left, right []int) []linePair {
+	type occurrence struct {
+		leftCount, rightCount int
+		leftIndex, rightIndex int
+	}
+	occurrences := make(map[int]*occurrence)
+	for i, line := range left {
+		o, ok := occurrences[line]
+		if !ok {
+			o = &occurrence{}
+			occurrences[line] = o
+		}
+		o.leftCount++
+		o.leftIndex = i
+	}
+	for j, line := range right {
+		if o, ok := occurrences[line]; ok {
+			o.rightCount++
+			o.rightIndex = j
+		}
+	}
+
+	var candidates []linePair
+	for _, o := range occurrences {
+		if o.leftCount == 1 && o.rightCount == 1 {
+			candidates = append(candidates, linePair{o.leftIndex, o.rightIndex})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].left < candidates[j].left
+	})
+	return longestIncreasing(candidates)
+}
+
+// longestIncreasing returns the longest subsequence of pairs, already in left
+// order, whose right indexes also increase, using patience sorting
+func longestIncreasing(pairs []linePair) []linePair {
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	// tops[k] is the index of the pair on top of pile k; each pair remembers
+	// the top of the previous pile when it was placed
+	tops := []int{}
+	previous := make([]int, len(pairs))
+	for i, pair := range pairs {
+		pile := sort.Search(len(tops), func(k int) bool {
+			return pairs[tops[k]].right > pair.right
+		})
+		if pile > 0 {
+			previous[i] = tops[pile-1]
+		} else {
+			previous[i] = -1
+		}
+		if pile == len(tops) {
+			tops = append(tops, i)
+		} else {
+			tops[pile] = i
+		}
+	}
+
+	result := make([]linePair, len(tops))
+	for i, k := len(tops)-1, tops[len(tops)-1]; i >= 0; i, k = i-1, previous[k] {
+		result[i] = pairs[k]
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func TestPatience_ComputeDiff(t *testing.T) {
+	patience := NewPatienceDefault()
+
+	t.Run("anchors on unique lines", func(t *testing.T) {
+		// LCS keeps the three braces; patience keeps the one meaningful line
+		left := []string{"name", "}", "}", "}"}
+		right := []string{"}", "}", "}", "name"}
+
+		types := []string{}
+		for _, line := range patience.ComputeDiff(left, right).Lines {
+			types = append(types, line.Type)
+		}
+		expected := []string{"added", "added", "added", "same", "removed", "removed", "removed"}
+		if !reflect.DeepEqual(types, expected) {
+			t.Errorf("Expected %v, got %v", expected, types)
+		}
+	})
+
+	t.Run("moved function", func(t *testing.T) {
+		left := []string{
+			"func a() {", "\treturn 1", "}", "",
+			"func b() {", "\treturn 2", "}", "",
+			"func c() {", "\treturn 3", "}",
+		}
+		right := []string{
+			"func a() {", "\treturn 1", "}", "",
+			"func c() {", "\treturn 3", "}", "",
+			"func b() {", "\treturn 2", "}",
+		}
+
+		result := patience.ComputeDiff(left, right)
+		gotLeft, gotRight := reconstruct(result)
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatal("Diff does not reproduce the inputs")
+		}
+		for _, line := range result.Lines {
+			if line.Type == "same" && line.LeftLine == "func a() {" && line.LeftNumber != 1 {
+				t.Errorf("Expected the unchanged function to stay in place, got %+v", line)
+			}
+		}
+	})
+
+	t.Run("no unique lines falls back to myers", func(t *testing.T) {
+		left := []string{"x", "y", "x", "y"}
+		right := []string{"y", "x", "y", "x"}
+		if got, want := countSame(patience.ComputeDiff(left, right)), countSame(NewMyersDefault().ComputeDiff(left, right)); got != want {
+			t.Errorf("Expected %d unchanged lines, got %d", want, got)
+		}
+	})
+}
+
+func TestPatience_RandomInputs(t *testing.T) {
+	patience := NewPatienceDefault()
+	rng := rand.New(rand.NewSource(2))
+	alphabet := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	for i := 0; i < 500; i++ {
+		left := make([]string, rng.Intn(25))
+		for j := range left {
+			left[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+		right := make([]string, rng.Intn(25))
+		for j := range right {
+			right[j] = alphabet[rng.Intn(len(alphabet))]
+		}
+
+		gotLeft, gotRight := reconstruct(patience.ComputeDiff(left, right))
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatalf("Diff of %v and %v does not reproduce the inputs", left, right)
+		}
+	}
+}
+
+func Test_longestIncreasing(t *testing.T) {
+	pairs := []linePair{{0, 3}, {1, 1}, {2, 4}, {3, 2}, {4, 5}, {5, 0}}
+	expected := []linePair{{1, 1}, {3, 2}, {4, 5}}
+	if got := longestIncreasing(pairs); !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, got)
+	}
+	if got := longestIncreasing(nil); got != nil {
+		t.Errorf("Expected nil, got %v", got)
+	}
+}
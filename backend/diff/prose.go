@@ -0,0 +1,192 @@
+package diff
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// mdListMarkerPattern matches a word that starts a list item
+var mdListMarkerPattern = regexp.MustCompile(`^([-*+]|\d+[.)])$`)
+
+// ProseDiff compares two prose documents, such as Markdown, paragraph by
+// paragraph with hard line wraps undone. Paragraphs and other blocks are
+// aligned by their text, so a re-flowed paragraph shows no difference, and
+// changed paragraphs are compared sentence by sentence, marking the changed
+// sentences on whichever lines they are wrapped onto. The lines of a changed
+// paragraph pair up in order, as the two need not wrap alike. Code blocks are
+// compared line by line. It reports false when either document has no text,
+// so the caller can fall back to a line diff.
+func ProseDiff(leftLines, rightLines []string, algorithm Algorithm) (*DiffResult, bool) {
+	left, right := proseDocument(leftLines), proseDocument(rightLines)
+	if left == nil || right == nil {
+		return nil, false
+	}
+
+	d := &structDiffer{
+		leftLines:  leftLines,
+		rightLines: rightLines,
+		algorithm:  algorithm,
+		compareLines: func(left, right []string) *DiffResult {
+			return compareParagraphs(left, right, algorithm)
+		},
+	}
+	return d.compare(left, right), true
+}
+
+// proseDocument returns a document as a sequence of its Markdown blocks,
+// keyed by their text with line breaks collapsed, or nil when it has none
+func proseDocument(lines []string) *structNode {
+	blocks := ParseMarkdown(lines)
+	if len(blocks) == 0 {
+		return nil
+	}
+	keys := blockKeys(blocks)
+	doc := &structNode{kind: '[', start: 0, end: len(lines) - 1, canonical: strings.Join(keys, "\x00"), split: true}
+	for i, block := range blocks {
+		item := structItem{
+			key:   keys[i],
+			start: block.StartLine - 1,
+			next:  len(lines),
+			value: &structNode{start: block.StartLine - 1, end: block.EndLine - 1, canonical: keys[i]},
+		}
+		if i+1 < len(blocks) {
+			item.next = blocks[i+1].StartLine - 1
+		}
+		doc.items = append(doc.items, item)
+	}
+	return doc
+}
+
+// compareParagraphs compares two changed blocks sentence by sentence and
+// pairs their lines in order, marking the changed sentences on each line.
+// Blocks only on one side and code blocks are compared line by line.
+func compareParagraphs(left, right []string, algorithm Algorithm) *DiffResult {
+	if len(left) == 0 || len(right) == 0 || mdFencePattern.MatchString(left[0]) || mdFencePattern.MatchString(right[0]) {
+		return algorithm.ComputeDiff(left, right)
+	}
+
+	leftSentences, rightSentences := splitSentences(left), splitSentences(right)
+	sentences := algorithm.ComputeDiff(sentenceTexts(leftSentences), sentenceTexts(rightSentences))
+	leftChanges := make([][]CharRange, len(left))
+	rightChanges := make([][]CharRange, len(right))
+	for _, line := range sentences.Lines {
+		if line.Type == "same" {
+			continue
+		}
+		if line.LeftNumber > 0 {
+			leftSentences[line.LeftNumber-1].mark(leftChanges)
+		}
+		if line.RightNumber > 0 {
+			rightSentences[line.RightNumber-1].mark(rightChanges)
+		}
+	}
+
+	result := &DiffResult{}
+	for i := 0; i < max(len(left), len(right)); i++ {
+		line := DiffLine{Type: "same"}
+		if i < len(left) {
+			line.LeftLine, line.LeftNumber, line.LeftChanges = left[i], i+1, leftChanges[i]
+		}
+		if i < len(right) {
+			line.RightLine, line.RightNumber, line.RightChanges = right[i], i+1, rightChanges[i]
+		}
+		switch {
+		case line.LeftChanges == nil && line.RightChanges == nil:
+		case line.RightNumber == 0:
+			line.Type, line.LeftChanges = "removed", nil
+		case line.LeftNumber == 0:
+			line.Type, line.RightChanges = "added", nil
+		default:
+			line.Type = "modified"
+		}
+		result.Lines = append(result.Lines, line)
+	}
+	return result
+}
+
+// proseSentence is a sentence of a paragraph with where it lies on each of
+// the lines it is wrapped onto
+type proseSentence struct {
+	words []string
+	lines []int // 0-based line of each span
+	spans []CharRange
+}
+
+// mark adds the sentence's spans to the changes of each line
+func (s *proseSentence) mark(changes [][]CharRange) {
+	for i, line := range s.lines {
+		changes[line] = append(changes[line], s.spans[i])
+	}
+}
+
+// splitSentences splits the text of lines into sentences, ignoring how the
+// text is wrapped and any blockquote markers. A sentence ends after a word
+// ending in ".", "!", or "?", and before a list marker starting a line.
+func splitSentences(lines []string) []*proseSentence {
+	var sentences []*proseSentence
+	var current *proseSentence
+	for number, line := range lines {
+		runes := []rune(line)
+		i := proseContentStart(runes)
+		first := true
+		for i < len(runes) {
+			if unicode.IsSpace(runes[i]) {
+				i++
+				continue
+			}
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+			if first && mdListMarkerPattern.MatchString(word) {
+				current = nil
+			}
+			first = false
+
+			if current == nil {
+				current = &proseSentence{}
+				sentences = append(sentences, current)
+			}
+			current.words = append(current.words, word)
+			if last := len(current.lines) - 1; last >= 0 && current.lines[last] == number {
+				current.spans[last].End = i
+			} else {
+				current.lines = append(current.lines, number)
+				current.spans = append(current.spans, CharRange{Start: start, End: i})
+			}
+			if endsSentence(word) {
+				current = nil
+			}
+		}
+	}
+	return sentences
+}
+
+// proseContentStart returns where the text of a line starts, after its
+// indentation and any blockquote markers
+func proseContentStart(runes []rune) int {
+	i := 0
+	for i < len(runes) && (unicode.IsSpace(runes[i]) || runes[i] == '>') {
+		i++
+	}
+	return i
+}
+
+// endsSentence reports whether a word ends a sentence, allowing for closing
+// quotes, brackets, and emphasis after the punctuation
+func endsSentence(word string) bool {
+	word = strings.TrimRight(word, `"')]*_`)
+	return strings.HasSuffix(word, ".") || strings.HasSuffix(word, "!") || strings.HasSuffix(word, "?")
+}
+
+// sentenceTexts returns the text of each sentence with its words separated
+// by single spaces
+func sentenceTexts(sentences []*proseSentence) []string {
+	texts := make([]string, len(sentences))
+	for i, sentence := range sentences {
+		texts[i] = strings.Join(sentence.words, " ")
+	}
+	return texts
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProseDiff(t *testing.T) {
+	left := strings.Split(`# Weld
+
+Weld compares files side by side. It highlights every change
+and lets you copy lines between the panes. Edits are saved
+when you ask.
+
+`+"```sh"+`
+make build
+`+"```", "\n")
+
+	check := func(t *testing.T, right []string) *DiffResult {
+		t.Helper()
+		result, ok := ProseDiff(left, right, NewLCSDefault())
+		if !ok {
+			t.Fatal("Expected both documents to have text")
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Fatalf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+		return result
+	}
+
+	t.Run("re-flowed paragraph", func(t *testing.T) {
+		right := strings.Split(`# Weld
+
+Weld compares files side by side.
+It highlights every change and lets you copy lines between the panes.
+Edits are saved when you ask.
+
+`+"```sh"+`
+make build
+`+"```", "\n")
+		if changed := changedLines(check(t, right)); len(changed) != 0 {
+			t.Errorf("Expected no changes, got %+v", changed)
+		}
+	})
+
+	t.Run("changed sentence in a re-flowed paragraph", func(t *testing.T) {
+		right := strings.Split(`# Weld
+
+Weld compares files side by side.
+It highlights every change and lets you copy lines between the panes.
+Edits are saved automatically.
+
+`+"```sh"+`
+make build
+`+"```", "\n")
+		changed := changedLines(check(t, right))
+		if len(changed) != 2 {
+			t.Fatalf("Expected the lines holding the changed sentence to change, got %+v", changed)
+		}
+		// The sentence starts on the left's fourth line and ends on its fifth
+		if changed[0].LeftNumber != 4 || !reflect.DeepEqual(changed[0].LeftChanges, []CharRange{{Start: 43, End: 58}}) {
+			t.Errorf("Expected the start of the sentence marked, got %+v", changed[0])
+		}
+		if changed[1].LeftNumber != 5 || !reflect.DeepEqual(changed[1].LeftChanges, []CharRange{{Start: 0, End: 13}}) {
+			t.Errorf("Expected the end of the sentence marked, got %+v", changed[1])
+		}
+		if changed[1].RightNumber != 5 || !reflect.DeepEqual(changed[1].RightChanges, []CharRange{{Start: 0, End: 30}}) {
+			t.Errorf("Expected the new sentence marked, got %+v", changed[1])
+		}
+		if len(changed[0].RightChanges) != 0 {
+			t.Errorf("Expected nothing marked on the right's fourth line, got %+v", changed[0].RightChanges)
+		}
+	})
+
+	t.Run("code blocks compare line by line", func(t *testing.T) {
+		right := append(append([]string{}, left[:len(left)-2]...), "make test", "```")
+		changed := changedLines(check(t, right))
+		if len(changed) != 2 || changed[0].LeftLine != "make build" || changed[1].RightLine != "make test" {
+			t.Errorf("Expected the command line to change, got %+v", changed)
+		}
+	})
+
+	t.Run("added paragraph", func(t *testing.T) {
+		right := append(append([]string{}, left...), "", "A new closing paragraph.")
+		changed := changedLines(check(t, right))
+		if len(changed) != 1 || changed[0].Type != "added" || changed[0].RightLine != "A new closing paragraph." {
+			t.Errorf("Expected the paragraph to be added, got %+v", changed)
+		}
+	})
+
+	t.Run("documents without text", func(t *testing.T) {
+		if _, ok := ProseDiff(left, []string{"", "  "}, NewLCSDefault()); ok {
+			t.Error("Expected a blank document to fall back")
+		}
+	})
+}
+
+func TestSplitSentences(t *testing.T) {
+	sentences := splitSentences([]string{
+		"> First one. Second",
+		"> one wraps! Third",
+		"- item one",
+		"- item two",
+	})
+	got := sentenceTexts(sentences)
+	want := []string{"First one.", "Second one wraps!", "Third", "- item one", "- item two"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("splitSentences() = %q, want %q", got, want)
+	}
+	if second := sentences[1]; !reflect.DeepEqual(second.lines, []int{0, 1}) ||
+		!reflect.DeepEqual(second.spans, []CharRange{{Start: 13, End: 19}, {Start: 2, End: 12}}) {
+		t.Errorf("Unexpected spans of the wrapped sentence: %+v", second)
+	}
+}
@@ -0,0 +1,72 @@
+package diff
+
+import "sort"
+
+// IsReordered reports whether two files contain exactly the same lines,
+// counting duplicates, but in a different order
+func IsReordered(leftLines, rightLines []string) bool {
+	if len(leftLines) != len(rightLines) {
+		return false
+	}
+
+	counts := make(map[string]int, len(leftLines))
+	inOrder := true
+	for i, line := range leftLines {
+		counts[line]++
+		counts[rightLines[i]]--
+		if line != rightLines[i] {
+			inOrder = false
+		}
+	}
+	if inOrder {
+		return false
+	}
+	for _, count := range counts {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// SortedDiff sorts both files' lines before diffing them, so content that only
+// moved compares as unchanged. Line numbers in the result still refer to each
+// line's position in its original file.
+func SortedDiff(leftLines, rightLines []string, algorithm Algorithm) *DiffResult {
+	leftOrder := sortedOrder(leftLines)
+	rightOrder := sortedOrder(rightLines)
+
+	result := algorithm.ComputeDiff(permute(leftLines, leftOrder), permute(rightLines, rightOrder))
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		if line.LeftNumber > 0 {
+			line.LeftNumber = leftOrder[line.LeftNumber-1] + 1
+		}
+		if line.RightNumber > 0 {
+			line.RightNumber = rightOrder[line.RightNumber-1] + 1
+		}
+	}
+	result.Chunks = GroupHunks(result)
+	return result
+}
+
+// sortedOrder returns the original indexes of lines in sorted order
+func sortedOrder(lines []string) []int {
+	order := make([]int, len(lines))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return lines[order[i]] < lines[order[j]]
+	})
+	return order
+}
+
+// permute returns lines rearranged into the given order
+func permute(lines []string, order []int) []string {
+	result := make([]string, len(order))
+	for i, index := range order {
+		result[i] = lines[index]
+	}
+	return result
+}
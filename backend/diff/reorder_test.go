@@ -0,0 +1,57 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsReordered(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right []string
+		expected    bool
+	}{
+		{"identical", []string{"a", "b"}, []string{"a", "b"}, false},
+		{"reordered", []string{"a", "b", "c"}, []string{"c", "a", "b"}, true},
+		{"reordered duplicates", []string{"a", "a", "b"}, []string{"a", "b", "a"}, true},
+		{"different duplicates", []string{"a", "a", "b"}, []string{"a", "b", "b"}, false},
+		{"different length", []string{"a", "b"}, []string{"b", "a", "a"}, false},
+		{"empty", []string{}, []string{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsReordered(tt.left, tt.right); got != tt.expected {
+				t.Errorf("IsReordered(%v, %v) = %v, expected %v", tt.left, tt.right, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSortedDiff(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	t.Run("reordered lines are unchanged", func(t *testing.T) {
+		result := SortedDiff([]string{"b", "c", "a"}, []string{"a", "b", "c"}, lcs)
+		expected := []DiffLine{
+			{LeftLine: "a", RightLine: "a", LeftNumber: 3, RightNumber: 1, Type: "same"},
+			{LeftLine: "b", RightLine: "b", LeftNumber: 1, RightNumber: 2, Type: "same"},
+			{LeftLine: "c", RightLine: "c", LeftNumber: 2, RightNumber: 3, Type: "same"},
+		}
+		if !reflect.DeepEqual(result.Lines, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, result.Lines)
+		}
+	})
+
+	t.Run("real changes keep original line numbers", func(t *testing.T) {
+		result := SortedDiff([]string{"z", "a"}, []string{"a", "y", "z"}, lcs)
+		var added []DiffLine
+		for _, line := range result.Lines {
+			if line.Type == "added" {
+				added = append(added, line)
+			}
+		}
+		if len(added) != 1 || added[0].RightLine != "y" || added[0].RightNumber != 2 {
+			t.Errorf("Expected y at right line 2 to be added, got %+v", result.Lines)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+)
+
+// SegmentWindow is the most lines of each file a segmented comparison diffs
+// at once
+const SegmentWindow = 5000
+
+// SegmentedDiff compares files too large to diff whole. Runs of matching
+// lines are paired directly; at each difference the comparison looks for the
+// nearest line the files have in common again, among the next window of
+// lines on either side, and diffs only the lines before it. When none of
+// those lines recurs, the windows are shown removed and added, with similar
+// lines paired as modified. Myers is used
+// whatever algorithm is configured. The result is flagged Segmented, since
+// differences longer than a window may be paired less precisely than a
+// whole-file diff would.
+func SegmentedDiff(leftLines, rightLines []string, algorithm Algorithm) *DiffResult {
+	return segmentedDiff(leftLines, rightLines, algorithm, SegmentWindow)
+}
+
+// segmentedDiff is SegmentedDiff with a given window size
+func segmentedDiff(leftLines, rightLines []string, algorithm Algorithm, window int) *DiffResult {
+	config := configOf(algorithm)
+	config.Algorithm = AlgorithmMyers
+	myers := New(config)
+
+	leftKeys, rightKeys := comparisonKeys(leftLines, config), comparisonKeys(rightLines, config)
+	leftPositions, rightPositions := linePositions(leftKeys), linePositions(rightKeys)
+
+	result := &DiffResult{Lines: make([]DiffLine, 0, max(len(leftLines), len(rightLines))), Segmented: true}
+	appendDiff := func(leftStart, leftEnd, rightStart, rightEnd int) {
+		part := myers.ComputeDiff(leftLines[leftStart:leftEnd], rightLines[rightStart:rightEnd])
+		OffsetLineNumbers(part, leftStart, rightStart)
+		result.Lines = append(result.Lines, part.Lines...)
+	}
+
+	l, r := 0, 0
+	for l < len(leftLines) || r < len(rightLines) {
+		for l < len(leftLines) && r < len(rightLines) && leftKeys[l] == rightKeys[r] {
+			result.Lines = append(result.Lines, DiffLine{
+				LeftLine:    leftLines[l],
+				RightLine:   rightLines[r],
+				LeftNumber:  l + 1,
+				RightNumber: r + 1,
+				Type:        "same",
+			})
+			l, r = l+1, r+1
+		}
+		if l == len(leftLines) && r == len(rightLines) {
+			break
+		}
+
+		if i, j, ok := resynchronize(leftKeys, rightKeys, leftPositions, rightPositions, l, r, window); ok {
+			// At most one side runs past a window, and what it has beyond
+			// the window is only added or removed
+			leftMid, rightMid := l+min(i, window), r+min(j, window)
+			appendDiff(l, leftMid, r, rightMid)
+			appendDiff(leftMid, l+i, rightMid, r+j)
+			l, r = l+i, r+j
+			continue
+		}
+		// The windows have no non-blank line in common, so diffing them
+		// would do little more than remove one and add the other
+		leftEnd, rightEnd := min(l+window, len(leftLines)), min(r+window, len(rightLines))
+		part := &DiffResult{Lines: make([]DiffLine, 0, leftEnd-l+rightEnd-r)}
+		for i := l; i < leftEnd; i++ {
+			part.Lines = append(part.Lines, DiffLine{LeftLine: leftLines[i], LeftNumber: i + 1, Type: "removed"})
+		}
+		for j := r; j < rightEnd; j++ {
+			part.Lines = append(part.Lines, DiffLine{RightLine: rightLines[j], RightNumber: j + 1, Type: "added"})
+		}
+		result.Lines = append(result.Lines, detectModifications(part, config).Lines...)
+		l, r = leftEnd, rightEnd
+	}
+
+	result.Chunks = GroupHunks(result)
+	return result
+}
+
+// linePositions maps each line to the ascending indexes it occurs at
+func linePositions(keys []string) map[string][]int {
+	positions := make(map[string][]int)
+	for i, key := range keys {
+		positions[key] = append(positions[key], i)
+	}
+	return positions
+}
+
+// resynchronize finds the nearest pair of matching non-blank lines at or
+// after left line l and right line r, where at least one of the two is
+// within window lines, returning their distances from l and r
+func resynchronize(leftKeys, rightKeys []string, leftPositions, rightPositions map[string][]int, l, r, window int) (int, int, bool) {
+	bestI, bestJ, found := 0, 0, false
+	consider := func(i, j int) {
+		if !found || i+j < bestI+bestJ {
+			bestI, bestJ, found = i, j, true
+		}
+	}
+	for k := 0; k < window; k++ {
+		if found && k >= bestI+bestJ {
+			break
+		}
+		if j, ok := nextPosition(leftKeys, l+k, rightPositions, r); ok {
+			consider(k, j-r)
+		}
+		if i, ok := nextPosition(rightKeys, r+k, leftPositions, l); ok {
+			consider(i-l, k)
+		}
+	}
+	return bestI, bestJ, found
+}
+
+// nextPosition returns the first index at or after from where the other file
+// has keys[at], unless keys[at] is out of range or blank
+func nextPosition(keys []string, at int, positions map[string][]int, from int) (int, bool) {
+	if at >= len(keys) || strings.TrimSpace(keys[at]) == "" {
+		return 0, false
+	}
+	candidates := positions[keys[at]]
+	next := sort.SearchInts(candidates, from)
+	if next == len(candidates) {
+		return 0, false
+	}
+	return candidates[next], true
+}
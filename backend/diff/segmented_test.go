@@ -0,0 +1,96 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// numberedLines returns lines "line 1" through "line n"
+func numberedLines(n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return lines
+}
+
+func TestSegmentedDiff(t *testing.T) {
+	lcs := NewLCSDefault()
+
+	t.Run("identical", func(t *testing.T) {
+		lines := numberedLines(100)
+		result := segmentedDiff(lines, lines, lcs, 10)
+		if !result.Segmented || countSame(result) != 100 || len(result.Chunks) != 0 {
+			t.Errorf("Expected 100 unchanged lines, got %+v", result)
+		}
+	})
+
+	t.Run("changes within a window match a whole diff", func(t *testing.T) {
+		left := numberedLines(100)
+		right := slices.Clone(left)
+		right = slices.Insert(right, 20, "inserted a", "inserted b")
+		right = slices.Delete(right, 60, 63)
+		right[80] = "replaced"
+
+		result := segmentedDiff(left, right, lcs, 10)
+		gotLeft, gotRight := reconstruct(result)
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatal("Expected the result to hold both files")
+		}
+		whole := NewMyersDefault().ComputeDiff(left, right)
+		if countSame(result) != countSame(whole) || len(result.Chunks) != len(whole.Chunks) {
+			t.Errorf("Expected %d unchanged lines in %d chunks, got %d in %d",
+				countSame(whole), len(whole.Chunks), countSame(result), len(result.Chunks))
+		}
+	})
+
+	t.Run("change longer than a window", func(t *testing.T) {
+		left := numberedLines(100)
+		inserted := make([]string, 30)
+		for i := range inserted {
+			inserted[i] = fmt.Sprintf("new %d", i)
+		}
+		right := slices.Insert(slices.Clone(left), 50, inserted...)
+
+		result := segmentedDiff(left, right, lcs, 10)
+		gotLeft, gotRight := reconstruct(result)
+		if !reflect.DeepEqual(gotLeft, left) || !reflect.DeepEqual(gotRight, right) {
+			t.Fatal("Expected the result to hold both files")
+		}
+		// The files pair up again once past the insertion
+		last := result.Lines[len(result.Lines)-1]
+		if last.Type != "same" || last.LeftNumber != 100 || last.RightNumber != 130 {
+			t.Errorf("Expected the files to resynchronize, ending with %+v", last)
+		}
+	})
+
+	t.Run("blank lines are not used to resynchronize", func(t *testing.T) {
+		left := []string{"a", "", "b", "c"}
+		right := []string{"x", "", "y", "b", "c"}
+		result := segmentedDiff(left, right, lcs, 10)
+		for _, line := range result.Lines {
+			if line.Type == "same" && line.LeftLine == "" && line.LeftNumber != 2 {
+				t.Errorf("Unexpected blank line pairing %+v", line)
+			}
+		}
+		if last := result.Lines[len(result.Lines)-1]; last.Type != "same" || last.LeftLine != "c" {
+			t.Errorf("Expected the files to end in common, got %+v", last)
+		}
+	})
+
+	t.Run("ignores whitespace as configured", func(t *testing.T) {
+		config := DefaultConfig()
+		config.Whitespace = WhitespaceIgnoreAll
+		left := numberedLines(50)
+		right := make([]string, len(left))
+		for i, line := range left {
+			right[i] = "  " + line
+		}
+		result := segmentedDiff(left, right, New(config), 10)
+		if countSame(result) != 50 {
+			t.Errorf("Expected every line to match, got %d", countSame(result))
+		}
+	})
+}
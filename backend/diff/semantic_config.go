@@ -0,0 +1,459 @@
+package diff
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IsConfigPath reports whether a file is INI or TOML by its extension
+func IsConfigPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".ini", ".cfg", ".toml":
+		return true
+	}
+	return false
+}
+
+// SemanticConfigDiff compares two INI or TOML files section by section,
+// matching sections by name and the keys within each section by name, so
+// sections and keys in a different order show no difference. Values are
+// compared by their TOML type, with quoting and spacing normalized, so 1
+// and "1" differ while "a" and 'a' are the same. The result counts the
+// keys added, removed, and changed in Keys. Comments are compared unless
+// ignoreComments is set.
+//
+// Keys before the first section form a section of their own, and TOML
+// arrays of tables pair up in order. It reports false when either file has
+// a line that is neither a section, a key, nor a comment, so the caller can
+// fall back to a line diff.
+func SemanticConfigDiff(leftLines, rightLines []string, algorithm Algorithm, ignoreComments bool) (*DiffResult, bool) {
+	left, ok := parseConfigLines(leftLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+	right, ok := parseConfigLines(rightLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+
+	d := &structDiffer{
+		leftLines:  leftLines,
+		rightLines: rightLines,
+		algorithm:  algorithm,
+		compareLines: func(left, right []string) *DiffResult {
+			return compareConfigKeys(left, right, algorithm)
+		},
+	}
+	if !ignoreComments {
+		d.framing = configComments
+	}
+	result := d.compare(left, right)
+	result.Keys = countConfigKeys(left, right)
+	return result, true
+}
+
+// compareConfigKeys compares changed lines, pairing a key on a line of its
+// own whose value changed as "modified", however little the values have in
+// common, and diffing any other lines line by line
+func compareConfigKeys(left, right []string, algorithm Algorithm) *DiffResult {
+	if len(left) != 1 || len(right) != 1 || configKey(left[0]) == "" || configKey(left[0]) != configKey(right[0]) {
+		return algorithm.ComputeDiff(left, right)
+	}
+	line := DiffLine{
+		LeftLine:    left[0],
+		RightLine:   right[0],
+		LeftNumber:  1,
+		RightNumber: 1,
+		Type:        "modified",
+		Segments:    WordSegments(left[0], right[0]),
+	}
+	if withinCharDiffLength(left[0], right[0], configOf(algorithm)) {
+		line.LeftChanges, line.RightChanges = CharRanges(left[0], right[0])
+	}
+	return &DiffResult{Lines: []DiffLine{line}}
+}
+
+// configKey returns the key a line sets, or "" for any other line
+func configKey(line string) string {
+	text, _ := splitConfigComment(line)
+	text = strings.TrimSpace(text)
+	if _, ok := configSectionName(text); ok {
+		return ""
+	}
+	key, _, _ := splitConfigKey(text)
+	return key
+}
+
+// splitConfigComment separates a line's content from its comment. A comment
+// starts at a # or ; that begins the line or follows whitespace, outside
+// quotes.
+func splitConfigComment(line string) (string, string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case (c == '#' || c == ';') && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t"), strings.TrimSpace(line[i+1:])
+		}
+	}
+	return strings.TrimRight(line, " \t"), ""
+}
+
+// configComments returns the comments of lines, in order
+func configComments(lines []string) string {
+	var comments []string
+	for _, line := range lines {
+		if _, comment := splitConfigComment(line); comment != "" {
+			comments = append(comments, comment)
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// parseConfigLines parses a config file into a mapping of its sections,
+// each a mapping of its keys. Each value's canonical form includes its
+// comments unless ignoreComments is set.
+func parseConfigLines(lines []string, ignoreComments bool) (*structNode, bool) {
+	root := &structNode{kind: '{', start: 0, end: len(lines) - 1}
+	var section *structNode
+	seenSections := make(map[string]int)
+	var seenKeys map[string]int
+
+	// closeSection sets the lines of the current section's last key and of
+	// the section, which runs until next
+	closeSection := func(next int) {
+		if section == nil {
+			return
+		}
+		if n := len(section.items); n > 0 {
+			section.items[n-1].next = section.items[n-1].value.end + 1
+			section.end = section.items[n-1].value.end
+		}
+		root.items[len(root.items)-1].next = next
+	}
+	openSection := func(key string, start, header int) {
+		seenSections[key]++
+		if seenSections[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, seenSections[key])
+		}
+		section = &structNode{kind: '{', start: header, end: header}
+		root.items = append(root.items, structItem{key: key, start: start, value: section})
+		seenKeys = make(map[string]int)
+	}
+
+	// commentsOf returns the comments within a node, to make them part of
+	// its canonical form, or nothing when comments are ignored
+	commentsOf := func(node *structNode) string {
+		if ignoreComments || len(lines) == 0 {
+			return ""
+		}
+		if comments := configComments(lines[node.start : node.end+1]); comments != "" {
+			return "\x00" + comments
+		}
+		return ""
+	}
+
+	for i := 0; i < len(lines); i++ {
+		text, _ := splitConfigComment(lines[i])
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" {
+			continue
+		}
+
+		if name, ok := configSectionName(trimmed); ok {
+			closeSection(i)
+			openSection(name, i, i)
+			continue
+		}
+
+		key, value, ok := splitConfigKey(trimmed)
+		if !ok {
+			return nil, false
+		}
+		if section == nil {
+			// Keys before the first section belong to one without a name,
+			// taking in the comments above them
+			openSection("", 0, i)
+		}
+		node := &structNode{start: i, end: i}
+		value, node.end = configValue(lines, i, value)
+		i = node.end
+		node.canonical = resolveConfigValue(value) + commentsOf(node)
+
+		// Repeated keys pair up in order
+		seenKeys[key]++
+		if seenKeys[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, seenKeys[key])
+		}
+		if n := len(section.items); n > 0 {
+			section.items[n-1].next = node.start
+		}
+		section.items = append(section.items, structItem{key: key, start: node.start, value: node})
+	}
+	closeSection(len(lines))
+
+	var parts []string
+	for _, item := range root.items {
+		item.value.split = len(item.value.items) > 0
+		item.value.canonical = configCanonical(item.value) + commentsOf(item.value)
+		parts = append(parts, strconv.Quote(item.key)+":"+item.value.canonical)
+	}
+	sort.Strings(parts)
+	root.canonical = "{" + strings.Join(parts, ",") + "}" + commentsOf(root)
+	root.split = len(root.items) > 0
+	return root, true
+}
+
+// configSectionName returns the name of the section a line starts, with
+// the spacing around the dots of a TOML table name removed. TOML arrays of
+// tables keep their double brackets, so they never match a table.
+func configSectionName(text string) (string, bool) {
+	if !strings.HasPrefix(text, "[") || !strings.HasSuffix(text, "]") {
+		return "", false
+	}
+	open, close := "[", "]"
+	if strings.HasPrefix(text, "[[") && strings.HasSuffix(text, "]]") {
+		open, close = "[[", "]]"
+	}
+	parts := strings.Split(text[len(open):len(text)-len(close)], ".")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+	}
+	return open + strings.Join(parts, ".") + close, true
+}
+
+// splitConfigKey splits a key line into its key and the value after the
+// first = or : outside quotes. A line of a single word is a key without a
+// value, as INI files allow.
+func splitConfigKey(text string) (string, string, bool) {
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '=' || c == ':':
+			key := strings.TrimSpace(text[:i])
+			if key == "" {
+				return "", "", false
+			}
+			return unquoteConfig(key), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	if strings.ContainsAny(text, " \t[]{}") {
+		return "", "", false
+	}
+	return text, "", true
+}
+
+// configValue returns the value starting with first on line i, read on
+// through the lines that continue it, and the line it ends on. TOML
+// multi-line strings, arrays, and inline tables run until they close; an
+// INI value runs on through the lines after it indented deeper than its key.
+func configValue(lines []string, i int, first string) (string, int) {
+	switch {
+	case strings.HasPrefix(first, `"""`) || strings.HasPrefix(first, "'''"):
+		delimiter := first[:3]
+		parts := []string{first}
+		end := i
+		for !strings.Contains(strings.Join(parts, "\n")[3:], delimiter) && end+1 < len(lines) {
+			end++
+			parts = append(parts, lines[end])
+		}
+		return strings.Join(parts, "\n"), end
+
+	case strings.HasPrefix(first, "[") || strings.HasPrefix(first, "{"):
+		parts := []string{first}
+		end := i
+		for configNesting(strings.Join(parts, "\n")) > 0 && end+1 < len(lines) {
+			end++
+			text, _ := splitConfigComment(lines[end])
+			parts = append(parts, text)
+		}
+		return compactConfigValue(strings.Join(parts, " ")), end
+	}
+
+	parts := []string{first}
+	end := i
+	for end+1 < len(lines) && configIndent(lines[end+1]) > configIndent(lines[i]) {
+		text, _ := splitConfigComment(lines[end+1])
+		if strings.TrimSpace(text) == "" {
+			break
+		}
+		end++
+		parts = append(parts, strings.TrimSpace(text))
+	}
+	return strings.Join(parts, "\n"), end
+}
+
+// configIndent returns the width of a line's indentation
+func configIndent(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// configNesting returns how many brackets and braces outside quotes text
+// leaves open
+func configNesting(text string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+	return depth
+}
+
+// compactConfigValue removes the whitespace outside quotes from an array or
+// inline table, and the trailing commas TOML allows in arrays
+func compactConfigValue(text string) string {
+	var b strings.Builder
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' && i+1 < len(text) {
+				b.WriteByte(c)
+				i++
+				c = text[i]
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t' || c == '\n':
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return strings.ReplaceAll(b.String(), ",]", "]")
+}
+
+// unquoteConfig returns the value of a quoted string, or text unchanged
+func unquoteConfig(text string) string {
+	if len(text) < 2 || text[len(text)-1] != text[0] {
+		return text
+	}
+	switch text[0] {
+	case '"':
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			return unquoted
+		}
+	case '\'':
+		return text[1 : len(text)-1]
+	}
+	return text
+}
+
+// TOML forms of bare values that are not strings
+var (
+	tomlIntPattern      = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)$|^0x[0-9a-fA-F](_?[0-9a-fA-F])*$|^0o[0-7](_?[0-7])*$|^0b[01](_?[01])*$`)
+	tomlFloatPattern    = regexp.MustCompile(`^[-+]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*)?([eE][-+]?[0-9](_?[0-9])*)?$|^[-+]?(inf|nan)$`)
+	tomlDateTimePattern = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}([Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?)?([Zz]|[-+][0-9]{2}:[0-9]{2})?$|^[0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?$`)
+)
+
+// resolveConfigValue returns the canonical form of a value by its TOML
+// type, so that 1 and "1" differ while 0x10 and 16 are the same. Quoted
+// values, bare words, and INI values continued on further lines are
+// strings; arrays, inline tables, and TOML multi-line strings are kept as
+// written.
+func resolveConfigValue(text string) string {
+	switch {
+	case text == "":
+		return strconv.Quote(text)
+	case text[0] == '[' || text[0] == '{' || strings.HasPrefix(text, `"""`) || strings.HasPrefix(text, "'''"):
+		return text
+	case strings.Contains(text, "\n"):
+		return strconv.Quote(text)
+	case text[0] == '"' || text[0] == '\'':
+		return strconv.Quote(unquoteConfig(text))
+	case text == "true" || text == "false":
+		return text
+	case tomlIntPattern.MatchString(text):
+		if n, err := strconv.ParseInt(text, 0, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case tomlFloatPattern.MatchString(text):
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil {
+			// Keep floats apart from integers of the same value
+			return "float:" + strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	case tomlDateTimePattern.MatchString(text):
+		return "datetime:" + text
+	}
+	return strconv.Quote(text)
+}
+
+// configCanonical returns a section's keys and values, sorted
+func configCanonical(section *structNode) string {
+	parts := make([]string, len(section.items))
+	for i, item := range section.items {
+		parts[i] = strconv.Quote(item.key) + "=" + item.value.canonical
+	}
+	sort.Strings(parts)
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+// countConfigKeys counts the keys of two config files by how their values
+// changed, pairing them by section and name
+func countConfigKeys(left, right *structNode) *RecordSummary {
+	values := func(file *structNode) map[string]string {
+		values := make(map[string]string)
+		for _, section := range file.items {
+			for _, item := range section.value.items {
+				// Leave out the comments a canonical form may carry
+				value, _, _ := strings.Cut(item.value.canonical, "\x00")
+				values[section.key+"\x00"+item.key] = value
+			}
+		}
+		return values
+	}
+
+	summary := &RecordSummary{}
+	leftValues, rightValues := values(left), values(right)
+	for key, value := range leftValues {
+		switch rightValue, ok := rightValues[key]; {
+		case !ok:
+			summary.Removed++
+		case rightValue != value:
+			summary.Changed++
+		default:
+			summary.Unchanged++
+		}
+	}
+	for key := range rightValues {
+		if _, ok := leftValues[key]; !ok {
+			summary.Added++
+		}
+	}
+	return summary
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsConfigPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"settings.ini":   true,
+		"setup.CFG":      true,
+		"pyproject.toml": true,
+		"config.yaml":    false,
+		"nginx.conf":     false,
+	} {
+		if got := IsConfigPath(path); got != want {
+			t.Errorf("IsConfigPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestSemanticConfigDiff(t *testing.T) {
+	left := strings.Split(`# Shared settings
+name = "weld"
+
+[server]
+host = "localhost"
+port = 8080
+
+[database]
+user = admin
+timeout = 30`, "\n")
+
+	check := func(t *testing.T, right []string, ignoreComments bool) *DiffResult {
+		t.Helper()
+		result, ok := SemanticConfigDiff(left, right, NewLCSDefault(), ignoreComments)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Fatalf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+		return result
+	}
+
+	t.Run("reordered sections and keys", func(t *testing.T) {
+		right := strings.Split(`# Shared settings
+name = 'weld'
+
+[database]
+timeout=30
+user = admin
+
+[ server ]
+port = 8080
+host = "localhost"`, "\n")
+		result := check(t, right, false)
+		if changed := changedLines(result); len(changed) != 0 {
+			t.Errorf("Expected no changes, got %+v", changed)
+		}
+		if *result.Keys != (RecordSummary{Unchanged: 5}) {
+			t.Errorf("Expected five unchanged keys, got %+v", *result.Keys)
+		}
+	})
+
+	t.Run("added, removed, and changed keys", func(t *testing.T) {
+		right := strings.Split(`# Shared settings
+name = "weld"
+
+[server]
+host = "0.0.0.0"
+port = 8080
+tls = true
+
+[database]
+user = admin`, "\n")
+		result := check(t, right, false)
+		if *result.Keys != (RecordSummary{Added: 1, Removed: 1, Changed: 1, Unchanged: 3}) {
+			t.Errorf("Unexpected key counts %+v", *result.Keys)
+		}
+		var modified []string
+		for _, line := range changedLines(result) {
+			if line.Type == "modified" {
+				modified = append(modified, line.RightLine)
+			}
+		}
+		if len(modified) != 1 || modified[0] != `host = "0.0.0.0"` {
+			t.Errorf("Expected only the host to be modified, got %q", modified)
+		}
+	})
+
+	t.Run("comments", func(t *testing.T) {
+		right := append([]string{"# Staging settings"}, left[1:]...)
+		if changed := changedLines(check(t, right, false)); len(changed) != 1 {
+			t.Errorf("Expected the comment to change, got %+v", changed)
+		}
+		if changed := changedLines(check(t, right, true)); len(changed) != 0 {
+			t.Errorf("Expected comments to be ignored, got %+v", changed)
+		}
+	})
+
+	t.Run("TOML arrays", func(t *testing.T) {
+		left := []string{"[tool]", "targets = [", `  "linux",`, `  "darwin",`, "]", "[[plugin]]", "name = \"a\"", "[[plugin]]", "name = \"b\""}
+		right := []string{"[[plugin]]", "name = \"a\"", "[tool]", `targets = ["linux", "darwin"]`, "[[plugin]]", "name = \"c\""}
+		result, ok := SemanticConfigDiff(left, right, NewLCSDefault(), false)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if *result.Keys != (RecordSummary{Changed: 1, Unchanged: 2}) {
+			t.Errorf("Expected only the second plugin to change, got %+v", *result.Keys)
+		}
+	})
+
+	t.Run("TOML value types", func(t *testing.T) {
+		left := []string{`a = 1`, `b = 0x10`, `c = 1.0`, `d = true`, `e = 'x'`, `f = 1979-05-27`}
+		right := []string{`a = "1"`, `b = 16`, `c = 1`, `d = "true"`, `e = "x"`, `f = "1979-05-27"`}
+		result, ok := SemanticConfigDiff(left, right, NewLCSDefault(), false)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if *result.Keys != (RecordSummary{Changed: 4, Unchanged: 2}) {
+			t.Errorf("Expected only values of another type to change, got %+v", *result.Keys)
+		}
+		var modified []string
+		for _, line := range changedLines(result) {
+			modified = append(modified, line.RightLine)
+		}
+		if !reflect.DeepEqual(modified, []string{`a = "1"`, `c = 1`, `d = "true"`, `f = "1979-05-27"`}) {
+			t.Errorf("Unexpected changed lines %q", modified)
+		}
+	})
+
+	t.Run("unparseable lines fall back", func(t *testing.T) {
+		if _, ok := SemanticConfigDiff(left, []string{"server {", "  listen 80;", "}"}, NewLCSDefault(), false); ok {
+			t.Error("Expected a file that is not INI or TOML not to parse")
+		}
+	})
+}
@@ -0,0 +1,204 @@
+package diff
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+)
+
+// goDecl is a top-level Go declaration with the lines it spans, from its doc
+// comment to the line before the next declaration
+type goDecl struct {
+	key   string // name identifying the declaration on both sides
+	start int    // 0-based first line
+	body  int    // 0-based line after the last non-blank line
+	end   int    // 0-based line after the blank lines that follow
+}
+
+// SemanticGoDiff compares two Go files declaration by declaration. Functions,
+// methods, types, and package-level variables and constants are aligned by
+// name, so moving a function shows it unchanged instead of removed and added
+// elsewhere. Lines are listed in left file order, with declarations only on
+// the right placed after their neighbor in the right file. Blank lines
+// separating matched declarations that have no counterpart, because the
+// declarations moved, are "same" with line number 0 on the other side. It
+// reports false when either file does not parse, so the caller can fall back
+// to a line diff.
+func SemanticGoDiff(leftLines, rightLines []string, algorithm Algorithm) (*DiffResult, bool) {
+	leftDecls, ok := parseGoDecls(leftLines)
+	if !ok {
+		return nil, false
+	}
+	rightDecls, ok := parseGoDecls(rightLines)
+	if !ok {
+		return nil, false
+	}
+
+	rightIndex := make(map[string]int, len(rightDecls))
+	for i, decl := range rightDecls {
+		rightIndex[decl.key] = i
+	}
+	leftKeys := make(map[string]bool, len(leftDecls))
+	for _, decl := range leftDecls {
+		leftKeys[decl.key] = true
+	}
+
+	result := &DiffResult{Lines: []DiffLine{}}
+	appendDiff := func(leftStart, leftEnd, rightStart, rightEnd int) {
+		part := algorithm.ComputeDiff(leftLines[leftStart:leftEnd], rightLines[rightStart:rightEnd])
+		OffsetLineNumbers(part, leftStart, rightStart)
+		result.Lines = append(result.Lines, part.Lines...)
+	}
+	// emitRightOnly adds the declarations only on the right, starting at
+	// index i, until the next one the left file also has
+	emitRightOnly := func(i int) {
+		for ; i < len(rightDecls) && !leftKeys[rightDecls[i].key]; i++ {
+			appendDiff(0, 0, rightDecls[i].start, rightDecls[i].end)
+		}
+	}
+
+	// The package clause and imports come before the first declaration
+	appendDiff(0, firstDeclStart(leftDecls, len(leftLines)), 0, firstDeclStart(rightDecls, len(rightLines)))
+	emitRightOnly(0)
+
+	for _, decl := range leftDecls {
+		match, ok := rightIndex[decl.key]
+		if !ok {
+			appendDiff(decl.start, decl.end, 0, 0)
+			continue
+		}
+		counterpart := rightDecls[match]
+		appendDiff(decl.start, decl.body, counterpart.start, counterpart.body)
+		result.Lines = appendSeparators(result.Lines, decl.body, decl.end, counterpart.body, counterpart.end)
+		emitRightOnly(match + 1)
+	}
+
+	result = detectModifications(result, configOf(algorithm))
+	return result, true
+}
+
+// appendSeparators pairs the blank lines after a matched declaration on each
+// side, keeping any extra on one side as "same" lines
+func appendSeparators(lines []DiffLine, leftStart, leftEnd, rightStart, rightEnd int) []DiffLine {
+	for l, r := leftStart, rightStart; l < leftEnd || r < rightEnd; l, r = l+1, r+1 {
+		line := DiffLine{Type: "same"}
+		if l < leftEnd {
+			line.LeftNumber = l + 1
+		}
+		if r < rightEnd {
+			line.RightNumber = r + 1
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// configOf returns the configuration of an algorithm, or the defaults
+func configOf(algorithm Algorithm) Config {
+	if configurable, ok := algorithm.(Configurable); ok {
+		return configurable.Config()
+	}
+	return DefaultConfig()
+}
+
+// firstDeclStart returns the first line of the first declaration, or the
+// number of lines when there are none
+func firstDeclStart(decls []goDecl, lineCount int) int {
+	if len(decls) == 0 {
+		return lineCount
+	}
+	return decls[0].start
+}
+
+// parseGoDecls parses a Go file and returns its top-level declarations
+// other than imports, each extended to the start of the next
+func parseGoDecls(lines []string) ([]goDecl, bool) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", strings.Join(lines, "\n"), parser.ParseComments)
+	if err != nil {
+		return nil, false
+	}
+
+	decls := []goDecl{}
+	seen := make(map[string]int)
+	for _, node := range file.Decls {
+		if gen, ok := node.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			continue
+		}
+		start := node.Pos()
+		switch d := node.(type) {
+		case *ast.FuncDecl:
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if d.Doc != nil {
+				start = d.Doc.Pos()
+			}
+		}
+
+		key := goDeclKey(node)
+		// Repeated names, like several init functions, pair up in order
+		seen[key]++
+		if seen[key] > 1 {
+			key = fmt.Sprintf("%s#%d", key, seen[key])
+		}
+		decls = append(decls, goDecl{key: key, start: fset.Position(start).Line - 1})
+	}
+
+	for i := range decls {
+		decls[i].end = len(lines)
+		if i+1 < len(decls) {
+			decls[i].end = decls[i+1].start
+		}
+		decls[i].body = decls[i].end
+		for decls[i].body > decls[i].start && strings.TrimSpace(lines[decls[i].body-1]) == "" {
+			decls[i].body--
+		}
+	}
+	return decls, true
+}
+
+// goDeclKey names a declaration: functions by name, methods by receiver type
+// and name, and type, variable, and constant declarations by their names
+func goDeclKey(node ast.Decl) string {
+	switch d := node.(type) {
+	case *ast.FuncDecl:
+		if d.Recv != nil && len(d.Recv.List) > 0 {
+			return "func (" + receiverTypeName(d.Recv.List[0].Type) + ")." + d.Name.Name
+		}
+		return "func " + d.Name.Name
+	case *ast.GenDecl:
+		var names []string
+		for _, spec := range d.Specs {
+			switch s := spec.(type) {
+			case *ast.TypeSpec:
+				names = append(names, s.Name.Name)
+			case *ast.ValueSpec:
+				for _, name := range s.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+		return d.Tok.String() + " " + strings.Join(names, ",")
+	}
+	return "decl"
+}
+
+// receiverTypeName returns the type name of a method receiver, without
+// pointers or type parameters
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSemanticGoDiff(t *testing.T) {
+	left := strings.Split(`package main
+
+import "fmt"
+
+// helper prints a greeting
+func helper() {
+	fmt.Println("hello")
+}
+
+func (s *Server) Run() error {
+	return nil
+}
+
+func run() {
+	helper()
+}`, "\n")
+	right := strings.Split(`package main
+
+import "fmt"
+
+func run() {
+	helper()
+}
+
+type Server struct{}
+
+// helper prints a greeting
+func helper() {
+	fmt.Println("hello, world")
+}`, "\n")
+
+	result, ok := SemanticGoDiff(left, right, NewLCSDefault())
+	if !ok {
+		t.Fatal("Expected both files to parse")
+	}
+
+	byLeft := make(map[string]DiffLine)
+	var added, removed []string
+	for _, line := range result.Lines {
+		if line.LeftNumber > 0 {
+			byLeft[line.LeftLine] = line
+		}
+		switch line.Type {
+		case "added":
+			added = append(added, line.RightLine)
+		case "removed":
+			removed = append(removed, line.LeftLine)
+		}
+	}
+
+	// The moved function is matched by name rather than removed and re-added
+	if line := byLeft["func run() {"]; line.Type != "same" || line.RightNumber != 5 {
+		t.Errorf("Expected run to be matched, got %+v", line)
+	}
+	if line := byLeft["\tfmt.Println(\"hello\")"]; line.Type != "modified" || line.RightNumber != 13 {
+		t.Errorf("Expected the changed body line to be modified, got %+v", line)
+	}
+	if !strings.Contains(strings.Join(removed, "\n"), "func (s *Server) Run() error {") {
+		t.Errorf("Expected the removed method, got %v", removed)
+	}
+	if !strings.Contains(strings.Join(added, "\n"), "type Server struct{}") {
+		t.Errorf("Expected the added type, got %v", added)
+	}
+	leftSeen, rightSeen := make(map[int]bool), make(map[int]bool)
+	for _, line := range result.Lines {
+		if line.LeftNumber > 0 {
+			leftSeen[line.LeftNumber] = true
+		}
+		if line.RightNumber > 0 {
+			rightSeen[line.RightNumber] = true
+		}
+	}
+	if len(leftSeen) != len(left) || len(rightSeen) != len(right) {
+		t.Errorf("Expected every line once, got %d of %d left and %d of %d right", len(leftSeen), len(left), len(rightSeen), len(right))
+	}
+	if len(result.Chunks) == 0 {
+		t.Error("Expected chunks to be set")
+	}
+
+	if _, ok := SemanticGoDiff([]string{"package main", "func broken( {"}, right, NewLCSDefault()); ok {
+		t.Error("Expected a parse error to report false")
+	}
+}
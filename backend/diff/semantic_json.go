@@ -0,0 +1,192 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsJSONPath reports whether a file is JSON by its extension
+func IsJSONPath(path string) bool {
+	return strings.ToLower(filepath.Ext(path)) == ".json"
+}
+
+// SemanticJSONDiff compares two JSON files value by value. Object members
+// are aligned by key and array elements by content, so reordered keys,
+// reformatting, and trailing commas show no difference. Lines are listed in
+// left file order, with members only on the right placed after their
+// neighbor in the right file. Unchanged values that span a different number
+// of lines on each side pad the shorter side with "same" lines numbered 0.
+// It reports false when either file is not valid JSON, so the caller can
+// fall back to a line diff.
+func SemanticJSONDiff(leftLines, rightLines []string, algorithm Algorithm) (*DiffResult, bool) {
+	left, ok := parseJSONLines(leftLines)
+	if !ok {
+		return nil, false
+	}
+	right, ok := parseJSONLines(rightLines)
+	if !ok {
+		return nil, false
+	}
+
+	d := &structDiffer{leftLines: leftLines, rightLines: rightLines, algorithm: algorithm}
+	return d.compare(left, right), true
+}
+
+// parseJSONLines parses a JSON document, recording the lines of each value
+func parseJSONLines(lines []string) (*structNode, bool) {
+	text := strings.Join(lines, "\n")
+	if !json.Valid([]byte(text)) {
+		return nil, false
+	}
+	p := &jsonParser{text: text}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			p.newlines = append(p.newlines, i)
+		}
+	}
+	p.skipSpace()
+	root, err := p.value()
+	if err != nil {
+		return nil, false
+	}
+	return root, true
+}
+
+// jsonParser reads JSON already known to be valid, tracking line positions
+type jsonParser struct {
+	text     string
+	pos      int
+	newlines []int // offsets of each newline
+}
+
+// line returns the 0-based line of an offset
+func (p *jsonParser) line(offset int) int {
+	return sort.SearchInts(p.newlines, offset)
+}
+
+func (p *jsonParser) skipSpace() {
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+// value parses the value at the current position
+func (p *jsonParser) value() (*structNode, error) {
+	if p.pos >= len(p.text) {
+		return nil, fmt.Errorf("unexpected end of JSON")
+	}
+	switch p.text[p.pos] {
+	case '{', '[':
+		return p.container()
+	}
+	start := p.pos
+	if p.text[p.pos] == '"' {
+		p.skipString()
+	} else {
+		for p.pos < len(p.text) && strings.IndexByte(",]} \t\r\n", p.text[p.pos]) < 0 {
+			p.pos++
+		}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(p.text[start:p.pos]), &decoded); err != nil {
+		return nil, err
+	}
+	canonical, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+	return &structNode{start: p.line(start), end: p.line(p.pos - 1), canonical: string(canonical)}, nil
+}
+
+// skipString moves past the string starting at the current position
+func (p *jsonParser) skipString() {
+	for p.pos++; p.pos < len(p.text) && p.text[p.pos] != '"'; p.pos++ {
+		if p.text[p.pos] == '\\' {
+			p.pos++
+		}
+	}
+	p.pos++
+}
+
+// container parses an object or array with its items
+func (p *jsonParser) container() (*structNode, error) {
+	open := p.text[p.pos]
+	closing := byte(']')
+	if open == '{' {
+		closing = '}'
+	}
+	node := &structNode{kind: open, start: p.line(p.pos)}
+	p.pos++
+
+	seen := make(map[string]int)
+	var parts []string
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.text) {
+			return nil, fmt.Errorf("unexpected end of JSON")
+		}
+		if p.text[p.pos] == closing {
+			break
+		}
+		if p.text[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+
+		item := structItem{start: p.line(p.pos)}
+		if open == '{' {
+			keyStart := p.pos
+			p.skipString()
+			if err := json.Unmarshal([]byte(p.text[keyStart:p.pos]), &item.key); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			p.pos++ // the colon
+			p.skipSpace()
+		}
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		item.value = value
+		if open == '[' {
+			item.key = value.canonical
+			parts = append(parts, value.canonical)
+		} else {
+			name, _ := json.Marshal(item.key)
+			parts = append(parts, string(name)+":"+value.canonical)
+			// Repeated keys pair up in order
+			seen[item.key]++
+			if seen[item.key] > 1 {
+				item.key = fmt.Sprintf("%s#%d", item.key, seen[item.key])
+			}
+		}
+		node.items = append(node.items, item)
+	}
+	node.end = p.line(p.pos)
+	p.pos++
+
+	// Each item runs up to the line the next one starts on, or the line of
+	// the closing bracket
+	node.split = len(node.items) > 0 && node.items[0].start > node.start
+	for i := range node.items {
+		if i+1 < len(node.items) {
+			node.items[i].next = node.items[i+1].start
+		} else {
+			node.items[i].next = node.end
+		}
+		if node.items[i].value.end >= node.items[i].next {
+			node.split = false
+		}
+	}
+	if open == '[' {
+		node.canonical = "[" + strings.Join(parts, ",") + "]"
+	} else {
+		sort.Strings(parts)
+		node.canonical = "{" + strings.Join(parts, ",") + "}"
+	}
+	return node, nil
+}
@@ -0,0 +1,146 @@
+package diff
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+// lineNumbers returns the line numbers each side of a diff covers, sorted
+func lineNumbers(result *DiffResult) ([]int, []int) {
+	var left, right []int
+	for _, line := range result.Lines {
+		if line.LeftNumber > 0 {
+			left = append(left, line.LeftNumber)
+		}
+		if line.RightNumber > 0 {
+			right = append(right, line.RightNumber)
+		}
+	}
+	sort.Ints(left)
+	sort.Ints(right)
+	return left, right
+}
+
+// coversEveryLine reports whether numbers lists 1 to n once each
+func coversEveryLine(numbers []int, n int) bool {
+	if len(numbers) != n {
+		return false
+	}
+	for i, number := range numbers {
+		if number != i+1 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSemanticJSONDiff(t *testing.T) {
+	left := strings.Split(`{
+  "name": "weld",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "vite build",
+    "test": "vitest"
+  },
+  "files": [
+    "dist",
+    "README.md"
+  ]
+}`, "\n")
+
+	check := func(t *testing.T, right []string) *DiffResult {
+		t.Helper()
+		result, ok := SemanticJSONDiff(left, right, NewLCSDefault())
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Fatalf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+		return result
+	}
+
+	t.Run("reordered keys and formatting", func(t *testing.T) {
+		right := strings.Split(`{
+  "files": ["dist", "README.md"],
+  "scripts": {
+    "test": "vitest",
+    "build": "vite build"
+  },
+  "version": "1.0.0",
+  "name": "weld"
+}`, "\n")
+		result := check(t, right)
+		if len(result.Chunks) != 0 {
+			t.Errorf("Expected no differences, got %+v", result.Lines)
+		}
+	})
+
+	t.Run("changed value", func(t *testing.T) {
+		right := strings.Split(`{
+  "version": "1.1.0",
+  "name": "weld",
+  "scripts": {
+    "test": "vitest",
+    "build": "vite build"
+  },
+  "files": [
+    "dist",
+    "README.md"
+  ]
+}`, "\n")
+		result := check(t, right)
+		var changed []DiffLine
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				changed = append(changed, line)
+			}
+		}
+		if len(changed) != 1 || changed[0].Type != "modified" || changed[0].LeftNumber != 3 || changed[0].RightNumber != 2 {
+			t.Errorf("Expected only the version to be modified, got %+v", changed)
+		}
+	})
+
+	t.Run("added member and element", func(t *testing.T) {
+		right := strings.Split(`{
+  "name": "weld",
+  "version": "1.0.0",
+  "scripts": {
+    "build": "vite build",
+    "lint": "eslint .",
+    "test": "vitest"
+  },
+  "files": [
+    "dist",
+    "LICENSE",
+    "README.md"
+  ]
+}`, "\n")
+		result := check(t, right)
+		var added []string
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				added = append(added, strings.TrimSpace(line.RightLine))
+			}
+		}
+		if strings.Join(added, " ") != `"lint": "eslint .", "LICENSE",` {
+			t.Errorf("Expected the new script and file to be added, got %q", added)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		if _, ok := SemanticJSONDiff(left, []string{"{", `"name": `}, NewLCSDefault()); ok {
+			t.Error("Expected invalid JSON to fall back")
+		}
+	})
+}
+
+func TestIsJSONPath(t *testing.T) {
+	for path, expected := range map[string]bool{"package.json": true, "DATA.JSON": true, "main.go": false, "json": false} {
+		if IsJSONPath(path) != expected {
+			t.Errorf("IsJSONPath(%q) = %v, expected %v", path, !expected, expected)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package diff
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// IsXMLPath reports whether a file is XML by its extension
+func IsXMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".xml", ".xsd", ".xsl", ".xslt", ".svg":
+		return true
+	}
+	return false
+}
+
+// SemanticXMLDiff compares two XML files element by element, like
+// SemanticJSONDiff. Attributes are compared regardless of their order and
+// quoting, an empty element matches a self-closing one, and whitespace
+// between elements and around text is ignored, so two exports of the same
+// document show no difference. Child elements keep their order, as it is
+// significant in XML. Comments are compared unless ignoreComments is set.
+// It reports false when either file is not well-formed XML, so the caller
+// can fall back to a line diff.
+func SemanticXMLDiff(leftLines, rightLines []string, algorithm Algorithm, ignoreComments bool) (*DiffResult, bool) {
+	left, ok := parseXMLLines(leftLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+	right, ok := parseXMLLines(rightLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+
+	d := &structDiffer{leftLines: leftLines, rightLines: rightLines, algorithm: algorithm, framing: xmlFraming(ignoreComments)}
+	return d.compare(left, right), true
+}
+
+// parseXMLLines parses an XML document, recording the lines of each node.
+// The document is a sequence of its top-level nodes, such as the XML
+// declaration and the root element, spanning every line.
+func parseXMLLines(lines []string, ignoreComments bool) (*structNode, bool) {
+	text := strings.Join(lines, "\n")
+	p := &xmlParser{decoder: newXMLDecoder(text), ignoreComments: ignoreComments}
+	for i := 0; i < len(text); i++ {
+		if text[i] == '\n' {
+			p.newlines = append(p.newlines, i)
+		}
+	}
+
+	doc := &structNode{kind: '[', start: 0, end: len(lines) - 1}
+	var parts []string
+	hasRoot := false
+	for {
+		offset := p.decoder.InputOffset()
+		token, err := p.decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, false
+		}
+		var node *structNode
+		if start, ok := token.(xml.StartElement); ok {
+			if node, err = p.element(start, offset); err != nil {
+				return nil, false
+			}
+			hasRoot = true
+		} else {
+			node = p.leaf(token, offset)
+		}
+		if node != nil {
+			doc.items = append(doc.items, structItem{key: node.canonical, start: node.start, value: node})
+			parts = append(parts, node.canonical)
+		}
+	}
+	if !hasRoot {
+		return nil, false
+	}
+
+	doc.canonical = strings.Join(parts, "")
+	for i := range doc.items {
+		doc.items[i].next = len(lines)
+		if i+1 < len(doc.items) {
+			doc.items[i].next = doc.items[i+1].start
+		}
+	}
+	doc.split = splitItems(doc)
+	return doc, true
+}
+
+// newXMLDecoder returns a decoder of text. Text has already been decoded
+// into lines, so any encoding it declares is taken as read.
+func newXMLDecoder(text string) *xml.Decoder {
+	decoder := xml.NewDecoder(strings.NewReader(text))
+	decoder.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return decoder
+}
+
+// xmlParser reads an XML document, tracking line positions
+type xmlParser struct {
+	decoder        *xml.Decoder
+	newlines       []int // offsets of each newline
+	ignoreComments bool
+}
+
+// line returns the 0-based line of an offset
+func (p *xmlParser) line(offset int64) int {
+	return sort.SearchInts(p.newlines, int(offset))
+}
+
+// element parses the element that start opens at offset, with its children
+func (p *xmlParser) element(start xml.StartElement, offset int64) (*structNode, error) {
+	node := &structNode{kind: '[', start: p.line(offset)}
+	tag := xmlStartTag(start)
+	tagEnd := p.line(p.decoder.InputOffset() - 1)
+
+	var parts []string
+	for {
+		offset := p.decoder.InputOffset()
+		token, err := p.decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		var child *structNode
+		switch t := token.(type) {
+		case xml.EndElement:
+			node.end = p.line(p.decoder.InputOffset() - 1)
+			node.canonical = tag + strings.Join(parts, "") + "</" + xmlName(t.Name) + ">"
+			// Each child runs up to the line the next one starts on, or the
+			// line of the end tag
+			for i := range node.items {
+				node.items[i].next = node.end
+				if i+1 < len(node.items) {
+					node.items[i].next = node.items[i+1].start
+				}
+			}
+			node.split = len(node.items) > 0 && node.items[0].start > tagEnd && splitItems(node)
+			return node, nil
+		case xml.StartElement:
+			if child, err = p.element(t, offset); err != nil {
+				return nil, err
+			}
+		default:
+			child = p.leaf(token, offset)
+		}
+		if child != nil {
+			node.items = append(node.items, structItem{key: child.canonical, start: child.start, value: child})
+			parts = append(parts, child.canonical)
+		}
+	}
+}
+
+// leaf returns the node of a token other than an element, read from offset,
+// or nil when it is insignificant, as whitespace and ignored comments are
+func (p *xmlParser) leaf(token xml.Token, offset int64) *structNode {
+	canonical := xmlCanonical(token, p.ignoreComments)
+	if canonical == "" {
+		return nil
+	}
+	end := p.decoder.InputOffset()
+	// Text spans only the lines of its content, not the whitespace around
+	// it, unless entities make its offsets unknown
+	if text, ok := token.(xml.CharData); ok {
+		if raw := string(text); len(raw) == int(end-offset) {
+			offset += int64(len(raw) - len(strings.TrimLeft(raw, " \t\r\n")))
+			end = offset + int64(len(strings.TrimSpace(raw)))
+		}
+	}
+	return &structNode{start: p.line(offset), end: p.line(end - 1), canonical: canonical}
+}
+
+// splitItems reports whether each item of a node ends before the next one
+// starts, or before the line of the node's end tag
+func splitItems(node *structNode) bool {
+	for _, item := range node.items {
+		if item.value.end >= item.next {
+			return false
+		}
+	}
+	return len(node.items) > 0
+}
+
+// xmlCanonical returns the canonical form of a token other than an
+// element, or an empty string when it is insignificant
+func xmlCanonical(token xml.Token, ignoreComments bool) string {
+	switch t := token.(type) {
+	case xml.CharData:
+		text := strings.TrimSpace(string(t))
+		if text == "" {
+			return ""
+		}
+		var b strings.Builder
+		xml.EscapeText(&b, []byte(text))
+		return b.String()
+	case xml.Comment:
+		if ignoreComments {
+			return ""
+		}
+		return "<!--" + strings.TrimSpace(string(t)) + "-->"
+	case xml.ProcInst:
+		inst := strings.Join(strings.Fields(string(t.Inst)), " ")
+		if t.Target == "xml" {
+			// The declaration's values never contain quotes themselves
+			inst = strings.ReplaceAll(inst, "'", `"`)
+		}
+		return "<?" + t.Target + " " + inst + "?>"
+	case xml.Directive:
+		return "<!" + strings.Join(strings.Fields(string(t)), " ") + ">"
+	}
+	return ""
+}
+
+// xmlStartTag returns the canonical form of a start tag, with its
+// attributes sorted
+func xmlStartTag(start xml.StartElement) string {
+	attrs := make([]string, len(start.Attr))
+	for i, attr := range start.Attr {
+		attrs[i] = fmt.Sprintf(" %s=%q", xmlName(attr.Name), attr.Value)
+	}
+	sort.Strings(attrs)
+	return "<" + xmlName(start.Name) + strings.Join(attrs, "") + ">"
+}
+
+// xmlName returns a name with its namespace, if any
+func xmlName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+// xmlFraming returns the framing function of an XML comparison, which
+// reduces lines around values, such as start and end tags, to their
+// canonical form. Those lines are not a document of their own, so tags are
+// read without matching them up.
+func xmlFraming(ignoreComments bool) func(lines []string) string {
+	return func(lines []string) string {
+		text := strings.Join(lines, "\n")
+		decoder := newXMLDecoder(text)
+		decoder.Strict = false
+		var parts []string
+		for {
+			token, err := decoder.RawToken()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return strings.Join(strings.Fields(text), " ")
+			}
+			switch t := token.(type) {
+			case xml.StartElement:
+				parts = append(parts, xmlStartTag(t))
+			case xml.EndElement:
+				parts = append(parts, "</"+xmlName(t.Name)+">")
+			default:
+				parts = append(parts, xmlCanonical(token, ignoreComments))
+			}
+		}
+		return strings.Join(parts, "")
+	}
+}
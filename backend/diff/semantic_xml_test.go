@@ -0,0 +1,122 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsXMLPath(t *testing.T) {
+	for path, want := range map[string]bool{
+		"export.xml":  true,
+		"Schema.XSD":  true,
+		"icon.svg":    true,
+		"config.json": false,
+		"xml":         false,
+	} {
+		if got := IsXMLPath(path); got != want {
+			t.Errorf("IsXMLPath(%q) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+func TestSemanticXMLDiff(t *testing.T) {
+	left := strings.Split(`<?xml version="1.0" encoding="UTF-8"?>
+<!-- exported catalog -->
+<catalog version="2" source="erp">
+  <book id="1" lang="en">
+    <title>Go in Practice</title>
+    <price>30</price>
+  </book>
+  <book id="2" lang="en">
+    <title>Diff Algorithms</title>
+    <tags/>
+  </book>
+</catalog>`, "\n")
+
+	check := func(t *testing.T, right []string, ignoreComments bool) *DiffResult {
+		t.Helper()
+		result, ok := SemanticXMLDiff(left, right, NewLCSDefault(), ignoreComments)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Fatalf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+		return result
+	}
+
+	t.Run("attribute order, self-closing tags, and whitespace", func(t *testing.T) {
+		right := strings.Split(`<?xml version='1.0'  encoding='UTF-8'?>
+<!-- exported catalog -->
+<catalog source='erp' version='2'>
+	<book lang="en" id="1">
+		<title>
+			Go in Practice
+		</title>
+		<price>30</price>
+	</book>
+
+	<book
+	    lang="en"
+	    id="2">
+		<title>Diff Algorithms</title>
+		<tags></tags>
+	</book>
+</catalog>`, "\n")
+		result := check(t, right, false)
+		if changed := changedLines(result); len(changed) != 0 {
+			t.Errorf("Expected no changes, got %+v", changed)
+		}
+	})
+
+	t.Run("a changed attribute shows on its tag only", func(t *testing.T) {
+		right := make([]string, len(left))
+		copy(right, left)
+		right[3] = `  <book id="1" lang="de">`
+		result := check(t, right, false)
+		changed := changedLines(result)
+		if len(changed) != 1 || changed[0].LeftNumber != 4 || changed[0].RightNumber != 4 {
+			t.Errorf("Expected only the book tag to change, got %+v", changed)
+		}
+	})
+
+	t.Run("a changed value shows on its line only", func(t *testing.T) {
+		right := make([]string, len(left))
+		copy(right, left)
+		right[5] = "    <price>35</price>"
+		changed := changedLines(check(t, right, false))
+		if len(changed) != 1 || changed[0].LeftNumber != 6 || changed[0].RightNumber != 6 {
+			t.Errorf("Expected only the price to change, got %+v", changed)
+		}
+	})
+
+	t.Run("an added element", func(t *testing.T) {
+		right := append(append(append([]string{}, left[:10]...), "    <isbn>123</isbn>"), left[10:]...)
+		changed := changedLines(check(t, right, false))
+		if len(changed) != 1 || changed[0].Type != "added" || changed[0].RightNumber != 11 {
+			t.Errorf("Expected one added line, got %+v", changed)
+		}
+	})
+
+	t.Run("comments", func(t *testing.T) {
+		right := make([]string, len(left))
+		copy(right, left)
+		right[1] = "<!-- exported nightly -->"
+		if changed := changedLines(check(t, right, false)); len(changed) != 1 {
+			t.Errorf("Expected the comment to change, got %+v", changed)
+		}
+		if changed := changedLines(check(t, right, true)); len(changed) != 0 {
+			t.Errorf("Expected comments to be ignored, got %+v", changed)
+		}
+	})
+
+	t.Run("not well-formed", func(t *testing.T) {
+		if _, ok := SemanticXMLDiff(left, []string{"<catalog>", "<book>", "</catalog>"}, NewLCSDefault(), false); ok {
+			t.Error("Expected mismatched tags not to parse")
+		}
+		if _, ok := SemanticXMLDiff(left, []string{"plain text"}, NewLCSDefault(), false); ok {
+			t.Error("Expected a file without a root element not to parse")
+		}
+	})
+}
@@ -0,0 +1,528 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// IsYAMLPath reports whether a file is YAML by its extension
+func IsYAMLPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// SemanticYAMLDiff compares two YAML files value by value, like
+// SemanticJSONDiff. Mapping keys are aligned by name and sequence entries by
+// content, so reordered keys and a different indentation style show no
+// difference. Documents separated by "---" are compared one to one, matched
+// by kind, namespace, and name when every document has them, as Kubernetes
+// manifests do, and in order otherwise. Comments are compared unless
+// ignoreComments is set.
+//
+// Block mappings, block sequences, and scalars are compared structurally;
+// flow collections, anchors, and tags are compared as the text they are
+// written as. It reports false when either file has a structure it cannot
+// follow, such as tab indentation, so the caller can fall back to a line
+// diff.
+func SemanticYAMLDiff(leftLines, rightLines []string, algorithm Algorithm, ignoreComments bool) (*DiffResult, bool) {
+	left, ok := parseYAMLLines(leftLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+	right, ok := parseYAMLLines(rightLines, ignoreComments)
+	if !ok {
+		return nil, false
+	}
+	keyYAMLDocuments(left, right)
+
+	d := &structDiffer{leftLines: leftLines, rightLines: rightLines, algorithm: algorithm}
+	if !ignoreComments {
+		d.framing = yamlComments
+	}
+	return d.compare(left, right), true
+}
+
+// yamlLine is a line of YAML content, without its indentation or comment
+type yamlLine struct {
+	index  int // 0-based line number
+	indent int
+	text   string
+}
+
+// splitYAMLComment separates a line's content from its comment. A comment
+// starts at a # that begins the line or follows whitespace, outside quotes.
+func splitYAMLComment(line string) (string, string) {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				i++
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			return strings.TrimRight(line[:i], " \t"), strings.TrimSpace(line[i+1:])
+		}
+	}
+	return strings.TrimRight(line, " \t"), ""
+}
+
+// yamlComments returns the comments of lines, in order
+func yamlComments(lines []string) string {
+	var comments []string
+	for _, line := range lines {
+		if _, comment := splitYAMLComment(line); comment != "" {
+			comments = append(comments, comment)
+		}
+	}
+	return strings.Join(comments, "\n")
+}
+
+// isYAMLSeparator reports whether a line starts a new document
+func isYAMLSeparator(line yamlLine) bool {
+	return line.indent == 0 && (line.text == "---" || strings.HasPrefix(line.text, "--- "))
+}
+
+// parseYAMLLines parses a YAML file into a sequence of its documents. Each
+// value's canonical form includes the comments within it unless
+// ignoreComments is set.
+func parseYAMLLines(lines []string, ignoreComments bool) (*structNode, bool) {
+	root := &structNode{kind: '[', start: 0, end: len(lines) - 1}
+	var content []yamlLine
+	docStart := 0
+	flush := func(next int) bool {
+		// Comments and a separator before the first document belong to it
+		if len(content) == 0 && len(root.items) == 0 && next < len(lines) {
+			return true
+		}
+		p := &yamlParser{lines: content, raw: lines, ignoreComments: ignoreComments}
+		var value *structNode
+		if len(content) == 0 {
+			value = &structNode{start: docStart, end: docStart, canonical: "null"}
+		} else {
+			var err error
+			if value, err = p.node(-1); err != nil || p.pos < len(content) {
+				return false
+			}
+		}
+		root.items = append(root.items, structItem{start: docStart, next: next, value: value})
+		content = nil
+		docStart = next
+		return true
+	}
+
+	for i, raw := range lines {
+		text, _ := splitYAMLComment(raw)
+		trimmed := strings.TrimLeft(text, " \t")
+		if trimmed == "" {
+			continue
+		}
+		indentation := text[:len(text)-len(trimmed)]
+		if strings.Contains(indentation, "\t") {
+			return nil, false
+		}
+		line := yamlLine{index: i, indent: len(indentation), text: trimmed}
+		switch {
+		case isYAMLSeparator(line):
+			if line.text != "---" {
+				return nil, false
+			}
+			if !flush(i) {
+				return nil, false
+			}
+		case line.indent == 0 && (line.text == "..." || strings.HasPrefix(line.text, "%")):
+			// Document end markers and directives carry no content
+		default:
+			content = append(content, line)
+		}
+	}
+	if len(content) > 0 || len(root.items) > 0 || docStart > 0 {
+		if !flush(len(lines)) {
+			return nil, false
+		}
+	}
+	root.split = len(root.items) > 0
+	return root, true
+}
+
+// yamlParser reads the content lines of one document
+type yamlParser struct {
+	lines          []yamlLine
+	pos            int
+	raw            []string
+	ignoreComments bool
+}
+
+// node parses the value starting at the current line, which is nested
+// deeper than parentIndent
+func (p *yamlParser) node(parentIndent int) (*structNode, error) {
+	line := p.lines[p.pos]
+	if isYAMLSequenceEntry(line.text) {
+		return p.sequence(line.indent)
+	}
+	if _, _, ok := splitYAMLKey(line.text); ok {
+		return p.mapping(line.indent)
+	}
+	p.pos++
+	return p.scalar(line, parentIndent), nil
+}
+
+// isYAMLSequenceEntry reports whether content starts a block sequence entry
+func isYAMLSequenceEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// splitYAMLKey splits a mapping entry into its key and the value after it
+func splitYAMLKey(text string) (string, string, bool) {
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", false
+		}
+		rest := text[end+2:]
+		if rest != "" && rest[0] != ' ' {
+			return "", "", false
+		}
+		return unquoteYAML(text[:end+1]), strings.TrimSpace(rest), true
+	}
+	// Flow collections, complex keys, and sequence entries are not keys
+	if strings.ContainsRune("[{?", rune(text[0])) || isYAMLSequenceEntry(text) {
+		return "", "", false
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ') {
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+// closingQuote returns the index of the quote closing the string text
+// starts with, or -1
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && quote == '"':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteYAML returns the value of a quoted scalar, or text unchanged
+func unquoteYAML(text string) string {
+	if len(text) < 2 || text[len(text)-1] != text[0] {
+		return text
+	}
+	switch text[0] {
+	case '"':
+		if unquoted, err := strconv.Unquote(text); err == nil {
+			return unquoted
+		}
+	case '\'':
+		return strings.ReplaceAll(text[1:len(text)-1], "''", "'")
+	}
+	return text
+}
+
+// sequence parses the block sequence whose entries are at indent
+func (p *yamlParser) sequence(indent int) (*structNode, error) {
+	node := &structNode{kind: '[', start: p.lines[p.pos].index}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceEntry(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		item := structItem{start: line.index}
+		rest := strings.TrimLeft(line.text[1:], " ")
+		var err error
+		if rest == "" {
+			item.value, err = p.nested(line, indent)
+		} else {
+			// The entry's content is read as if it started a line of its
+			// own, so a mapping can begin on the entry's line
+			p.lines[p.pos] = yamlLine{index: line.index, indent: indent + len(line.text) - len(rest), text: rest}
+			item.value, err = p.node(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		item.key = item.value.canonical
+		node.items = append(node.items, item)
+	}
+	return p.finish(node, indent)
+}
+
+// mapping parses the block mapping whose keys are at indent
+func (p *yamlParser) mapping(indent int) (*structNode, error) {
+	node := &structNode{kind: '{', start: p.lines[p.pos].index}
+	seen := make(map[string]int)
+	var parts []string
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent {
+		line := p.lines[p.pos]
+		key, rest, ok := splitYAMLKey(line.text)
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a mapping key", line.index+1)
+		}
+		item := structItem{start: line.index}
+		var err error
+		if rest == "" {
+			item.value, err = p.nested(line, indent)
+		} else {
+			p.pos++
+			item.value = p.scalar(yamlLine{index: line.index, indent: indent, text: rest}, indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name, _ := json.Marshal(key)
+		parts = append(parts, string(name)+":"+item.value.canonical)
+		// Repeated keys pair up in order
+		seen[key]++
+		item.key = key
+		if seen[key] > 1 {
+			item.key = fmt.Sprintf("%s#%d", key, seen[key])
+		}
+		node.items = append(node.items, item)
+	}
+	sort.Strings(parts)
+	node.canonical = "{" + strings.Join(parts, ",") + "}"
+	return p.finish(node, indent)
+}
+
+// nested parses the value of a key or sequence entry with nothing after it
+// on its line: a block on the following lines, or null. A sequence may be
+// the value of a key at the key's own indentation.
+func (p *yamlParser) nested(line yamlLine, indent int) (*structNode, error) {
+	p.pos++
+	if p.pos < len(p.lines) {
+		next := p.lines[p.pos]
+		if next.indent > indent || (next.indent == indent && isYAMLSequenceEntry(next.text) && !isYAMLSequenceEntry(line.text)) {
+			return p.node(indent)
+		}
+	}
+	return p.scalar(yamlLine{index: line.index, indent: indent, text: ""}, indent), nil
+}
+
+// finish completes a collection once its items are read, failing when the
+// next line is indented as if it still belonged to it
+func (p *yamlParser) finish(node *structNode, indent int) (*structNode, error) {
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, fmt.Errorf("line %d: unexpected indentation", p.lines[p.pos].index+1)
+	}
+	for i := range node.items {
+		if i+1 < len(node.items) {
+			node.items[i].next = node.items[i+1].start
+		} else {
+			node.items[i].next = node.items[i].value.end + 1
+		}
+	}
+	last := node.items[len(node.items)-1]
+	node.end = last.value.end
+	node.split = true
+	if node.kind == '[' {
+		parts := make([]string, len(node.items))
+		for i, item := range node.items {
+			parts[i] = item.value.canonical
+		}
+		node.canonical = "[" + strings.Join(parts, ",") + "]"
+	}
+	node.canonical += p.commentsOf(node)
+	return node, nil
+}
+
+// scalar reads a scalar starting with first, including the lines nested
+// deeper than parentIndent that continue it, such as a block scalar's
+// content
+func (p *yamlParser) scalar(first yamlLine, parentIndent int) *structNode {
+	node := &structNode{start: first.index, end: first.index}
+	parts := []string{first.text}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent > parentIndent {
+		parts = append(parts, p.lines[p.pos].text)
+		node.end = p.lines[p.pos].index
+		p.pos++
+	}
+
+	var canonical []byte
+	switch {
+	case strings.HasPrefix(parts[0], "|") || strings.HasPrefix(parts[0], ">"):
+		canonical, _ = json.Marshal(p.blockScalar(parts[0], node))
+	case len(parts) == 1:
+		canonical = []byte(resolveYAMLScalar(parts[0]))
+	default:
+		canonical, _ = json.Marshal(strings.Join(parts, " "))
+	}
+	node.canonical = string(canonical) + p.commentsOf(node)
+	return node
+}
+
+// blockScalar returns a block scalar's header followed by its content lines
+// as written, less the indentation of the block, so that re-indenting a line
+// within the block is a change. The raw lines are used because content such
+// as "# not a comment" is not a comment inside a block scalar.
+func (p *yamlParser) blockScalar(header string, node *structNode) string {
+	lines := []string{header}
+	blockIndent := -1
+	for i := node.start + 1; i <= node.end; i++ {
+		line := strings.TrimRight(p.raw[i], " \t\r")
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if blockIndent < 0 {
+			blockIndent = len(line) - len(trimmed)
+		}
+		if indent := len(line) - len(trimmed); indent >= blockIndent {
+			lines = append(lines, line[blockIndent:])
+		} else {
+			lines = append(lines, trimmed)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// YAML 1.2 core schema forms of plain scalars that are not strings
+var (
+	yamlIntPattern   = regexp.MustCompile(`^[-+]?[0-9]+$|^0o[0-7]+$|^0x[0-9a-fA-F]+$`)
+	yamlFloatPattern = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+)
+
+// resolveYAMLScalar returns the canonical form of a single-line scalar by
+// its type under the YAML core schema, so that 1 and "1" differ while ~ and
+// null are the same. Quoted scalars are always strings.
+func resolveYAMLScalar(text string) string {
+	if text != "" && (text[0] == '"' || text[0] == '\'') {
+		canonical, _ := json.Marshal(unquoteYAML(text))
+		return string(canonical)
+	}
+	switch text {
+	case "", "~", "null", "Null", "NULL":
+		return "null"
+	case "true", "True", "TRUE":
+		return "true"
+	case "false", "False", "FALSE":
+		return "false"
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return "float:+inf"
+	case "-.inf", "-.Inf", "-.INF":
+		return "float:-inf"
+	case ".nan", ".NaN", ".NAN":
+		return "float:nan"
+	}
+	if yamlIntPattern.MatchString(text) {
+		digits, base := strings.TrimPrefix(text, "+"), 10
+		switch {
+		case strings.HasPrefix(digits, "0o"):
+			digits, base = digits[2:], 8
+		case strings.HasPrefix(digits, "0x"):
+			digits, base = digits[2:], 16
+		}
+		if n, ok := new(big.Int).SetString(digits, base); ok {
+			return n.String()
+		}
+	}
+	if yamlFloatPattern.MatchString(text) {
+		if f, err := strconv.ParseFloat(text, 64); err == nil {
+			// Keep floats apart from integers of the same value
+			return "float:" + strconv.FormatFloat(f, 'g', -1, 64)
+		}
+	}
+	canonical, _ := json.Marshal(text)
+	return string(canonical)
+}
+
+func (p *yamlParser) commentsOf(node *structNode) string {
+	if p.ignoreComments {
+		return ""
+	}
+	comments := yamlComments(p.raw[node.start : node.end+1])
+	if comments == "" {
+		return ""
+	}
+	return "\x00" + comments
+}
+
+// keyYAMLDocuments keys the documents of two files so they can be paired.
+// When every document is a mapping with a kind and a name, they are keyed
+// by those, as Kubernetes manifests are; otherwise they are paired as a
+// sequence. The files' canonical forms are set from their documents.
+func keyYAMLDocuments(left, right *structNode) {
+	identified := true
+	for _, file := range []*structNode{left, right} {
+		for _, item := range file.items {
+			if _, ok := yamlDocumentIdentity(item.value); !ok {
+				identified = false
+			}
+		}
+	}
+
+	for _, file := range []*structNode{left, right} {
+		seen := make(map[string]int)
+		parts := make([]string, len(file.items))
+		for i := range file.items {
+			item := &file.items[i]
+			parts[i] = item.value.canonical
+			item.key = item.value.canonical
+			if identified {
+				item.key, _ = yamlDocumentIdentity(item.value)
+				seen[item.key]++
+				if seen[item.key] > 1 {
+					item.key = fmt.Sprintf("%s#%d", item.key, seen[item.key])
+				}
+			}
+		}
+		if identified {
+			file.kind = '{'
+			sort.Strings(parts)
+		}
+		file.canonical = "[" + strings.Join(parts, ",") + "]"
+	}
+}
+
+// yamlDocumentIdentity returns a document's kind, namespace, and name
+func yamlDocumentIdentity(doc *structNode) (string, bool) {
+	field := func(node *structNode, key string) *structNode {
+		if node == nil || node.kind != '{' {
+			return nil
+		}
+		for _, item := range node.items {
+			if item.key == key {
+				return item.value
+			}
+		}
+		return nil
+	}
+	text := func(node *structNode) string {
+		if node == nil || node.kind != 0 {
+			return ""
+		}
+		// Leave out the comments a canonical form may carry
+		canonical, _, _ := strings.Cut(node.canonical, "\x00")
+		return canonical
+	}
+
+	kind := text(field(doc, "kind"))
+	metadata := field(doc, "metadata")
+	name := text(field(metadata, "name"))
+	if kind == "" || name == "" {
+		return "", false
+	}
+	return kind + "/" + text(field(metadata, "namespace")) + "/" + name, true
+}
@@ -0,0 +1,216 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// changedLines returns the lines of a diff other than "same" lines
+func changedLines(result *DiffResult) []DiffLine {
+	var changed []DiffLine
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			changed = append(changed, line)
+		}
+	}
+	return changed
+}
+
+func TestSemanticYAMLDiff(t *testing.T) {
+	left := strings.Split(`# CI configuration
+name: build
+on:
+  push:
+    branches:
+      - main
+jobs:
+  test:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Test
+        run: go test ./...`, "\n")
+
+	check := func(t *testing.T, right []string, ignoreComments bool) *DiffResult {
+		t.Helper()
+		result, ok := SemanticYAMLDiff(left, right, NewLCSDefault(), ignoreComments)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		leftNumbers, rightNumbers := lineNumbers(result)
+		if !coversEveryLine(leftNumbers, len(left)) || !coversEveryLine(rightNumbers, len(right)) {
+			t.Fatalf("Expected every line once, got %v and %v", leftNumbers, rightNumbers)
+		}
+		return result
+	}
+
+	t.Run("reordered keys and indentation", func(t *testing.T) {
+		right := strings.Split(`# CI configuration
+jobs:
+    test:
+        steps:
+        -   uses: actions/checkout@v4
+        -   run: go test ./...
+            name: Test
+        runs-on: "ubuntu-latest"
+on:
+    push:
+        branches: [main]
+name: build`, "\n")
+		result := check(t, right, false)
+		// Only the flow sequence is compared as text
+		changed := changedLines(result)
+		if len(changed) == 0 || len(changed) > 3 {
+			t.Errorf("Expected only the branches to differ, got %+v", changed)
+		}
+		for _, line := range changed {
+			if !strings.Contains(line.LeftLine+line.RightLine, "branches") && !strings.Contains(line.LeftLine, "- main") {
+				t.Errorf("Unexpected change %+v", line)
+			}
+		}
+	})
+
+	t.Run("changed value", func(t *testing.T) {
+		right := strings.Split(strings.Replace(strings.Join(left, "\n"), "ubuntu-latest", "macos-latest", 1), "\n")
+		changed := changedLines(check(t, right, false))
+		if len(changed) != 1 || changed[0].Type != "modified" || changed[0].LeftNumber != 9 {
+			t.Errorf("Expected only runs-on to be modified, got %+v", changed)
+		}
+	})
+
+	t.Run("comments", func(t *testing.T) {
+		right := append([]string{"# Continuous integration"}, left[1:]...)
+		right[2] += " # when pushed"
+		if changed := changedLines(check(t, right, false)); len(changed) != 4 {
+			t.Errorf("Expected both changed comment lines, got %+v", changed)
+		}
+		if changed := changedLines(check(t, right, true)); len(changed) != 0 {
+			t.Errorf("Expected comments to be ignored, got %+v", changed)
+		}
+	})
+
+	t.Run("not YAML it can follow", func(t *testing.T) {
+		if _, ok := SemanticYAMLDiff(left, []string{"a:", "\tb: 1"}, NewLCSDefault(), false); ok {
+			t.Error("Expected tab indentation to fall back")
+		}
+		if _, ok := SemanticYAMLDiff(left, []string{"  a: 1", "b: 2"}, NewLCSDefault(), false); ok {
+			t.Error("Expected inconsistent indentation to fall back")
+		}
+	})
+}
+
+func TestSemanticYAMLDiff_Scalars(t *testing.T) {
+	changes := func(t *testing.T, left, right string) []DiffLine {
+		t.Helper()
+		result, ok := SemanticYAMLDiff(strings.Split(left, "\n"), strings.Split(right, "\n"), NewLCSDefault(), false)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		return changedLines(result)
+	}
+
+	for _, tt := range []struct {
+		name, left, right string
+		changed           bool
+	}{
+		{"integer and string", "replicas: 1", `replicas: "1"`, true},
+		{"boolean and string", "enabled: true", "enabled: 'true'", true},
+		{"null spellings", "value: ~", "value: null", false},
+		{"empty and null", "value:", "value: null", false},
+		{"boolean spellings", "enabled: true", "enabled: True", false},
+		{"integer spellings", "mode: 0x1ff", "mode: 511", false},
+		{"decimal with a leading zero", "mode: 010", "mode: 8", true},
+		{"integer and float", "ratio: 1", "ratio: 1.0", true},
+		{"float spellings", "ratio: 1.50", "ratio: 1.5", false},
+		{"quote styles", `name: 'web'`, `name: "web"`, false},
+		{"plain and quoted string", "name: web", `name: "web"`, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if changed := changes(t, tt.left, tt.right); (len(changed) > 0) != tt.changed {
+				t.Errorf("Expected changed=%v, got %+v", tt.changed, changed)
+			}
+		})
+	}
+
+	t.Run("block scalar indentation", func(t *testing.T) {
+		left := "run: |\n  if true; then\n    echo ok\n  fi"
+		changed := changes(t, left, "run: |\n  if true; then\n  echo ok\n  fi")
+		if len(changed) == 0 {
+			t.Error("Expected re-indenting a line of the block to be a change")
+		}
+		if changed := changes(t, left, "run: |\n    if true; then\n      echo ok\n    fi"); len(changed) != 0 {
+			t.Errorf("Expected re-indenting the whole block to be no change, got %+v", changed)
+		}
+	})
+
+	t.Run("block scalar content that looks like a comment", func(t *testing.T) {
+		result, ok := SemanticYAMLDiff([]string{"run: |", "  echo a # one"}, []string{"run: |", "  echo a # two"}, NewLCSDefault(), true)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		if changed := changedLines(result); len(changed) == 0 {
+			t.Error("Expected a change inside the block to be reported with comments ignored")
+		}
+	})
+}
+
+func TestSemanticYAMLDiff_Documents(t *testing.T) {
+	left := strings.Split(`apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  port: 80
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 2`, "\n")
+
+	t.Run("matched by kind and name", func(t *testing.T) {
+		right := strings.Split(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  port: 80`, "\n")
+		result, ok := SemanticYAMLDiff(left, right, NewLCSDefault(), false)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		changed := changedLines(result)
+		if len(changed) != 1 || changed[0].LeftNumber != 13 || changed[0].RightNumber != 6 {
+			t.Errorf("Expected only the replicas to change, got %+v", changed)
+		}
+	})
+
+	t.Run("matched in order", func(t *testing.T) {
+		l := []string{"a: 1", "---", "title: the second document"}
+		r := []string{"a: 1", "---", "title: the second document, edited", "---", "c: 4"}
+		result, ok := SemanticYAMLDiff(l, r, NewLCSDefault(), false)
+		if !ok {
+			t.Fatal("Expected both files to parse")
+		}
+		changed := changedLines(result)
+		if len(changed) != 3 || changed[0].Type != "modified" || changed[1].RightLine != "---" || changed[2].RightLine != "c: 4" {
+			t.Errorf("Expected the second document modified and a third added, got %+v", changed)
+		}
+	})
+}
+
+func TestIsYAMLPath(t *testing.T) {
+	for path, expected := range map[string]bool{"ci.yml": true, "deploy.YAML": true, "data.json": false} {
+		if IsYAMLPath(path) != expected {
+			t.Errorf("IsYAMLPath(%q) = %v, expected %v", path, !expected, expected)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Dictionary is a set of correctly spelled words for one language
+type Dictionary struct {
+	words map[string]bool
+}
+
+// SpellingIssue is a word added by the diff that is not in the dictionary
+type SpellingIssue struct {
+	Line  int    `json:"line"`  // 1-based right line number
+	Word  string `json:"word"`  // the misspelled word
+	Start int    `json:"start"` // character (rune) offset within the line
+	End   int    `json:"end"`   // exclusive character offset
+}
+
+// ParseDictionary reads a word list with one word per line. Hunspell .dic
+// files are accepted too: the leading word count and the affix flags after
+// a slash are ignored. Lines starting with '#' are comments.
+func ParseDictionary(lines []string) *Dictionary {
+	dictionary := &Dictionary{words: make(map[string]bool, len(lines))}
+	for i, line := range lines {
+		word := strings.TrimSpace(line)
+		if slash := strings.IndexByte(word, '/'); slash >= 0 {
+			word = word[:slash]
+		}
+		if word == "" || strings.HasPrefix(word, "#") {
+			continue
+		}
+		if _, err := strconv.Atoi(word); err == nil && i == 0 {
+			continue
+		}
+		dictionary.words[word] = true
+	}
+	return dictionary
+}
+
+// Contains reports whether a word is spelled correctly. Capitalized forms of
+// dictionary words are accepted, so sentence-initial words are not flagged.
+func (d *Dictionary) Contains(word string) bool {
+	if d.words[word] || d.words[strings.ToLower(word)] {
+		return true
+	}
+	// Accept possessives of known words
+	if base, ok := strings.CutSuffix(word, "'s"); ok {
+		return d.Contains(base)
+	}
+	return false
+}
+
+// Len returns the number of words in the dictionary
+func (d *Dictionary) Len() int {
+	return len(d.words)
+}
+
+// SpellCheck returns the words on the right side of a diff that were added by
+// it and are not in the dictionary. Added lines are checked in full and
+// modified lines only in their added words, so existing typos are not
+// reported again.
+func SpellCheck(result *DiffResult, dictionary *Dictionary) []SpellingIssue {
+	issues := []SpellingIssue{}
+	if result == nil || dictionary == nil {
+		return issues
+	}
+	for _, line := range result.Lines {
+		switch line.Type {
+		case "added":
+			issues = appendSpellingIssues(issues, line.RightNumber, line.RightLine, 0, dictionary)
+		case "modified":
+			offset := 0
+			for _, segment := range line.Segments {
+				if segment.Type == "removed" {
+					continue
+				}
+				if segment.Type == "added" {
+					issues = appendSpellingIssues(issues, line.RightNumber, segment.Text, offset, dictionary)
+				}
+				offset += len([]rune(segment.Text))
+			}
+		}
+	}
+	return issues
+}
+
+// appendSpellingIssues checks the words of text, which starts at the given
+// character offset of a line
+func appendSpellingIssues(issues []SpellingIssue, lineNumber int, text string, offset int, dictionary *Dictionary) []SpellingIssue {
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && isSpellingRune(runes, i) {
+			i++
+		}
+		word := string(runes[start:i])
+		normalized := strings.ReplaceAll(word, "’", "'")
+		if isCheckableWord(normalized) && !dictionary.Contains(normalized) {
+			issues = append(issues, SpellingIssue{
+				Line:  lineNumber,
+				Word:  word,
+				Start: offset + start,
+				End:   offset + i,
+			})
+		}
+	}
+	return issues
+}
+
+// isSpellingRune reports whether runes[i] continues a word. Apostrophes
+// continue a word only between letters, as in "don't".
+func isSpellingRune(runes []rune, i int) bool {
+	r := runes[i]
+	if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+		return true
+	}
+	return (r == '\'' || r == '’') && i+1 < len(runes) && unicode.IsLetter(runes[i+1])
+}
+
+// isCheckableWord skips words that are likely code or abbreviations rather
+// than prose: single letters, words with digits or underscores, acronyms,
+// and camelCase identifiers
+func isCheckableWord(word string) bool {
+	runes := []rune(word)
+	if len(runes) < 2 {
+		return false
+	}
+	for i, r := range runes {
+		if unicode.IsDigit(r) || r == '_' || (i > 0 && unicode.IsUpper(r)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDictionary(t *testing.T) {
+	dictionary := ParseDictionary([]string{"3", "color/MS", "# comment", "the", "", "don't"})
+	if dictionary.Len() != 3 {
+		t.Errorf("Expected 3 words, got %d", dictionary.Len())
+	}
+	for _, word := range []string{"color", "Color", "the", "don't", "color's"} {
+		if !dictionary.Contains(word) {
+			t.Errorf("Expected %q to be spelled correctly", word)
+		}
+	}
+	if dictionary.Contains("colour") {
+		t.Error("Expected colour to be missing")
+	}
+}
+
+func TestSpellCheck(t *testing.T) {
+	dictionary := ParseDictionary([]string{"the", "quick", "brown", "fox", "jumps", "over", "dog", "lazy"})
+	left := []string{"the quick brwn fox", "unchanged"}
+	right := []string{"the quikc brwn fox", "unchanged", "jumps ovr the HTTP lazyDog x2 dog"}
+
+	result := NewLCSDefault().ComputeDiff(left, right)
+	issues := SpellCheck(result, dictionary)
+	expected := []SpellingIssue{
+		// The pre-existing typo "brwn" is not reported
+		{Line: 1, Word: "quikc", Start: 4, End: 9},
+		{Line: 3, Word: "ovr", Start: 6, End: 9},
+	}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, issues)
+	}
+}
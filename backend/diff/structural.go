@@ -0,0 +1,192 @@
+package diff
+
+// structNode is a parsed value of a structured file, such as JSON or YAML,
+// with the lines it spans
+type structNode struct {
+	kind      byte   // '{' for mappings, '[' for sequences, 0 for scalars
+	start     int    // 0-based first line
+	end       int    // 0-based last line
+	canonical string // the value with keys sorted and formatting removed
+	items     []structItem
+	// split is set when each item is on lines of its own, not shared with
+	// another item or with the container's brackets, so the container can
+	// be compared item by item
+	split bool
+}
+
+// structItem is a mapping member or sequence element. Its lines run from
+// start to the line before next, taking in the blank and comment lines
+// after the value.
+type structItem struct {
+	key   string // member name, or the canonical value of an element
+	start int
+	next  int
+	value *structNode
+}
+
+// structDiffer accumulates the lines of a structural comparison. Lines that
+// frame values, such as keys, separators, and brackets, are paired as "same"
+// when what is significant in them matches, as reported by framing, which
+// may be nil for formats where nothing is, such as JSON. Changed values are
+// compared by compareLines, or line by line when it is nil.
+type structDiffer struct {
+	leftLines, rightLines []string
+	algorithm             Algorithm
+	framing               func(lines []string) string
+	compareLines          func(left, right []string) *DiffResult
+	lines                 []DiffLine
+}
+
+// compare appends the comparison of two documents and returns the result
+func (d *structDiffer) compare(left, right *structNode) *DiffResult {
+	d.frame(0, left.start, 0, right.start)
+	d.value(left, right)
+	d.frame(left.end+1, len(d.leftLines), right.end+1, len(d.rightLines))
+	return detectModifications(&DiffResult{Lines: d.lines}, configOf(d.algorithm))
+}
+
+// descendable reports whether two values are containers of the same kind
+// that can be compared item by item
+func descendable(left, right *structNode) bool {
+	return left.kind != 0 && left.kind == right.kind && left.split && right.split
+}
+
+// value compares two values, descending into containers that are split
+// into items, even equal ones so their lines pair up by key, and diffing
+// any others line by line
+func (d *structDiffer) value(left, right *structNode) {
+	switch {
+	case !descendable(left, right) && left.canonical == right.canonical:
+		d.same(left.start, left.end+1, right.start, right.end+1)
+	case !descendable(left, right):
+		d.lineDiff(left.start, left.end+1, right.start, right.end+1)
+	default:
+		d.frame(left.start, left.items[0].start, right.start, right.items[0].start)
+		if left.kind == '{' {
+			d.members(left.items, right.items)
+		} else {
+			d.elements(left.items, right.items)
+		}
+		d.frame(left.items[len(left.items)-1].next, left.end+1, right.items[len(right.items)-1].next, right.end+1)
+	}
+}
+
+// members pairs mapping members by key
+func (d *structDiffer) members(left, right []structItem) {
+	rightIndex := make(map[string]int, len(right))
+	for i, item := range right {
+		rightIndex[item.key] = i
+	}
+	leftKeys := make(map[string]bool, len(left))
+	for _, item := range left {
+		leftKeys[item.key] = true
+	}
+	// emitRightOnly adds the members only on the right, starting at index
+	// i, until the next one the left mapping also has
+	emitRightOnly := func(i int) {
+		for ; i < len(right) && !leftKeys[right[i].key]; i++ {
+			d.lineDiff(0, 0, right[i].start, right[i].next)
+		}
+	}
+
+	emitRightOnly(0)
+	for _, item := range left {
+		match, ok := rightIndex[item.key]
+		if !ok {
+			d.lineDiff(item.start, item.next, 0, 0)
+			continue
+		}
+		d.item(item, right[match])
+		emitRightOnly(match + 1)
+	}
+}
+
+// elements aligns sequence elements by diffing their canonical values. In
+// each run of changed elements, those removed and added are paired in order
+// and compared, so an element changed in place shows only what changed.
+func (d *structDiffer) elements(left, right []structItem) {
+	keys := func(items []structItem) []string {
+		keys := make([]string, len(items))
+		for i, item := range items {
+			keys[i] = item.key
+		}
+		return keys
+	}
+
+	var removed, added []structItem
+	flush := func() {
+		paired := min(len(removed), len(added))
+		for i := 0; i < paired; i++ {
+			d.item(removed[i], added[i])
+		}
+		for _, item := range removed[paired:] {
+			d.lineDiff(item.start, item.next, 0, 0)
+		}
+		for _, item := range added[paired:] {
+			d.lineDiff(0, 0, item.start, item.next)
+		}
+		removed, added = removed[:0], added[:0]
+	}
+	for _, line := range d.algorithm.ComputeDiff(keys(left), keys(right)).Lines {
+		switch line.Type {
+		case "removed":
+			removed = append(removed, left[line.LeftNumber-1])
+		case "added":
+			added = append(added, right[line.RightNumber-1])
+		default:
+			flush()
+			d.item(left[line.LeftNumber-1], right[line.RightNumber-1])
+		}
+	}
+	flush()
+}
+
+// item compares a matched member or element: the lines before its value,
+// such as a key on a line of its own, the value, and the lines after. A
+// changed value compared line by line takes the lines before it along, so
+// a key is diffed with the value it introduces.
+func (d *structDiffer) item(left, right structItem) {
+	if !descendable(left.value, right.value) && left.value.canonical != right.value.canonical {
+		d.lineDiff(left.start, left.value.end+1, right.start, right.value.end+1)
+	} else {
+		d.frame(left.start, left.value.start, right.start, right.value.start)
+		d.value(left.value, right.value)
+	}
+	d.frame(left.value.end+1, left.next, right.value.end+1, right.next)
+}
+
+// frame compares lines around values, pairing them as "same" unless what
+// is significant in them differs
+func (d *structDiffer) frame(leftStart, leftEnd, rightStart, rightEnd int) {
+	if d.framing != nil && d.framing(d.leftLines[leftStart:leftEnd]) != d.framing(d.rightLines[rightStart:rightEnd]) {
+		d.lineDiff(leftStart, leftEnd, rightStart, rightEnd)
+		return
+	}
+	d.same(leftStart, leftEnd, rightStart, rightEnd)
+}
+
+// lineDiff appends a comparison of the given ranges of each file
+func (d *structDiffer) lineDiff(leftStart, leftEnd, rightStart, rightEnd int) {
+	compare := d.algorithm.ComputeDiff
+	if d.compareLines != nil {
+		compare = d.compareLines
+	}
+	part := compare(d.leftLines[leftStart:leftEnd], d.rightLines[rightStart:rightEnd])
+	OffsetLineNumbers(part, leftStart, rightStart)
+	d.lines = append(d.lines, part.Lines...)
+}
+
+// same pairs the lines of equivalent ranges of each file in order, padding
+// the shorter range with "same" lines numbered 0
+func (d *structDiffer) same(leftStart, leftEnd, rightStart, rightEnd int) {
+	for l, r := leftStart, rightStart; l < leftEnd || r < rightEnd; l, r = l+1, r+1 {
+		line := DiffLine{Type: "same"}
+		if l < leftEnd {
+			line.LeftLine, line.LeftNumber = d.leftLines[l], l+1
+		}
+		if r < rightEnd {
+			line.RightLine, line.RightNumber = d.rightLines[r], r+1
+		}
+		d.lines = append(d.lines, line)
+	}
+}
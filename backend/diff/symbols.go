@@ -0,0 +1,328 @@
+package diff
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Symbol kinds
+const (
+	SymbolFunction = "function"
+	SymbolMethod   = "method"
+	SymbolClass    = "class"
+	SymbolType     = "type"
+	SymbolSection  = "section"
+)
+
+// Symbol is a function, type, or document section and the lines it spans
+type Symbol struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	StartLine int    `json:"startLine"` // 1-based, inclusive
+	EndLine   int    `json:"endLine"`   // 1-based, inclusive
+	Depth     int    `json:"depth"`     // number of enclosing symbols
+}
+
+// Patterns that start a symbol, by language
+var (
+	goFuncPattern = regexp.MustCompile(`^func\s+(?:\(\s*(?:\w+\s+)?\*?(\w+)[^)]*\)\s*)?(\w+)`)
+	goTypePattern = regexp.MustCompile(`^type\s+(\w+)`)
+
+	pythonDefPattern   = regexp.MustCompile(`^(\s*)(?:async\s+)?def\s+(\w+)`)
+	pythonClassPattern = regexp.MustCompile(`^(\s*)class\s+(\w+)`)
+
+	jsFunctionPattern = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+(\w+)`)
+	jsClassPattern    = regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+(\w+)`)
+	jsTypePattern     = regexp.MustCompile(`^\s*(?:export\s+)?(?:interface|enum)\s+(\w+)`)
+	jsArrowPattern    = regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let|var)\s+(\w+)\s*(?::[^=]+)?=\s*(?:async\s+)?(?:\([^)]*\)|\w+)\s*(?::[^=]+)?=>`)
+	jsMethodPattern   = regexp.MustCompile(`^\s+(?:(?:public|private|protected|static|async|get|set)\s+)*(\w+)\s*\([^)]*\)\s*(?::[^{]+)?\{`)
+
+	markdownHeadingPattern = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+)
+
+// jsKeywords are words that look like method names when followed by parentheses
+var jsKeywords = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"function": true, "return": true, "with": true,
+}
+
+// FindSymbols lists the functions, types, and sections of a file in the
+// given language, ordered by start line. Unrecognized languages have none.
+func FindSymbols(lines []string, language string) []Symbol {
+	var symbols []Symbol
+	switch language {
+	case LanguageGo:
+		symbols = findGoSymbols(lines)
+	case LanguagePython:
+		symbols = findPythonSymbols(lines)
+	case LanguageJavaScript:
+		symbols = findJavaScriptSymbols(lines)
+	case LanguageMarkdown:
+		symbols = findMarkdownSymbols(lines)
+	}
+	if symbols == nil {
+		return []Symbol{}
+	}
+
+	sort.SliceStable(symbols, func(i, j int) bool {
+		return symbols[i].StartLine < symbols[j].StartLine
+	})
+	for i := range symbols {
+		for j := 0; j < i; j++ {
+			if symbols[j].StartLine <= symbols[i].StartLine && symbols[j].EndLine >= symbols[i].EndLine {
+				symbols[i].Depth++
+			}
+		}
+	}
+	return symbols
+}
+
+// FindSymbol returns the first symbol with the given name
+func FindSymbol(symbols []Symbol, name string) (Symbol, bool) {
+	for _, symbol := range symbols {
+		if symbol.Name == name {
+			return symbol, true
+		}
+	}
+	return Symbol{}, false
+}
+
+// findGoSymbols finds top-level functions, methods, and types
+func findGoSymbols(lines []string) []Symbol {
+	symbols := []Symbol{}
+	for i, line := range lines {
+		if match := goFuncPattern.FindStringSubmatch(line); match != nil {
+			symbol := Symbol{Name: match[2], Kind: SymbolFunction, StartLine: i + 1}
+			if match[1] != "" {
+				symbol.Name = match[1] + "." + match[2]
+				symbol.Kind = SymbolMethod
+			}
+			symbol.EndLine = braceBlockEnd(lines, i)
+			symbols = append(symbols, symbol)
+		} else if match := goTypePattern.FindStringSubmatch(line); match != nil {
+			symbols = append(symbols, Symbol{
+				Name:      match[1],
+				Kind:      SymbolType,
+				StartLine: i + 1,
+				EndLine:   braceBlockEnd(lines, i),
+			})
+		}
+	}
+	return symbols
+}
+
+// findPythonSymbols finds classes and functions, which end where the
+// indentation returns to their own level
+func findPythonSymbols(lines []string) []Symbol {
+	symbols := []Symbol{}
+	for i, line := range lines {
+		kind := SymbolFunction
+		match := pythonDefPattern.FindStringSubmatch(line)
+		if match == nil {
+			kind = SymbolClass
+			match = pythonClassPattern.FindStringSubmatch(line)
+		}
+		if match == nil {
+			continue
+		}
+
+		indent := len(match[1])
+		if kind == SymbolFunction && indent > 0 && enclosingPythonClass(symbols, lines, i, indent) {
+			kind = SymbolMethod
+		}
+		symbols = append(symbols, Symbol{
+			Name:      match[2],
+			Kind:      kind,
+			StartLine: i + 1,
+			EndLine:   indentedBlockEnd(lines, i, indent),
+		})
+	}
+	return symbols
+}
+
+// enclosingPythonClass returns whether the innermost symbol enclosing a line
+// at the given indentation is a class
+func enclosingPythonClass(symbols []Symbol, lines []string, index, indent int) bool {
+	for j := len(symbols) - 1; j >= 0; j-- {
+		symbol := symbols[j]
+		if symbol.EndLine < index+1 {
+			continue
+		}
+		if leadingWidth(lines[symbol.StartLine-1]) < indent {
+			return symbol.Kind == SymbolClass
+		}
+	}
+	return false
+}
+
+// indentedBlockEnd returns the 1-based last line of a block whose header is
+// at index, ending before the next non-blank line indented no deeper
+func indentedBlockEnd(lines []string, index, indent int) int {
+	end := index + 1
+	for j := index + 1; j < len(lines); j++ {
+		if strings.TrimSpace(lines[j]) == "" {
+			continue
+		}
+		if leadingWidth(lines[j]) <= indent {
+			break
+		}
+		end = j + 1
+	}
+	return end
+}
+
+// leadingWidth returns the number of leading whitespace characters of a line
+func leadingWidth(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
+
+// findJavaScriptSymbols finds functions, classes, interfaces, arrow
+// functions, and class methods
+func findJavaScriptSymbols(lines []string) []Symbol {
+	symbols := []Symbol{}
+	for i, line := range lines {
+		symbol := Symbol{StartLine: i + 1}
+		if match := jsFunctionPattern.FindStringSubmatch(line); match != nil {
+			symbol.Name, symbol.Kind = match[1], SymbolFunction
+		} else if match := jsClassPattern.FindStringSubmatch(line); match != nil {
+			symbol.Name, symbol.Kind = match[1], SymbolClass
+		} else if match := jsTypePattern.FindStringSubmatch(line); match != nil {
+			symbol.Name, symbol.Kind = match[1], SymbolType
+		} else if match := jsArrowPattern.FindStringSubmatch(line); match != nil {
+			symbol.Name, symbol.Kind = match[1], SymbolFunction
+		} else if match := jsMethodPattern.FindStringSubmatch(line); match != nil && !jsKeywords[match[1]] {
+			class, ok := innermostClass(symbols, i+1)
+			if !ok {
+				continue
+			}
+			symbol.Name, symbol.Kind = class+"."+match[1], SymbolMethod
+		} else {
+			continue
+		}
+		symbol.EndLine = braceBlockEnd(lines, i)
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// innermostClass returns the name of the innermost class spanning a line
+func innermostClass(symbols []Symbol, line int) (string, bool) {
+	for j := len(symbols) - 1; j >= 0; j-- {
+		if symbols[j].Kind == SymbolClass && symbols[j].StartLine < line && symbols[j].EndLine >= line {
+			return symbols[j].Name, true
+		}
+	}
+	return "", false
+}
+
+// findMarkdownSymbols finds headings, each spanning the lines up to the next
+// heading of the same or a higher level
+func findMarkdownSymbols(lines []string) []Symbol {
+	type heading struct {
+		symbol Symbol
+		level  int
+	}
+	var headings []heading
+	inFence := false
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := markdownHeadingPattern.FindStringSubmatch(line); match != nil {
+			headings = append(headings, heading{
+				symbol: Symbol{Name: match[2], Kind: SymbolSection, StartLine: i + 1},
+				level:  len(match[1]),
+			})
+		}
+	}
+
+	symbols := make([]Symbol, len(headings))
+	for i, h := range headings {
+		h.symbol.EndLine = len(lines)
+		for _, next := range headings[i+1:] {
+			if next.level <= h.level {
+				h.symbol.EndLine = next.symbol.StartLine - 1
+				break
+			}
+		}
+		symbols[i] = h.symbol
+	}
+	return symbols
+}
+
+// braceBlockEnd returns the 1-based line on which the first brace opened at
+// or after index is closed. A declaration without braces on its first line
+// ends on that line.
+func braceBlockEnd(lines []string, index int) int {
+	depth := 0
+	opened := false
+	scanner := braceScanner{}
+	for j := index; j < len(lines); j++ {
+		for _, delta := range scanner.braces(lines[j]) {
+			depth += delta
+			if delta > 0 {
+				opened = true
+			}
+			if opened && depth == 0 {
+				return j + 1
+			}
+		}
+		if !opened {
+			return index + 1
+		}
+	}
+	return len(lines)
+}
+
+// braceScanner tracks strings and comments across lines so braces inside
+// them are not counted
+type braceScanner struct {
+	inBlockComment bool
+	quote          rune // open multi-line string delimiter, e.g. a backtick
+}
+
+// braces returns +1 for each opening and -1 for each closing brace in code
+func (s *braceScanner) braces(line string) []int {
+	var deltas []int
+	runes := []rune(line)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case s.inBlockComment:
+			if r == '*' && i+1 < len(runes) && runes[i+1] == '/' {
+				s.inBlockComment = false
+				i++
+			}
+		case s.quote != 0:
+			if r == '\\' {
+				i++
+			} else if r == s.quote {
+				s.quote = 0
+			}
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '/':
+			return deltas
+		case r == '#' && i == 0:
+			return deltas
+		case r == '/' && i+1 < len(runes) && runes[i+1] == '*':
+			s.inBlockComment = true
+			i++
+		case r == '"' || r == '\'' || r == '`':
+			s.quote = r
+		case r == '{':
+			deltas = append(deltas, 1)
+		case r == '}':
+			deltas = append(deltas, -1)
+		}
+	}
+	// Only backtick strings span lines
+	if s.quote == '"' || s.quote == '\'' {
+		s.quote = 0
+	}
+	return deltas
+}
@@ -0,0 +1,120 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindSymbols(t *testing.T) {
+	t.Run("Go functions, methods, and types", func(t *testing.T) {
+		lines := []string{
+			"package main",
+			"",
+			"type Server struct {",
+			"\tname string",
+			"}",
+			"",
+			"func (s *Server) Start() error {",
+			"\tif s.name == \"}\" {",
+			"\t\treturn nil",
+			"\t}",
+			"\treturn nil",
+			"}",
+			"",
+			"func main() {",
+			"}",
+		}
+		expected := []Symbol{
+			{Name: "Server", Kind: SymbolType, StartLine: 3, EndLine: 5},
+			{Name: "Server.Start", Kind: SymbolMethod, StartLine: 7, EndLine: 12},
+			{Name: "main", Kind: SymbolFunction, StartLine: 14, EndLine: 15},
+		}
+		if got := FindSymbols(lines, LanguageGo); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("Python classes and methods", func(t *testing.T) {
+		lines := []string{
+			"class Greeter:",
+			"    def greet(self):",
+			"        return 'hi'",
+			"",
+			"    def wave(self):",
+			"        pass",
+			"",
+			"def main():",
+			"    Greeter().greet()",
+		}
+		expected := []Symbol{
+			{Name: "Greeter", Kind: SymbolClass, StartLine: 1, EndLine: 6},
+			{Name: "greet", Kind: SymbolMethod, StartLine: 2, EndLine: 3, Depth: 1},
+			{Name: "wave", Kind: SymbolMethod, StartLine: 5, EndLine: 6, Depth: 1},
+			{Name: "main", Kind: SymbolFunction, StartLine: 8, EndLine: 9},
+		}
+		if got := FindSymbols(lines, LanguagePython); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("JavaScript classes and functions", func(t *testing.T) {
+		lines := []string{
+			"export class Cart {",
+			"  add(item) {",
+			"    if (item) {",
+			"      this.items.push(item);",
+			"    }",
+			"  }",
+			"}",
+			"const total = (cart) => {",
+			"  return 0;",
+			"};",
+		}
+		expected := []Symbol{
+			{Name: "Cart", Kind: SymbolClass, StartLine: 1, EndLine: 7},
+			{Name: "Cart.add", Kind: SymbolMethod, StartLine: 2, EndLine: 6, Depth: 1},
+			{Name: "total", Kind: SymbolFunction, StartLine: 8, EndLine: 10},
+		}
+		if got := FindSymbols(lines, LanguageJavaScript); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("Markdown sections", func(t *testing.T) {
+		lines := []string{
+			"# Guide",
+			"intro",
+			"## Install",
+			"```",
+			"# not a heading",
+			"```",
+			"## Usage",
+			"# Appendix",
+		}
+		expected := []Symbol{
+			{Name: "Guide", Kind: SymbolSection, StartLine: 1, EndLine: 7},
+			{Name: "Install", Kind: SymbolSection, StartLine: 3, EndLine: 6, Depth: 1},
+			{Name: "Usage", Kind: SymbolSection, StartLine: 7, EndLine: 7, Depth: 1},
+			{Name: "Appendix", Kind: SymbolSection, StartLine: 8, EndLine: 8},
+		}
+		if got := FindSymbols(lines, LanguageMarkdown); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Expected %+v, got %+v", expected, got)
+		}
+	})
+
+	t.Run("unrecognized language", func(t *testing.T) {
+		if got := FindSymbols([]string{"func main() {}"}, ""); len(got) != 0 {
+			t.Errorf("Expected no symbols, got %+v", got)
+		}
+	})
+}
+
+func TestFindSymbol(t *testing.T) {
+	symbols := []Symbol{{Name: "a", StartLine: 1}, {Name: "b", StartLine: 4}}
+	if symbol, ok := FindSymbol(symbols, "b"); !ok || symbol.StartLine != 4 {
+		t.Errorf("Expected to find b at line 4, got %+v (%v)", symbol, ok)
+	}
+	if _, ok := FindSymbol(symbols, "c"); ok {
+		t.Error("Expected c not to be found")
+	}
+}
@@ -0,0 +1,25 @@
+package diff
+
+import "strings"
+
+// ExpandTabs replaces each tab with spaces up to the next multiple of
+// tabWidth columns. A tabWidth below 1 leaves the line unchanged.
+func ExpandTabs(line string, tabWidth int) string {
+	if tabWidth < 1 || !strings.Contains(line, "\t") {
+		return line
+	}
+
+	var builder strings.Builder
+	column := 0
+	for _, r := range line {
+		if r == '\t' {
+			spaces := tabWidth - column%tabWidth
+			builder.WriteString(strings.Repeat(" ", spaces))
+			column += spaces
+			continue
+		}
+		builder.WriteRune(r)
+		column++
+	}
+	return builder.String()
+}
@@ -0,0 +1,43 @@
+package diff
+
+import "testing"
+
+func TestExpandTabs(t *testing.T) {
+	tests := []struct {
+		line     string
+		width    int
+		expected string
+	}{
+		{"no tabs", 4, "no tabs"},
+		{"\tx", 4, "    x"},
+		{"ab\tx", 4, "ab  x"},
+		{"abcd\tx", 4, "abcd    x"},
+		{"\t\tx", 2, "    x"},
+		{"\tx", 8, "        x"},
+		{"\tx", 0, "\tx"},
+	}
+	for _, tt := range tests {
+		if got := ExpandTabs(tt.line, tt.width); got != tt.expected {
+			t.Errorf("ExpandTabs(%q, %d) = %q, expected %q", tt.line, tt.width, got, tt.expected)
+		}
+	}
+}
+
+func TestLCS_TabWidthSimilarity(t *testing.T) {
+	// Reindenting from tabs to spaces with a small edit is a modification
+	// when tabs are measured at their displayed width
+	left := []string{"\t\t\tvalue := compute(a)"}
+	right := []string{"            value := compute(b)"}
+
+	config := DefaultConfig()
+	result := NewLCS(config).ComputeDiff(left, right)
+	if len(result.Lines) != 1 || result.Lines[0].Type != "modified" {
+		t.Errorf("Expected a modified line with tab width 4, got %+v", result.Lines)
+	}
+
+	config.TabWidth = 8
+	result = NewLCS(config).ComputeDiff(left, right)
+	if len(result.Lines) != 2 {
+		t.Errorf("Expected a removed and added line with tab width 8, got %+v", result.Lines)
+	}
+}
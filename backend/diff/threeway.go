@@ -0,0 +1,123 @@
+package diff
+
+import "slices"
+
+// Types of a chunk in a three-way comparison
+const (
+	ThreeWayUnchanged = "unchanged"
+	ThreeWayLeftOnly  = "left-only"
+	ThreeWayRightOnly = "right-only"
+	ThreeWayBothSame  = "both-same"
+	ThreeWayConflict  = "conflict"
+)
+
+// ThreeWayChunk is a region of the common ancestor with the corresponding
+// lines of both descendants. Starts are 1-based; for an empty side the start
+// is the line before which the lines of the other sides would go.
+type ThreeWayChunk struct {
+	Type       string   `json:"type"`
+	BaseStart  int      `json:"baseStart"`
+	BaseLines  []string `json:"baseLines"`
+	LeftStart  int      `json:"leftStart"`
+	LeftLines  []string `json:"leftLines"`
+	RightStart int      `json:"rightStart"`
+	RightLines []string `json:"rightLines"`
+}
+
+// ThreeWayResult is the comparison of two files with their common ancestor
+type ThreeWayResult struct {
+	Chunks    []ThreeWayChunk `json:"chunks"`
+	Conflicts int             `json:"conflicts"`
+}
+
+// CompareThree compares left and right with their common ancestor base. Each
+// region where either side differs from base is classified as changed on the
+// left only, on the right only, changed the same way on both sides, or
+// conflicting. Lines unchanged on both sides form "unchanged" chunks.
+func CompareThree(base, left, right []string, algorithm Algorithm) *ThreeWayResult {
+	leftMatch := baseMatches(algorithm.ComputeDiff(base, left), len(base))
+	rightMatch := baseMatches(algorithm.ComputeDiff(base, right), len(base))
+
+	result := &ThreeWayResult{Chunks: []ThreeWayChunk{}}
+	b, l, r := 0, 0, 0
+	for b < len(base) || l < len(left) || r < len(right) {
+		// A base line kept at the current position of both sides is stable
+		if b < len(base) && leftMatch[b] == l && rightMatch[b] == r {
+			result.addUnchanged(base[b], b, l, r)
+			b, l, r = b+1, l+1, r+1
+			continue
+		}
+
+		// Otherwise the changed region runs to the next stable line
+		end, leftEnd, rightEnd := len(base), len(left), len(right)
+		for j := b; j < len(base); j++ {
+			if leftMatch[j] >= l && rightMatch[j] >= r {
+				end, leftEnd, rightEnd = j, leftMatch[j], rightMatch[j]
+				break
+			}
+		}
+
+		chunk := ThreeWayChunk{
+			BaseStart:  b + 1,
+			BaseLines:  append([]string{}, base[b:end]...),
+			LeftStart:  l + 1,
+			LeftLines:  append([]string{}, left[l:leftEnd]...),
+			RightStart: r + 1,
+			RightLines: append([]string{}, right[r:rightEnd]...),
+		}
+		leftChanged := !slices.Equal(chunk.BaseLines, chunk.LeftLines)
+		rightChanged := !slices.Equal(chunk.BaseLines, chunk.RightLines)
+		switch {
+		case leftChanged && rightChanged && slices.Equal(chunk.LeftLines, chunk.RightLines):
+			chunk.Type = ThreeWayBothSame
+		case leftChanged && rightChanged:
+			chunk.Type = ThreeWayConflict
+			result.Conflicts++
+		case leftChanged:
+			chunk.Type = ThreeWayLeftOnly
+		case rightChanged:
+			chunk.Type = ThreeWayRightOnly
+		default:
+			chunk.Type = ThreeWayUnchanged
+		}
+		result.Chunks = append(result.Chunks, chunk)
+		b, l, r = end, leftEnd, rightEnd
+	}
+	return result
+}
+
+// addUnchanged appends a stable line, extending the last chunk when it is
+// unchanged too
+func (r *ThreeWayResult) addUnchanged(line string, base, left, right int) {
+	if n := len(r.Chunks); n > 0 && r.Chunks[n-1].Type == ThreeWayUnchanged {
+		last := &r.Chunks[n-1]
+		last.BaseLines = append(last.BaseLines, line)
+		last.LeftLines = append(last.LeftLines, line)
+		last.RightLines = append(last.RightLines, line)
+		return
+	}
+	r.Chunks = append(r.Chunks, ThreeWayChunk{
+		Type:       ThreeWayUnchanged,
+		BaseStart:  base + 1,
+		BaseLines:  []string{line},
+		LeftStart:  left + 1,
+		LeftLines:  []string{line},
+		RightStart: right + 1,
+		RightLines: []string{line},
+	})
+}
+
+// baseMatches maps each base (left side) line of a diff to the 0-based index
+// of the other side's line it matched, or -1 when it was changed
+func baseMatches(result *DiffResult, baseLength int) []int {
+	matches := make([]int, baseLength)
+	for i := range matches {
+		matches[i] = -1
+	}
+	for _, line := range result.Lines {
+		if line.Type == "same" && line.LeftNumber > 0 && line.RightNumber > 0 {
+			matches[line.LeftNumber-1] = line.RightNumber - 1
+		}
+	}
+	return matches
+}
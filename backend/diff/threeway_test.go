@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareThree(t *testing.T) {
+	base := []string{"a", "b", "x", "c", "d", "e", "f"}
+	left := []string{"a", "B", "x", "c", "d", "E", "f", "g"}
+	right := []string{"a", "b", "x", "C", "d", "E", "f", "h"}
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+		result := CompareThree(base, left, right, New(config))
+
+		var types []string
+		for _, chunk := range result.Chunks {
+			types = append(types, chunk.Type)
+		}
+		want := []string{
+			ThreeWayUnchanged, ThreeWayLeftOnly, ThreeWayUnchanged, ThreeWayRightOnly,
+			ThreeWayUnchanged, ThreeWayBothSame, ThreeWayUnchanged, ThreeWayConflict,
+		}
+		if !reflect.DeepEqual(types, want) {
+			t.Errorf("%s: expected chunks %v, got %v", name, want, types)
+			continue
+		}
+		if result.Conflicts != 1 {
+			t.Errorf("%s: expected 1 conflict, got %d", name, result.Conflicts)
+		}
+
+		conflict := result.Chunks[7]
+		if conflict.BaseStart != 8 || len(conflict.BaseLines) != 0 ||
+			!reflect.DeepEqual(conflict.LeftLines, []string{"g"}) || !reflect.DeepEqual(conflict.RightLines, []string{"h"}) {
+			t.Errorf("%s: unexpected conflict: %+v", name, conflict)
+		}
+		if leftOnly := result.Chunks[1]; leftOnly.LeftStart != 2 || leftOnly.RightStart != 2 || leftOnly.RightLines[0] != "b" {
+			t.Errorf("%s: unexpected left-only chunk: %+v", name, leftOnly)
+		}
+	}
+}
+
+func TestCompareThree_Identical(t *testing.T) {
+	lines := []string{"one", "two"}
+	result := CompareThree(lines, lines, lines, NewLCSDefault())
+	if len(result.Chunks) != 1 || result.Chunks[0].Type != ThreeWayUnchanged || len(result.Chunks[0].BaseLines) != 2 {
+		t.Errorf("Expected a single unchanged chunk, got %+v", result.Chunks)
+	}
+
+	result = CompareThree(nil, []string{"x"}, nil, NewLCSDefault())
+	if len(result.Chunks) != 1 || result.Chunks[0].Type != ThreeWayLeftOnly {
+		t.Errorf("Expected a left-only addition to an empty base, got %+v", result.Chunks)
+	}
+}
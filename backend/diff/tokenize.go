@@ -0,0 +1,107 @@
+package diff
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MinifiedLineLength is the line length, in bytes, from which a line is
+// treated as minified and split into segments for a tokenized diff
+const MinifiedLineLength = 1000
+
+// maxSegmentLength caps the runes in a segment of a split line, for content
+// such as long strings with no statement delimiters
+const maxSegmentLength = 120
+
+// IsMinified reports whether any line is long enough that a line diff of it
+// would be unreadable
+func IsMinified(lines []string) bool {
+	for _, line := range lines {
+		if len(line) >= MinifiedLineLength {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitSegments breaks a line into segments ending after statement and block
+// delimiters, soft-wrapping anything longer than maxSegmentLength at the last
+// space, so the pieces concatenate back to the line
+func SplitSegments(line string) []string {
+	var segments []string
+	start := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case ';', '{', '}', ',':
+			segments = append(segments, wrapSegment(line[start:i+1])...)
+			start = i + 1
+		}
+	}
+	if start < len(line) {
+		segments = append(segments, wrapSegment(line[start:])...)
+	}
+	return segments
+}
+
+// wrapSegment splits a segment longer than maxSegmentLength runes
+func wrapSegment(segment string) []string {
+	var pieces []string
+	for utf8.RuneCountInString(segment) > maxSegmentLength {
+		// Byte offset just past maxSegmentLength runes
+		cut, count := 0, 0
+		for cut < len(segment) && count < maxSegmentLength {
+			_, size := utf8.DecodeRuneInString(segment[cut:])
+			cut += size
+			count++
+		}
+		if space := strings.LastIndexByte(segment[:cut], ' '); space > 0 {
+			cut = space + 1
+		}
+		pieces = append(pieces, segment[:cut])
+		segment = segment[cut:]
+	}
+	return append(pieces, segment)
+}
+
+// TokenizedDiff compares two files with their minified lines split into
+// segments, so a change inside a very long line shows up as a changed
+// segment instead of the whole line. Segments keep the number of the line
+// they came from. Myers is used whatever algorithm is configured, since
+// split files can have many more segments than lines.
+func TokenizedDiff(leftLines, rightLines []string, algorithm Algorithm) *DiffResult {
+	leftSegments, leftOrigins := splitMinified(leftLines)
+	rightSegments, rightOrigins := splitMinified(rightLines)
+
+	config := configOf(algorithm)
+	config.Algorithm = AlgorithmMyers
+	result := New(config).ComputeDiff(leftSegments, rightSegments)
+	for i := range result.Lines {
+		line := &result.Lines[i]
+		if line.LeftNumber > 0 {
+			line.LeftNumber = leftOrigins[line.LeftNumber-1]
+		}
+		if line.RightNumber > 0 {
+			line.RightNumber = rightOrigins[line.RightNumber-1]
+		}
+	}
+	return result
+}
+
+// splitMinified splits the minified lines of a file into segments, returning
+// the segments and the 1-based line number each came from
+func splitMinified(lines []string) ([]string, []int) {
+	segments := make([]string, 0, len(lines))
+	origins := make([]int, 0, len(lines))
+	for i, line := range lines {
+		if len(line) < MinifiedLineLength {
+			segments = append(segments, line)
+			origins = append(origins, i+1)
+			continue
+		}
+		for _, segment := range SplitSegments(line) {
+			segments = append(segments, segment)
+			origins = append(origins, i+1)
+		}
+	}
+	return segments, origins
+}
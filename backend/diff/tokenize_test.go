@@ -0,0 +1,76 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSplitSegments(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"empty", "", nil},
+		{"no delimiters", "abc", []string{"abc"}},
+		{"statements", "a=1;b=2;c", []string{"a=1;", "b=2;", "c"}},
+		{"blocks", "f(){x,y}", []string{"f(){", "x,", "y}"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitSegments(tt.line)
+			if strings.Join(got, "|") != strings.Join(tt.expected, "|") || len(got) != len(tt.expected) {
+				t.Errorf("SplitSegments(%q) = %q, expected %q", tt.line, got, tt.expected)
+			}
+		})
+	}
+
+	t.Run("soft wrap", func(t *testing.T) {
+		line := strings.Repeat("word ", 60)
+		segments := SplitSegments(line)
+		if len(segments) < 3 {
+			t.Fatalf("Expected a long segment to wrap, got %d pieces", len(segments))
+		}
+		for _, segment := range segments[:len(segments)-1] {
+			if !strings.HasSuffix(segment, " ") || len(segment) > maxSegmentLength {
+				t.Errorf("Expected wrapping after a space within %d runes, got %q", maxSegmentLength, segment)
+			}
+		}
+		if strings.Join(segments, "") != line {
+			t.Error("Expected segments to concatenate back to the line")
+		}
+	})
+}
+
+func TestTokenizedDiff(t *testing.T) {
+	var statements []string
+	for i := 0; i < 200; i++ {
+		statements = append(statements, "var v"+strings.Repeat("x", i%7)+"=1;")
+	}
+	left := []string{"// header", strings.Join(statements, "")}
+	statements[100] = "var changed=2;"
+	right := []string{"// header", strings.Join(statements, "")}
+
+	if !IsMinified(left) || IsMinified([]string{"short"}) {
+		t.Fatal("Expected only the long line to count as minified")
+	}
+
+	result := TokenizedDiff(left, right, NewLCSDefault())
+	var changed []DiffLine
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			changed = append(changed, line)
+		}
+		if line.LeftNumber > 2 || line.RightNumber > 2 {
+			t.Fatalf("Expected segments to keep their file line numbers, got %+v", line)
+		}
+	}
+	if len(changed) == 0 || len(changed) > 2 {
+		t.Fatalf("Expected only the changed statement to differ, got %+v", changed)
+	}
+	for _, line := range changed {
+		if line.LeftLine != "" && line.LeftLine != "var vxx=1;" || line.RightLine != "" && line.RightLine != "var changed=2;" {
+			t.Errorf("Expected the change to be confined to one statement, got %+v", line)
+		}
+	}
+}
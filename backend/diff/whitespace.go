@@ -0,0 +1,67 @@
+package diff
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Whitespace modes control which whitespace differences are ignored when
+// matching lines
+const (
+	// WhitespaceExact treats any whitespace difference as a change
+	WhitespaceExact = ""
+	// WhitespaceIgnoreLeadingTrailing ignores whitespace at either end of a line
+	WhitespaceIgnoreLeadingTrailing = "trim"
+	// WhitespaceIgnoreAmount ignores changes in the amount of whitespace, so
+	// any run of whitespace matches any other
+	WhitespaceIgnoreAmount = "amount"
+	// WhitespaceIgnoreAll ignores all whitespace, including where none was before
+	WhitespaceIgnoreAll = "all"
+)
+
+// IsWhitespaceMode returns whether mode is a known whitespace mode
+func IsWhitespaceMode(mode string) bool {
+	switch mode {
+	case WhitespaceExact, WhitespaceIgnoreLeadingTrailing, WhitespaceIgnoreAmount, WhitespaceIgnoreAll:
+		return true
+	}
+	return false
+}
+
+// NormalizeWhitespace returns the form of a line that is compared under the
+// given whitespace mode
+func NormalizeWhitespace(line, mode string) string {
+	switch mode {
+	case WhitespaceIgnoreLeadingTrailing:
+		return strings.TrimSpace(line)
+	case WhitespaceIgnoreAmount:
+		return strings.Join(strings.Fields(line), " ")
+	case WhitespaceIgnoreAll:
+		return strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, line)
+	default:
+		return line
+	}
+}
+
+// IsWhitespaceOnly reports whether two files differ, but only in whitespace:
+// their lines match once all whitespace is removed and blank lines dropped
+func IsWhitespaceOnly(leftLines, rightLines []string) bool {
+	return !slices.Equal(leftLines, rightLines) && slices.Equal(significantLines(leftLines), significantLines(rightLines))
+}
+
+// significantLines returns the non-blank lines with all whitespace removed
+func significantLines(lines []string) []string {
+	significant := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if stripped := NormalizeWhitespace(line, WhitespaceIgnoreAll); stripped != "" {
+			significant = append(significant, stripped)
+		}
+	}
+	return significant
+}
@@ -0,0 +1,113 @@
+package diff
+
+import "testing"
+
+func TestNormalizeWhitespace(t *testing.T) {
+	tests := []struct {
+		line     string
+		mode     string
+		expected string
+	}{
+		{"  a  b ", WhitespaceExact, "  a  b "},
+		{"  a  b ", WhitespaceIgnoreLeadingTrailing, "a  b"},
+		{"\ta \t b ", WhitespaceIgnoreAmount, "a b"},
+		{" a ( b ) ", WhitespaceIgnoreAll, "a(b)"},
+	}
+	for _, tt := range tests {
+		if got := NormalizeWhitespace(tt.line, tt.mode); got != tt.expected {
+			t.Errorf("NormalizeWhitespace(%q, %q) = %q, expected %q", tt.line, tt.mode, got, tt.expected)
+		}
+	}
+	if IsWhitespaceMode("tabs") {
+		t.Error("Expected an unknown mode to be rejected")
+	}
+}
+
+func TestAlgorithms_WhitespaceModes(t *testing.T) {
+	left := []string{"func main() {", "    x := 1", "y=2", "}"}
+	right := []string{"func main() {", "\tx  :=  1  ", "y = 2", "}"}
+
+	tests := []struct {
+		mode string
+		same int
+	}{
+		{WhitespaceExact, 2},
+		{WhitespaceIgnoreLeadingTrailing, 2},
+		{WhitespaceIgnoreAmount, 3},
+		{WhitespaceIgnoreAll, 4},
+	}
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		for _, tt := range tests {
+			config := DefaultConfig()
+			config.Algorithm = name
+			config.Whitespace = tt.mode
+			result := New(config).ComputeDiff(left, right)
+
+			same := 0
+			for _, line := range result.Lines {
+				if line.Type == "same" {
+					same++
+				}
+			}
+			if same != tt.same {
+				t.Errorf("%s with mode %q: expected %d same lines, got %+v", name, tt.mode, tt.same, result.Lines)
+			}
+		}
+	}
+
+	// Matched lines keep their own text on each side
+	config := DefaultConfig()
+	config.Whitespace = WhitespaceIgnoreAll
+	line := NewLCS(config).ComputeDiff(left, right).Lines[2]
+	if line.LeftLine != "y=2" || line.RightLine != "y = 2" {
+		t.Errorf("Expected original text on both sides, got %+v", line)
+	}
+}
+
+func TestAlgorithms_IgnoreCase(t *testing.T) {
+	left := []string{"SELECT id FROM users", "WHERE  active = 1"}
+	right := []string{"select id from users", "where active = 1"}
+
+	for _, name := range []string{AlgorithmLCS, AlgorithmMyers, AlgorithmPatience, AlgorithmHistogram} {
+		config := DefaultConfig()
+		config.Algorithm = name
+		config.IgnoreCase = true
+		result := New(config).ComputeDiff(left, right)
+		if result.Lines[0].Type != "same" || result.Lines[0].RightLine != right[0] {
+			t.Errorf("%s: expected the first line to match ignoring case, got %+v", name, result.Lines[0])
+		}
+		if len(result.Lines) < 2 || result.Lines[1].Type == "same" {
+			t.Errorf("%s: expected whitespace to still count, got %+v", name, result.Lines)
+		}
+
+		config.Whitespace = WhitespaceIgnoreAmount
+		for _, line := range New(config).ComputeDiff(left, right).Lines {
+			if line.Type != "same" {
+				t.Errorf("%s: expected case and whitespace to be ignored together, got %+v", name, line)
+			}
+		}
+	}
+}
+
+func TestIsWhitespaceOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		left     []string
+		right    []string
+		expected bool
+	}{
+		{"identical", []string{"a b", "c"}, []string{"a b", "c"}, false},
+		{"indentation", []string{"if x {", "y()", "}"}, []string{"if x {", "\ty()", "}"}, true},
+		{"trailing and inner spaces", []string{"a b  ", "c"}, []string{"a  b", "c"}, true},
+		{"blank lines", []string{"a", "b"}, []string{"a", "", "  ", "b"}, true},
+		{"content change", []string{"a", "b"}, []string{"a ", "c"}, false},
+		{"added line", []string{"a"}, []string{"a", "b"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsWhitespaceOnly(tt.left, tt.right); got != tt.expected {
+				t.Errorf("IsWhitespaceOnly(%q, %q) = %v, expected %v", tt.left, tt.right, got, tt.expected)
+			}
+		})
+	}
+}
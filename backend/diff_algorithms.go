@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"fmt"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"weld/backend/diff"
+)
+
+// AlgorithmInfo describes a diff algorithm the user can choose
+type AlgorithmInfo struct {
+	Name        string `json:"name"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+}
+
+// availableAlgorithms lists the selectable diff algorithms in menu order
+var availableAlgorithms = []AlgorithmInfo{
+	{
+		Name:        diff.AlgorithmLCS,
+		Label:       "LCS",
+		Description: "Longest common subsequence. Exact, but slow and memory hungry on large files.",
+	},
+	{
+		Name:        diff.AlgorithmMyers,
+		Label:       "Myers",
+		Description: "Fast on large files with few changes.",
+	},
+	{
+		Name:        diff.AlgorithmPatience,
+		Label:       "Patience",
+		Description: "Anchors on unique lines, so moved or reordered code reads better.",
+	},
+	{
+		Name:        diff.AlgorithmHistogram,
+		Label:       "Histogram",
+		Description: "Anchors on rare lines; handles logs and generated code with many repeated lines.",
+	},
+}
+
+// isAvailableAlgorithm returns whether name is a selectable diff algorithm
+func isAvailableAlgorithm(name string) bool {
+	for _, algorithm := range availableAlgorithms {
+		if algorithm.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// GetAvailableAlgorithms returns the diff algorithms the user can choose
+func (a *App) GetAvailableAlgorithms() []AlgorithmInfo {
+	return append([]AlgorithmInfo{}, availableAlgorithms...)
+}
+
+// GetDiffAlgorithm returns the name of the selected diff algorithm
+func (a *App) GetDiffAlgorithm() string {
+	name := a.settings.Get().DiffAlgorithm
+	if name == "" {
+		return diff.AlgorithmLCS
+	}
+	return name
+}
+
+// SetDiffAlgorithm selects the diff algorithm by name and re-compares the
+// current files with it. The new result is delivered through the
+// "diff-updated" event.
+func (a *App) SetDiffAlgorithm(name string) error {
+	if !isAvailableAlgorithm(name) {
+		return fmt.Errorf("unknown diff algorithm: %s", name)
+	}
+
+	err := a.settings.Update(func(s *Settings) error {
+		s.DiffAlgorithm = name
+		return nil
+	})
+
+	a.updateDiffAlgorithmMenu()
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// SetDiffAlgorithmMenuItems stores the radio menu items used to pick an
+// algorithm, keyed by algorithm name
+func (a *App) SetDiffAlgorithmMenuItems(items map[string]*menu.MenuItem) {
+	a.menuMutex.Lock()
+	a.diffAlgorithmMenuItems = items
+	a.menuMutex.Unlock()
+}
+
+// updateDiffAlgorithmMenu checks the menu item of the selected algorithm
+func (a *App) updateDiffAlgorithmMenu() {
+	selected := a.GetDiffAlgorithm()
+	a.menuMutex.Lock()
+	hasItems := len(a.diffAlgorithmMenuItems) > 0
+	for name, item := range a.diffAlgorithmMenuItems {
+		item.Checked = name == selected
+	}
+	a.menuMutex.Unlock()
+
+	if hasItems {
+		a.refreshMenu()
+	}
+}
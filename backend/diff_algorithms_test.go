@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"weld/backend/diff"
+)
+
+func TestApp_SetDiffAlgorithm(t *testing.T) {
+	app := NewApp()
+	items := make(map[string]*menu.MenuItem)
+	for _, algorithm := range app.GetAvailableAlgorithms() {
+		items[algorithm.Name] = menu.Radio(algorithm.Label, algorithm.Name == diff.AlgorithmLCS, nil, nil)
+	}
+	app.SetDiffAlgorithmMenuItems(items)
+
+	if got := app.GetDiffAlgorithm(); got != diff.AlgorithmLCS {
+		t.Errorf("Expected default algorithm %q, got %q", diff.AlgorithmLCS, got)
+	}
+
+	tests := []struct {
+		name     string
+		expected interface{}
+	}{
+		{diff.AlgorithmMyers, &diff.Myers{}},
+		{diff.AlgorithmPatience, &diff.Patience{}},
+		{diff.AlgorithmHistogram, &diff.Histogram{}},
+		{diff.AlgorithmLCS, &diff.LCS{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.SetDiffAlgorithm(tt.name); err != nil {
+				t.Fatalf("SetDiffAlgorithm returned error: %v", err)
+			}
+			algorithm := app.algorithmFor(app.resolveCompareOptions(CompareOptions{}))
+			if got, want := typeName(algorithm), typeName(tt.expected); got != want {
+				t.Errorf("Expected %s, got %s", want, got)
+			}
+			for name, item := range items {
+				if item.Checked != (name == tt.name) {
+					t.Errorf("Expected only %s to be checked, %s is %v", tt.name, name, item.Checked)
+				}
+			}
+		})
+	}
+
+	if err := app.SetDiffAlgorithm("quantum"); err == nil {
+		t.Error("Expected error for unknown algorithm")
+	}
+}
+
+// typeName returns the dynamic type of a value for comparison in tests
+func typeName(v interface{}) string {
+	return fmt.Sprintf("%T", v)
+}
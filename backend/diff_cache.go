@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"weld/backend/diff"
+)
+
+// maxDiffCacheBytes bounds the estimated memory held by cached diff results
+const maxDiffCacheBytes = 64 << 20
+
+// diffLineOverhead approximates the memory of a diff line besides its text
+const diffLineOverhead = 64
+
+// DiffCacheStats describes the diff result cache
+type DiffCacheStats struct {
+	Entries int `json:"entries"`
+	Bytes   int `json:"bytes"` // estimated memory held by the cached results
+	Hits    int `json:"hits"`
+	Misses  int `json:"misses"`
+}
+
+// diffCache keeps recent diff results keyed by the content of both files and
+// the settings they were compared with, evicting the least recently used
+// results beyond maxBytes. A nil cache caches nothing.
+type diffCache struct {
+	mu       sync.Mutex
+	maxBytes int
+	bytes    int
+	order    *list.List // of *diffCacheEntry, most recently used first
+	entries  map[string]*list.Element
+	hits     int
+	misses   int
+}
+
+// diffCacheEntry is a cached result and its estimated size
+type diffCacheEntry struct {
+	key    string
+	result *DiffResult
+	size   int
+}
+
+// newDiffCache creates a cache holding up to maxBytes of results
+func newDiffCache(maxBytes int) *diffCache {
+	return &diffCache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// get returns a copy of the result cached under key
+func (c *diffCache) get(key string) (*DiffResult, bool) {
+	if c == nil || key == "" {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(element)
+	return cloneDiffResult(element.Value.(*diffCacheEntry).result), true
+}
+
+// put caches a copy of result under key
+func (c *diffCache) put(key string, result *DiffResult) {
+	if c == nil || key == "" {
+		return
+	}
+	size := diffResultSize(result)
+	if size > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.removeLocked(element)
+	}
+	c.entries[key] = c.order.PushFront(&diffCacheEntry{key: key, result: cloneDiffResult(result), size: size})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.removeLocked(c.order.Back())
+	}
+}
+
+// removeLocked drops an entry (must be called with mu held)
+func (c *diffCache) removeLocked(element *list.Element) {
+	entry := c.order.Remove(element).(*diffCacheEntry)
+	delete(c.entries, entry.key)
+	c.bytes -= entry.size
+}
+
+// clear drops every entry and resets the statistics
+func (c *diffCache) clear() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.order.Init()
+	clear(c.entries)
+	c.bytes, c.hits, c.misses = 0, 0, 0
+}
+
+// stats returns the size and effectiveness of the cache
+func (c *diffCache) stats() DiffCacheStats {
+	if c == nil {
+		return DiffCacheStats{}
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return DiffCacheStats{Entries: len(c.entries), Bytes: c.bytes, Hits: c.hits, Misses: c.misses}
+}
+
+// cloneDiffResult copies a result deeply enough that annotating or offsetting
+// its lines leaves the original untouched
+func cloneDiffResult(result *DiffResult) *DiffResult {
+	clone := *result
+	clone.Lines = slices.Clone(result.Lines)
+	clone.Chunks = slices.Clone(result.Chunks)
+	clone.Folds = slices.Clone(result.Folds)
+	if result.Metadata != nil {
+		metadata := *result.Metadata
+		clone.Metadata = &metadata
+	}
+	return &clone
+}
+
+// diffResultSize estimates the memory held by a result
+func diffResultSize(result *DiffResult) int {
+	size := 0
+	for _, line := range result.Lines {
+		size += diffLineOverhead + len(line.LeftLine) + len(line.RightLine)
+	}
+	return size
+}
+
+// diffCacheKey identifies a comparison by the hashes of both files' lines,
+// their names, which select the file type and language, and every setting
+// that shapes the result. Algorithms without a configuration are not cached.
+func diffCacheKey(leftPath, rightPath string, leftLines, rightLines []string, options CompareOptions, algorithm diff.Algorithm, alignImports bool) string {
+	configurable, ok := algorithm.(diff.Configurable)
+	if !ok {
+		return ""
+	}
+	config := configurable.Config()
+	patterns := make([]string, len(config.IgnorePatterns))
+	for i, pattern := range config.IgnorePatterns {
+		patterns[i] = pattern.String()
+	}
+	config.IgnorePatterns = nil
+
+	return fmt.Sprintf("%s:%s:%s", hashLines(leftLines), hashLines(rightLines), hashSettings(
+		filepath.Base(leftPath), filepath.Base(rightPath), options, config, patterns, alignImports))
+}
+
+// hashLines returns the SHA-256 of a file's lines
+func hashLines(lines []string) string {
+	hash := sha256.New()
+	for _, line := range lines {
+		fmt.Fprintf(hash, "%d:%s", len(line), line)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// hashSettings returns the SHA-256 of values printed with their field names
+func hashSettings(values ...interface{}) string {
+	hash := sha256.New()
+	for _, value := range values {
+		fmt.Fprintf(hash, "%#v\x00", value)
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// ClearDiffCache drops every cached diff result
+func (a *App) ClearDiffCache() {
+	a.diffCache.clear()
+}
+
+// GetDiffCacheStats returns the size and hit rate of the diff result cache
+func (a *App) GetDiffCacheStats() DiffCacheStats {
+	return a.diffCache.stats()
+}
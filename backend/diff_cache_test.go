@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiffCache_Eviction(t *testing.T) {
+	result := func(text string) *DiffResult {
+		return &DiffResult{Lines: []DiffLine{{LeftLine: text, RightLine: text, Type: "same"}}}
+	}
+	entrySize := diffResultSize(result("aaaa"))
+	cache := newDiffCache(2 * entrySize)
+
+	cache.put("a", result("aaaa"))
+	cache.put("b", result("bbbb"))
+	if _, ok := cache.get("a"); !ok {
+		t.Fatal("Expected a to be cached")
+	}
+	// b is now the least recently used entry
+	cache.put("c", result("cccc"))
+	if _, ok := cache.get("b"); ok {
+		t.Error("Expected b to be evicted")
+	}
+	if _, ok := cache.get("a"); !ok {
+		t.Error("Expected a to be kept")
+	}
+	if stats := cache.stats(); stats.Entries != 2 || stats.Bytes != 2*entrySize {
+		t.Errorf("Expected 2 entries of %d bytes, got %+v", entrySize, stats)
+	}
+
+	cache.put("huge", result(string(make([]byte, 3*entrySize))))
+	if _, ok := cache.get("huge"); ok {
+		t.Error("Expected a result larger than the cache not to be cached")
+	}
+
+	// Callers may change the results they get
+	got, _ := cache.get("a")
+	got.Lines[0].LeftLine = "changed"
+	if again, _ := cache.get("a"); again.Lines[0].LeftLine != "aaaa" {
+		t.Error("Expected the cached result to be unaffected by changes to a copy")
+	}
+
+	var disabled *diffCache
+	disabled.put("a", result("aaaa"))
+	if _, ok := disabled.get("a"); ok {
+		t.Error("Expected a nil cache to cache nothing")
+	}
+}
+
+func TestApp_DiffCache(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	if err := os.WriteFile(leftPath, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("one\nthree\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	first, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	// Saving the same content again does not change the key
+	if err := os.WriteFile(rightPath, []byte("one\nthree\n"), 0644); err != nil {
+		t.Fatalf("Failed to rewrite temp file: %v", err)
+	}
+	second, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if stats := app.GetDiffCacheStats(); stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("Expected the second comparison to hit the cache, got %+v", stats)
+	}
+	if len(second.Lines) != len(first.Lines) {
+		t.Errorf("Expected the cached result to match, got %+v and %+v", first.Lines, second.Lines)
+	}
+
+	// Different settings are cached separately
+	if _, err := app.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{IgnoreCase: true}); err != nil {
+		t.Fatalf("CompareFilesWithOptions returned error: %v", err)
+	}
+	if stats := app.GetDiffCacheStats(); stats.Misses != 2 {
+		t.Errorf("Expected other options to miss the cache, got %+v", stats)
+	}
+
+	app.ClearDiffCache()
+	if stats := app.GetDiffCacheStats(); stats != (DiffCacheStats{}) {
+		t.Errorf("Expected an empty cache, got %+v", stats)
+	}
+}
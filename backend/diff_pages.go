@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// maxDiffPageLines is the most lines returned by one GetDiffPage call
+const maxDiffPageLines = 5000
+
+// DiffSummary describes the current comparison without its lines, so the
+// frontend can size a virtualized view and fetch lines with GetDiffPage
+type DiffSummary struct {
+	Total    int `json:"total"` // number of lines as displayed
+	Added    int `json:"added"`
+	Removed  int `json:"removed"`
+	Modified int `json:"modified"`
+
+	Chunks            []diff.DiffChunk    `json:"chunks"`
+	Folds             []FoldedRegion      `json:"folds,omitempty"`
+	Metadata          *ComparisonMetadata `json:"metadata,omitempty"`
+	Identical         bool                `json:"identical"`
+	Reordered         bool                `json:"reordered"`
+	Minified          bool                `json:"minified"`
+	WhitespaceOnly    bool                `json:"whitespaceOnly"`
+	Approximate       bool                `json:"approximate"`
+	LineEndingsDiffer bool                `json:"lineEndingsDiffer"`
+	Segmented         bool                `json:"segmented"`
+}
+
+// DiffPage is a window of the current comparison's lines
+type DiffPage struct {
+	Offset int        `json:"offset"`
+	Lines  []DiffLine `json:"lines"`
+	Total  int        `json:"total"`
+}
+
+// GetDiffSummary returns the counts, chunks, and flags of the current
+// comparison as displayed, folded when folding is on
+func (a *App) GetDiffSummary() (*DiffSummary, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	result := a.displayResult(current.Result)
+
+	summary := &DiffSummary{
+		Total:             len(result.Lines),
+		Chunks:            result.Chunks,
+		Folds:             result.Folds,
+		Metadata:          result.Metadata,
+		Identical:         result.Identical,
+		Reordered:         result.Reordered,
+		Minified:          result.Minified,
+		WhitespaceOnly:    result.WhitespaceOnly,
+		Approximate:       result.Approximate,
+		LineEndingsDiffer: result.LineEndingsDiffer,
+		Segmented:         result.Segmented,
+	}
+	// Counted over every line, including those folded away
+	for _, line := range current.Result.Lines {
+		switch line.Type {
+		case "added":
+			summary.Added++
+		case "removed":
+			summary.Removed++
+		case "modified":
+			summary.Modified++
+		}
+	}
+	return summary, nil
+}
+
+// GetDiffPage returns up to count lines of the current comparison as
+// displayed, starting at offset. Indexes match those of GetDiffSummary's
+// chunks and folds. A page past the end is empty.
+func (a *App) GetDiffPage(offset, count int) (*DiffPage, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("offset cannot be negative")
+	}
+	if count < 0 {
+		return nil, fmt.Errorf("count cannot be negative")
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	result := a.displayResult(current.Result)
+
+	total := len(result.Lines)
+	start := min(offset, total)
+	end := min(start+min(count, maxDiffPageLines), total)
+	return &DiffPage{
+		Offset: start,
+		Lines:  append([]DiffLine{}, result.Lines[start:end]...),
+		Total:  total,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestApp_DiffPages(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	if _, err := app.GetDiffSummary(); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+	if _, err := app.GetDiffPage(0, 10); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+
+	var left, right []string
+	for i := 1; i <= 30; i++ {
+		line := fmt.Sprintf("line %d", i)
+		left = append(left, line)
+		if i == 15 {
+			line = "changed"
+		}
+		right = append(right, line)
+	}
+	right = append(right, "appended")
+	compareTempFiles(t, app, left, right)
+
+	t.Run("summary", func(t *testing.T) {
+		summary, err := app.GetDiffSummary()
+		if err != nil {
+			t.Fatalf("GetDiffSummary returned error: %v", err)
+		}
+		if summary.Total != 32 || summary.Added != 2 || summary.Removed != 1 || summary.Modified != 0 {
+			t.Errorf("Expected 32 lines with 2 added and 1 removed, got %+v", summary)
+		}
+		if len(summary.Chunks) != 2 || summary.Identical {
+			t.Errorf("Expected 2 chunks of differences, got %+v", summary)
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		page, err := app.GetDiffPage(10, 5)
+		if err != nil {
+			t.Fatalf("GetDiffPage returned error: %v", err)
+		}
+		if page.Offset != 10 || page.Total != 32 || len(page.Lines) != 5 || page.Lines[0].LeftLine != "line 11" {
+			t.Errorf("Expected lines 11-15, got %+v", page)
+		}
+
+		page, err = app.GetDiffPage(30, 10)
+		if err != nil {
+			t.Fatalf("GetDiffPage returned error: %v", err)
+		}
+		if len(page.Lines) != 2 {
+			t.Errorf("Expected the last 2 lines, got %d", len(page.Lines))
+		}
+
+		page, err = app.GetDiffPage(100, 10)
+		if err != nil || len(page.Lines) != 0 || page.Offset != 32 {
+			t.Errorf("Expected an empty page past the end, got %+v, %v", page, err)
+		}
+	})
+
+	t.Run("invalid windows", func(t *testing.T) {
+		if _, err := app.GetDiffPage(-1, 10); err == nil {
+			t.Error("Expected error for a negative offset")
+		}
+		if _, err := app.GetDiffPage(0, -1); err == nil {
+			t.Error("Expected error for a negative count")
+		}
+	})
+
+	t.Run("folded", func(t *testing.T) {
+		if err := app.SetFoldUnchanged(true); err != nil {
+			t.Fatalf("SetFoldUnchanged returned error: %v", err)
+		}
+		t.Cleanup(func() { app.SetFoldUnchanged(false) })
+
+		summary, err := app.GetDiffSummary()
+		if err != nil {
+			t.Fatalf("GetDiffSummary returned error: %v", err)
+		}
+		if summary.Total >= 32 || len(summary.Folds) == 0 || summary.Added != 2 {
+			t.Errorf("Expected a folded summary counting every change, got %+v", summary)
+		}
+		page, err := app.GetDiffPage(0, maxDiffPageLines+1)
+		if err != nil {
+			t.Fatalf("GetDiffPage returned error: %v", err)
+		}
+		if len(page.Lines) != summary.Total {
+			t.Errorf("Expected %d folded lines, got %d", summary.Total, len(page.Lines))
+		}
+	})
+}
@@ -0,0 +1,216 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DirectoryEntry describes a single file or directory in a listing
+type DirectoryEntry struct {
+	Name     string    `json:"name"`
+	Path     string    `json:"path"`
+	IsDir    bool      `json:"isDir"`
+	IsHidden bool      `json:"isHidden"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modTime"`
+}
+
+// DirectoryListOptions controls sorting and filtering of a directory listing
+type DirectoryListOptions struct {
+	SortBy     string `json:"sortBy"` // "name" (default), "size", "modified"
+	Descending bool   `json:"descending"`
+	Filter     string `json:"filter"` // glob pattern (e.g. "*.go") or case-insensitive substring
+}
+
+// DirectoryListing is the result of listing a directory
+type DirectoryListing struct {
+	Path    string           `json:"path"`
+	Parent  string           `json:"parent"`
+	Entries []DirectoryEntry `json:"entries"`
+}
+
+// LocationShortcut is a quick-access location for the in-app file browser
+type LocationShortcut struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	Kind string `json:"kind"` // "home", "place", "favorite", "volume"
+}
+
+// ListDirectory lists the contents of a directory for the in-app file browser.
+// Directories are always listed before files; the filter only applies to files.
+func (a *App) ListDirectory(path string, options DirectoryListOptions) (*DirectoryListing, error) {
+	expanded, err := expandHomeDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	absPath, err := filepath.Abs(expanded)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving directory path: %w", err)
+	}
+
+	dirEntries, err := os.ReadDir(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+
+	showHidden := a.GetShowHiddenFiles()
+
+	entries := make([]DirectoryEntry, 0, len(dirEntries))
+	for _, dirEntry := range dirEntries {
+		name := dirEntry.Name()
+		hidden := strings.HasPrefix(name, ".")
+		if hidden && !showHidden {
+			continue
+		}
+
+		isDir := isDirEntry(absPath, dirEntry)
+		if !isDir && !matchesListFilter(name, options.Filter) {
+			continue
+		}
+
+		entry := DirectoryEntry{
+			Name:     name,
+			Path:     filepath.Join(absPath, name),
+			IsDir:    isDir,
+			IsHidden: hidden,
+		}
+		if info, err := dirEntry.Info(); err == nil {
+			entry.ModTime = info.ModTime()
+			if !isDir {
+				entry.Size = info.Size()
+			}
+		}
+		entries = append(entries, entry)
+	}
+
+	sortDirectoryEntries(entries, options, nameComparer(a.GetSortOrder()))
+
+	parent := filepath.Dir(absPath)
+	if parent == absPath {
+		parent = ""
+	}
+
+	return &DirectoryListing{
+		Path:    absPath,
+		Parent:  parent,
+		Entries: entries,
+	}, nil
+}
+
+// matchesListFilter reports whether a file name passes the listing filter
+func matchesListFilter(name, filter string) bool {
+	if filter == "" {
+		return true
+	}
+	if strings.ContainsAny(filter, "*?[") {
+		matched, err := filepath.Match(strings.ToLower(filter), strings.ToLower(name))
+		return err == nil && matched
+	}
+	return strings.Contains(strings.ToLower(name), strings.ToLower(filter))
+}
+
+// sortDirectoryEntries orders entries with directories first, then by the
+// requested key, comparing names with compareNames
+func sortDirectoryEntries(entries []DirectoryEntry, options DirectoryListOptions, compareNames func(a, b string) int) {
+	less := func(i, j int) bool {
+		switch options.SortBy {
+		case "size":
+			if entries[i].Size != entries[j].Size {
+				return entries[i].Size < entries[j].Size
+			}
+		case "modified":
+			return entries[i].ModTime.Before(entries[j].ModTime)
+		}
+		return compareNames(entries[i].Name, entries[j].Name) < 0
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].IsDir != entries[j].IsDir {
+			return entries[i].IsDir
+		}
+		// Swapping the arguments keeps equal entries in their original order
+		if options.Descending {
+			return less(j, i)
+		}
+		return less(i, j)
+	})
+}
+
+// GetLocationShortcuts returns quick-access locations for the in-app file
+// browser: the home directory, common user folders, favorites, and mounted volumes
+func (a *App) GetLocationShortcuts() []LocationShortcut {
+	shortcuts := []LocationShortcut{}
+
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		shortcuts = append(shortcuts, LocationShortcut{Name: "Home", Path: homeDir, Kind: "home"})
+		for _, place := range []string{"Desktop", "Documents", "Downloads"} {
+			placePath := filepath.Join(homeDir, place)
+			if info, err := os.Stat(placePath); err == nil && info.IsDir() {
+				shortcuts = append(shortcuts, LocationShortcut{Name: place, Path: placePath, Kind: "place"})
+			}
+		}
+	}
+
+	for _, dir := range a.GetFavoriteDirectories() {
+		shortcuts = append(shortcuts, LocationShortcut{Name: filepath.Base(dir), Path: dir, Kind: "favorite"})
+	}
+
+	for _, volume := range listVolumes() {
+		shortcuts = append(shortcuts, LocationShortcut{Name: volume.name, Path: volume.path, Kind: "volume"})
+	}
+
+	return shortcuts
+}
+
+type volume struct {
+	name string
+	path string
+}
+
+// listVolumes returns mounted volumes using the platform's conventions
+func listVolumes() []volume {
+	volumes := []volume{}
+
+	switch goruntime.GOOS {
+	case "windows":
+		for letter := 'A'; letter <= 'Z'; letter++ {
+			root := string(letter) + `:\`
+			if _, err := os.Stat(root); err == nil {
+				volumes = append(volumes, volume{name: string(letter) + ":", path: root})
+			}
+		}
+	case "darwin":
+		volumes = append(volumes, mountedDirectories("/Volumes")...)
+	default:
+		volumes = append(volumes, volume{name: "File System", path: "/"})
+		if user := os.Getenv("USER"); user != "" {
+			volumes = append(volumes, mountedDirectories(filepath.Join("/media", user))...)
+			volumes = append(volumes, mountedDirectories(filepath.Join("/run/media", user))...)
+		}
+		volumes = append(volumes, mountedDirectories("/mnt")...)
+	}
+
+	return volumes
+}
+
+// mountedDirectories lists the immediate subdirectories of a mount root
+func mountedDirectories(root string) []volume {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	volumes := []volume{}
+	for _, entry := range entries {
+		if isDirEntry(root, entry) {
+			volumes = append(volumes, volume{name: entry.Name(), path: filepath.Join(root, entry.Name())})
+		}
+	}
+	return volumes
+}
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestApp_ListDirectory(t *testing.T) {
+	app := NewApp()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.go":    "package b",
+		"a.txt":   "aaaaaaaaaa",
+		"C.go":    "c",
+		".hidden": "h",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "zdir"), 0755); err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	names := func(listing *DirectoryListing) []string {
+		result := []string{}
+		for _, entry := range listing.Entries {
+			result = append(result, entry.Name)
+		}
+		return result
+	}
+
+	t.Run("sorted by name with directories first", func(t *testing.T) {
+		listing, err := app.ListDirectory(dir, DirectoryListOptions{})
+		if err != nil {
+			t.Fatalf("ListDirectory returned error: %v", err)
+		}
+		expected := []string{"zdir", ".hidden", "a.txt", "b.go", "C.go"}
+		if got := names(listing); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ListDirectory returned %v, expected %v", got, expected)
+		}
+		if listing.Parent != filepath.Dir(dir) {
+			t.Errorf("Expected parent %s, got %s", filepath.Dir(dir), listing.Parent)
+		}
+	})
+
+	t.Run("sorted by size descending", func(t *testing.T) {
+		app.SetShowHiddenFiles(false)
+		defer app.SetShowHiddenFiles(true)
+
+		listing, err := app.ListDirectory(dir, DirectoryListOptions{SortBy: "size", Descending: true})
+		if err != nil {
+			t.Fatalf("ListDirectory returned error: %v", err)
+		}
+		expected := []string{"zdir", "a.txt", "b.go", "C.go"}
+		if got := names(listing); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ListDirectory returned %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("sorted by modification time", func(t *testing.T) {
+		old := time.Now().Add(-time.Hour)
+		os.Chtimes(filepath.Join(dir, "b.go"), old, old)
+
+		listing, err := app.ListDirectory(dir, DirectoryListOptions{SortBy: "modified", Filter: "*.go"})
+		if err != nil {
+			t.Fatalf("ListDirectory returned error: %v", err)
+		}
+		expected := []string{"zdir", "b.go", "C.go"}
+		if got := names(listing); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ListDirectory returned %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("substring filter", func(t *testing.T) {
+		listing, err := app.ListDirectory(dir, DirectoryListOptions{Filter: "TXT"})
+		if err != nil {
+			t.Fatalf("ListDirectory returned error: %v", err)
+		}
+		expected := []string{"zdir", "a.txt"}
+		if got := names(listing); !reflect.DeepEqual(got, expected) {
+			t.Errorf("ListDirectory returned %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		if _, err := app.ListDirectory(filepath.Join(dir, "missing"), DirectoryListOptions{}); err == nil {
+			t.Error("Expected error for missing directory")
+		}
+	})
+}
+
+func TestApp_GetLocationShortcuts(t *testing.T) {
+	app := NewApp()
+	favorite := t.TempDir()
+	if err := app.AddFavoriteDirectory(favorite); err != nil {
+		t.Fatalf("AddFavoriteDirectory returned error: %v", err)
+	}
+
+	found := false
+	for _, shortcut := range app.GetLocationShortcuts() {
+		if shortcut.Kind == "favorite" && shortcut.Path == favorite {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected favorite %s in location shortcuts", favorite)
+	}
+}
+
+func TestSortDirectoryEntries_DescendingTies(t *testing.T) {
+	modified := time.Now()
+	entries := []DirectoryEntry{
+		{Name: "first.txt", ModTime: modified},
+		{Name: "newest.txt", ModTime: modified.Add(time.Minute)},
+		{Name: "second.txt", ModTime: modified},
+	}
+	sortDirectoryEntries(entries, DirectoryListOptions{SortBy: "modified", Descending: true}, func(a, b string) int {
+		return strings.Compare(a, b)
+	})
+
+	// Entries modified at the same time keep their order
+	got := []string{entries[0].Name, entries[1].Name, entries[2].Name}
+	expected := []string{"newest.txt", "first.txt", "second.txt"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("sortDirectoryEntries returned %v, expected %v", got, expected)
+	}
+}
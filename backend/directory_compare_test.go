@@ -0,0 +1,151 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApp_CompareDirectories(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewFileStorage(t.TempDir())
+	t.Cleanup(app.closeDirectoryIndex)
+
+	left := t.TempDir()
+	right := t.TempDir()
+	write := func(root, path, content string) {
+		full := filepath.Join(root, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	write(left, "same.txt", "same")
+	write(right, "same.txt", "same")
+	write(left, "src/changed.go", "package a")
+	write(right, "src/changed.go", "package b")
+	write(left, "old.txt", "gone")
+	write(right, "docs/new.md", "new")
+
+	t.Run("first comparison", func(t *testing.T) {
+		result, err := app.CompareDirectories(left, right)
+		if err != nil {
+			t.Fatalf("CompareDirectories returned error: %v", err)
+		}
+
+		statuses := make(map[string]string)
+		for _, entry := range result.Entries {
+			statuses[entry.Path] = entry.Status
+		}
+		expected := map[string]string{
+			"docs/new.md":    "added",
+			"old.txt":        "removed",
+			"same.txt":       "same",
+			"src/changed.go": "modified",
+		}
+		if !reflect.DeepEqual(statuses, expected) {
+			t.Errorf("Expected %v, got %v", expected, statuses)
+		}
+		if result.Entries[0].Path != "docs/new.md" {
+			t.Errorf("Expected entries sorted by path, got %+v", result.Entries)
+		}
+		if result.LeftStats.Hashed != 3 || result.LeftStats.Reused != 0 {
+			t.Errorf("Expected every left file to be hashed, got %+v", result.LeftStats)
+		}
+	})
+
+	t.Run("repeat comparison reuses the index", func(t *testing.T) {
+		// Change one file with a distinct modification time
+		write(right, "same.txt", "edited")
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(filepath.Join(right, "same.txt"), later, later); err != nil {
+			t.Fatalf("Failed to set modification time: %v", err)
+		}
+
+		result, err := app.CompareDirectories(left, right)
+		if err != nil {
+			t.Fatalf("CompareDirectories returned error: %v", err)
+		}
+		if result.LeftStats.Hashed != 0 || result.LeftStats.Reused != 3 {
+			t.Errorf("Expected every left hash to be reused, got %+v", result.LeftStats)
+		}
+		if result.RightStats.Hashed != 1 || result.RightStats.Reused != 2 {
+			t.Errorf("Expected only the edited right file to be rehashed, got %+v", result.RightStats)
+		}
+		for _, entry := range result.Entries {
+			if entry.Path == "same.txt" && entry.Status != "modified" {
+				t.Errorf("Expected edited file to be modified, got %s", entry.Status)
+			}
+		}
+	})
+
+	t.Run("deleted files leave the index", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(left, "old.txt")); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+		if _, err := app.CompareDirectories(left, right); err != nil {
+			t.Fatalf("CompareDirectories returned error: %v", err)
+		}
+
+		app.dirIndexMutex.Lock()
+		indexed, err := app.dirIndex.load(left)
+		app.dirIndexMutex.Unlock()
+		if err != nil {
+			t.Fatalf("load returned error: %v", err)
+		}
+		if _, ok := indexed["old.txt"]; ok || len(indexed) != 2 {
+			t.Errorf("Expected only the remaining files to be indexed, got %v", indexed)
+		}
+	})
+
+	t.Run("unreadable files are reported", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("root can read any file")
+		}
+		write(right, "secret.txt", "secret")
+		secret := filepath.Join(right, "secret.txt")
+		if err := os.Chmod(secret, 0); err != nil {
+			t.Fatalf("Failed to change permissions: %v", err)
+		}
+		t.Cleanup(func() { os.Chmod(secret, 0644) })
+
+		result, err := app.CompareDirectories(left, right)
+		if err != nil {
+			t.Fatalf("CompareDirectories returned error: %v", err)
+		}
+		if result.RightStats.Failed != 1 {
+			t.Errorf("Expected one file to fail, got %+v", result.RightStats)
+		}
+		for _, entry := range result.Entries {
+			if entry.Path == "secret.txt" && (entry.Status != "error" || entry.Error == "") {
+				t.Errorf("Expected the unreadable file to report its error, got %+v", entry)
+			}
+		}
+	})
+
+	t.Run("without file storage", func(t *testing.T) {
+		memoryApp := NewApp()
+		memoryApp.Storage = NewMemoryStorage()
+		result, err := memoryApp.CompareDirectories(left, right)
+		if err != nil {
+			t.Fatalf("CompareDirectories returned error: %v", err)
+		}
+		if result.LeftStats.Reused != 0 {
+			t.Errorf("Expected no index without file storage, got %+v", result.LeftStats)
+		}
+	})
+
+	t.Run("invalid roots", func(t *testing.T) {
+		if _, err := app.CompareDirectories("", right); err == nil {
+			t.Error("Expected error for empty path")
+		}
+		if _, err := app.CompareDirectories(filepath.Join(left, "same.txt"), right); err == nil {
+			t.Error("Expected error for a file instead of a directory")
+		}
+	})
+}
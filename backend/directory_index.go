@@ -0,0 +1,200 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+	"weld/backend/fileio"
+)
+
+// directoryIndexKey is the storage key of the directory comparison index
+const directoryIndexKey = "directory-index.db"
+
+// indexedFile is the metadata and content hash of one file in a tree
+type indexedFile struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"modTime"` // Unix nanoseconds
+	Hash    string `json:"hash"`
+	// Error is why the file or directory could not be read, in which case
+	// it has no hash and is left out of the index
+	Error string `json:"-"`
+}
+
+// IndexStats reports how much hashing work a directory scan needed
+type IndexStats struct {
+	Files  int `json:"files"`
+	Hashed int `json:"hashed"` // files that were new or changed since the last scan
+	Reused int `json:"reused"` // files whose hash was taken from the index
+	Failed int `json:"failed"` // files and directories that could not be read
+}
+
+// directoryIndex caches file hashes per tree in a bbolt database, keyed by
+// relative path and invalidated by size and modification time. A nil db
+// hashes every file on every scan.
+type directoryIndex struct {
+	db *bolt.DB
+}
+
+// openDirectoryIndex opens or creates the index database at path
+func openDirectoryIndex(path string) (*directoryIndex, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create index directory: %w", err)
+	}
+	// Fail fast instead of blocking when another instance holds the lock
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open directory index: %w", err)
+	}
+	return &directoryIndex{db: db}, nil
+}
+
+// Close releases the index database
+func (idx *directoryIndex) Close() error {
+	if idx == nil || idx.db == nil {
+		return nil
+	}
+	return idx.db.Close()
+}
+
+// scan walks a tree and returns every regular file keyed by slash-separated
+// relative path. Hashes of files whose size and modification time are
+// unchanged since the last scan are reused; entries for deleted files are dropped.
+// Files and directories that cannot be read are returned with their error
+// rather than failing the scan, which only fails when root cannot be read.
+func (idx *directoryIndex) scan(root string) (map[string]indexedFile, IndexStats, error) {
+	var stats IndexStats
+	files := make(map[string]indexedFile)
+
+	var cached map[string]indexedFile
+	if idx != nil && idx.db != nil {
+		var err error
+		if cached, err = idx.load(root); err != nil {
+			return nil, stats, err
+		}
+	}
+
+	// fail records a file or directory that could not be read
+	fail := func(path string, err error) {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			rel = path
+		}
+		files[filepath.ToSlash(rel)] = indexedFile{Error: err.Error()}
+		stats.Failed++
+	}
+
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if path == root {
+				return err
+			}
+			// The directory's contents are skipped when it cannot be read
+			fail(path, err)
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			fail(path, err)
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		file := indexedFile{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		if previous, ok := cached[rel]; ok && previous.Size == file.Size && previous.ModTime == file.ModTime {
+			file.Hash = previous.Hash
+			stats.Reused++
+		} else {
+			if file.Hash, err = fileio.Hash(path); err != nil {
+				fail(path, err)
+				return nil
+			}
+			stats.Hashed++
+		}
+		stats.Files++
+		files[rel] = file
+		return nil
+	})
+	if err != nil {
+		return nil, stats, fmt.Errorf("failed to scan %s: %w", root, err)
+	}
+
+	if idx != nil && idx.db != nil {
+		if err := idx.store(root, files, cached); err != nil {
+			return nil, stats, err
+		}
+	}
+	return files, stats, nil
+}
+
+// load reads the indexed files of a tree
+func (idx *directoryIndex) load(root string) (map[string]indexedFile, error) {
+	files := make(map[string]indexedFile)
+	err := idx.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(root))
+		if bucket == nil {
+			return nil
+		}
+		return bucket.ForEach(func(key, value []byte) error {
+			var file indexedFile
+			if err := json.Unmarshal(value, &file); err != nil {
+				// Treat a corrupt entry as unindexed
+				return nil
+			}
+			files[string(key)] = file
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory index: %w", err)
+	}
+	return files, nil
+}
+
+// store updates the indexed files of a tree from a scan, writing only the
+// entries that changed since the cached scan and deleting those of files that
+// are gone. Files that could not be read keep their previous entry.
+func (idx *directoryIndex) store(root string, files, cached map[string]indexedFile) error {
+	err := idx.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte(root))
+		if err != nil {
+			return err
+		}
+		for path, file := range files {
+			if file.Error != "" || cached[path] == file {
+				continue
+			}
+			value, err := json.Marshal(file)
+			if err != nil {
+				return err
+			}
+			if err := bucket.Put([]byte(path), value); err != nil {
+				return err
+			}
+		}
+		for path := range cached {
+			if _, ok := files[path]; ok {
+				continue
+			}
+			if err := bucket.Delete([]byte(path)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update directory index: %w", err)
+	}
+	return nil
+}
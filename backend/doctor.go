@@ -0,0 +1,197 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+	"strconv"
+	"strings"
+)
+
+// Outcomes of a doctor check
+const (
+	DoctorOK      = "ok"
+	DoctorWarning = "warning"
+	DoctorError   = "error"
+)
+
+// minInotifyWatches is the inotify watch limit below which other programs
+// can easily exhaust the watches Weld needs
+const minInotifyWatches = 8192
+
+// doctorTools are optional command-line tools and the features that use them
+var doctorTools = []struct {
+	name    string
+	feature string
+}{
+	{"git", "line ages"},
+	{"gofmt", "formatting Go files"},
+}
+
+// DoctorCheck is the outcome of checking one platform prerequisite
+type DoctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // "ok", "warning", or "error"
+	Detail string `json:"detail"`
+}
+
+// doctorEnv is the view of the system the checks inspect, replaced in tests
+type doctorEnv struct {
+	goos     string
+	dataDir  string
+	getenv   func(string) string
+	glob     func(string) ([]string, error)
+	readFile func(string) ([]byte, error)
+	lookPath func(string) (string, error)
+}
+
+// RunDoctor checks the prerequisites Weld needs on this platform, for the
+// --doctor command-line mode. In portable mode the data directory beside the
+// executable is checked instead of the user config directory.
+func RunDoctor(portable bool) []DoctorCheck {
+	dataDir := defaultDataDir()
+	if portable {
+		if executable, err := executablePath(); err == nil {
+			dataDir = filepath.Join(portableRoot(executable), portableDataDirName)
+		}
+	}
+	return runDoctor(doctorEnv{
+		goos:     goruntime.GOOS,
+		dataDir:  dataDir,
+		getenv:   os.Getenv,
+		glob:     filepath.Glob,
+		readFile: os.ReadFile,
+		lookPath: exec.LookPath,
+	})
+}
+
+// runDoctor runs every check against an environment
+func runDoctor(env doctorEnv) []DoctorCheck {
+	checks := []DoctorCheck{checkWebView(env)}
+	if env.goos == "linux" {
+		checks = append(checks, checkInotify(env))
+	}
+	checks = append(checks, checkDataDir(env))
+	for _, tool := range doctorTools {
+		if path, err := env.lookPath(tool.name); err == nil {
+			checks = append(checks, DoctorCheck{Name: tool.name, Status: DoctorOK, Detail: path})
+		} else {
+			checks = append(checks, DoctorCheck{
+				Name:   tool.name,
+				Status: DoctorWarning,
+				Detail: fmt.Sprintf("not found on PATH; %s is unavailable", tool.feature),
+			})
+		}
+	}
+	return checks
+}
+
+// checkWebView looks for the web view runtime the window is drawn with
+func checkWebView(env doctorEnv) DoctorCheck {
+	check := DoctorCheck{Name: "WebView runtime"}
+	var patterns []string
+	switch env.goos {
+	case "darwin":
+		check.Status, check.Detail = DoctorOK, "WebKit is part of macOS"
+		return check
+	case "windows":
+		for _, variable := range []string{"ProgramFiles(x86)", "ProgramFiles", "LOCALAPPDATA"} {
+			if dir := env.getenv(variable); dir != "" {
+				patterns = append(patterns, filepath.Join(dir, "Microsoft", "EdgeWebView", "Application", "*", "msedgewebview2.exe"))
+			}
+		}
+	case "linux":
+		for _, dir := range []string{"/usr/lib", "/usr/lib64", "/usr/lib/*-linux-gnu", "/usr/local/lib"} {
+			patterns = append(patterns, filepath.Join(dir, "libwebkit2gtk-4.*.so*"))
+		}
+	default:
+		check.Status, check.Detail = DoctorWarning, "unsupported platform "+env.goos
+		return check
+	}
+
+	for _, pattern := range patterns {
+		if matches, _ := env.glob(pattern); len(matches) > 0 {
+			check.Status, check.Detail = DoctorOK, matches[0]
+			return check
+		}
+	}
+	check.Status = DoctorError
+	if env.goos == "windows" {
+		check.Detail = "Microsoft Edge WebView2 Runtime is not installed"
+	} else {
+		check.Detail = "WebKitGTK (libwebkit2gtk-4.0 or 4.1) is not installed"
+	}
+	return check
+}
+
+// checkInotify reads the inotify watch limit file watching depends on
+func checkInotify(env doctorEnv) DoctorCheck {
+	check := DoctorCheck{Name: "inotify watches"}
+	data, err := env.readFile("/proc/sys/fs/inotify/max_user_watches")
+	if err != nil {
+		check.Status, check.Detail = DoctorWarning, fmt.Sprintf("cannot read limit: %v", err)
+		return check
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil {
+		check.Status, check.Detail = DoctorWarning, fmt.Sprintf("cannot parse limit %q", strings.TrimSpace(string(data)))
+		return check
+	}
+	if limit < minInotifyWatches {
+		check.Status = DoctorWarning
+		check.Detail = fmt.Sprintf("limit is %d; raise fs.inotify.max_user_watches to at least %d if files stop reloading", limit, minInotifyWatches)
+		return check
+	}
+	check.Status, check.Detail = DoctorOK, fmt.Sprintf("limit is %d", limit)
+	return check
+}
+
+// checkDataDir verifies that settings and history can be written
+func checkDataDir(env doctorEnv) DoctorCheck {
+	check := DoctorCheck{Name: "data directory"}
+	if env.dataDir == "" {
+		check.Status, check.Detail = DoctorError, "no user config directory; run with --portable or --private"
+		return check
+	}
+	if err := os.MkdirAll(env.dataDir, 0755); err != nil {
+		check.Status, check.Detail = DoctorError, fmt.Sprintf("cannot create %s: %v", env.dataDir, err)
+		return check
+	}
+	probe, err := os.CreateTemp(env.dataDir, ".doctor-*")
+	if err != nil {
+		check.Status, check.Detail = DoctorError, fmt.Sprintf("%s is not writable: %v", env.dataDir, err)
+		return check
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+	check.Status, check.Detail = DoctorOK, env.dataDir
+	return check
+}
+
+// WriteDoctorReport prints checks as a readable report
+func WriteDoctorReport(w io.Writer, checks []DoctorCheck) error {
+	width := 0
+	for _, check := range checks {
+		width = max(width, len(check.Name))
+	}
+	for _, check := range checks {
+		if _, err := fmt.Fprintf(w, "[%-7s] %-*s  %s\n", check.Status, width, check.Name, check.Detail); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DoctorFailed reports whether any check found a problem that stops Weld
+// from running
+func DoctorFailed(checks []DoctorCheck) bool {
+	for _, check := range checks {
+		if check.Status == DoctorError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunDoctor(t *testing.T) {
+	files := map[string]string{"/proc/sys/fs/inotify/max_user_watches": "4096\n"}
+	env := doctorEnv{
+		goos:    "linux",
+		dataDir: filepath.Join(t.TempDir(), "Weld"),
+		getenv:  func(string) string { return "" },
+		glob: func(pattern string) ([]string, error) {
+			if pattern == "/usr/lib/*-linux-gnu/libwebkit2gtk-4.*.so*" {
+				return []string{"/usr/lib/x86_64-linux-gnu/libwebkit2gtk-4.1.so.0"}, nil
+			}
+			return nil, nil
+		},
+		readFile: func(path string) ([]byte, error) {
+			if data, ok := files[path]; ok {
+				return []byte(data), nil
+			}
+			return nil, errors.New("no such file")
+		},
+		lookPath: func(name string) (string, error) {
+			if name == "git" {
+				return "/usr/bin/git", nil
+			}
+			return "", errors.New("not found")
+		},
+	}
+
+	checks := runDoctor(env)
+	statuses := make(map[string]string)
+	for _, check := range checks {
+		statuses[check.Name] = check.Status
+	}
+	want := map[string]string{
+		"WebView runtime": DoctorOK,
+		"inotify watches": DoctorWarning,
+		"data directory":  DoctorOK,
+		"git":             DoctorOK,
+		"gofmt":           DoctorWarning,
+	}
+	for name, status := range want {
+		if statuses[name] != status {
+			t.Errorf("Expected %s to be %q, got %q", name, status, statuses[name])
+		}
+	}
+	if DoctorFailed(checks) {
+		t.Error("Expected warnings not to fail the doctor")
+	}
+
+	var report bytes.Buffer
+	if err := WriteDoctorReport(&report, checks); err != nil {
+		t.Fatalf("WriteDoctorReport returned error: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(report.String()), "\n"); len(lines) != len(checks) || !strings.Contains(lines[0], "libwebkit2gtk") {
+		t.Errorf("Unexpected report:\n%s", report.String())
+	}
+
+	// Without a web view the app cannot start
+	env.glob = func(string) ([]string, error) { return nil, nil }
+	if checks := runDoctor(env); !DoctorFailed(checks) {
+		t.Errorf("Expected a missing web view to fail, got %+v", checks)
+	}
+}
@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Accepted ranges of the editor font settings
+const (
+	minEditorFontSize   = 6
+	maxEditorFontSize   = 72
+	minEditorLineHeight = 1.0
+	maxEditorLineHeight = 3.0
+)
+
+// EditorFont holds how the text of the panes is rendered
+type EditorFont struct {
+	// Family is a CSS font-family list, such as "JetBrains Mono, monospace"
+	Family    string `json:"family"`
+	Size      int    `json:"size"` // pixels
+	Ligatures bool   `json:"ligatures"`
+	// LineHeight is a multiple of the font size
+	LineHeight float64 `json:"lineHeight"`
+}
+
+// defaultEditorFont matches the styling the panes used before it was configurable
+func defaultEditorFont() EditorFont {
+	return EditorFont{Family: "monospace", Size: 13, LineHeight: 1.5}
+}
+
+// GetEditorFont returns the font settings of the panes
+func (a *App) GetEditorFont() EditorFont {
+	return a.settings.Get().EditorFont
+}
+
+// SetEditorFont sets the font settings of the panes and emits
+// "editor-font-changed" so open windows restyle without reloading
+func (a *App) SetEditorFont(font EditorFont) error {
+	font.Family = strings.TrimSpace(font.Family)
+	if err := validateEditorFont(font); err != nil {
+		return err
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.EditorFont = font
+		return nil
+	})
+
+	a.runtime().EventsEmit("editor-font-changed", font)
+	return err
+}
+
+// validateEditorFont checks font settings, keeping the family to a plain list
+// of names so it cannot break out of the style it is placed in
+func validateEditorFont(font EditorFont) error {
+	if font.Family == "" {
+		return fmt.Errorf("font family cannot be empty")
+	}
+	if strings.ContainsAny(font.Family, ";{}<>\\") {
+		return fmt.Errorf("invalid font family: %s", font.Family)
+	}
+	if font.Size < minEditorFontSize || font.Size > maxEditorFontSize {
+		return fmt.Errorf("font size must be between %d and %d", minEditorFontSize, maxEditorFontSize)
+	}
+	if font.LineHeight < minEditorLineHeight || font.LineHeight > maxEditorLineHeight {
+		return fmt.Errorf("line height must be between %.1f and %.1f", minEditorLineHeight, maxEditorLineHeight)
+	}
+	return nil
+}
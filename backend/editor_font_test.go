@@ -0,0 +1,45 @@
+package backend
+
+import "testing"
+
+func TestApp_EditorFont(t *testing.T) {
+	storage := NewMemoryStorage()
+	app := NewApp()
+	app.Storage = storage
+
+	if font := app.GetEditorFont(); font != defaultEditorFont() {
+		t.Errorf("Expected the default font, got %+v", font)
+	}
+
+	want := EditorFont{Family: "Fira Code, monospace", Size: 15, Ligatures: true, LineHeight: 1.6}
+	if err := app.SetEditorFont(EditorFont{Family: "  Fira Code, monospace ", Size: 15, Ligatures: true, LineHeight: 1.6}); err != nil {
+		t.Fatalf("SetEditorFont returned error: %v", err)
+	}
+	if font := app.GetEditorFont(); font != want {
+		t.Errorf("Expected %+v, got %+v", want, font)
+	}
+
+	reloaded := NewApp()
+	reloaded.Storage = storage
+	if err := reloaded.loadSettings(); err != nil {
+		t.Fatalf("loadSettings returned error: %v", err)
+	}
+	if font := reloaded.GetEditorFont(); font != want {
+		t.Errorf("Expected the font to persist, got %+v", font)
+	}
+
+	invalid := []EditorFont{
+		{Family: "", Size: 13, LineHeight: 1.5},
+		{Family: "mono; color: red", Size: 13, LineHeight: 1.5},
+		{Family: "monospace", Size: 2, LineHeight: 1.5},
+		{Family: "monospace", Size: 13, LineHeight: 0.5},
+	}
+	for _, font := range invalid {
+		if err := app.SetEditorFont(font); err == nil {
+			t.Errorf("Expected an error for %+v", font)
+		}
+	}
+	if font := app.GetEditorFont(); font != want {
+		t.Errorf("Expected invalid fonts to change nothing, got %+v", font)
+	}
+}
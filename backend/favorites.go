@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// GetFavoriteDirectories returns the pinned directories shown as quick-access
+// locations when selecting files. Directories that no longer exist are skipped.
+func (a *App) GetFavoriteDirectories() []string {
+	favorites := a.settings.Get().FavoriteDirectories
+	existing := make([]string, 0, len(favorites))
+	for _, dir := range favorites {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			existing = append(existing, dir)
+		}
+	}
+	return existing
+}
+
+// AddFavoriteDirectory pins a directory to the quick-access list. An empty
+// path pins the directory of the most recently selected file.
+func (a *App) AddFavoriteDirectory(dir string) error {
+	if dir == "" {
+		dir = a.lastUsedDirectory
+	}
+	if dir == "" {
+		return fmt.Errorf("no directory to add to favorites")
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving directory path: %w", err)
+	}
+
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return fmt.Errorf("error reading directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("not a directory: %s", absDir)
+	}
+
+	return a.settings.Update(func(s *Settings) error {
+		if !slices.Contains(s.FavoriteDirectories, absDir) {
+			s.FavoriteDirectories = append(slices.Clip(s.FavoriteDirectories), absDir)
+		}
+		return nil
+	})
+}
+
+// RemoveFavoriteDirectory unpins a directory from the quick-access list
+func (a *App) RemoveFavoriteDirectory(dir string) error {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("error resolving directory path: %w", err)
+	}
+
+	return a.settings.Update(func(s *Settings) error {
+		index := slices.Index(s.FavoriteDirectories, absDir)
+		if index < 0 {
+			return fmt.Errorf("directory is not a favorite: %s", absDir)
+		}
+		s.FavoriteDirectories = slices.Delete(slices.Clone(s.FavoriteDirectories), index, index+1)
+		return nil
+	})
+}
+
+// SelectFileFromDirectory opens the file dialog starting in the given directory,
+// typically one of the favorite directories
+func (a *App) SelectFileFromDirectory(dir string) (string, error) {
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("directory does not exist: %s", dir)
+	}
+	return a.selectFileIn(dir)
+}
@@ -0,0 +1,102 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApp_FavoriteDirectories(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewFileStorage(t.TempDir())
+
+	projectA := t.TempDir()
+	projectB := t.TempDir()
+
+	t.Run("add favorites", func(t *testing.T) {
+		if err := app.AddFavoriteDirectory(projectA); err != nil {
+			t.Fatalf("AddFavoriteDirectory returned error: %v", err)
+		}
+		if err := app.AddFavoriteDirectory(projectB); err != nil {
+			t.Fatalf("AddFavoriteDirectory returned error: %v", err)
+		}
+		// Adding a duplicate is a no-op
+		if err := app.AddFavoriteDirectory(projectA); err != nil {
+			t.Fatalf("AddFavoriteDirectory returned error for duplicate: %v", err)
+		}
+
+		expected := []string{projectA, projectB}
+		if got := app.GetFavoriteDirectories(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("GetFavoriteDirectories returned %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("empty path uses last used directory", func(t *testing.T) {
+		other := NewApp()
+		if err := other.AddFavoriteDirectory(""); err == nil {
+			t.Error("Expected error when there is no last used directory")
+		}
+
+		other.lastUsedDirectory = projectB
+		if err := other.AddFavoriteDirectory(""); err != nil {
+			t.Fatalf("AddFavoriteDirectory returned error: %v", err)
+		}
+		if got := other.GetFavoriteDirectories(); !reflect.DeepEqual(got, []string{projectB}) {
+			t.Errorf("Expected last used directory to be pinned, got %v", got)
+		}
+	})
+
+	t.Run("rejects files and missing paths", func(t *testing.T) {
+		file := filepath.Join(projectA, "file.txt")
+		if err := os.WriteFile(file, []byte("content"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		if err := app.AddFavoriteDirectory(file); err == nil {
+			t.Error("Expected error when adding a file as favorite")
+		}
+		if err := app.AddFavoriteDirectory(filepath.Join(projectA, "missing")); err == nil {
+			t.Error("Expected error when adding a missing directory")
+		}
+	})
+
+	t.Run("favorites persist", func(t *testing.T) {
+		reloaded := NewApp()
+		reloaded.Storage = app.Storage
+		if err := reloaded.loadSettings(); err != nil {
+			t.Fatalf("loadSettings returned error: %v", err)
+		}
+
+		expected := []string{projectA, projectB}
+		if got := reloaded.GetFavoriteDirectories(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Reloaded favorites %v, expected %v", got, expected)
+		}
+	})
+
+	t.Run("remove favorite", func(t *testing.T) {
+		if err := app.RemoveFavoriteDirectory(projectA); err != nil {
+			t.Fatalf("RemoveFavoriteDirectory returned error: %v", err)
+		}
+		if got := app.GetFavoriteDirectories(); !reflect.DeepEqual(got, []string{projectB}) {
+			t.Errorf("Expected only %s to remain, got %v", projectB, got)
+		}
+		if err := app.RemoveFavoriteDirectory(projectA); err == nil {
+			t.Error("Expected error when removing a directory that is not a favorite")
+		}
+	})
+
+	t.Run("missing directories are hidden", func(t *testing.T) {
+		gone := filepath.Join(t.TempDir(), "gone")
+		if err := os.Mkdir(gone, 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := app.AddFavoriteDirectory(gone); err != nil {
+			t.Fatalf("AddFavoriteDirectory returned error: %v", err)
+		}
+		os.Remove(gone)
+
+		if got := app.GetFavoriteDirectories(); !reflect.DeepEqual(got, []string{projectB}) {
+			t.Errorf("Expected missing directory to be hidden, got %v", got)
+		}
+	})
+}
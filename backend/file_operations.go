@@ -2,20 +2,21 @@ package backend
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
-	"io"
 	"os"
 	"path/filepath"
-	"sync"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"weld/backend/diff"
+	"weld/backend/fileio"
 )
 
-// In-memory storage for unsaved file changes with thread safety
-var (
-	fileCache      = make(map[string][]string)
-	fileCacheMutex sync.RWMutex
-)
+// maxDiffLines is the largest file, in lines, that is diffed whole. Larger
+// files are compared a window at a time, or can be mapped by region with
+// CompareLargeFiles.
+const maxDiffLines = 100000
 
 // SelectFile opens a file dialog and returns the selected file path
 func (a *App) SelectFile() (string, error) {
@@ -32,10 +33,17 @@ func (a *App) SelectFile() (string, error) {
 		}
 	}
 
-	file, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+	return a.selectFileIn(defaultDir)
+}
+
+// selectFileIn opens a file dialog in defaultDir and validates the selection
+func (a *App) selectFileIn(defaultDir string) (string, error) {
+	showHidden := a.settings.Get().ShowHiddenFiles
+
+	file, err := a.dialogs().OpenFile(runtime.OpenDialogOptions{
 		Title:                      "Select File to Compare",
 		DefaultDirectory:           defaultDir,
-		ShowHiddenFiles:            true,
+		ShowHiddenFiles:            showHidden,
 		CanCreateDirectories:       false,
 		ResolvesAliases:            true,
 		TreatPackagesAsDirectories: false,
@@ -60,48 +68,13 @@ func (a *App) SelectFile() (string, error) {
 // IsBinaryFile checks if a file is binary by reading the first 512 bytes
 // and looking for null bytes or other non-text indicators
 func IsBinaryFile(filepath string) (bool, error) {
-	file, err := os.Open(filepath)
-	if err != nil {
+	// Virtual files are always text
+	if IsVirtualPath(filepath) {
+		_, err := readVirtualFile(filepath)
 		return false, err
 	}
-	defer file.Close()
-
-	// Read first 512 bytes (or less if file is smaller)
-	buf := make([]byte, 512)
-	n, err := file.Read(buf)
-	if err != nil && err != io.EOF {
-		return false, err
-	}
-
-	// Empty files are considered text
-	if n == 0 {
-		return false, nil
-	}
-
-	// Check for null bytes, which are a strong indicator of binary content
-	for i := 0; i < n; i++ {
-		if buf[i] == 0 {
-			return true, nil
-		}
-	}
-
-	// Additional check: count non-printable characters
-	// If more than 30% of characters are non-printable, consider it binary
-	nonPrintable := 0
-	for i := 0; i < n; i++ {
-		b := buf[i]
-		// Check if character is printable ASCII or common whitespace
-		if (b < 32 || b > 126) && b != '\t' && b != '\n' && b != '\r' {
-			nonPrintable++
-		}
-	}
-
-	// If more than 30% non-printable, consider it binary
-	if float64(nonPrintable)/float64(n) > 0.3 {
-		return true, nil
-	}
 
-	return false, nil
+	return fileio.IsBinary(filepath)
 }
 
 // ReadFileContent reads the content of a file and returns it as lines
@@ -109,6 +82,9 @@ func (a *App) ReadFileContent(filepath string) ([]string, error) {
 	if filepath == "" {
 		return []string{}, nil
 	}
+	if IsVirtualPath(filepath) {
+		return readVirtualFile(filepath)
+	}
 
 	// Check if file is binary before attempting to read as text
 	isBinary, err := IsBinaryFile(filepath)
@@ -119,14 +95,15 @@ func (a *App) ReadFileContent(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("cannot read binary file: %s", filepath)
 	}
 
-	file, err := os.Open(filepath)
+	// A file still being written, such as a live log, is read as it was at
+	// one moment rather than torn mid-write
+	data, err := fileio.ReadStable(filepath)
 	if err != nil {
 		return nil, err
 	}
-	defer file.Close()
 
 	var lines []string
-	scanner := bufio.NewScanner(file)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
 	// Increase buffer size to handle long lines (e.g., minified files)
 	// Default is 64KB, we set to 1MB to handle most practical cases
 	const maxScanTokenSize = 1024 * 1024 // 1MB
@@ -143,11 +120,7 @@ func (a *App) ReadFileContent(filepath string) ([]string, error) {
 // ReadFileContentWithCache checks memory cache first before reading from disk
 func (a *App) ReadFileContentWithCache(filepath string) ([]string, error) {
 	// Check memory cache first
-	fileCacheMutex.RLock()
-	cachedLines, exists := fileCache[filepath]
-	fileCacheMutex.RUnlock()
-
-	if exists {
+	if cachedLines, exists := a.compare.Edited(filepath); exists {
 		return cachedLines, nil
 	}
 
@@ -157,37 +130,52 @@ func (a *App) ReadFileContentWithCache(filepath string) ([]string, error) {
 
 // storeFileInMemory stores file lines in the memory cache
 func (a *App) storeFileInMemory(filepath string, lines []string) error {
-	fileCacheMutex.Lock()
-	fileCache[filepath] = lines
-	fileCacheMutex.Unlock()
+	a.compare.SetEdited(filepath, lines)
 	return nil
 }
 
-// CopyToFile copies a line from source to target file in memory
-func (a *App) CopyToFile(sourceFile, targetFile string, lineNumber int, lineContent string) error {
+// editFileInMemory replaces the lines of a file, read from the cache or disk,
+// with the lines edit returns. Edits to files run one at a time.
+func (a *App) editFileInMemory(path string, edit func(lines []string) ([]string, error)) error {
 	// Read target file from cache if available, otherwise from disk
-	targetLines, err := a.ReadFileContentWithCache(targetFile)
-	if err != nil {
-		return fmt.Errorf("failed to read target file: %w", err)
-	}
+	return a.compare.Edit(path, func(path string) ([]string, error) {
+		lines, err := a.ReadFileContent(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read target file: %w", err)
+		}
+		return lines, nil
+	}, edit)
+}
 
-	// Insert line at specified position (1-based line numbers)
-	insertIndex := lineNumber - 1
-	if insertIndex < 0 {
-		insertIndex = 0
-	}
-	if insertIndex > len(targetLines) {
-		insertIndex = len(targetLines)
-	}
+// CopyToFile copies a line from source to target file in memory
+func (a *App) CopyToFile(sourceFile, targetFile string, lineNumber int, lineContent string) error {
+	a.RecordActivity()
+	a.operationMutex.Lock()
+	defer a.operationMutex.Unlock()
+	return a.copyToFileLocked(sourceFile, targetFile, lineNumber, lineContent)
+}
 
-	// Create new slice with inserted line
-	newLines := make([]string, 0, len(targetLines)+1)
-	newLines = append(newLines, targetLines[:insertIndex]...)
-	newLines = append(newLines, lineContent)
-	newLines = append(newLines, targetLines[insertIndex:]...)
+// copyToFileLocked copies a line into a file in memory and records it for
+// undo (must be called with operationMutex held)
+func (a *App) copyToFileLocked(sourceFile, targetFile string, lineNumber int, lineContent string) error {
+	var insertIndex int
+	err := a.editFileInMemory(targetFile, func(targetLines []string) ([]string, error) {
+		// Insert line at specified position (1-based line numbers)
+		insertIndex = lineNumber - 1
+		if insertIndex < 0 {
+			insertIndex = 0
+		}
+		if insertIndex > len(targetLines) {
+			insertIndex = len(targetLines)
+		}
 
-	// Store in memory
-	err = a.storeFileInMemory(targetFile, newLines)
+		// Create new slice with inserted line
+		newLines := make([]string, 0, len(targetLines)+1)
+		newLines = append(newLines, targetLines[:insertIndex]...)
+		newLines = append(newLines, lineContent)
+		newLines = append(newLines, targetLines[insertIndex:]...)
+		return newLines, nil
+	})
 	if err != nil {
 		return err
 	}
@@ -207,29 +195,32 @@ func (a *App) CopyToFile(sourceFile, targetFile string, lineNumber int, lineCont
 
 // RemoveLineFromFile removes a line from a file in memory
 func (a *App) RemoveLineFromFile(targetFile string, lineNumber int) error {
+	a.RecordActivity()
+	a.operationMutex.Lock()
+	defer a.operationMutex.Unlock()
+	return a.removeLineFromFileLocked(targetFile, lineNumber)
+}
 
-	// Read target file from cache if available, otherwise from disk
-	targetLines, err := a.ReadFileContentWithCache(targetFile)
-	if err != nil {
-		return fmt.Errorf("failed to read target file: %w", err)
-	}
-
-	// Remove line at specified position (1-based line numbers)
-	removeIndex := lineNumber - 1
-	if removeIndex < 0 || removeIndex >= len(targetLines) {
-		return fmt.Errorf("line number %d is out of range", lineNumber)
-	}
-
-	// Store the line content before removing (for undo)
-	removedContent := targetLines[removeIndex]
+// removeLineFromFileLocked removes a line from a file in memory and records
+// it for undo (must be called with operationMutex held)
+func (a *App) removeLineFromFileLocked(targetFile string, lineNumber int) error {
+	var removedContent string
+	err := a.editFileInMemory(targetFile, func(targetLines []string) ([]string, error) {
+		// Remove line at specified position (1-based line numbers)
+		removeIndex := lineNumber - 1
+		if removeIndex < 0 || removeIndex >= len(targetLines) {
+			return nil, fmt.Errorf("line number %d is out of range", lineNumber)
+		}
 
-	// Create new slice without the line
-	newLines := make([]string, 0, len(targetLines)-1)
-	newLines = append(newLines, targetLines[:removeIndex]...)
-	newLines = append(newLines, targetLines[removeIndex+1:]...)
+		// Store the line content before removing (for undo)
+		removedContent = targetLines[removeIndex]
 
-	// Store in memory
-	err = a.storeFileInMemory(targetFile, newLines)
+		// Create new slice without the line
+		newLines := make([]string, 0, len(targetLines)-1)
+		newLines = append(newLines, targetLines[:removeIndex]...)
+		newLines = append(newLines, targetLines[removeIndex+1:]...)
+		return newLines, nil
+	})
 	if err != nil {
 		return err
 	}
@@ -249,6 +240,74 @@ func (a *App) RemoveLineFromFile(targetFile string, lineNumber int) error {
 
 // CompareFiles compares two files and returns diff results
 func (a *App) CompareFiles(leftPath, rightPath string) (*DiffResult, error) {
+	return a.CompareFilesWithOptions(leftPath, rightPath, CompareOptions{})
+}
+
+// CompareFilesWithOptions compares two files, overriding settings such as the
+// tab width or the whitespace differences to ignore for this comparison and
+// the re-diffs that follow edits to it. It cancels any comparison running in
+// the background, which would otherwise replace this one when it finished.
+func (a *App) CompareFilesWithOptions(leftPath, rightPath string, options CompareOptions) (*DiffResult, error) {
+	a.RecordActivity()
+	a.CancelCompare()
+	if !diff.IsWhitespaceMode(options.Whitespace) {
+		return nil, fmt.Errorf("unknown whitespace mode: %s", options.Whitespace)
+	}
+	for _, column := range options.KeyColumns {
+		if column < 0 {
+			return nil, fmt.Errorf("invalid key column: %d", column)
+		}
+	}
+	options = a.resolveCompareOptions(options)
+	result, err := a.computeDiff(leftPath, rightPath, options)
+	if err != nil {
+		return nil, err
+	}
+	return a.startComparison(leftPath, rightPath, options, result), nil
+}
+
+// startComparison makes a computed diff the current comparison, starting the
+// watching and record keeping that go with it, and returns it for display
+func (a *App) startComparison(leftPath, rightPath string, options CompareOptions, result *DiffResult) *DiffResult {
+	// Keep the result so hunks can be referenced by ID
+	a.setCurrentComparison(leftPath, rightPath, options, result)
+
+	// Start summarizing the merge of this pair
+	a.startMergeSession(leftPath, rightPath)
+
+	// Start watching these files for changes
+	a.StartFileWatching(leftPath, rightPath)
+
+	// Virtual files do not outlive the session, so keep them out of history
+	if !IsVirtualPath(leftPath) && !IsVirtualPath(rightPath) {
+		// Remember the pair for counterpart suggestions
+		a.recordRecentComparison(leftPath, rightPath)
+
+		// Track how the amount of change develops across runs
+		if err := a.recordComparisonRun(leftPath, rightPath, result); err != nil {
+			a.runtime().LogErrorf("Failed to record comparison statistics: %v", err)
+		}
+	}
+
+	return a.displayResult(result)
+}
+
+// computeDiff validates and reads both files, then runs the diff algorithm
+// without any of the side effects of starting a comparison
+func (a *App) computeDiff(leftPath, rightPath string, options CompareOptions) (*DiffResult, error) {
+	return a.computeDiffContext(context.Background(), leftPath, rightPath, options, nil)
+}
+
+// computeDiffContext is computeDiff reporting each stage to progress, when
+// given, and giving up once ctx is cancelled
+func (a *App) computeDiffContext(ctx context.Context, leftPath, rightPath string, options CompareOptions, progress func(stage string)) (*DiffResult, error) {
+	report := func(stage string) error {
+		if progress != nil {
+			progress(stage)
+		}
+		return ctx.Err()
+	}
+
 	// Validate both files exist and are not empty paths
 	if leftPath == "" || rightPath == "" {
 		return nil, fmt.Errorf("file paths cannot be empty")
@@ -271,6 +330,9 @@ func (a *App) CompareFiles(leftPath, rightPath string) (*DiffResult, error) {
 		return nil, fmt.Errorf("cannot compare binary file: %s", filepath.Base(rightPath))
 	}
 
+	if err := report(CompareStageReading); err != nil {
+		return nil, err
+	}
 	leftLines, err := a.ReadFileContentWithCache(leftPath)
 	if err != nil {
 		return nil, fmt.Errorf("error reading left file: %w", err)
@@ -281,75 +343,157 @@ func (a *App) CompareFiles(leftPath, rightPath string) (*DiffResult, error) {
 		return nil, fmt.Errorf("error reading right file: %w", err)
 	}
 
-	// Additional safety check for very large files that might cause memory issues
-	const maxLines = 100000
-	if len(leftLines) > maxLines || len(rightLines) > maxLines {
-		return nil, fmt.Errorf("file too large for comparison (max %d lines)", maxLines)
+	if err := report(CompareStageComparing); err != nil {
+		return nil, err
 	}
 
-	result := a.diffAlgorithm.ComputeDiff(leftLines, rightLines)
+	algorithm := a.algorithmFor(a.resolveCompareOptions(options))
+	algorithm, metadata := a.tuneAlgorithm(algorithm, leftPath, leftLines)
+	// Unchanged content compared the same way gives the same result
+	cacheKey := diffCacheKey(leftPath, rightPath, leftLines, rightLines, options, algorithm, a.GetAlignImports())
+	result, cached := a.diffCache.get(cacheKey)
+	if !cached {
+		// Cancelling stops the diff early, leaving a result to discard
+		running := diff.WithContext(ctx, algorithm)
+		if len(leftLines) > maxDiffLines || len(rightLines) > maxDiffLines {
+			result = diff.SegmentedDiff(leftLines, rightLines, running)
+		} else {
+			result = a.diffContent(leftPath, rightPath, leftLines, rightLines, options, running)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.Metadata = metadata
+		result.Minified = diff.IsMinified(leftLines) || diff.IsMinified(rightLines)
+		diff.MarkIdentical(result, leftLines)
+		// A result cut short by the time budget may be completed another time
+		if !result.Approximate {
+			a.diffCache.put(cacheKey, result)
+		}
+	}
+	summarizeWhitespace(result, leftPath, rightPath, leftLines, rightLines)
 
-	// Start watching these files for changes
-	a.StartFileWatching(leftPath, rightPath)
+	if err := report(CompareStageFinishing); err != nil {
+		return nil, err
+	}
 
+	// Line ages come from git and are only looked up when they are shown
+	if a.GetShowLineAges() {
+		a.annotateLineTimes(result, leftPath, rightPath, leftLines, rightLines)
+	}
 	return result, nil
 }
 
-// DiscardAllChanges clears all cached file changes
-func (a *App) DiscardAllChanges() error {
-	// Clear the entire cache
-	fileCacheMutex.Lock()
-	fileCache = make(map[string][]string)
-	fileCacheMutex.Unlock()
-	return nil
+// diffContent runs the comparison suited to the files: by declaration for Go
+// in semantic mode, with import sections as sets, or line by line
+func (a *App) diffContent(leftPath, rightPath string, leftLines, rightLines []string, options CompareOptions, algorithm diff.Algorithm) *DiffResult {
+	var result *DiffResult
+	// Files that do not parse fall back to a line diff
+	switch semanticFormat(options, leftPath, rightPath) {
+	case diff.LanguageGo:
+		result, _ = diff.SemanticGoDiff(leftLines, rightLines, algorithm)
+	case semanticJSON:
+		result, _ = diff.SemanticJSONDiff(leftLines, rightLines, algorithm)
+	case semanticYAML:
+		result, _ = diff.SemanticYAMLDiff(leftLines, rightLines, algorithm, options.IgnoreComments)
+	case semanticXML:
+		result, _ = diff.SemanticXMLDiff(leftLines, rightLines, algorithm, options.IgnoreComments)
+	case semanticConfig:
+		result, _ = diff.SemanticConfigDiff(leftLines, rightLines, algorithm, options.IgnoreComments)
+	case semanticCSV:
+		result, _ = diff.KeyedCSVDiff(leftLines, rightLines, diff.CSVDelimiter(leftPath), options.KeyColumns)
+	case semanticProse:
+		result, _ = diff.ProseDiff(leftLines, rightLines, algorithm)
+	}
+	// Content that only moved renders as a wall of changes, so flag it to
+	// let the user switch to a sorted comparison
+	if result == nil && diff.IsReordered(leftLines, rightLines) {
+		result = algorithm.ComputeDiff(leftLines, rightLines)
+		result.Reordered = true
+	} else if result == nil && a.GetAlignImports() {
+		// Compare import sections as a set when both files are in the same language
+		if language := diff.LanguageForPath(leftPath); language != "" && language == diff.LanguageForPath(rightPath) {
+			result = diff.AlignImports(leftLines, rightLines, language, algorithm)
+		}
+	}
+	if result == nil {
+		result = algorithm.ComputeDiff(leftLines, rightLines)
+	}
+	return result
 }
 
-// HasUnsavedChanges checks if a file has unsaved changes in the cache
-func (a *App) HasUnsavedChanges(filepath string) bool {
-	fileCacheMutex.RLock()
-	_, exists := fileCache[filepath]
-	fileCacheMutex.RUnlock()
-	return exists
+// FilesIdentical reports whether two files have no differences under the
+// current comparison settings, without starting a comparison
+func (a *App) FilesIdentical(leftPath, rightPath string) (bool, error) {
+	result, err := a.computeDiff(leftPath, rightPath, a.resolveCompareOptions(CompareOptions{}))
+	if err != nil {
+		return false, err
+	}
+	return result.Identical, nil
 }
 
-// GetUnsavedFilesList returns a list of files with unsaved changes
-func (a *App) GetUnsavedFilesList() []string {
-	fileCacheMutex.RLock()
-	files := make([]string, 0, len(fileCache))
-	for filepath := range fileCache {
-		files = append(files, filepath)
+// CompareFilesSorted diffs two files with their lines sorted, the follow-up for
+// files whose content is identical but ordered differently. The result is for
+// viewing only and does not replace the current comparison used by hunk operations.
+func (a *App) CompareFilesSorted(leftPath, rightPath string) (*DiffResult, error) {
+	a.RecordActivity()
+	if leftPath == "" || rightPath == "" {
+		return nil, fmt.Errorf("file paths cannot be empty")
 	}
-	fileCacheMutex.RUnlock()
-	return files
+
+	leftLines, err := a.ReadFileContentWithCache(leftPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading left file: %w", err)
+	}
+	rightLines, err := a.ReadFileContentWithCache(rightPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading right file: %w", err)
+	}
+
+	algorithm := a.algorithmFor(a.resolveCompareOptions(a.comparisonOptions(leftPath, rightPath)))
+	algorithm = algorithmForSize(algorithm, leftLines, rightLines)
+	result := diff.SortedDiff(leftLines, rightLines, algorithm)
+	result.Reordered = diff.IsReordered(leftLines, rightLines)
+	return result, nil
 }
 
-// Test helpers - only for use in tests
+// CompareFilesTokenized diffs two files with their minified lines split into
+// segments at statement and block delimiters, the follow-up for results
+// flagged Minified. Like CompareFilesSorted, the result is for viewing only.
+func (a *App) CompareFilesTokenized(leftPath, rightPath string) (*DiffResult, error) {
+	a.RecordActivity()
+	if leftPath == "" || rightPath == "" {
+		return nil, fmt.Errorf("file paths cannot be empty")
+	}
 
-// TestResetFileCache clears the file cache - FOR TESTING ONLY
-func TestResetFileCache() {
-	fileCacheMutex.Lock()
-	fileCache = make(map[string][]string)
-	fileCacheMutex.Unlock()
+	leftLines, err := a.ReadFileContentWithCache(leftPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading left file: %w", err)
+	}
+	rightLines, err := a.ReadFileContentWithCache(rightPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading right file: %w", err)
+	}
+
+	result := diff.TokenizedDiff(leftLines, rightLines, a.algorithmFor(a.resolveCompareOptions(CompareOptions{})))
+	result.Minified = diff.IsMinified(leftLines) || diff.IsMinified(rightLines)
+	return result, nil
 }
 
-// TestSetFileCache sets a file in the cache - FOR TESTING ONLY
-func TestSetFileCache(filepath string, lines []string) {
-	fileCacheMutex.Lock()
-	fileCache[filepath] = lines
-	fileCacheMutex.Unlock()
+// DiscardAllChanges clears all cached file changes
+func (a *App) DiscardAllChanges() error {
+	// Clear the entire cache
+	a.compare.DiscardAll()
+	return nil
 }
 
-// TestGetFileCache gets a file from the cache - FOR TESTING ONLY
-func TestGetFileCache(filepath string) ([]string, bool) {
-	fileCacheMutex.RLock()
-	lines, exists := fileCache[filepath]
-	fileCacheMutex.RUnlock()
-	return lines, exists
+// HasUnsavedChanges checks if a file has unsaved changes in the cache
+func (a *App) HasUnsavedChanges(filepath string) bool {
+	_, exists := a.compare.Edited(filepath)
+	return exists
 }
 
-// TestDeleteFromCache removes a file from the cache - FOR TESTING ONLY
-func TestDeleteFromCache(filepath string) {
-	fileCacheMutex.Lock()
-	delete(fileCache, filepath)
-	fileCacheMutex.Unlock()
+// GetUnsavedFilesList returns a list of files with unsaved changes
+func (a *App) GetUnsavedFilesList() []string {
+	return a.compare.EditedPaths()
 }
@@ -0,0 +1,49 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/watch"
+)
+
+// Bounds and default for the interval at which files are polled when they
+// cannot be watched
+const (
+	defaultPollIntervalMs = 2000
+	minPollIntervalMs     = 250
+)
+
+// reportPollingFallback warns the frontend that change detection is slower
+// for the files that could not be watched and are polled instead
+func (a *App) reportPollingFallback(paths []string, cause error) {
+	interval := a.GetPollInterval()
+	a.runtime().LogWarningf("File watching failed, polling every %dms instead: %v", interval, cause)
+	a.runtime().EventsEmit("watch-fallback", map[string]interface{}{
+		"paths":      paths,
+		"error":      cause.Error(),
+		"hint":       watch.ErrorHint(cause),
+		"intervalMs": interval,
+	})
+}
+
+// GetPollInterval returns the milliseconds between checks of files that
+// cannot be watched
+func (a *App) GetPollInterval() int {
+	interval := a.settings.Get().PollIntervalMs
+	if interval < minPollIntervalMs {
+		return defaultPollIntervalMs
+	}
+	return interval
+}
+
+// SetPollInterval sets the milliseconds between checks of files that cannot
+// be watched. A running poller picks up the interval on the next comparison.
+func (a *App) SetPollInterval(intervalMs int) error {
+	if intervalMs < minPollIntervalMs {
+		return fmt.Errorf("poll interval must be at least %dms", minPollIntervalMs)
+	}
+	return a.settings.Update(func(s *Settings) error {
+		s.PollIntervalMs = intervalMs
+		return nil
+	})
+}
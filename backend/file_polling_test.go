@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_PollingFallback(t *testing.T) {
+	app := NewApp()
+	if err := app.SetPollInterval(minPollIntervalMs); err != nil {
+		t.Fatalf("SetPollInterval returned error: %v", err)
+	}
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	if err := os.WriteFile(leftPath, []byte("left"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	// The right file does not exist yet, so it cannot be watched
+	app.StartFileWatching(leftPath, rightPath)
+	if paths := app.GetWatchStatus().Paths; paths[0].Method != "events" || paths[1].Method != "polling" {
+		t.Fatal("Expected only the unwatchable file to be polled")
+	}
+
+	if err := os.WriteFile(rightPath, []byte("right"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if last := app.GetWatchStatus().Paths[1].LastEvent; last != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the poller to notice the new file")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	app.StopFileWatching()
+	if app.watcher.Watching() {
+		t.Error("Expected stopping to stop the poller")
+	}
+}
+
+func TestApp_SetPollInterval(t *testing.T) {
+	app := NewApp()
+	if got := app.GetPollInterval(); got != defaultPollIntervalMs {
+		t.Errorf("Expected default interval %d, got %d", defaultPollIntervalMs, got)
+	}
+	if err := app.SetPollInterval(500); err != nil {
+		t.Fatalf("SetPollInterval returned error: %v", err)
+	}
+	if got := app.GetPollInterval(); got != 500 {
+		t.Errorf("Expected interval 500, got %d", got)
+	}
+	if err := app.SetPollInterval(minPollIntervalMs - 1); err == nil {
+		t.Error("Expected error for an interval below the minimum")
+	}
+}
@@ -0,0 +1,33 @@
+package fileio
+
+import (
+	"bytes"
+	"os"
+)
+
+// Line ending styles of a file
+const (
+	LineEndingLF    = "lf"
+	LineEndingCRLF  = "crlf"
+	LineEndingMixed = "mixed"
+)
+
+// DetectLineEnding returns the line ending style of a file, or "" when it
+// has no line breaks
+func DetectLineEnding(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	crlf := bytes.Count(data, []byte("\r\n"))
+	lf := bytes.Count(data, []byte("\n")) - crlf
+	switch {
+	case crlf > 0 && lf > 0:
+		return LineEndingMixed, nil
+	case crlf > 0:
+		return LineEndingCRLF, nil
+	case lf > 0:
+		return LineEndingLF, nil
+	}
+	return "", nil
+}
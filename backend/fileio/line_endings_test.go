@@ -0,0 +1,30 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectLineEnding(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		content  string
+		expected string
+	}{
+		{"a\nb\n", LineEndingLF},
+		{"a\r\nb\r\n", LineEndingCRLF},
+		{"a\r\nb\n", LineEndingMixed},
+		{"single line", ""},
+	}
+	for i, tt := range tests {
+		path := filepath.Join(dir, filepath.Base(t.Name())+string(rune('a'+i)))
+		if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		got, err := DetectLineEnding(path)
+		if err != nil || got != tt.expected {
+			t.Errorf("DetectLineEnding(%q) = %q, %v; expected %q", tt.content, got, err, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package fileio
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+)
+
+// maxStableReadAttempts is how many times a file that changes while it is
+// read is read again before settling for the complete lines of the last read
+const maxStableReadAttempts = 3
+
+// ReadStable reads a file as it was at one moment. The file is read up to
+// the size it had when opened and is read again if its size or modification
+// time changed meanwhile, as when a log is appended to. A file that keeps
+// changing is returned up to its last complete line, so a line still being
+// written is not shown cut short.
+func ReadStable(path string) ([]byte, error) {
+	var data []byte
+	for attempt := 0; attempt < maxStableReadAttempts; attempt++ {
+		before := StampOf(path)
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		data, err = io.ReadAll(io.LimitReader(file, before.Size))
+		file.Close()
+		if err != nil {
+			return nil, err
+		}
+		if StampOf(path) == before && int64(len(data)) == before.Size {
+			return data, nil
+		}
+	}
+	if end := bytes.LastIndexByte(data, '\n'); end >= 0 {
+		return data[:end+1], nil
+	}
+	return data, nil
+}
+
+// IsBinary checks if a file is binary by reading the first 512 bytes and
+// looking for null bytes or other non-text indicators
+func IsBinary(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	// Read first 512 bytes (or less if file is smaller)
+	buf := make([]byte, 512)
+	n, err := file.Read(buf)
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+
+	// Empty files are considered text
+	if n == 0 {
+		return false, nil
+	}
+
+	// Check for null bytes, which are a strong indicator of binary content
+	for i := 0; i < n; i++ {
+		if buf[i] == 0 {
+			return true, nil
+		}
+	}
+
+	// Additional check: count non-printable characters
+	// If more than 30% of characters are non-printable, consider it binary
+	nonPrintable := 0
+	for i := 0; i < n; i++ {
+		b := buf[i]
+		// Check if character is printable ASCII or common whitespace
+		if (b < 32 || b > 126) && b != '\t' && b != '\n' && b != '\r' {
+			nonPrintable++
+		}
+	}
+
+	// If more than 30% non-printable, consider it binary
+	if float64(nonPrintable)/float64(n) > 0.3 {
+		return true, nil
+	}
+
+	return false, nil
+}
+
+// Hash returns the hex SHA-256 of a file's contents
+func Hash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,50 @@
+package fileio
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadStable(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	if err := os.WriteFile(path, []byte("first\nsecond\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	data, err := ReadStable(path)
+	if err != nil {
+		t.Fatalf("ReadStable returned error: %v", err)
+	}
+	if string(data) != "first\nsecond\n" {
+		t.Errorf("Expected the whole file, got %q", data)
+	}
+
+	if _, err := ReadStable(filepath.Join(t.TempDir(), "missing.log")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func TestHash(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		return path
+	}
+
+	empty, err := Hash(write("empty.txt", ""))
+	if err != nil {
+		t.Fatalf("Hash returned error: %v", err)
+	}
+	if empty != "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("Unexpected hash of an empty file: %s", empty)
+	}
+	a, _ := Hash(write("a.txt", "same"))
+	b, _ := Hash(write("b.txt", "same"))
+	if a != b || a == empty {
+		t.Errorf("Expected equal contents to hash alike, got %s and %s", a, b)
+	}
+}
@@ -0,0 +1,26 @@
+// Package fileio reads files from disk the way comparisons need them: whole
+// and consistent while they grow, hashed, and sniffed for binary content and
+// line endings
+package fileio
+
+import (
+	"os"
+)
+
+// Stamp is the size and modification time of a file, which change whenever
+// its contents do
+type Stamp struct {
+	Exists  bool
+	ModTime int64
+	Size    int64
+}
+
+// StampOf returns the current stamp of a file, the zero Stamp when it
+// cannot be read
+func StampOf(path string) Stamp {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Stamp{}
+	}
+	return Stamp{Exists: true, ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+}
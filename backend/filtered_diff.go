@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// DiffFilter selects which lines of the current diff to show
+type DiffFilter struct {
+	// Types lists the line types to keep ("same", "added", "removed",
+	// "modified"); empty keeps every type
+	Types []string `json:"types"`
+	// Pattern is a regular expression that the left or right text of a line
+	// must match; empty matches every line
+	Pattern string `json:"pattern"`
+	// Context is the number of surrounding lines kept around each match
+	Context int `json:"context"`
+}
+
+// FilteredDiff is the part of the current diff that passed a filter
+type FilteredDiff struct {
+	Lines []DiffLine `json:"lines"`
+	// Indexes maps each kept line to its index in the full diff
+	Indexes []int `json:"indexes"`
+	// Total is the number of lines in the full diff
+	Total int `json:"total"`
+}
+
+// GetFilteredDiff applies a filter to the current diff and returns the kept
+// lines with their positions in the full diff
+func (a *App) GetFilteredDiff(filter DiffFilter) (*FilteredDiff, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	return filterDiff(current.Result, filter)
+}
+
+// filterDiff returns the lines of a diff that pass the filter, plus context
+func filterDiff(result *DiffResult, filter DiffFilter) (*FilteredDiff, error) {
+	if filter.Context < 0 {
+		return nil, fmt.Errorf("context cannot be negative")
+	}
+	var pattern *regexp.Regexp
+	if filter.Pattern != "" {
+		var err error
+		pattern, err = regexp.Compile(filter.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid filter pattern: %w", err)
+		}
+	}
+	types := make(map[string]bool)
+	for _, lineType := range filter.Types {
+		switch lineType {
+		case "same", "added", "removed", "modified":
+			types[lineType] = true
+		default:
+			return nil, fmt.Errorf("invalid line type: %s", lineType)
+		}
+	}
+
+	// Mark matching lines and the context around them
+	keep := make([]bool, len(result.Lines))
+	for i, line := range result.Lines {
+		if len(types) > 0 && !types[line.Type] {
+			continue
+		}
+		if pattern != nil && !pattern.MatchString(line.LeftLine) && !pattern.MatchString(line.RightLine) {
+			continue
+		}
+		start := max(0, i-filter.Context)
+		end := min(len(result.Lines)-1, i+filter.Context)
+		for j := start; j <= end; j++ {
+			keep[j] = true
+		}
+	}
+
+	filtered := &FilteredDiff{Lines: []DiffLine{}, Indexes: []int{}, Total: len(result.Lines)}
+	for i, kept := range keep {
+		if kept {
+			filtered.Lines = append(filtered.Lines, result.Lines[i])
+			filtered.Indexes = append(filtered.Indexes, i)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilterDiff(t *testing.T) {
+	result := &DiffResult{Lines: []DiffLine{
+		{LeftLine: "package main", RightLine: "package main", LeftNumber: 1, RightNumber: 1, Type: "same"},
+		{LeftLine: "import \"os\"", LeftNumber: 2, Type: "removed"},
+		{LeftLine: "func a() {}", RightLine: "func a() {}", LeftNumber: 3, RightNumber: 2, Type: "same"},
+		{RightLine: "func b() {}", RightNumber: 3, Type: "added"},
+		{LeftLine: "var x = 1", RightLine: "var x = 2", LeftNumber: 4, RightNumber: 4, Type: "modified"},
+		{LeftLine: "func c() {}", RightLine: "func c() {}", LeftNumber: 5, RightNumber: 5, Type: "same"},
+	}}
+
+	tests := []struct {
+		name     string
+		filter   DiffFilter
+		expected []int
+		wantErr  bool
+	}{
+		{"no filter", DiffFilter{}, []int{0, 1, 2, 3, 4, 5}, false},
+		{"hide same lines", DiffFilter{Types: []string{"added", "removed", "modified"}}, []int{1, 3, 4}, false},
+		{"only additions", DiffFilter{Types: []string{"added"}}, []int{3}, false},
+		{"only modifications with context", DiffFilter{Types: []string{"modified"}, Context: 1}, []int{3, 4, 5}, false},
+		{"pattern on either side", DiffFilter{Pattern: `^func [bc]`}, []int{3, 5}, false},
+		{"type and pattern", DiffFilter{Types: []string{"same"}, Pattern: "func"}, []int{2, 5}, false},
+		{"context at the edges", DiffFilter{Types: []string{"same"}, Pattern: "package", Context: 2}, []int{0, 1, 2}, false},
+		{"invalid pattern", DiffFilter{Pattern: "("}, nil, true},
+		{"invalid type", DiffFilter{Types: []string{"moved"}}, nil, true},
+		{"negative context", DiffFilter{Context: -1}, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := filterDiff(result, tt.filter)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("filterDiff returned error: %v", err)
+			}
+			if !reflect.DeepEqual(filtered.Indexes, tt.expected) {
+				t.Errorf("Expected indexes %v, got %v", tt.expected, filtered.Indexes)
+			}
+			for i, index := range filtered.Indexes {
+				if !reflect.DeepEqual(filtered.Lines[i], result.Lines[index]) {
+					t.Errorf("Line %d does not match full diff line %d", i, index)
+				}
+			}
+			if filtered.Total != len(result.Lines) {
+				t.Errorf("Expected total %d, got %d", len(result.Lines), filtered.Total)
+			}
+		})
+	}
+}
+
+func TestApp_GetFilteredDiff(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	if _, err := app.GetFilteredDiff(DiffFilter{}); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+
+	compareTempFiles(t, app, []string{"a", "b", "c"}, []string{"a", "c", "d"})
+	filtered, err := app.GetFilteredDiff(DiffFilter{Types: []string{"added"}})
+	if err != nil {
+		t.Fatalf("GetFilteredDiff returned error: %v", err)
+	}
+	if len(filtered.Lines) != 1 || filtered.Lines[0].RightLine != "d" {
+		t.Errorf("Expected the added line, got %+v", filtered.Lines)
+	}
+}
@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"encoding/csv"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Kinds of change a fixture pair can contain
+const (
+	FixtureAdd    = "add"
+	FixtureRemove = "remove"
+	FixtureMove   = "move"
+	FixtureModify = "modify"
+)
+
+// fixtureManifestName is the pairs manifest written beside generated
+// fixtures, so they can be opened with --pairs-file
+const fixtureManifestName = "pairs.csv"
+
+// maxFixtureMove is the most lines a single move relocates
+const maxFixtureMove = 5
+
+// fixtureWords is the vocabulary generated lines are built from
+var fixtureWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+	"quebec", "romeo", "sierra", "tango", "uniform", "victor", "whiskey",
+	"xray", "yankee", "zulu",
+}
+
+// FixtureOptions configures the file pairs GenerateFixtures writes
+type FixtureOptions struct {
+	Count int // number of pairs
+	Lines int // lines in each left file
+	// Density is the number of changes per line of the left file, from 0 to 1
+	Density float64
+	// Kinds are the changes to choose from, any of the Fixture constants
+	Kinds []string
+	// Seed makes the generated content reproducible
+	Seed uint64
+}
+
+// DefaultFixtureOptions returns options for pairs of 200 lines with one
+// change in ten lines, of every kind
+func DefaultFixtureOptions() FixtureOptions {
+	return FixtureOptions{
+		Count:   1,
+		Lines:   200,
+		Density: 0.1,
+		Kinds:   []string{FixtureAdd, FixtureRemove, FixtureMove, FixtureModify},
+		Seed:    1,
+	}
+}
+
+// GenerateFixtures writes synthetic file pairs to dir for testing and
+// benchmarking diff algorithms, along with a pairs manifest listing them.
+// The same options always produce the same files.
+func GenerateFixtures(dir string, options FixtureOptions) ([]ComparisonPair, error) {
+	if options.Count < 1 {
+		return nil, fmt.Errorf("fixture count must be at least 1")
+	}
+	if options.Lines < 1 {
+		return nil, fmt.Errorf("fixture line count must be at least 1")
+	}
+	if options.Density < 0 || options.Density > 1 {
+		return nil, fmt.Errorf("change density must be between 0 and 1")
+	}
+	if len(options.Kinds) == 0 {
+		return nil, fmt.Errorf("at least one change kind is required")
+	}
+	for _, kind := range options.Kinds {
+		switch kind {
+		case FixtureAdd, FixtureRemove, FixtureMove, FixtureModify:
+		default:
+			return nil, fmt.Errorf("unknown change kind: %s", kind)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create fixture directory: %w", err)
+	}
+
+	pairs := make([]ComparisonPair, 0, options.Count)
+	rows := [][]string{{"left", "right"}}
+	for i := 1; i <= options.Count; i++ {
+		// Each pair has its own stream, so it does not depend on the count
+		rng := rand.New(rand.NewPCG(options.Seed, uint64(i)))
+		left := generateFixtureLines(rng, options.Lines)
+		right := applyFixtureChanges(rng, left, options)
+
+		leftName := fmt.Sprintf("pair-%03d-left.txt", i)
+		rightName := fmt.Sprintf("pair-%03d-right.txt", i)
+		pair := ComparisonPair{LeftFile: filepath.Join(dir, leftName), RightFile: filepath.Join(dir, rightName)}
+		if err := os.WriteFile(pair.LeftFile, []byte(strings.Join(left, "\n")+"\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write fixture: %w", err)
+		}
+		if err := os.WriteFile(pair.RightFile, []byte(strings.Join(right, "\n")+"\n"), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write fixture: %w", err)
+		}
+		pairs = append(pairs, pair)
+		rows = append(rows, []string{leftName, rightName})
+	}
+
+	if err := writeFixtureManifest(filepath.Join(dir, fixtureManifestName), rows); err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// writeFixtureManifest writes the manifest rows as CSV
+func writeFixtureManifest(path string, rows [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to write fixture manifest: %w", err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	if err := writer.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write fixture manifest: %w", err)
+	}
+	return nil
+}
+
+// generateFixtureLines returns lines of random words, numbered so that
+// lines rarely repeat
+func generateFixtureLines(rng *rand.Rand, count int) []string {
+	lines := make([]string, count)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("%04d %s", i+1, fixtureSentence(rng))
+	}
+	return lines
+}
+
+// fixtureSentence returns three to eight random words
+func fixtureSentence(rng *rand.Rand) string {
+	words := make([]string, 3+rng.IntN(6))
+	for i := range words {
+		words[i] = fixtureWords[rng.IntN(len(fixtureWords))]
+	}
+	return strings.Join(words, " ")
+}
+
+// applyFixtureChanges returns a copy of lines with changes of the configured
+// kinds applied at random positions
+func applyFixtureChanges(rng *rand.Rand, lines []string, options FixtureOptions) []string {
+	changed := slices.Clone(lines)
+	count := int(options.Density*float64(len(lines)) + 0.5)
+	if options.Density > 0 {
+		count = max(count, 1)
+	}
+
+	for i := 0; i < count; i++ {
+		switch options.Kinds[rng.IntN(len(options.Kinds))] {
+		case FixtureAdd:
+			changed = slices.Insert(changed, rng.IntN(len(changed)+1), "new "+fixtureSentence(rng))
+		case FixtureRemove:
+			if len(changed) > 1 {
+				at := rng.IntN(len(changed))
+				changed = slices.Delete(changed, at, at+1)
+			}
+		case FixtureMove:
+			size := 1 + rng.IntN(min(maxFixtureMove, len(changed)))
+			from := rng.IntN(len(changed) - size + 1)
+			block := slices.Clone(changed[from : from+size])
+			changed = slices.Delete(changed, from, from+size)
+			changed = slices.Insert(changed, rng.IntN(len(changed)+1), block...)
+		case FixtureModify:
+			// Replacing one word keeps the line similar enough to pair up
+			at := rng.IntN(len(changed))
+			words := strings.Fields(changed[at])
+			words[1+rng.IntN(len(words)-1)] = fixtureWords[rng.IntN(len(fixtureWords))] + "ed"
+			changed[at] = strings.Join(words, " ")
+		}
+	}
+	return changed
+}
@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFixtures(t *testing.T) {
+	t.Run("writes pairs and a manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		options := DefaultFixtureOptions()
+		options.Count = 3
+		pairs, err := GenerateFixtures(dir, options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		if len(pairs) != 3 {
+			t.Fatalf("Expected 3 pairs, got %d", len(pairs))
+		}
+
+		left := readFixture(t, pairs[0].LeftFile)
+		if len(left) != options.Lines {
+			t.Errorf("Expected %d left lines, got %d", options.Lines, len(left))
+		}
+		if slices.Equal(left, readFixture(t, pairs[0].RightFile)) {
+			t.Error("Expected the right file to differ from the left")
+		}
+
+		manifest, err := LoadPairsManifest(filepath.Join(dir, fixtureManifestName))
+		if err != nil {
+			t.Fatalf("LoadPairsManifest returned error: %v", err)
+		}
+		if !slices.Equal(manifest, pairs) {
+			t.Errorf("Expected the manifest to list %v, got %v", pairs, manifest)
+		}
+	})
+
+	t.Run("same seed gives same content", func(t *testing.T) {
+		options := DefaultFixtureOptions()
+		first, err := GenerateFixtures(t.TempDir(), options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		second, err := GenerateFixtures(t.TempDir(), options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		if !slices.Equal(readFixture(t, first[0].RightFile), readFixture(t, second[0].RightFile)) {
+			t.Error("Expected identical fixtures for the same seed")
+		}
+
+		options.Seed++
+		third, err := GenerateFixtures(t.TempDir(), options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		if slices.Equal(readFixture(t, first[0].LeftFile), readFixture(t, third[0].LeftFile)) {
+			t.Error("Expected different fixtures for another seed")
+		}
+	})
+
+	t.Run("only the chosen kinds of change", func(t *testing.T) {
+		options := DefaultFixtureOptions()
+		options.Kinds = []string{FixtureRemove}
+		options.Density = 0.2
+		pairs, err := GenerateFixtures(t.TempDir(), options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		left, right := readFixture(t, pairs[0].LeftFile), readFixture(t, pairs[0].RightFile)
+		if len(right) != len(left)-40 {
+			t.Errorf("Expected 40 lines removed, got %d lines of %d", len(right), len(left))
+		}
+		for _, line := range right {
+			if !slices.Contains(left, line) {
+				t.Errorf("Expected only removals, found new line %q", line)
+			}
+		}
+	})
+
+	t.Run("zero density gives identical pairs", func(t *testing.T) {
+		options := DefaultFixtureOptions()
+		options.Density = 0
+		pairs, err := GenerateFixtures(t.TempDir(), options)
+		if err != nil {
+			t.Fatalf("GenerateFixtures returned error: %v", err)
+		}
+		if !slices.Equal(readFixture(t, pairs[0].LeftFile), readFixture(t, pairs[0].RightFile)) {
+			t.Error("Expected identical files without changes")
+		}
+	})
+
+	t.Run("rejects invalid options", func(t *testing.T) {
+		for name, modify := range map[string]func(*FixtureOptions){
+			"no pairs":     func(o *FixtureOptions) { o.Count = 0 },
+			"no lines":     func(o *FixtureOptions) { o.Lines = 0 },
+			"density":      func(o *FixtureOptions) { o.Density = 1.5 },
+			"no kinds":     func(o *FixtureOptions) { o.Kinds = nil },
+			"unknown kind": func(o *FixtureOptions) { o.Kinds = []string{"shuffle"} },
+		} {
+			options := DefaultFixtureOptions()
+			modify(&options)
+			if _, err := GenerateFixtures(t.TempDir(), options); err == nil {
+				t.Errorf("%s: expected an error", name)
+			}
+		}
+	})
+}
+
+// readFixture returns the lines of a generated file
+func readFixture(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read fixture: %v", err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
@@ -0,0 +1,45 @@
+package backend
+
+import (
+	"github.com/wailsapp/wails/v2/pkg/menu"
+)
+
+// SetFocusModeMenuItem stores the View > Focus Mode checkbox item
+func (a *App) SetFocusModeMenuItem(item *menu.MenuItem) {
+	a.setMenuItem(&a.focusModeMenuItem, item)
+}
+
+// GetFocusMode returns whether the comparison is shown in focus mode
+func (a *App) GetFocusMode() bool {
+	return a.settings.Get().FocusMode
+}
+
+// SetFocusMode turns focus mode on or off. Focus mode makes the window full
+// screen and emits "focus-mode-changed" so the frontend hides the minimap and
+// gutters and widens the panes. The choice persists across launches.
+func (a *App) SetFocusMode(enabled bool) error {
+	err := a.settings.Update(func(s *Settings) error {
+		s.FocusMode = enabled
+		return nil
+	})
+
+	a.applyFocusMode()
+	return err
+}
+
+// applyFocusMode brings the window and menu in line with the focus mode setting
+func (a *App) applyFocusMode() {
+	enabled := a.GetFocusMode()
+	a.menuMutex.Lock()
+	if a.focusModeMenuItem != nil {
+		a.focusModeMenuItem.Checked = enabled
+	}
+	a.menuMutex.Unlock()
+	a.runtime().MenuUpdateApplicationMenu()
+	if enabled {
+		a.runtime().WindowFullscreen()
+	} else {
+		a.runtime().WindowUnfullscreen()
+	}
+	a.runtime().EventsEmit("focus-mode-changed", enabled)
+}
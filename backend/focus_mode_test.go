@@ -0,0 +1,41 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+)
+
+func TestApp_FocusMode(t *testing.T) {
+	storage := NewMemoryStorage()
+	app := NewApp()
+	app.Storage = storage
+	item := &menu.MenuItem{Label: "Focus Mode", Type: menu.CheckboxType}
+	app.SetFocusModeMenuItem(item)
+
+	if app.GetFocusMode() {
+		t.Error("Expected focus mode to be off by default")
+	}
+	if err := app.SetFocusMode(true); err != nil {
+		t.Fatalf("SetFocusMode returned error: %v", err)
+	}
+	if !app.GetFocusMode() || !item.Checked {
+		t.Error("Expected focus mode and its menu item to be on")
+	}
+
+	reloaded := NewApp()
+	reloaded.Storage = storage
+	if err := reloaded.loadSettings(); err != nil {
+		t.Fatalf("loadSettings returned error: %v", err)
+	}
+	if !reloaded.GetFocusMode() {
+		t.Error("Expected focus mode to persist")
+	}
+
+	if err := app.SetFocusMode(false); err != nil {
+		t.Fatalf("SetFocusMode returned error: %v", err)
+	}
+	if app.GetFocusMode() || item.Checked {
+		t.Error("Expected focus mode and its menu item to be off")
+	}
+}
@@ -0,0 +1,85 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// maxFoldContextLines is the most unchanged context lines kept around a change
+const maxFoldContextLines = 100
+
+// FoldedRegion is now imported from the diff package
+type FoldedRegion = diff.FoldedRegion
+
+// GetFoldUnchanged returns whether long runs of unchanged lines are folded
+// out of comparison results
+func (a *App) GetFoldUnchanged() bool {
+	return a.settings.Get().FoldUnchanged
+}
+
+// SetFoldUnchanged sets whether long runs of unchanged lines are folded out
+// of comparison results, and re-compares the current files. The new result
+// is delivered through the "diff-updated" event.
+func (a *App) SetFoldUnchanged(fold bool) error {
+	err := a.settings.Update(func(s *Settings) error {
+		s.FoldUnchanged = fold
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// GetFoldContextLines returns how many unchanged lines are kept around each
+// change when folding
+func (a *App) GetFoldContextLines() int {
+	return a.settings.Get().FoldContextLines
+}
+
+// SetFoldContextLines sets how many unchanged lines are kept around each
+// change when folding, and re-compares the current files
+func (a *App) SetFoldContextLines(lines int) error {
+	if lines < 0 || lines > maxFoldContextLines {
+		return fmt.Errorf("context lines must be between 0 and %d", maxFoldContextLines)
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.FoldContextLines = lines
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// ExpandFold returns the unchanged lines hidden by a folded region of the
+// current comparison
+func (a *App) ExpandFold(foldID string) ([]DiffLine, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	lines, ok := diff.FoldedLines(current.Result, a.GetFoldContextLines(), foldID)
+	if !ok {
+		return nil, fmt.Errorf("folded region not found: %s", foldID)
+	}
+	return lines, nil
+}
+
+// displayResult returns the result as sent to the frontend, folded when
+// folding is on. The unfolded result stays the current comparison, so hunk
+// operations keep working on every line.
+func (a *App) displayResult(result *DiffResult) *DiffResult {
+	if !a.GetFoldUnchanged() {
+		return result
+	}
+	return diff.FoldUnchanged(result, a.GetFoldContextLines())
+}
+
+// rediffCurrentComparison re-compares the current files in full, if any,
+// after a setting changed how they are compared
+func (a *App) rediffCurrentComparison() {
+	if current := a.compare.Current(); current != nil {
+		a.requestRediff(current.LeftPath, current.RightPath, true)
+	}
+}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestApp_FoldUnchanged(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	var left, right []string
+	for i := 1; i <= 30; i++ {
+		line := fmt.Sprintf("line %d", i)
+		left = append(left, line)
+		if i == 15 {
+			line = "changed"
+		}
+		right = append(right, line)
+	}
+
+	if err := app.SetFoldUnchanged(true); err != nil {
+		t.Fatalf("SetFoldUnchanged returned error: %v", err)
+	}
+	if err := app.SetFoldContextLines(3); err != nil {
+		t.Fatalf("SetFoldContextLines returned error: %v", err)
+	}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if len(result.Folds) != 2 || len(result.Lines) != 8 {
+		t.Fatalf("Expected 8 lines and 2 folds, got %d lines and %+v", len(result.Lines), result.Folds)
+	}
+
+	// Hunk operations still see every line
+	hunks, err := app.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks returned error: %v", err)
+	}
+	if len(hunks) != 1 || hunks[0].StartIndex != 14 {
+		t.Errorf("Expected the hunk to index the unfolded lines, got %+v", hunks)
+	}
+
+	lines, err := app.ExpandFold(result.Folds[0].ID)
+	if err != nil {
+		t.Fatalf("ExpandFold returned error: %v", err)
+	}
+	if len(lines) != 11 || lines[0].LeftLine != "line 1" {
+		t.Errorf("Unexpected expanded lines: %+v", lines)
+	}
+	if _, err := app.ExpandFold("fold-0-1"); err == nil {
+		t.Error("Expected an error for an unknown fold")
+	}
+	if err := app.SetFoldContextLines(-1); err == nil {
+		t.Error("Expected an error for negative context lines")
+	}
+
+	if err := app.SetFoldUnchanged(false); err != nil {
+		t.Fatalf("SetFoldUnchanged returned error: %v", err)
+	}
+	result, err = app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if len(result.Folds) != 0 || len(result.Lines) != 31 {
+		t.Errorf("Expected the full result when folding is off, got %d lines", len(result.Lines))
+	}
+}
@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"strings"
+)
+
+// GetTailMode returns whether a compared file that is appended to, such as
+// a live log, is followed by comparing only what was appended
+func (a *App) GetTailMode() bool {
+	return a.settings.Get().TailMode
+}
+
+// SetTailMode sets whether compared files that are appended to are followed
+func (a *App) SetTailMode(enabled bool) error {
+	return a.settings.Update(func(s *Settings) error {
+		s.TailMode = enabled
+		return nil
+	})
+}
+
+// followAppend updates the current comparison after path changed on disk,
+// when tail mode is on and the file was only appended to. Only the region
+// from the last lines the files have in common onward is compared again,
+// and the update is delivered through the "diff-patched" event. It reports
+// false when the change has to be handled as any other external change.
+func (a *App) followAppend(path string) bool {
+	if !a.GetTailMode() || a.HasUnsavedChanges(path) {
+		return false
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return false
+	}
+	var previous []string
+	switch path {
+	case current.LeftPath:
+		previous = comparedLines(current.Result, true)
+	case current.RightPath:
+		previous = comparedLines(current.Result, false)
+	default:
+		return false
+	}
+	lines, err := a.ReadFileContent(path)
+	if err != nil || !appendedTo(previous, lines) {
+		return false
+	}
+
+	result, patch, ok := a.patchCurrentComparison(current.LeftPath, current.RightPath)
+	if !ok {
+		return false
+	}
+	a.emitRediffPatch(current.LeftPath, current.RightPath, result, patch)
+	return true
+}
+
+// comparedLines returns the lines of one side's file as they were compared
+func comparedLines(result *DiffResult, left bool) []string {
+	var lines []string
+	for _, line := range result.Lines {
+		number, text := line.RightNumber, line.RightLine
+		if left {
+			number, text = line.LeftNumber, line.LeftLine
+		}
+		if number > 0 {
+			lines = append(lines, text)
+		}
+	}
+	return lines
+}
+
+// appendedTo reports whether lines only add to previous: previous is a
+// prefix of lines, except that its last line, which may have been read
+// while it was still being written, may have grown
+func appendedTo(previous, lines []string) bool {
+	if len(lines) < len(previous) {
+		return false
+	}
+	for i, line := range previous {
+		if lines[i] != line && (i < len(previous)-1 || !strings.HasPrefix(lines[i], line)) {
+			return false
+		}
+	}
+	return true
+}
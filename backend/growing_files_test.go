@@ -0,0 +1,116 @@
+package backend
+
+import (
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestAppendedTo(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous []string
+		lines    []string
+		want     bool
+	}{
+		{"lines appended", []string{"a", "b"}, []string{"a", "b", "c"}, true},
+		{"unchanged", []string{"a", "b"}, []string{"a", "b"}, true},
+		{"last line completed", []string{"a", "par"}, []string{"a", "partial", "c"}, true},
+		{"earlier line changed", []string{"a", "b"}, []string{"x", "b", "c"}, false},
+		{"truncated", []string{"a", "b"}, []string{"a"}, false},
+		{"last line replaced", []string{"a", "b"}, []string{"a", "c"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendedTo(tt.previous, tt.lines); got != tt.want {
+				t.Errorf("appendedTo(%q, %q) = %v, want %v", tt.previous, tt.lines, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApp_FollowAppend(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	recorder := &recordingRuntime{}
+	app.Runtime = recorder
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"start", "request 1", "request 2"}
+	right := []string{"start", "request 1"}
+
+	t.Run("off by default", func(t *testing.T) {
+		leftPath, _ := compareTempFiles(t, app, left, right)
+		appendLines(t, leftPath, "request 3")
+		if app.followAppend(leftPath) {
+			t.Error("Expected appends not to be followed with tail mode off")
+		}
+	})
+
+	if err := app.SetTailMode(true); err != nil {
+		t.Fatalf("SetTailMode returned error: %v", err)
+	}
+	if !app.GetTailMode() {
+		t.Fatal("Expected tail mode to be on")
+	}
+
+	t.Run("appended lines patch the comparison", func(t *testing.T) {
+		leftPath, rightPath := compareTempFiles(t, app, left, right)
+		appendLines(t, rightPath, "request 2", "request 3")
+		if !app.followAppend(rightPath) {
+			t.Fatal("Expected the append to be followed")
+		}
+		recorder.mu.Lock()
+		patched := recorder.events["diff-patched"]
+		recorder.mu.Unlock()
+		if patched == nil {
+			t.Error("Expected a diff-patched event")
+		}
+
+		current, err := app.currentComparison()
+		if err != nil {
+			t.Fatalf("currentComparison returned error: %v", err)
+		}
+		full, err := app.computeDiff(leftPath, rightPath, CompareOptions{})
+		if err != nil {
+			t.Fatalf("computeDiff returned error: %v", err)
+		}
+		if !reflect.DeepEqual(current.Result.Lines, full.Lines) {
+			t.Errorf("Expected followed lines to match a full comparison, got %+v", current.Result.Lines)
+		}
+	})
+
+	t.Run("rewritten files are not followed", func(t *testing.T) {
+		leftPath, _ := compareTempFiles(t, app, left, right)
+		if err := os.WriteFile(leftPath, []byte("rotated\n"), 0644); err != nil {
+			t.Fatalf("Failed to rewrite file: %v", err)
+		}
+		if app.followAppend(leftPath) {
+			t.Error("Expected a rewritten file not to be followed")
+		}
+	})
+
+	t.Run("files with unsaved changes are not followed", func(t *testing.T) {
+		leftPath, _ := compareTempFiles(t, app, left, right)
+		app.compare.SetEdited(leftPath, left)
+		appendLines(t, leftPath, "request 3")
+		if app.followAppend(leftPath) {
+			t.Error("Expected a file with unsaved changes not to be followed")
+		}
+	})
+}
+
+// appendLines appends lines to a file written by compareTempFiles
+func appendLines(t *testing.T, path string, lines ...string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	for _, line := range lines {
+		if _, err := file.WriteString("\n" + line); err != nil {
+			t.Fatalf("Failed to append to file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Heatmap dimensions, in pixels
+const (
+	heatmapWidth     = 24
+	heatmapRowHeight = 4
+)
+
+// maxHeatmapBuckets is the most rows ExportHeatmap will draw
+const maxHeatmapBuckets = 1000
+
+var (
+	heatmapBackground = color.RGBA{0xf4, 0xf4, 0xf4, 0xff}
+	heatmapRemoved    = color.RGBA{0xd7, 0x3a, 0x49, 0xff}
+	heatmapAdded      = color.RGBA{0x28, 0xa7, 0x45, 0xff}
+)
+
+// ExportHeatmap writes a heatmap of where one side's file of the current
+// comparison changed, as a strip of the given number of rows from the top of
+// the file to the bottom, each shaded by the share of its lines that
+// changed. Side is "left" or "right"; the image is a PNG or SVG according to
+// the extension of path.
+func (a *App) ExportHeatmap(side, path string, buckets int) error {
+	if path == "" {
+		return fmt.Errorf("export path cannot be empty")
+	}
+	if side != "left" && side != "right" {
+		return fmt.Errorf("invalid side: %s", side)
+	}
+	if buckets <= 0 {
+		return fmt.Errorf("bucket count must be positive")
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return err
+	}
+
+	densities := fileChangeDensities(current.Result, side, min(buckets, maxHeatmapBuckets))
+	tint := heatmapRemoved
+	if side == "right" {
+		tint = heatmapAdded
+	}
+
+	var data []byte
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".png":
+		data, err = heatmapPNG(densities, tint)
+	case ".svg":
+		data = heatmapSVG(densities, tint)
+	default:
+		return fmt.Errorf("unsupported heatmap format %q; use .png or .svg", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode heatmap: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write heatmap: %w", err)
+	}
+	return nil
+}
+
+// fileChangeDensities splits one side's file into n runs of lines, or one
+// per line when it has fewer, and returns the share of each run's lines
+// that were removed or modified on the left, or added or modified on the
+// right
+func fileChangeDensities(result *DiffResult, side string, n int) []float64 {
+	var changed []bool
+	for _, line := range result.Lines {
+		number, change := line.LeftNumber, line.Type == "removed"
+		if side == "right" {
+			number, change = line.RightNumber, line.Type == "added"
+		}
+		if number == 0 {
+			continue
+		}
+		for len(changed) < number {
+			changed = append(changed, false)
+		}
+		changed[number-1] = change || line.Type == "modified"
+	}
+
+	n = min(n, len(changed))
+	densities := make([]float64, n)
+	for i := range densities {
+		start, end := i*len(changed)/n, (i+1)*len(changed)/n
+		count := 0
+		for _, c := range changed[start:end] {
+			if c {
+				count++
+			}
+		}
+		densities[i] = float64(count) / float64(end-start)
+	}
+	return densities
+}
+
+// heatmapColor blends the background toward tint by density
+func heatmapColor(density float64, tint color.RGBA) color.RGBA {
+	blend := func(from, to uint8) uint8 {
+		return uint8(float64(from) + (float64(to)-float64(from))*density + 0.5)
+	}
+	return color.RGBA{
+		R: blend(heatmapBackground.R, tint.R),
+		G: blend(heatmapBackground.G, tint.G),
+		B: blend(heatmapBackground.B, tint.B),
+		A: 0xff,
+	}
+}
+
+// heatmapPNG draws densities as rows of a PNG image
+func heatmapPNG(densities []float64, tint color.RGBA) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, heatmapWidth, max(len(densities), 1)*heatmapRowHeight))
+	for y := 0; y < img.Bounds().Dy(); y++ {
+		c := heatmapBackground
+		if row := y / heatmapRowHeight; row < len(densities) {
+			c = heatmapColor(densities[row], tint)
+		}
+		for x := 0; x < heatmapWidth; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// heatmapSVG draws densities as rows of an SVG image
+func heatmapSVG(densities []float64, tint color.RGBA) []byte {
+	height := max(len(densities), 1) * heatmapRowHeight
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		heatmapWidth, height, heatmapWidth, height)
+	fmt.Fprintf(&b, `  <rect width="%d" height="%d" fill="%s"/>`+"\n", heatmapWidth, height, hexColor(heatmapBackground))
+	for i, density := range densities {
+		if density == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, `  <rect y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+			i*heatmapRowHeight, heatmapWidth, heatmapRowHeight, hexColor(heatmapColor(density, tint)))
+	}
+	b.WriteString("</svg>\n")
+	return []byte(b.String())
+}
+
+// hexColor formats a color as #rrggbb
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
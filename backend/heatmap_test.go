@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"fmt"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_ExportHeatmap(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	if err := app.ExportHeatmap("left", filepath.Join(dir, "none.png"), 10); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+
+	// The right file adds 10 lines at the end of 10 unchanged ones
+	var left, right []string
+	for i := 1; i <= 10; i++ {
+		left = append(left, fmt.Sprintf("line %d", i))
+	}
+	right = append(right, left...)
+	for i := 1; i <= 10; i++ {
+		right = append(right, fmt.Sprintf("appended %d", i))
+	}
+	compareTempFiles(t, app, left, right)
+
+	t.Run("densities", func(t *testing.T) {
+		current, err := app.currentComparison()
+		if err != nil {
+			t.Fatal(err)
+		}
+		densities := fileChangeDensities(current.Result, "right", 4)
+		if fmt.Sprint(densities) != "[0 0 1 1]" {
+			t.Errorf("Expected the changes in the bottom half, got %v", densities)
+		}
+		if densities := fileChangeDensities(current.Result, "left", 40); len(densities) != 10 {
+			t.Errorf("Expected one row per left line, got %v", densities)
+		}
+	})
+
+	t.Run("png", func(t *testing.T) {
+		path := filepath.Join(dir, "right.png")
+		if err := app.ExportHeatmap("right", path, 4); err != nil {
+			t.Fatalf("ExportHeatmap returned error: %v", err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		img, err := png.Decode(file)
+		if err != nil {
+			t.Fatalf("Expected a PNG, got %v", err)
+		}
+		if img.Bounds().Dx() != heatmapWidth || img.Bounds().Dy() != 4*heatmapRowHeight {
+			t.Errorf("Unexpected image size %v", img.Bounds())
+		}
+		top, bottom := img.At(0, 0), img.At(0, 4*heatmapRowHeight-1)
+		if top == bottom {
+			t.Error("Expected changed rows to be shaded differently")
+		}
+	})
+
+	t.Run("svg", func(t *testing.T) {
+		path := filepath.Join(dir, "right.svg")
+		if err := app.ExportHeatmap("right", path, 4); err != nil {
+			t.Fatalf("ExportHeatmap returned error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		svg := string(data)
+		if !strings.HasPrefix(svg, "<svg") || strings.Count(svg, "<rect") != 3 || !strings.Contains(svg, hexColor(heatmapAdded)) {
+			t.Errorf("Expected a background and 2 shaded rows, got %s", svg)
+		}
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		if err := app.ExportHeatmap("both", filepath.Join(dir, "x.png"), 4); err == nil {
+			t.Error("Expected error for an invalid side")
+		}
+		if err := app.ExportHeatmap("left", filepath.Join(dir, "x.gif"), 4); err == nil {
+			t.Error("Expected error for an unsupported format")
+		}
+		if err := app.ExportHeatmap("left", filepath.Join(dir, "x.png"), 0); err == nil {
+			t.Error("Expected error for zero buckets")
+		}
+		if err := app.ExportHeatmap("left", "", 4); err == nil {
+			t.Error("Expected error for an empty path")
+		}
+	})
+}
@@ -0,0 +1,400 @@
+// Package history keeps the undo and redo history of line operations, and a
+// log of every operation group applied, undone, or redone
+package history
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OperationType is the kind of a line operation
+type OperationType string
+
+const (
+	OpCopy   OperationType = "copy"
+	OpRemove OperationType = "remove"
+)
+
+// Operation represents a single atomic operation
+type Operation struct {
+	Type        OperationType
+	SourceFile  string
+	TargetFile  string
+	LineNumber  int
+	LineContent string
+	InsertIndex int
+	Timestamp   time.Time
+}
+
+// Group represents a group of operations that should be undone together
+type Group struct {
+	ID          string      `json:"id"`
+	Description string      `json:"description"`
+	Operations  []Operation `json:"operations"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Author      string      `json:"author,omitempty"` // OS user who made the changes
+}
+
+// Actions recorded in the log
+const (
+	LogApply = "apply"
+	LogUndo  = "undo"
+	LogRedo  = "redo"
+)
+
+// maxLogSize caps how many entries the log keeps
+const maxLogSize = 10000
+
+// LogEntry records an operation group being applied, undone, or redone
+type LogEntry struct {
+	Action string    `json:"action"` // "apply", "undo", or "redo"
+	Time   time.Time `json:"time"`
+	Group  Group     `json:"group"`
+}
+
+// Errors returned when there is nothing to undo or redo
+var (
+	ErrNothingToUndo = errors.New("no operations to undo")
+	ErrNothingToRedo = errors.New("no operations to redo")
+)
+
+// History is the undo and redo history of line operations. Operations are
+// recorded into an open group when one was begun, or as a group of their
+// own otherwise. Undoing and redoing replay a group through a function of
+// the caller, and operations recorded while it runs are ignored.
+type History interface {
+	// Begin opens a group that operations are recorded into, committing any
+	// group already open. It returns the group's ID and whether a group was
+	// committed.
+	Begin(description string) (id string, committed bool)
+	// Commit adds the open group to the undo history, unless it is empty,
+	// and clears the redo history
+	Commit()
+	// Rollback closes the open group without adding it to the undo history,
+	// after revert reverses its operations. It returns whether the group had
+	// any operations to revert.
+	Rollback(revert func(Group)) bool
+	// Pending returns the open group, if any
+	Pending() (Group, bool)
+	// Record adds an operation to the open group, or to the undo history as
+	// a group of its own, returning whether the undo history changed
+	Record(op Operation) bool
+	// Undo reverses the last group through apply and moves it to the redo
+	// history. When apply fails, the history is left unchanged.
+	Undo(apply func(Group) error) error
+	// Redo reapplies the last undone group through apply and moves it back
+	// to the undo history. When apply fails, the history is left unchanged.
+	Redo(apply func(Group) error) error
+	// CanUndo returns whether there are groups to undo
+	CanUndo() bool
+	// CanRedo returns whether there are groups to redo
+	CanRedo() bool
+	// UndoDescription returns the description of the group undone next
+	UndoDescription() string
+	// RedoDescription returns the description of the group redone next
+	RedoDescription() string
+	// Resize sets how many groups are kept for undo, and as many again for
+	// redo, dropping the oldest groups beyond it
+	Resize(size int)
+	// Snapshot returns copies of the undo and redo histories, oldest first
+	Snapshot() (undo, redo []Group)
+	// Restore replaces the undo and redo histories
+	Restore(undo, redo []Group)
+	// Log returns everything applied, undone, or redone, oldest first.
+	// Unlike the undo history, the log is not capped by the history size
+	// and keeps undone groups.
+	Log() []LogEntry
+}
+
+// Config configures a History
+type Config struct {
+	// Size is how many groups are kept for undo, and as many again for redo
+	Size int
+	// Author names who makes the recorded changes
+	Author string
+	// OnChange, when set, is called with whether there is anything to undo
+	// and redo whenever either may have changed. It is called with the
+	// history locked, so it must not call back into the history.
+	OnChange func(canUndo, canRedo bool)
+	// OnLog, when set, is called with each entry added to the log, with the
+	// history locked
+	OnLog func(LogEntry)
+}
+
+// history is the History returned by New
+type history struct {
+	config Config
+
+	mu          sync.Mutex
+	undo        []Group
+	redo        []Group
+	transaction *Group
+	log         []LogEntry
+
+	// replaying is set while a group is undone, redone, or rolled back, so
+	// the operations replaying it are not recorded. It is read without mu,
+	// which the replay holds.
+	replaying atomic.Bool
+}
+
+// New returns an empty History
+func New(config Config) History {
+	return &history{config: config}
+}
+
+// Begin opens a group that operations are recorded into
+func (h *history) Begin(description string) (string, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	committed := h.transaction != nil
+	if committed {
+		// If there's an existing transaction, commit it first
+		h.commitLocked()
+	}
+
+	h.transaction = h.newGroup(description, []Operation{})
+	return h.transaction.ID, committed
+}
+
+// Commit adds the open group to the undo history
+func (h *history) Commit() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.commitLocked()
+}
+
+// commitLocked adds the open group to the undo history (must be called with
+// mu held)
+func (h *history) commitLocked() {
+	if h.transaction == nil || len(h.transaction.Operations) == 0 {
+		h.transaction = nil
+		return
+	}
+	group := *h.transaction
+	h.transaction = nil
+	h.applyLocked(group)
+}
+
+// applyLocked adds a newly made group to the undo history and clears the
+// redo history (must be called with mu held)
+func (h *history) applyLocked(group Group) {
+	h.undo = trim(append(h.undo, group), h.config.Size)
+	h.redo = nil
+	h.logLocked(LogApply, group)
+	h.changedLocked()
+}
+
+// Rollback closes the open group after revert reverses its operations
+func (h *history) Rollback(revert func(Group)) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.transaction == nil || len(h.transaction.Operations) == 0 {
+		h.transaction = nil
+		return false
+	}
+
+	h.replaying.Store(true)
+	revert(*h.transaction)
+	h.replaying.Store(false)
+
+	h.transaction = nil
+	h.changedLocked()
+	return true
+}
+
+// Pending returns the open group
+func (h *history) Pending() (Group, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.transaction == nil {
+		return Group{}, false
+	}
+	group := *h.transaction
+	group.Operations = append([]Operation(nil), group.Operations...)
+	return group, true
+}
+
+// Record adds an operation to the open group or as a group of its own
+func (h *history) Record(op Operation) bool {
+	// Check this BEFORE acquiring the lock, which a replay holds
+	if h.replaying.Load() {
+		return false
+	}
+	if op.Timestamp.IsZero() {
+		op.Timestamp = time.Now()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.transaction != nil {
+		h.transaction.Operations = append(h.transaction.Operations, op)
+		return false
+	}
+	h.applyLocked(*h.newGroup(fmt.Sprintf("%s line", op.Type), []Operation{op}))
+	return true
+}
+
+// Undo reverses the last group and moves it to the redo history
+func (h *history) Undo(apply func(Group) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.undo) == 0 {
+		return ErrNothingToUndo
+	}
+	group := h.undo[len(h.undo)-1]
+
+	// Replay BEFORE modifying the stacks, so a failure leaves them unchanged
+	h.replaying.Store(true)
+	err := apply(group)
+	h.replaying.Store(false)
+	if err != nil {
+		return err
+	}
+
+	h.undo = h.undo[:len(h.undo)-1]
+	h.redo = trim(append(h.redo, group), h.config.Size)
+	h.logLocked(LogUndo, group)
+	h.changedLocked()
+	return nil
+}
+
+// Redo reapplies the last undone group and moves it to the undo history
+func (h *history) Redo(apply func(Group) error) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.redo) == 0 {
+		return ErrNothingToRedo
+	}
+	group := h.redo[len(h.redo)-1]
+
+	// Replay BEFORE modifying the stacks, so a failure leaves them unchanged
+	h.replaying.Store(true)
+	err := apply(group)
+	h.replaying.Store(false)
+	if err != nil {
+		return err
+	}
+
+	h.redo = h.redo[:len(h.redo)-1]
+	h.undo = trim(append(h.undo, group), h.config.Size)
+	h.logLocked(LogRedo, group)
+	h.changedLocked()
+	return nil
+}
+
+// CanUndo returns whether there are groups to undo
+func (h *history) CanUndo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.undo) > 0
+}
+
+// CanRedo returns whether there are groups to redo
+func (h *history) CanRedo() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.redo) > 0
+}
+
+// UndoDescription returns the description of the group undone next
+func (h *history) UndoDescription() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.undo) == 0 {
+		return ""
+	}
+	return h.undo[len(h.undo)-1].Description
+}
+
+// RedoDescription returns the description of the group redone next
+func (h *history) RedoDescription() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.redo) == 0 {
+		return ""
+	}
+	return h.redo[len(h.redo)-1].Description
+}
+
+// Resize sets how many groups are kept and trims both histories to it
+func (h *history) Resize(size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.config.Size = size
+	h.undo = trim(h.undo, size)
+	h.redo = trim(h.redo, size)
+	h.changedLocked()
+}
+
+// Snapshot returns copies of the undo and redo histories
+func (h *history) Snapshot() ([]Group, []Group) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]Group(nil), h.undo...), append([]Group(nil), h.redo...)
+}
+
+// Restore replaces the undo and redo histories, trimmed to the history size
+func (h *history) Restore(undo, redo []Group) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.undo = trim(append([]Group(nil), undo...), h.config.Size)
+	h.redo = trim(append([]Group(nil), redo...), h.config.Size)
+	h.changedLocked()
+}
+
+// Log returns everything applied, undone, or redone
+func (h *history) Log() []LogEntry {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]LogEntry{}, h.log...)
+}
+
+// newGroup returns a group of operations made now
+func (h *history) newGroup(description string, ops []Operation) *Group {
+	return &Group{
+		ID:          uuid.New().String(),
+		Description: description,
+		Operations:  ops,
+		Timestamp:   time.Now(),
+		Author:      h.config.Author,
+	}
+}
+
+// logLocked records an action in the log (must be called with mu held)
+func (h *history) logLocked(action string, group Group) {
+	entry := LogEntry{Action: action, Time: time.Now(), Group: group}
+	h.log = append(h.log, entry)
+	if len(h.log) > maxLogSize {
+		h.log = h.log[len(h.log)-maxLogSize:]
+	}
+	if h.config.OnLog != nil {
+		h.config.OnLog(entry)
+	}
+}
+
+// changedLocked reports whether there is anything to undo and redo (must be
+// called with mu held)
+func (h *history) changedLocked() {
+	if h.config.OnChange != nil {
+		h.config.OnChange(len(h.undo) > 0, len(h.redo) > 0)
+	}
+}
+
+// trim drops the oldest groups of a history beyond size
+func trim(groups []Group, size int) []Group {
+	if size > 0 && len(groups) > size {
+		return groups[len(groups)-size:]
+	}
+	return groups
+}
@@ -0,0 +1,138 @@
+package history
+
+import (
+	"errors"
+	"testing"
+)
+
+func copyOp(line int) Operation {
+	return Operation{Type: OpCopy, TargetFile: "target.txt", LineNumber: line, InsertIndex: line}
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("operations outside a group are groups of their own", func(t *testing.T) {
+		h := New(Config{Size: 10, Author: "someone"})
+		if !h.Record(copyOp(1)) {
+			t.Error("Expected the undo history to change")
+		}
+		undo, _ := h.Snapshot()
+		if len(undo) != 1 || undo[0].Description != "copy line" || undo[0].Author != "someone" {
+			t.Errorf("Unexpected undo history %+v", undo)
+		}
+	})
+
+	t.Run("groups commit together and roll back", func(t *testing.T) {
+		h := New(Config{Size: 10})
+		h.Begin("Copy hunk")
+		h.Record(copyOp(1))
+		h.Record(copyOp(2))
+		if h.CanUndo() {
+			t.Error("Expected an open group not to be undoable")
+		}
+		h.Commit()
+		if h.UndoDescription() != "Copy hunk" {
+			t.Errorf("Expected the group to be committed, got %q", h.UndoDescription())
+		}
+
+		h.Begin("Discarded")
+		h.Record(copyOp(3))
+		var reverted []Operation
+		if !h.Rollback(func(group Group) { reverted = group.Operations }) {
+			t.Error("Expected the group to be rolled back")
+		}
+		if len(reverted) != 1 {
+			t.Errorf("Expected the open group's operations to be reverted, got %+v", reverted)
+		}
+		if undo, _ := h.Snapshot(); len(undo) != 1 {
+			t.Errorf("Expected the rolled back group to be left out, got %+v", undo)
+		}
+		if _, ok := h.Pending(); ok {
+			t.Error("Expected no open group")
+		}
+	})
+
+	t.Run("undo and redo move groups between stacks", func(t *testing.T) {
+		var log []string
+		h := New(Config{Size: 10, OnLog: func(entry LogEntry) { log = append(log, entry.Action) }})
+		h.Record(copyOp(1))
+
+		if err := h.Undo(func(Group) error { return nil }); err != nil {
+			t.Fatalf("Undo returned error: %v", err)
+		}
+		if h.CanUndo() || !h.CanRedo() {
+			t.Error("Expected the group to move to the redo history")
+		}
+		if err := h.Redo(func(Group) error { return nil }); err != nil {
+			t.Fatalf("Redo returned error: %v", err)
+		}
+		if !h.CanUndo() || h.CanRedo() {
+			t.Error("Expected the group to move back to the undo history")
+		}
+		if len(log) != 3 || log[0] != LogApply || log[1] != LogUndo || log[2] != LogRedo {
+			t.Errorf("Unexpected log %v", log)
+		}
+		if len(h.Log()) != 3 {
+			t.Errorf("Expected 3 log entries, got %d", len(h.Log()))
+		}
+		if err := h.Redo(func(Group) error { return nil }); !errors.Is(err, ErrNothingToRedo) {
+			t.Errorf("Expected ErrNothingToRedo, got %v", err)
+		}
+	})
+
+	t.Run("failed undo leaves the history unchanged", func(t *testing.T) {
+		h := New(Config{Size: 10})
+		h.Record(copyOp(1))
+		failure := errors.New("failed")
+		if err := h.Undo(func(Group) error { return failure }); err != failure {
+			t.Errorf("Expected the replay's error, got %v", err)
+		}
+		if !h.CanUndo() || h.CanRedo() {
+			t.Error("Expected the history to be unchanged")
+		}
+	})
+
+	t.Run("operations replaying a group are not recorded", func(t *testing.T) {
+		h := New(Config{Size: 10})
+		h.Record(copyOp(1))
+		h.Undo(func(Group) error {
+			h.Record(copyOp(2))
+			return nil
+		})
+		if h.CanUndo() {
+			t.Error("Expected no operations to be recorded during undo")
+		}
+		h.Record(copyOp(3))
+		if !h.CanUndo() || h.CanRedo() {
+			t.Error("Expected a new group to be recorded and clear the redo history")
+		}
+	})
+
+	t.Run("resize trims the oldest groups", func(t *testing.T) {
+		h := New(Config{Size: 10})
+		for i := 1; i <= 5; i++ {
+			h.Record(copyOp(i))
+		}
+		h.Resize(2)
+		undo, _ := h.Snapshot()
+		if len(undo) != 2 || undo[0].Operations[0].LineNumber != 4 {
+			t.Errorf("Expected the two newest groups, got %+v", undo)
+		}
+		h.Record(copyOp(6))
+		if undo, _ := h.Snapshot(); len(undo) != 2 {
+			t.Errorf("Expected the history to stay at 2 groups, got %d", len(undo))
+		}
+	})
+
+	t.Run("changes are reported", func(t *testing.T) {
+		var canUndo, canRedo bool
+		h := New(Config{Size: 10, OnChange: func(undo, redo bool) { canUndo, canRedo = undo, redo }})
+		h.Restore([]Group{{ID: "a"}}, []Group{{ID: "b"}})
+		if !canUndo || !canRedo {
+			t.Error("Expected restoring to report both histories")
+		}
+		h.Record(copyOp(1))
+		if !canUndo || canRedo {
+			t.Error("Expected a new group to report the redo history cleared")
+		}
+	})
+}
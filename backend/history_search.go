@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// HistoryMatch is an operation group in the undo or redo history that
+// matches a search
+type HistoryMatch struct {
+	Stack       string    `json:"stack"` // "undo" or "redo"
+	Depth       int       `json:"depth"` // undos (or redos) needed to reach the group, 1 being the next
+	GroupID     string    `json:"groupId"`
+	Description string    `json:"description"`
+	Timestamp   time.Time `json:"timestamp"`
+	Operations  []int     `json:"operations"` // indexes of the operations whose file or line matches
+}
+
+// SearchOperationHistory finds the operation groups in the undo and redo
+// history whose description, file names, or line content contain query,
+// ignoring case. Undo matches come first, then redo matches, each nearest
+// first, so the history panel can jump straight to them.
+func (a *App) SearchOperationHistory(query string) []HistoryMatch {
+	query = strings.ToLower(strings.TrimSpace(query))
+	if query == "" {
+		return []HistoryMatch{}
+	}
+
+	undo, redo := a.history.Snapshot()
+	matches := []HistoryMatch{}
+	for _, stack := range []struct {
+		name    string
+		history []OperationGroup
+	}{{"undo", undo}, {"redo", redo}} {
+		for i := len(stack.history) - 1; i >= 0; i-- {
+			group := stack.history[i]
+			operations := []int{}
+			for j, op := range group.Operations {
+				if operationMatches(op, query) {
+					operations = append(operations, j)
+				}
+			}
+			if len(operations) == 0 && !strings.Contains(strings.ToLower(group.Description), query) {
+				continue
+			}
+			matches = append(matches, HistoryMatch{
+				Stack:       stack.name,
+				Depth:       len(stack.history) - i,
+				GroupID:     group.ID,
+				Description: group.Description,
+				Timestamp:   group.Timestamp,
+				Operations:  operations,
+			})
+		}
+	}
+	return matches
+}
+
+// operationMatches reports whether the name of a file an operation touches
+// or the line it copies or removes contains query, which is lower case
+func operationMatches(op SingleOperation, query string) bool {
+	for _, text := range []string{op.LineContent, fileName(op.SourceFile), fileName(op.TargetFile)} {
+		if strings.Contains(strings.ToLower(text), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// fileName returns the last element of path, or "" for no path
+func fileName(path string) string {
+	if path == "" {
+		return ""
+	}
+	return filepath.Base(path)
+}
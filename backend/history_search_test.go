@@ -0,0 +1,57 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApp_SearchOperationHistory(t *testing.T) {
+	app := NewApp()
+
+	undo := []OperationGroup{
+		{ID: "a", Description: "Copy block to right", Operations: []SingleOperation{
+			{Type: OpCopy, SourceFile: "/work/left/main.go", TargetFile: "/work/right/main.go", LineContent: "func main() {"},
+		}},
+		{ID: "b", Description: "Delete lines", Operations: []SingleOperation{
+			{Type: OpRemove, TargetFile: "/work/right/util.go", LineContent: "// deprecated helper"},
+			{Type: OpRemove, TargetFile: "/work/right/util.go", LineContent: "func Helper() {}"},
+		}},
+	}
+	redo := []OperationGroup{
+		{ID: "c", Description: "Copy helper", Operations: []SingleOperation{
+			{Type: OpCopy, TargetFile: "/work/right/util.go", LineContent: "return nil"},
+		}},
+		{ID: "d", Description: "Copy line", Operations: []SingleOperation{
+			{Type: OpCopy, TargetFile: "/work/right/main.go", LineContent: "x := 1"},
+		}},
+	}
+	app.history.Restore(undo, redo)
+
+	t.Run("line content and description across stacks", func(t *testing.T) {
+		got := app.SearchOperationHistory("HELPER")
+		want := []HistoryMatch{
+			{Stack: "undo", Depth: 1, GroupID: "b", Description: "Delete lines", Operations: []int{0, 1}},
+			{Stack: "redo", Depth: 2, GroupID: "c", Description: "Copy helper", Operations: []int{}},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SearchOperationHistory() = %+v, want %+v", got, want)
+		}
+	})
+
+	t.Run("file names", func(t *testing.T) {
+		got := app.SearchOperationHistory("main.go")
+		if len(got) != 2 || got[0].GroupID != "a" || got[0].Depth != 2 || got[1].GroupID != "d" || got[1].Depth != 1 {
+			t.Errorf("Expected the groups touching main.go, got %+v", got)
+		}
+		// Directories are not file names
+		if got := app.SearchOperationHistory("work"); len(got) != 0 {
+			t.Errorf("Expected no matches on directories, got %+v", got)
+		}
+	})
+
+	t.Run("blank query", func(t *testing.T) {
+		if got := app.SearchOperationHistory("  "); len(got) != 0 {
+			t.Errorf("Expected no matches, got %+v", got)
+		}
+	})
+}
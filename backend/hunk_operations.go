@@ -0,0 +1,260 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"weld/backend/compare"
+	"weld/backend/diff"
+)
+
+// Hunk is now imported from the diff package
+type Hunk = diff.Hunk
+
+// Copy directions for hunk operations
+const (
+	DirectionLeftToRight = "left-to-right"
+	DirectionRightToLeft = "right-to-left"
+)
+
+// previewContextLines is the number of unchanged lines shown around a hunk preview
+const previewContextLines = 3
+
+// comparison holds the most recently computed diff so hunk-based APIs can
+// refer to hunks by ID without the frontend sending the whole result back
+type comparison = compare.Comparison[CompareOptions]
+
+// HunkPreview describes the result of applying a hunk without changing anything
+type HunkPreview struct {
+	HunkID      string      `json:"hunkId"`
+	Direction   string      `json:"direction"`
+	TargetFile  string      `json:"targetFile"`
+	StartLine   int         `json:"startLine"`   // 1-based target line where ResultLines begins
+	ResultLines []string    `json:"resultLines"` // target lines around the hunk after applying it
+	Diff        *DiffResult `json:"diff"`        // diff of the affected region after applying it
+}
+
+// setCurrentComparison records the result of the latest comparison
+func (a *App) setCurrentComparison(leftPath, rightPath string, options CompareOptions, result *DiffResult) {
+	a.compare.SetCurrent(&comparison{
+		LeftPath:  leftPath,
+		RightPath: rightPath,
+		Options:   options,
+		Result:    result,
+		Hunks:     diff.GroupHunks(result),
+	})
+}
+
+// currentComparison returns the latest comparison, or an error if none exists
+func (a *App) currentComparison() (*comparison, error) {
+	current := a.compare.Current()
+	if current == nil {
+		return nil, fmt.Errorf("no files have been compared")
+	}
+	// The result is dropped while idle and recomputed on first use
+	if current.Result == nil {
+		return a.restoreComparison(current)
+	}
+	return current, nil
+}
+
+// GetHunks returns the hunks of the current comparison
+func (a *App) GetHunks() ([]Hunk, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	return append([]Hunk{}, current.Hunks...), nil
+}
+
+// findHunk looks up a hunk of the current comparison by ID
+func (a *App) findHunk(hunkID string) (*comparison, Hunk, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, Hunk{}, err
+	}
+	for _, hunk := range current.Hunks {
+		if hunk.ID == hunkID {
+			return current, hunk, nil
+		}
+	}
+	return nil, Hunk{}, fmt.Errorf("hunk not found: %s", hunkID)
+}
+
+// hunkSide identifies the lines a hunk covers in one file
+type hunkSide struct {
+	path  string
+	start int // 1-based
+	count int
+}
+
+// hunkSides resolves the source and target of applying a hunk in a direction
+func hunkSides(current *comparison, hunk Hunk, direction string) (hunkSide, hunkSide, error) {
+	left := hunkSide{path: current.LeftPath, start: hunk.LeftStart, count: hunk.LeftCount}
+	right := hunkSide{path: current.RightPath, start: hunk.RightStart, count: hunk.RightCount}
+
+	switch direction {
+	case DirectionLeftToRight:
+		return left, right, nil
+	case DirectionRightToLeft:
+		return right, left, nil
+	}
+	return hunkSide{}, hunkSide{}, fmt.Errorf("invalid direction: %s", direction)
+}
+
+// applyHunkLines returns the target lines with the hunk's target lines
+// replaced by the source lines
+func applyHunkLines(sourceLines, targetLines []string, source, target hunkSide) ([]string, error) {
+	if source.start-1+source.count > len(sourceLines) || target.start-1+target.count > len(targetLines) {
+		return nil, fmt.Errorf("hunk is out of date; compare the files again")
+	}
+
+	result := make([]string, 0, len(targetLines)-target.count+source.count)
+	result = append(result, targetLines[:target.start-1]...)
+	result = append(result, sourceLines[source.start-1:source.start-1+source.count]...)
+	result = append(result, targetLines[target.start-1+target.count:]...)
+	return result, nil
+}
+
+// PreviewApplyHunk returns what the target file would look like around a hunk
+// if it were copied in the given direction, without modifying any file content
+func (a *App) PreviewApplyHunk(hunkID, direction string) (*HunkPreview, error) {
+	current, hunk, err := a.findHunk(hunkID)
+	if err != nil {
+		return nil, err
+	}
+
+	source, target, err := hunkSides(current, hunk, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceLines, err := a.ReadFileContentWithCache(source.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source file: %w", err)
+	}
+	targetLines, err := a.ReadFileContentWithCache(target.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target file: %w", err)
+	}
+
+	applied, err := applyHunkLines(sourceLines, targetLines, source, target)
+	if err != nil {
+		return nil, err
+	}
+
+	// After applying, the source and target agree around the hunk, so the
+	// same amount of context can be taken from both
+	before := min(previewContextLines, source.start-1, target.start-1)
+	after := min(previewContextLines,
+		len(sourceLines)-(source.start-1+source.count),
+		len(targetLines)-(target.start-1+target.count))
+
+	sourceFrom := source.start - 1 - before
+	sourceTo := source.start - 1 + source.count + after
+	targetFrom := target.start - 1 - before
+	targetTo := target.start - 1 + source.count + after
+
+	resultLines := applied[targetFrom:targetTo]
+
+	// Diffs are always expressed left to right, compared as the files are
+	algorithm := a.algorithmFor(a.resolveCompareOptions(current.Options))
+	var regionDiff *DiffResult
+	if direction == DirectionLeftToRight {
+		regionDiff = algorithm.ComputeDiff(sourceLines[sourceFrom:sourceTo], resultLines)
+		diff.OffsetLineNumbers(regionDiff, sourceFrom, targetFrom)
+	} else {
+		regionDiff = algorithm.ComputeDiff(resultLines, sourceLines[sourceFrom:sourceTo])
+		diff.OffsetLineNumbers(regionDiff, targetFrom, sourceFrom)
+	}
+
+	return &HunkPreview{
+		HunkID:      hunkID,
+		Direction:   direction,
+		TargetFile:  target.path,
+		StartLine:   targetFrom + 1,
+		ResultLines: append([]string{}, resultLines...),
+		Diff:        regionDiff,
+	}, nil
+}
+
+// hunkLines returns the lines a hunk covers on one side
+func (a *App) hunkLines(side hunkSide) ([]string, error) {
+	lines, err := a.ReadFileContentWithCache(side.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if side.start-1+side.count > len(lines) {
+		return nil, fmt.Errorf("hunk is out of date; compare the files again")
+	}
+	return lines[side.start-1 : side.start-1+side.count], nil
+}
+
+// ExportHunk writes a hunk's lines to a new file. Side is "left" or "right" to
+// export one side, or "both" to export both sides separated by conflict markers.
+// An existing file at path is never overwritten.
+func (a *App) ExportHunk(hunkID, side, path string) error {
+	if path == "" {
+		return fmt.Errorf("export path cannot be empty")
+	}
+
+	current, hunk, err := a.findHunk(hunkID)
+	if err != nil {
+		return err
+	}
+	left := hunkSide{path: current.LeftPath, start: hunk.LeftStart, count: hunk.LeftCount}
+	right := hunkSide{path: current.RightPath, start: hunk.RightStart, count: hunk.RightCount}
+
+	var output []string
+	switch side {
+	case "left", "right":
+		selected := left
+		if side == "right" {
+			selected = right
+		}
+		output, err = a.hunkLines(selected)
+		if err != nil {
+			return err
+		}
+	case "both":
+		leftLines, err := a.hunkLines(left)
+		if err != nil {
+			return err
+		}
+		rightLines, err := a.hunkLines(right)
+		if err != nil {
+			return err
+		}
+		output = append(output, "<<<<<<< "+filepath.Base(current.LeftPath))
+		output = append(output, leftLines...)
+		output = append(output, "=======")
+		output = append(output, rightLines...)
+		output = append(output, ">>>>>>> "+filepath.Base(current.RightPath))
+	default:
+		return fmt.Errorf("invalid side: %s", side)
+	}
+
+	content := strings.Join(output, "\n")
+	if len(output) > 0 {
+		content += "\n"
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("file already exists: %s", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write hunk: %w", err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write hunk: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write hunk: %w", err)
+	}
+	return nil
+}
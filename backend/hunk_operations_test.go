@@ -0,0 +1,170 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// compareTempFiles writes both contents to temp files and compares them
+func compareTempFiles(t *testing.T, app *App, left, right []string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	if err := os.WriteFile(leftPath, []byte(strings.Join(left, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte(strings.Join(right, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if _, err := app.CompareFiles(leftPath, rightPath); err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	return leftPath, rightPath
+}
+
+func TestApp_PreviewApplyHunk(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	t.Run("requires a comparison", func(t *testing.T) {
+		if _, err := NewApp().PreviewApplyHunk("hunk-1", DirectionLeftToRight); err == nil {
+			t.Error("Expected error when nothing has been compared")
+		}
+	})
+
+	left := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	right := []string{"a", "b", "c", "d", "inserted", "e", "f", "g", "h"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	hunks, err := app.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks returned error: %v", err)
+	}
+	if len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %d", len(hunks))
+	}
+	hunkID := hunks[0].ID
+
+	t.Run("right to left inserts the line", func(t *testing.T) {
+		preview, err := app.PreviewApplyHunk(hunkID, DirectionRightToLeft)
+		if err != nil {
+			t.Fatalf("PreviewApplyHunk returned error: %v", err)
+		}
+		if preview.TargetFile != leftPath {
+			t.Errorf("Expected target %s, got %s", leftPath, preview.TargetFile)
+		}
+		if preview.StartLine != 2 {
+			t.Errorf("Expected preview to start at line 2, got %d", preview.StartLine)
+		}
+		expected := []string{"b", "c", "d", "inserted", "e", "f", "g"}
+		if !reflect.DeepEqual(preview.ResultLines, expected) {
+			t.Errorf("Expected %v, got %v", expected, preview.ResultLines)
+		}
+		for _, line := range preview.Diff.Lines {
+			if line.Type != "same" {
+				t.Errorf("Expected region to be identical after applying, got %+v", line)
+			}
+		}
+		if preview.Diff.Lines[0].LeftNumber != 2 || preview.Diff.Lines[0].RightNumber != 2 {
+			t.Errorf("Expected region line numbers to be offset, got %+v", preview.Diff.Lines[0])
+		}
+	})
+
+	t.Run("left to right removes the line", func(t *testing.T) {
+		preview, err := app.PreviewApplyHunk(hunkID, DirectionLeftToRight)
+		if err != nil {
+			t.Fatalf("PreviewApplyHunk returned error: %v", err)
+		}
+		if preview.TargetFile != rightPath {
+			t.Errorf("Expected target %s, got %s", rightPath, preview.TargetFile)
+		}
+		expected := []string{"b", "c", "d", "e", "f", "g"}
+		if !reflect.DeepEqual(preview.ResultLines, expected) {
+			t.Errorf("Expected %v, got %v", expected, preview.ResultLines)
+		}
+	})
+
+	t.Run("does not modify the cache", func(t *testing.T) {
+		if app.HasUnsavedChanges(leftPath) || app.HasUnsavedChanges(rightPath) {
+			t.Error("Expected preview to leave files unmodified")
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		if _, err := app.PreviewApplyHunk("missing", DirectionLeftToRight); err == nil {
+			t.Error("Expected error for unknown hunk")
+		}
+		if _, err := app.PreviewApplyHunk(hunkID, "sideways"); err == nil {
+			t.Error("Expected error for invalid direction")
+		}
+	})
+}
+
+func TestApp_ExportHunk(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"func a() {", "  return 1", "}"}
+	right := []string{"func a() {", "  return 2", "  // changed", "}"}
+	compareTempFiles(t, app, left, right)
+
+	hunks, err := app.GetHunks()
+	if err != nil || len(hunks) != 1 {
+		t.Fatalf("Expected 1 hunk, got %v (err %v)", hunks, err)
+	}
+	outDir := t.TempDir()
+
+	tests := []struct {
+		side     string
+		expected string
+	}{
+		{"left", "  return 1\n"},
+		{"right", "  return 2\n  // changed\n"},
+		{"both", "<<<<<<< left.txt\n  return 1\n=======\n  return 2\n  // changed\n>>>>>>> right.txt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.side, func(t *testing.T) {
+			out := filepath.Join(outDir, tt.side+".snippet")
+			if err := app.ExportHunk(hunks[0].ID, tt.side, out); err != nil {
+				t.Fatalf("ExportHunk returned error: %v", err)
+			}
+			content, err := os.ReadFile(out)
+			if err != nil {
+				t.Fatalf("Failed to read exported hunk: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, string(content))
+			}
+		})
+	}
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		if err := app.ExportHunk(hunks[0].ID, "middle", filepath.Join(outDir, "x")); err == nil {
+			t.Error("Expected error for invalid side")
+		}
+		if err := app.ExportHunk("missing", "left", filepath.Join(outDir, "x")); err == nil {
+			t.Error("Expected error for unknown hunk")
+		}
+		if err := app.ExportHunk(hunks[0].ID, "left", ""); err == nil {
+			t.Error("Expected error for empty path")
+		}
+	})
+
+	t.Run("existing file is kept", func(t *testing.T) {
+		out := filepath.Join(outDir, "existing.snippet")
+		if err := os.WriteFile(out, []byte("keep me\n"), 0644); err != nil {
+			t.Fatalf("Failed to create file: %v", err)
+		}
+		if err := app.ExportHunk(hunks[0].ID, "left", out); err == nil {
+			t.Error("Expected error for an existing file")
+		}
+		if content, _ := os.ReadFile(out); string(content) != "keep me\n" {
+			t.Errorf("Expected the existing file to be unchanged, got %q", content)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"runtime/debug"
+	"time"
+
+	"weld/backend/diff"
+)
+
+// defaultIdleTimeoutMinutes is how long Weld waits without interaction before
+// releasing memory it can rebuild later
+const defaultIdleTimeoutMinutes = 10
+
+// pausedWatch remembers the watched files while watching is paused, so
+// changes made in the meantime can be reported on resume
+type pausedWatch struct {
+	leftPath     string
+	rightPath    string
+	leftModTime  time.Time
+	rightModTime time.Time
+}
+
+// RecordActivity tells the backend the user interacted with the window. It
+// restarts the idle countdown and restores anything paused while idle. The
+// bound methods behind comparing, editing, undoing, and saving call it too.
+func (a *App) RecordActivity() {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
+
+	a.lastActivity = time.Now()
+	a.armIdleTimerLocked()
+
+	if !a.idle {
+		return
+	}
+	a.idle = false
+	paused := a.pausedWatch
+	a.pausedWatch = nil
+	a.watchPausedFlag.Store(false)
+	a.resumeFileWatching(paused)
+}
+
+// IsIdle returns whether idle resources have been released
+func (a *App) IsIdle() bool {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
+	return a.idle
+}
+
+// GetIdleTimeout returns the minutes without interaction before resources are
+// released, or 0 when idle reclamation is disabled
+func (a *App) GetIdleTimeout() int {
+	return a.settings.Get().IdleTimeoutMinutes
+}
+
+// SetIdleTimeout sets the minutes without interaction before resources are
+// released. Zero disables idle reclamation.
+func (a *App) SetIdleTimeout(minutes int) error {
+	if minutes < 0 {
+		return fmt.Errorf("idle timeout cannot be negative")
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.IdleTimeoutMinutes = minutes
+		return nil
+	})
+
+	a.RecordActivity()
+	return err
+}
+
+// armIdleTimerLocked schedules the switch to idle, or cancels it when idle
+// reclamation is disabled (must be called with idleMutex held)
+func (a *App) armIdleTimerLocked() {
+	timeout := time.Duration(a.GetIdleTimeout()) * time.Minute
+	if timeout <= 0 {
+		if a.idleTimer != nil {
+			a.idleTimer.Stop()
+		}
+		return
+	}
+	if a.idleTimer == nil {
+		a.idleTimer = time.AfterFunc(timeout, a.enterIdle)
+	} else {
+		a.idleTimer.Reset(timeout)
+	}
+}
+
+// stopIdleTimer cancels any pending switch to idle
+func (a *App) stopIdleTimer() {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
+	if a.idleTimer != nil {
+		a.idleTimer.Stop()
+	}
+}
+
+// enterIdle pauses file watching and releases memory that can be rebuilt on demand
+func (a *App) enterIdle() {
+	a.idleMutex.Lock()
+	defer a.idleMutex.Unlock()
+
+	// Activity may have arrived while the timer was firing
+	timeout := time.Duration(a.GetIdleTimeout()) * time.Minute
+	if a.idle || timeout <= 0 || time.Since(a.lastActivity) < timeout {
+		return
+	}
+	a.idle = true
+	a.pausedWatch = a.pauseFileWatching()
+	a.watchPausedFlag.Store(a.pausedWatch != nil)
+	a.reclaimIdleResources()
+}
+
+// reclaimIdleResources drops cached results that are recomputed lazily and
+// returns the freed memory to the OS
+func (a *App) reclaimIdleResources() {
+	// The diff of the current comparison is recomputed on next use
+	if current := a.compare.Current(); current != nil && current.Result != nil {
+		a.compare.ReplaceCurrent(current, &comparison{
+			LeftPath:  current.LeftPath,
+			RightPath: current.RightPath,
+			Options:   current.Options,
+		})
+	}
+
+	// Trends are reloaded from storage, so they can only go if they are stored
+	if a.Storage != nil {
+		a.trendMutex.Lock()
+		a.trends = nil
+		a.trendMutex.Unlock()
+	}
+
+	// The directory index reopens on the next directory comparison
+	a.closeDirectoryIndex()
+
+	a.compare.Compact()
+	debug.FreeOSMemory()
+}
+
+// pauseFileWatching stops watching the compared files, returning what is
+// needed to resume, or nil if nothing was being watched
+func (a *App) pauseFileWatching() *pausedWatch {
+	if !a.watcher.Watching() {
+		return nil
+	}
+	paused := &pausedWatch{}
+	paused.leftPath, paused.rightPath = a.watcher.Paths()
+
+	paused.leftModTime = modTime(paused.leftPath)
+	paused.rightModTime = modTime(paused.rightPath)
+	a.StopFileWatching()
+	return paused
+}
+
+// resumeFileWatching watches paused files again and reports any that changed
+// while watching was paused
+func (a *App) resumeFileWatching(paused *pausedWatch) {
+	if paused == nil {
+		return
+	}
+	a.StartFileWatching(paused.leftPath, paused.rightPath)
+
+	if !modTime(paused.leftPath).Equal(paused.leftModTime) {
+		a.watcher.Changed(paused.leftPath)
+	}
+	if !modTime(paused.rightPath).Equal(paused.rightModTime) {
+		a.watcher.Changed(paused.rightPath)
+	}
+}
+
+// modTime returns a file's modification time, or the zero time if it cannot be read
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// restoreComparison recomputes a comparison whose result was dropped while idle
+func (a *App) restoreComparison(dropped *comparison) (*comparison, error) {
+	result, err := a.computeDiff(dropped.LeftPath, dropped.RightPath, dropped.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore comparison: %w", err)
+	}
+	restored := &comparison{
+		LeftPath:  dropped.LeftPath,
+		RightPath: dropped.RightPath,
+		Options:   dropped.Options,
+		Result:    result,
+		Hunks:     diff.GroupHunks(result),
+	}
+
+	// A newer comparison may have started while this one was being restored,
+	// in which case it is kept
+	a.compare.ReplaceCurrent(dropped, restored)
+	return restored, nil
+}
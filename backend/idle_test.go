@@ -0,0 +1,138 @@
+package backend
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestApp_IdleReclamation(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() {
+		app.stopIdleTimer()
+		app.StopFileWatching()
+	})
+
+	leftPath, rightPath := compareTempFiles(t, app, []string{"a", "b", "c"}, []string{"a", "x", "c"})
+	hunks, err := app.GetHunks()
+	if err != nil {
+		t.Fatalf("GetHunks returned error: %v", err)
+	}
+
+	t.Run("recent activity keeps resources", func(t *testing.T) {
+		app.RecordActivity()
+		app.enterIdle()
+		if app.IsIdle() {
+			t.Error("Expected app not to go idle right after activity")
+		}
+	})
+
+	// Pretend the last interaction was long ago
+	app.idleMutex.Lock()
+	app.lastActivity = time.Now().Add(-time.Duration(defaultIdleTimeoutMinutes+1) * time.Minute)
+	app.idleMutex.Unlock()
+	app.enterIdle()
+
+	t.Run("idle releases the diff and pauses watching", func(t *testing.T) {
+		if !app.IsIdle() {
+			t.Fatal("Expected app to be idle")
+		}
+		if app.compare.Current().Result != nil {
+			t.Error("Expected the diff result to be dropped")
+		}
+		if app.watcher.Watching() {
+			t.Error("Expected file watching to be paused")
+		}
+	})
+
+	t.Run("hunks are restored lazily", func(t *testing.T) {
+		restored, err := app.GetHunks()
+		if err != nil {
+			t.Fatalf("GetHunks returned error: %v", err)
+		}
+		if !reflect.DeepEqual(restored, hunks) {
+			t.Errorf("Expected %v, got %v", hunks, restored)
+		}
+	})
+
+	t.Run("activity resumes watching", func(t *testing.T) {
+		// Change a file while paused so resuming has something to report
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(leftPath, later, later); err != nil {
+			t.Fatalf("Failed to touch file: %v", err)
+		}
+		app.RecordActivity()
+		if app.IsIdle() {
+			t.Error("Expected activity to end idle")
+		}
+		watchedLeft, _ := app.watcher.Paths()
+		if !app.watcher.Watching() || watchedLeft != leftPath {
+			t.Error("Expected file watching to resume")
+		}
+		if status := app.GetWatchStatus(); status.Paths[0].LastEvent == nil {
+			t.Error("Expected the change made while paused to be reported")
+		}
+	})
+
+	t.Run("resuming reports a file that cannot be watched", func(t *testing.T) {
+		app.idleMutex.Lock()
+		app.lastActivity = time.Now().Add(-time.Duration(defaultIdleTimeoutMinutes+1) * time.Minute)
+		app.idleMutex.Unlock()
+		app.enterIdle()
+		if status := app.GetWatchStatus(); status.State != WatchStatePaused {
+			t.Errorf("Expected watching to be paused, got %q", status.State)
+		}
+		if err := os.Remove(leftPath); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+
+		// Resuming reports the failure while the idle lock is held
+		done := make(chan struct{})
+		go func() {
+			app.RecordActivity()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected activity to resume watching without deadlocking")
+		}
+		if status := app.GetWatchStatus(); status.State == WatchStatePaused {
+			t.Error("Expected watching not to be reported as paused after resuming")
+		}
+	})
+
+	t.Run("editing counts as activity", func(t *testing.T) {
+		app.idleMutex.Lock()
+		app.lastActivity = time.Now().Add(-time.Duration(defaultIdleTimeoutMinutes+1) * time.Minute)
+		app.idleMutex.Unlock()
+		app.enterIdle()
+		if !app.IsIdle() {
+			t.Fatal("Expected app to be idle")
+		}
+		if err := app.CopyToFile(leftPath, rightPath, 1, "copied"); err != nil {
+			t.Fatalf("CopyToFile returned error: %v", err)
+		}
+		if app.IsIdle() {
+			t.Error("Expected an edit to end idle")
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if err := app.SetIdleTimeout(0); err != nil {
+			t.Fatalf("SetIdleTimeout returned error: %v", err)
+		}
+		app.idleMutex.Lock()
+		app.lastActivity = time.Time{}
+		app.idleMutex.Unlock()
+		app.enterIdle()
+		if app.IsIdle() {
+			t.Error("Expected no idle state when disabled")
+		}
+		if err := app.SetIdleTimeout(-1); err == nil {
+			t.Error("Expected error for negative timeout")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package backend
+
+import (
+	"regexp"
+
+	"weld/backend/diff"
+)
+
+// GetIgnorePatterns returns the regular expressions matching lines that
+// always compare equal
+func (a *App) GetIgnorePatterns() []string {
+	return append([]string{}, a.settings.Get().IgnorePatterns...)
+}
+
+// SetIgnorePatterns sets the regular expressions matching lines that always
+// compare equal, such as generated timestamps or version stamps, and
+// re-compares the current files. The new result is delivered through the
+// "diff-updated" event.
+func (a *App) SetIgnorePatterns(patterns []string) error {
+	if _, err := diff.CompileIgnorePatterns(patterns); err != nil {
+		return err
+	}
+
+	err := a.settings.Update(func(s *Settings) error {
+		s.IgnorePatterns = append([]string{}, patterns...)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.rediffCurrentComparison()
+	return nil
+}
+
+// ignorePatterns compiles the ignore patterns setting. Patterns are validated
+// when set and when settings load, so none should fail to compile.
+func (a *App) ignorePatterns() []*regexp.Regexp {
+	compiled, err := diff.CompileIgnorePatterns(a.GetIgnorePatterns())
+	if err != nil {
+		return nil
+	}
+	return compiled
+}
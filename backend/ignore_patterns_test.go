@@ -0,0 +1,40 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApp_SetIgnorePatterns(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"version: 1.2.3", "name: weld"}
+	right := []string{"version: 1.2.4", "name: weld"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	if err := app.SetIgnorePatterns([]string{"[invalid"}); err == nil {
+		t.Error("Expected error for an invalid pattern")
+	}
+	if len(app.GetIgnorePatterns()) != 0 {
+		t.Errorf("Expected no patterns after a rejected update, got %v", app.GetIgnorePatterns())
+	}
+
+	patterns := []string{`^version:`}
+	if err := app.SetIgnorePatterns(patterns); err != nil {
+		t.Fatalf("SetIgnorePatterns returned error: %v", err)
+	}
+	if got := app.GetIgnorePatterns(); !reflect.DeepEqual(got, patterns) {
+		t.Errorf("Expected %v, got %v", patterns, got)
+	}
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	for _, line := range result.Lines {
+		if line.Type != "same" {
+			t.Errorf("Expected the version stamp to be ignored, got %+v", line)
+		}
+	}
+}
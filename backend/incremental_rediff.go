@@ -0,0 +1,68 @@
+package backend
+
+import "weld/backend/diff"
+
+// patchCurrentComparison updates the current comparison of leftPath and
+// rightPath after its files were edited, comparing again only the region
+// around the edits. It reports false when the comparison has to be
+// recomputed in full: for another pair, for results laid out other than
+// line by line, when the result was dropped while idle, or when the edits
+// changed how the files are compared.
+func (a *App) patchCurrentComparison(leftPath, rightPath string) (*DiffResult, diff.Patch, bool) {
+	current := a.compare.Current()
+	if current == nil || current.LeftPath != leftPath || current.RightPath != rightPath {
+		return nil, diff.Patch{}, false
+	}
+	// There is nothing to patch once the result was dropped while idle
+	if current.Result == nil {
+		return nil, diff.Patch{}, false
+	}
+	previous := current.Result
+	if previous.Reordered || previous.Imports != nil || a.GetShowLineAges() {
+		return nil, diff.Patch{}, false
+	}
+	if semanticFormat(current.Options, leftPath, rightPath) != "" {
+		return nil, diff.Patch{}, false
+	}
+	leftLanguage, rightLanguage := diff.LanguageForPath(leftPath), diff.LanguageForPath(rightPath)
+	if a.GetAlignImports() && leftLanguage != "" && leftLanguage == rightLanguage {
+		return nil, diff.Patch{}, false
+	}
+
+	leftLines, err := a.ReadFileContentWithCache(leftPath)
+	if err != nil {
+		return nil, diff.Patch{}, false
+	}
+	rightLines, err := a.ReadFileContentWithCache(rightPath)
+	if err != nil {
+		return nil, diff.Patch{}, false
+	}
+	if len(leftLines) > maxDiffLines || len(rightLines) > maxDiffLines || diff.IsReordered(leftLines, rightLines) {
+		return nil, diff.Patch{}, false
+	}
+
+	algorithm := a.algorithmFor(a.resolveCompareOptions(current.Options))
+	algorithm, metadata := a.tuneAlgorithm(algorithm, leftPath, leftLines)
+	if !sameMetadata(metadata, previous.Metadata) {
+		return nil, diff.Patch{}, false
+	}
+
+	result, patch, ok := diff.PatchResult(previous, leftLines, rightLines, algorithm)
+	if !ok {
+		return nil, diff.Patch{}, false
+	}
+	result.Minified = diff.IsMinified(leftLines) || diff.IsMinified(rightLines)
+	diff.MarkIdentical(result, leftLines)
+	summarizeWhitespace(result, leftPath, rightPath, leftLines, rightLines)
+
+	a.setCurrentComparison(leftPath, rightPath, current.Options, result)
+	return result, patch, true
+}
+
+// sameMetadata reports whether two comparisons were tuned the same way
+func sameMetadata(a, b *ComparisonMetadata) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApp_PatchCurrentComparison(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"first line", "second line", "third line", "fourth line", "fifth line"}
+	right := []string{"first line", "second line", "inserted line", "third line", "fourth line", "fifth line"}
+
+	t.Run("requires the current comparison", func(t *testing.T) {
+		if _, _, ok := NewApp().patchCurrentComparison("left.txt", "right.txt"); ok {
+			t.Error("Expected no patch without a comparison")
+		}
+	})
+
+	t.Run("removing a line patches the result", func(t *testing.T) {
+		leftPath, rightPath := compareTempFiles(t, app, left, right)
+		if err := app.RemoveLineFromFile(rightPath, 3); err != nil {
+			t.Fatalf("RemoveLineFromFile returned error: %v", err)
+		}
+
+		result, patch, ok := app.patchCurrentComparison(leftPath, rightPath)
+		if !ok {
+			t.Fatal("Expected the comparison to be patched")
+		}
+		if !result.Identical {
+			t.Error("Expected the files to be identical after the removal")
+		}
+		if patch.RightShift != -1 || patch.Count != 1 || len(patch.Lines) != 0 {
+			t.Errorf("Expected the added line to be dropped, got %+v", patch)
+		}
+
+		full, err := app.computeDiff(leftPath, rightPath, CompareOptions{})
+		if err != nil {
+			t.Fatalf("computeDiff returned error: %v", err)
+		}
+		if !reflect.DeepEqual(result.Lines, full.Lines) {
+			t.Errorf("Expected patched lines to match a full comparison, got %+v", result.Lines)
+		}
+
+		current, err := app.currentComparison()
+		if err != nil || current.Result != result {
+			t.Error("Expected the patched result to become the current comparison")
+		}
+	})
+
+	t.Run("results dropped while idle compare in full", func(t *testing.T) {
+		leftPath, rightPath := compareTempFiles(t, app, left, right)
+		app.reclaimIdleResources()
+		if err := app.RemoveLineFromFile(rightPath, 3); err != nil {
+			t.Fatalf("RemoveLineFromFile returned error: %v", err)
+		}
+		if _, _, ok := app.patchCurrentComparison(leftPath, rightPath); ok {
+			t.Error("Expected no patch without a result")
+		}
+
+		app.runRediff()
+		current, err := app.currentComparison()
+		if err != nil || current.Result == nil || !current.Result.Identical {
+			t.Errorf("Expected a full re-diff of the edited files, got %+v (%v)", current, err)
+		}
+	})
+
+	t.Run("settings changes compare in full", func(t *testing.T) {
+		leftPath, rightPath := compareTempFiles(t, app, left, right)
+		app.rediffCurrentComparison()
+		app.rediffMutex.Lock()
+		full := app.rediffFull
+		app.rediffMutex.Unlock()
+		if !full {
+			t.Error("Expected a settings re-diff to skip patching")
+		}
+
+		app.runRediff()
+		app.rediffMutex.Lock()
+		full = app.rediffFull
+		app.rediffMutex.Unlock()
+		if full {
+			t.Error("Expected the full re-diff request to be consumed")
+		}
+		if current, err := app.currentComparison(); err != nil || current.LeftPath != leftPath || current.RightPath != rightPath {
+			t.Error("Expected the files to remain the current comparison")
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package backend
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+)
+
+// Keybinding scopes decide when a key is active
+const (
+	// KeyScopeGlobal keys work whether or not a pane is being edited
+	KeyScopeGlobal = "global"
+	// KeyScopeNavigation keys type characters, so they are suppressed while editing
+	KeyScopeNavigation = "navigation"
+	// KeyScopeEditing keys only work while a pane is being edited
+	KeyScopeEditing = "editing"
+)
+
+// Keybinding maps a key to an action within a scope
+type Keybinding struct {
+	Key    string `json:"key"` // lowercase with modifiers first, e.g. "shift+g"
+	Action string `json:"action"`
+	Scope  string `json:"scope"`
+}
+
+// defaultKeybindings are the shortcuts handled by the diff view
+var defaultKeybindings = []Keybinding{
+	{Key: "g", Action: "first-diff", Scope: KeyScopeNavigation},
+	{Key: "shift+g", Action: "last-diff", Scope: KeyScopeNavigation},
+	{Key: "k", Action: "prev-diff", Scope: KeyScopeNavigation},
+	{Key: "j", Action: "next-diff", Scope: KeyScopeNavigation},
+	{Key: "shift+h", Action: "copy-left", Scope: KeyScopeNavigation},
+	{Key: "shift+l", Action: "copy-right", Scope: KeyScopeNavigation},
+	{Key: "f2", Action: "next-marker", Scope: KeyScopeGlobal},
+	{Key: "shift+f2", Action: "prev-marker", Scope: KeyScopeGlobal},
+	{Key: "escape", Action: "exit-edit", Scope: KeyScopeEditing},
+}
+
+// EditingContext describes whether a pane is in edit mode
+type EditingContext struct {
+	Editing bool   `json:"editing"`
+	Pane    string `json:"pane"` // "left", "right", or "" when not editing
+}
+
+// KeyResolution tells the frontend what to do with a key press
+type KeyResolution struct {
+	Action string `json:"action"` // empty when the key has no action in the current context
+	// Suppressed is set when the key has an action that is disabled while
+	// editing, so the key should be typed into the pane instead
+	Suppressed bool `json:"suppressed"`
+}
+
+// GetKeybindings returns the shortcuts of the diff view with their scopes
+func (a *App) GetKeybindings() []Keybinding {
+	return append([]Keybinding{}, defaultKeybindings...)
+}
+
+// GetEditingContext returns whether a pane is being edited
+func (a *App) GetEditingContext() EditingContext {
+	a.editingMutex.Lock()
+	defer a.editingMutex.Unlock()
+	return EditingContext{Editing: a.editingPane != "", Pane: a.editingPane}
+}
+
+// SetEditingPane puts a pane ("left" or "right") into edit mode, or leaves
+// edit mode when pane is empty. While editing, single-key navigation
+// shortcuts are removed from the menu so they reach the editor as text.
+func (a *App) SetEditingPane(pane string) error {
+	if pane != "" && pane != "left" && pane != "right" {
+		return fmt.Errorf("invalid pane: %s", pane)
+	}
+
+	a.editingMutex.Lock()
+	if a.editingPane == pane {
+		a.editingMutex.Unlock()
+		return nil
+	}
+	a.editingPane = pane
+	a.applyKeybindingScopesLocked()
+	a.editingMutex.Unlock()
+
+	a.runtime().MenuUpdateApplicationMenu()
+	a.runtime().EventsEmit("editing-context-changed", a.GetEditingContext())
+	return nil
+}
+
+// ResolveKeybinding returns the action for a key press in the current
+// editing context
+func (a *App) ResolveKeybinding(key string) KeyResolution {
+	key = normalizeKey(key)
+	editing := a.GetEditingContext().Editing
+
+	for _, binding := range defaultKeybindings {
+		if binding.Key != key {
+			continue
+		}
+		switch binding.Scope {
+		case KeyScopeNavigation:
+			if editing {
+				return KeyResolution{Suppressed: true}
+			}
+		case KeyScopeEditing:
+			if !editing {
+				return KeyResolution{}
+			}
+		}
+		return KeyResolution{Action: binding.Action}
+	}
+	return KeyResolution{}
+}
+
+// navigationMenuItems returns the menu items whose accelerators are
+// navigation-scope keys (must be called with menuMutex held)
+func (a *App) navigationMenuItems() []*menu.MenuItem {
+	return []*menu.MenuItem{
+		a.firstDiffMenuItem,
+		a.lastDiffMenuItem,
+		a.prevDiffMenuItem,
+		a.nextDiffMenuItem,
+		a.copyLeftMenuItem,
+		a.copyRightMenuItem,
+	}
+}
+
+// applyKeybindingScopesLocked removes navigation accelerators from the menu
+// while editing and restores them afterwards (must be called with editingMutex held)
+func (a *App) applyKeybindingScopesLocked() {
+	a.menuMutex.Lock()
+	defer a.menuMutex.Unlock()
+	if a.editingPane != "" {
+		if a.suspendedAccelerators == nil {
+			a.suspendedAccelerators = make(map[*menu.MenuItem]*keys.Accelerator)
+		}
+		for _, item := range a.navigationMenuItems() {
+			if item == nil || item.Accelerator == nil {
+				continue
+			}
+			a.suspendedAccelerators[item] = item.Accelerator
+			item.Accelerator = nil
+		}
+		return
+	}
+
+	for item, accelerator := range a.suspendedAccelerators {
+		item.Accelerator = accelerator
+	}
+	a.suspendedAccelerators = nil
+}
+
+// normalizeKey converts a key description such as "G" or "Shift+F2" to the
+// form used by keybindings
+func normalizeKey(key string) string {
+	key = strings.TrimSpace(key)
+	// A lone uppercase letter is that letter with shift held
+	if runes := []rune(key); len(runes) == 1 && unicode.IsUpper(runes[0]) {
+		return "shift+" + strings.ToLower(key)
+	}
+	return strings.ToLower(key)
+}
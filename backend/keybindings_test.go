@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/menu"
+	"github.com/wailsapp/wails/v2/pkg/menu/keys"
+)
+
+func TestApp_ResolveKeybinding(t *testing.T) {
+	app := NewApp()
+
+	tests := []struct {
+		name     string
+		pane     string
+		key      string
+		expected KeyResolution
+	}{
+		{"navigation key", "", "j", KeyResolution{Action: "next-diff"}},
+		{"uppercase letter", "", "G", KeyResolution{Action: "last-diff"}},
+		{"modifier spelling", "", "Shift+L", KeyResolution{Action: "copy-right"}},
+		{"unbound key", "", "x", KeyResolution{}},
+		{"editing key outside edit mode", "", "Escape", KeyResolution{}},
+		{"navigation key while editing", "left", "j", KeyResolution{Suppressed: true}},
+		{"global key while editing", "left", "F2", KeyResolution{Action: "next-marker"}},
+		{"editing key while editing", "right", "Escape", KeyResolution{Action: "exit-edit"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := app.SetEditingPane(tt.pane); err != nil {
+				t.Fatalf("SetEditingPane returned error: %v", err)
+			}
+			if got := app.ResolveKeybinding(tt.key); got != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, got)
+			}
+		})
+	}
+
+	if err := app.SetEditingPane("middle"); err == nil {
+		t.Error("Expected error for invalid pane")
+	}
+}
+
+func TestApp_SetEditingPane_Accelerators(t *testing.T) {
+	app := NewApp()
+	nextDiff := menu.Text("Next Diff", keys.Key("j"), nil)
+	app.SetNextDiffMenuItem(nextDiff)
+
+	if err := app.SetEditingPane("left"); err != nil {
+		t.Fatalf("SetEditingPane returned error: %v", err)
+	}
+	if nextDiff.Accelerator != nil {
+		t.Error("Expected navigation accelerator to be removed while editing")
+	}
+	if ctx := app.GetEditingContext(); !ctx.Editing || ctx.Pane != "left" {
+		t.Errorf("Expected editing context for left pane, got %+v", ctx)
+	}
+
+	// Switching panes keeps the accelerator suspended
+	if err := app.SetEditingPane("right"); err != nil {
+		t.Fatalf("SetEditingPane returned error: %v", err)
+	}
+	if err := app.SetEditingPane(""); err != nil {
+		t.Fatalf("SetEditingPane returned error: %v", err)
+	}
+	if nextDiff.Accelerator == nil || nextDiff.Accelerator.Key != "j" {
+		t.Errorf("Expected accelerator to be restored, got %+v", nextDiff.Accelerator)
+	}
+}
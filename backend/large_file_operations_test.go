@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_CompareLargeFiles(t *testing.T) {
+	app := NewApp()
+	dir := t.TempDir()
+
+	var lines []string
+	for i := 0; i < 20000; i++ {
+		lines = append(lines, fmt.Sprintf("%d,record-%d,%d", i, i*7, i*i))
+	}
+	original := strings.Join(lines, "\n") + "\n"
+	lines[10000] = "10000,edited,0"
+	modified := strings.Join(lines, "\n") + "\n"
+
+	leftPath := filepath.Join(dir, "left.csv")
+	rightPath := filepath.Join(dir, "right.csv")
+	if err := os.WriteFile(leftPath, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte(modified), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareLargeFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareLargeFiles returned error: %v", err)
+	}
+	if result.LeftSize != int64(len(original)) || result.RightSize != int64(len(modified)) {
+		t.Errorf("Unexpected sizes: %d, %d", result.LeftSize, result.RightSize)
+	}
+
+	var changed []ChunkRegion
+	for _, region := range result.Regions {
+		if region.Status != "same" {
+			changed = append(changed, region)
+		}
+	}
+	if len(changed) != 1 {
+		t.Fatalf("Expected one changed region, got %+v", result.Regions)
+	}
+	if changed[0].LeftStartLine > 10001 || changed[0].LeftEndLine < 10001 {
+		t.Errorf("Expected the changed region to contain line 10001, got %d-%d", changed[0].LeftStartLine, changed[0].LeftEndLine)
+	}
+	if result.ChangedBytes == 0 || result.ChangedBytes >= result.LeftSize/2 {
+		t.Errorf("Expected a small changed byte count, got %d", result.ChangedBytes)
+	}
+
+	if _, err := app.CompareLargeFiles(leftPath, filepath.Join(dir, "missing.csv")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
+
+func TestApp_CompareRange(t *testing.T) {
+	app := NewApp()
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.log")
+	rightPath := filepath.Join(dir, "right.log")
+	if err := os.WriteFile(leftPath, []byte("a\nb\nc\nd\ne\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("a\nb\nx\nc\nd\ne\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	t.Run("windows keep file line numbers", func(t *testing.T) {
+		result, err := app.CompareRange(leftPath, rightPath, 2, 3, 2, 4)
+		if err != nil {
+			t.Fatalf("CompareRange returned error: %v", err)
+		}
+		var added []DiffLine
+		for _, line := range result.Lines {
+			if line.Type == "added" {
+				added = append(added, line)
+			}
+		}
+		if len(added) != 1 || added[0].RightNumber != 3 || added[0].RightLine != "x" {
+			t.Errorf("Expected right line 3 to be added, got %+v", result.Lines)
+		}
+		if first := result.Lines[0]; first.LeftNumber != 2 || first.RightNumber != 2 {
+			t.Errorf("Expected the first line to be line 2 on both sides, got %+v", first)
+		}
+	})
+
+	t.Run("empty window", func(t *testing.T) {
+		result, err := app.CompareRange(leftPath, rightPath, 3, 2, 3, 3)
+		if err != nil {
+			t.Fatalf("CompareRange returned error: %v", err)
+		}
+		if len(result.Lines) != 1 || result.Lines[0].Type != "added" || result.Lines[0].RightNumber != 3 {
+			t.Errorf("Expected a single added line, got %+v", result.Lines)
+		}
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		if _, err := app.CompareRange(leftPath, rightPath, 0, 2, 1, 2); err == nil {
+			t.Error("Expected error for a zero start line")
+		}
+		if _, err := app.CompareRange(leftPath, rightPath, 4, 2, 1, 2); err == nil {
+			t.Error("Expected error for a reversed range")
+		}
+		if _, err := app.CompareRange(leftPath, rightPath, 1, 10, 1, 2); err == nil {
+			t.Error("Expected error for a range past the end of the file")
+		}
+	})
+}
+
+func TestApp_CompareFiles_BeyondLineLimit(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	count := maxDiffLines + 20000
+	var left, right []string
+	for i := 0; i < count; i++ {
+		line := fmt.Sprintf("%d,record-%d,%d", i, i*7, i*i)
+		left = append(left, line)
+		switch {
+		case i == 50000:
+			right = append(right, "50000,edited,0")
+		case i == 90000:
+			// removed from the right file
+		default:
+			right = append(right, line)
+		}
+		if i == 110000 {
+			right = append(right, "inserted record")
+		}
+	}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if !result.Segmented {
+		t.Error("Expected a file over the line limit to be compared a window at a time")
+	}
+	if len(result.Chunks) != 3 {
+		t.Errorf("Expected 3 changes, got %d", len(result.Chunks))
+	}
+	if last := result.Lines[len(result.Lines)-1]; last.Type != "same" || last.LeftNumber != count || last.RightNumber != count {
+		t.Errorf("Expected the files to end in common, got %+v", last)
+	}
+}
+
+func TestApp_SortedAndThreeWay_BeyondLineLimit(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	count := maxDiffLines + 1000
+	var base, left, right []string
+	for i := 0; i < count; i++ {
+		line := fmt.Sprintf("record-%06d", i)
+		base = append(base, line)
+		left = append(left, line)
+		if i == 500 {
+			right = append(right, "record-edited")
+		} else {
+			right = append(right, line)
+		}
+	}
+	// The left file holds the same lines with its first two swapped
+	left[0], left[1] = left[1], left[0]
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	sorted, err := app.CompareFilesSorted(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFilesSorted returned error: %v", err)
+	}
+	// Sorted, the edited line is removed in place and added at the end
+	if !sorted.Segmented || len(sorted.Chunks) != 2 {
+		t.Errorf("Expected only the edit compared a window at a time, got segmented %v with %d changes", sorted.Segmented, len(sorted.Chunks))
+	}
+
+	basePath := filepath.Join(t.TempDir(), "base.txt")
+	if err := os.WriteFile(basePath, []byte(strings.Join(base, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	threeWay, err := app.CompareThreeFiles(basePath, leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareThreeFiles returned error: %v", err)
+	}
+	if threeWay.Conflicts != 0 {
+		t.Errorf("Expected no conflicts, got %d", threeWay.Conflicts)
+	}
+}
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"fmt"
+	"sort"
+
+	"weld/backend/diff"
+)
+
+// Marker kinds shown in the gutter
+const (
+	MarkerBookmark = "bookmark"
+	MarkerTodo     = "todo"
+	MarkerWarning  = "warning"
+)
+
+// markerSearchRadius is how far from its recorded line a marker's content is
+// searched for when edits have shifted it
+const markerSearchRadius = 50
+
+// LineMarker is a gutter marker attached to a line of a file
+type LineMarker struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line"`
+	Kind     string `json:"kind"`
+	Note     string `json:"note"`
+	LineHash string `json:"lineHash"` // hash of the marked line's content
+}
+
+// SetLineMarker adds a marker to a line, replacing any marker already on that line
+func (a *App) SetLineMarker(path string, line int, kind, note string) error {
+	switch kind {
+	case MarkerBookmark, MarkerTodo, MarkerWarning:
+	default:
+		return fmt.Errorf("unknown marker kind: %s", kind)
+	}
+
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if line < 1 || line > len(lines) {
+		return fmt.Errorf("line number %d is out of range", line)
+	}
+
+	marker := LineMarker{
+		Path:     path,
+		Line:     line,
+		Kind:     kind,
+		Note:     note,
+		LineHash: diff.HashLine(lines[line-1]),
+	}
+
+	return a.session.Update(func(s *Session) error {
+		if s.Markers == nil {
+			s.Markers = make(map[string][]LineMarker)
+		}
+		markers := s.Markers[path]
+		replaced := false
+		for i := range markers {
+			if markers[i].Line == line {
+				markers[i] = marker
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			markers = append(markers, marker)
+		}
+		s.Markers[path] = markers
+		return nil
+	})
+}
+
+// ClearMarkers removes markers from a file. An empty kind removes all kinds.
+func (a *App) ClearMarkers(path, kind string) error {
+	return a.session.Update(func(s *Session) error {
+		if kind == "" {
+			delete(s.Markers, path)
+			return nil
+		}
+
+		kept := []LineMarker{}
+		for _, marker := range s.Markers[path] {
+			if marker.Kind != kind {
+				kept = append(kept, marker)
+			}
+		}
+		if len(kept) == 0 {
+			delete(s.Markers, path)
+		} else {
+			s.Markers[path] = kept
+		}
+		return nil
+	})
+}
+
+// ListMarkers returns the markers of a file ordered by line. Markers whose line
+// content has moved because of edits are relocated to the new position.
+func (a *App) ListMarkers(path string) []LineMarker {
+	var markers []LineMarker
+	a.session.View(func(s *Session) {
+		markers = append([]LineMarker{}, s.Markers[path]...)
+	})
+
+	if lines, err := a.ReadFileContentWithCache(path); err == nil {
+		for i := range markers {
+			markers[i].Line = relocateMarker(lines, markers[i])
+		}
+	}
+
+	sort.Slice(markers, func(i, j int) bool {
+		return markers[i].Line < markers[j].Line
+	})
+	return markers
+}
+
+// relocateMarker finds the line nearest the marker's recorded position whose
+// content still matches, falling back to the recorded line
+func relocateMarker(lines []string, marker LineMarker) int {
+	matches := func(line int) bool {
+		return line >= 1 && line <= len(lines) && diff.HashLine(lines[line-1]) == marker.LineHash
+	}
+
+	for offset := 0; offset <= markerSearchRadius; offset++ {
+		if matches(marker.Line - offset) {
+			return marker.Line - offset
+		}
+		if matches(marker.Line + offset) {
+			return marker.Line + offset
+		}
+	}
+	return marker.Line
+}
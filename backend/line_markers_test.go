@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestApp_LineMarkers(t *testing.T) {
+
+	app := NewApp()
+	app.Storage = NewFileStorage(t.TempDir())
+	path := "markers.txt"
+	app.compare.SetEdited(path, []string{"alpha", "beta", "gamma", "delta"})
+
+	t.Run("set and list markers", func(t *testing.T) {
+		if err := app.SetLineMarker(path, 3, MarkerTodo, "check this"); err != nil {
+			t.Fatalf("SetLineMarker returned error: %v", err)
+		}
+		if err := app.SetLineMarker(path, 1, MarkerBookmark, ""); err != nil {
+			t.Fatalf("SetLineMarker returned error: %v", err)
+		}
+
+		markers := app.ListMarkers(path)
+		if len(markers) != 2 {
+			t.Fatalf("Expected 2 markers, got %d", len(markers))
+		}
+		if markers[0].Line != 1 || markers[1].Line != 3 {
+			t.Errorf("Expected markers ordered by line, got %+v", markers)
+		}
+		if markers[1].Note != "check this" {
+			t.Errorf("Expected note to be kept, got %q", markers[1].Note)
+		}
+	})
+
+	t.Run("replaces marker on same line", func(t *testing.T) {
+		if err := app.SetLineMarker(path, 3, MarkerWarning, "careful"); err != nil {
+			t.Fatalf("SetLineMarker returned error: %v", err)
+		}
+		markers := app.ListMarkers(path)
+		if len(markers) != 2 || markers[1].Kind != MarkerWarning {
+			t.Errorf("Expected marker on line 3 to be replaced, got %+v", markers)
+		}
+	})
+
+	t.Run("rejects invalid markers", func(t *testing.T) {
+		if err := app.SetLineMarker(path, 9, MarkerTodo, ""); err == nil {
+			t.Error("Expected error for out of range line")
+		}
+		if err := app.SetLineMarker(path, 1, "sticker", ""); err == nil {
+			t.Error("Expected error for unknown kind")
+		}
+	})
+
+	t.Run("markers follow shifted lines", func(t *testing.T) {
+		if err := app.CopyToFile("", path, 1, "inserted"); err != nil {
+			t.Fatalf("CopyToFile returned error: %v", err)
+		}
+		markers := app.ListMarkers(path)
+		if markers[0].Line != 2 || markers[1].Line != 4 {
+			t.Errorf("Expected markers to move down one line, got %+v", markers)
+		}
+	})
+
+	t.Run("markers persist in the session", func(t *testing.T) {
+		reloaded := NewApp()
+		reloaded.Storage = app.Storage
+		if err := reloaded.session.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if markers := reloaded.ListMarkers(path); len(markers) != 2 {
+			t.Errorf("Expected 2 persisted markers, got %d", len(markers))
+		}
+	})
+
+	t.Run("clear markers by kind", func(t *testing.T) {
+		if err := app.ClearMarkers(path, MarkerWarning); err != nil {
+			t.Fatalf("ClearMarkers returned error: %v", err)
+		}
+		markers := app.ListMarkers(path)
+		if len(markers) != 1 || markers[0].Kind != MarkerBookmark {
+			t.Errorf("Expected only the bookmark to remain, got %+v", markers)
+		}
+
+		if err := app.ClearMarkers(path, ""); err != nil {
+			t.Fatalf("ClearMarkers returned error: %v", err)
+		}
+		if markers := app.ListMarkers(path); len(markers) != 0 {
+			t.Errorf("Expected no markers, got %+v", markers)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_CompareLocalizationFiles(t *testing.T) {
+	app := NewApp()
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "old", "messages_de.properties")
+	rightPath := filepath.Join(dir, "new", "messages_de.properties")
+	for path, content := range map[string]string{
+		leftPath:  "save=Speichern\nopen=Öffnen\n",
+		rightPath: "open=Öffnen\nsave=Sichern\nquit=Beenden\n",
+	} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	report, err := app.CompareLocalizationFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareLocalizationFiles returned error: %v", err)
+	}
+	if report.Format != "properties" || len(report.Entries) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	if entry := report.Entries[0]; entry.Key != "save" || entry.Status != "translation-changed" {
+		t.Errorf("Expected save to have a changed translation, got %+v", entry)
+	}
+	if len(report.Warnings) != 1 {
+		t.Errorf("Expected a warning for quit, got %v", report.Warnings)
+	}
+
+	if _, err := app.CompareLocalizationFiles(leftPath, filepath.Join(dir, "fr.po")); err == nil {
+		t.Error("Expected error for files of different formats")
+	}
+}
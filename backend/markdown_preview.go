@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// MarkdownBlockDiff is imported from the diff package
+type MarkdownBlockDiff = diff.MarkdownBlockDiff
+
+// MarkdownPreview is a rendered comparison of two Markdown files
+type MarkdownPreview struct {
+	LeftHTML  string              `json:"leftHtml"`
+	RightHTML string              `json:"rightHtml"`
+	Blocks    []MarkdownBlockDiff `json:"blocks"` // block-level diff of the rendered documents
+	Diff      *DiffResult         `json:"diff"`   // raw text diff of the sources
+}
+
+// CompareMarkdownPreview renders two Markdown files to sanitized HTML and
+// diffs them block by block, so changed paragraphs can be reviewed as they
+// read rather than as wrapped source lines. The raw text diff is included.
+func (a *App) CompareMarkdownPreview(leftPath, rightPath string) (*MarkdownPreview, error) {
+	if leftPath == "" || rightPath == "" {
+		return nil, fmt.Errorf("file paths cannot be empty")
+	}
+	if diff.LanguageForPath(leftPath) != diff.LanguageMarkdown || diff.LanguageForPath(rightPath) != diff.LanguageMarkdown {
+		return nil, fmt.Errorf("markdown preview requires two Markdown files")
+	}
+
+	options := a.resolveCompareOptions(a.comparisonOptions(leftPath, rightPath))
+	result, err := a.computeDiff(leftPath, rightPath, options)
+	if err != nil {
+		return nil, err
+	}
+
+	leftLines, err := a.ReadFileContentWithCache(leftPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading left file: %w", err)
+	}
+	rightLines, err := a.ReadFileContentWithCache(rightPath)
+	if err != nil {
+		return nil, fmt.Errorf("error reading right file: %w", err)
+	}
+
+	return &MarkdownPreview{
+		LeftHTML:  diff.RenderMarkdown(leftLines),
+		RightHTML: diff.RenderMarkdown(rightLines),
+		Blocks:    diff.DiffMarkdown(leftLines, rightLines, a.algorithmFor(options)),
+		Diff:      result,
+	}, nil
+}
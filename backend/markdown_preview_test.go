@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_CompareMarkdownPreview(t *testing.T) {
+	app := NewApp()
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.md")
+	rightPath := filepath.Join(dir, "right.md")
+	if err := os.WriteFile(leftPath, []byte("# Notes\n\nFirst draft of the text.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("# Notes\n\nSecond draft of the text.\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	preview, err := app.CompareMarkdownPreview(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareMarkdownPreview returned error: %v", err)
+	}
+	if preview.LeftHTML != "<h1>Notes</h1>\n<p>First draft of the text.</p>" {
+		t.Errorf("Unexpected left HTML: %q", preview.LeftHTML)
+	}
+	if len(preview.Blocks) != 2 || preview.Blocks[1].Type != "modified" {
+		t.Errorf("Expected the paragraph to be modified, got %+v", preview.Blocks)
+	}
+	if preview.Diff == nil || len(preview.Diff.Lines) == 0 {
+		t.Error("Expected the raw text diff to be included")
+	}
+
+	if _, err := app.CompareMarkdownPreview(leftPath, filepath.Join(dir, "other.txt")); err == nil {
+		t.Error("Expected an error for a non-Markdown file")
+	}
+}
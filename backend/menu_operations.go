@@ -2,86 +2,105 @@ package backend
 
 import (
 	"github.com/wailsapp/wails/v2/pkg/menu"
-	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// setMenuItem stores a reference to a menu item the App updates
+func (a *App) setMenuItem(field **menu.MenuItem, item *menu.MenuItem) {
+	a.menuMutex.Lock()
+	*field = item
+	a.menuMutex.Unlock()
+}
+
+// refreshMenu redraws the application menu after menu items changed. It is
+// called without menuMutex held, since redrawing reads every item.
+func (a *App) refreshMenu() {
+	a.runtime().MenuUpdateApplicationMenu()
+}
+
 // SetMinimapMenuItem stores a reference to the minimap menu item
 func (a *App) SetMinimapMenuItem(item *menu.MenuItem) {
-	a.minimapMenuItem = item
+	a.setMenuItem(&a.minimapMenuItem, item)
 }
 
 // SetMinimapVisible sets the minimap visibility state
 func (a *App) SetMinimapVisible(visible bool) {
+	a.menuMutex.Lock()
 	a.minimapVisible = visible
 	// Update the menu checkmark
-	if a.minimapMenuItem != nil {
+	hasItem := a.minimapMenuItem != nil
+	if hasItem {
 		a.minimapMenuItem.Checked = visible
-		runtime.MenuUpdateApplicationMenu(a.ctx)
+	}
+	a.menuMutex.Unlock()
+
+	if hasItem {
+		a.refreshMenu()
 	}
 }
 
 // SetUndoMenuItem stores a reference to the undo menu item
 func (a *App) SetUndoMenuItem(item *menu.MenuItem) {
-	a.undoMenuItem = item
+	a.setMenuItem(&a.undoMenuItem, item)
 }
 
 // SetRedoMenuItem stores a reference to the redo menu item
 func (a *App) SetRedoMenuItem(item *menu.MenuItem) {
-	a.redoMenuItem = item
+	a.setMenuItem(&a.redoMenuItem, item)
 }
 
 // SetDiscardMenuItem stores a reference to the discard menu item
 func (a *App) SetDiscardMenuItem(item *menu.MenuItem) {
-	a.discardMenuItem = item
+	a.setMenuItem(&a.discardMenuItem, item)
 }
 
 // SetSaveLeftMenuItem stores a reference to the save left menu item
 func (a *App) SetSaveLeftMenuItem(item *menu.MenuItem) {
-	a.saveLeftMenuItem = item
+	a.setMenuItem(&a.saveLeftMenuItem, item)
 }
 
 // SetSaveRightMenuItem stores a reference to the save right menu item
 func (a *App) SetSaveRightMenuItem(item *menu.MenuItem) {
-	a.saveRightMenuItem = item
+	a.setMenuItem(&a.saveRightMenuItem, item)
 }
 
 // SetSaveAllMenuItem stores a reference to the save all menu item
 func (a *App) SetSaveAllMenuItem(item *menu.MenuItem) {
-	a.saveAllMenuItem = item
+	a.setMenuItem(&a.saveAllMenuItem, item)
 }
 
 // SetFirstDiffMenuItem stores a reference to the first diff menu item
 func (a *App) SetFirstDiffMenuItem(item *menu.MenuItem) {
-	a.firstDiffMenuItem = item
+	a.setMenuItem(&a.firstDiffMenuItem, item)
 }
 
 // SetLastDiffMenuItem stores a reference to the last diff menu item
 func (a *App) SetLastDiffMenuItem(item *menu.MenuItem) {
-	a.lastDiffMenuItem = item
+	a.setMenuItem(&a.lastDiffMenuItem, item)
 }
 
 // SetPrevDiffMenuItem stores a reference to the previous diff menu item
 func (a *App) SetPrevDiffMenuItem(item *menu.MenuItem) {
-	a.prevDiffMenuItem = item
+	a.setMenuItem(&a.prevDiffMenuItem, item)
 }
 
 // SetNextDiffMenuItem stores a reference to the next diff menu item
 func (a *App) SetNextDiffMenuItem(item *menu.MenuItem) {
-	a.nextDiffMenuItem = item
+	a.setMenuItem(&a.nextDiffMenuItem, item)
 }
 
 // SetCopyLeftMenuItem stores a reference to the copy left menu item
 func (a *App) SetCopyLeftMenuItem(item *menu.MenuItem) {
-	a.copyLeftMenuItem = item
+	a.setMenuItem(&a.copyLeftMenuItem, item)
 }
 
 // SetCopyRightMenuItem stores a reference to the copy right menu item
 func (a *App) SetCopyRightMenuItem(item *menu.MenuItem) {
-	a.copyRightMenuItem = item
+	a.setMenuItem(&a.copyRightMenuItem, item)
 }
 
 // UpdateSaveMenuItems updates the state of all save-related menu items
 func (a *App) UpdateSaveMenuItems(hasUnsavedLeft, hasUnsavedRight bool) {
+	a.menuMutex.Lock()
 	// Update individual save items
 	if a.saveLeftMenuItem != nil {
 		a.saveLeftMenuItem.Disabled = !hasUnsavedLeft
@@ -99,12 +118,14 @@ func (a *App) UpdateSaveMenuItems(hasUnsavedLeft, hasUnsavedRight bool) {
 	if a.discardMenuItem != nil {
 		a.discardMenuItem.Disabled = !hasUnsavedLeft && !hasUnsavedRight
 	}
+	a.menuMutex.Unlock()
 
-	runtime.MenuUpdateApplicationMenu(a.ctx)
+	a.refreshMenu()
 }
 
 // UpdateDiffNavigationMenuItems updates the state of the diff navigation menu items
 func (a *App) UpdateDiffNavigationMenuItems(hasPrevDiff, hasNextDiff, hasFirstDiff, hasLastDiff bool) {
+	a.menuMutex.Lock()
 	if a.firstDiffMenuItem != nil {
 		a.firstDiffMenuItem.Disabled = !hasFirstDiff
 	}
@@ -117,7 +138,9 @@ func (a *App) UpdateDiffNavigationMenuItems(hasPrevDiff, hasNextDiff, hasFirstDi
 	if a.nextDiffMenuItem != nil {
 		a.nextDiffMenuItem.Disabled = !hasNextDiff
 	}
-	runtime.MenuUpdateApplicationMenu(a.ctx)
+	a.menuMutex.Unlock()
+
+	a.refreshMenu()
 }
 
 // UpdateCopyMenuItems updates the state of the copy menu items based on whether a diff is selected
@@ -126,6 +149,7 @@ func (a *App) UpdateCopyMenuItems(currentDiffType string) {
 	// Both panes are equal - users can copy in either direction for any diff
 	hasDiff := currentDiffType != ""
 
+	a.menuMutex.Lock()
 	if a.copyLeftMenuItem != nil {
 		a.copyLeftMenuItem.Disabled = !hasDiff
 	}
@@ -133,6 +157,7 @@ func (a *App) UpdateCopyMenuItems(currentDiffType string) {
 	if a.copyRightMenuItem != nil {
 		a.copyRightMenuItem.Disabled = !hasDiff
 	}
+	a.menuMutex.Unlock()
 
-	runtime.MenuUpdateApplicationMenu(a.ctx)
+	a.refreshMenu()
 }
@@ -0,0 +1,177 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Origins of the changes made during a merge session
+const (
+	mergeOriginLeft   = "left"
+	mergeOriginRight  = "right"
+	mergeOriginManual = "manual"
+)
+
+// MergeSummary describes the changes made between comparing two files and
+// saving the merged result
+type MergeSummary struct {
+	LeftFile       string    `json:"leftFile"`
+	RightFile      string    `json:"rightFile"`
+	StartedAt      time.Time `json:"startedAt"`
+	SavedAt        time.Time `json:"savedAt"`
+	DurationMs     int64     `json:"durationMs"`
+	HunksFromLeft  int       `json:"hunksFromLeft"`  // changes copied from the left file into the right
+	HunksFromRight int       `json:"hunksFromRight"` // changes copied from the right file into the left
+	ManualChanges  int       `json:"manualChanges"`  // changes not copied from the other side
+	LinesAdded     int       `json:"linesAdded"`
+	LinesRemoved   int       `json:"linesRemoved"`
+}
+
+// mergeSession tracks the operation groups applied to the compared pair since
+// it was compared or last saved
+type mergeSession struct {
+	leftPath  string
+	rightPath string
+	startedAt time.Time
+	summary   MergeSummary // counts of the applied groups
+}
+
+// startMergeSession begins tracking changes to a newly compared pair
+func (a *App) startMergeSession(leftPath, rightPath string) {
+	a.mergeMutex.Lock()
+	defer a.mergeMutex.Unlock()
+	a.merge = &mergeSession{leftPath: leftPath, rightPath: rightPath, startedAt: time.Now()}
+}
+
+// trackMergeGroup adds an applied operation group to the merge session, or
+// subtracts it again when delta is -1 because the group was undone
+func (a *App) trackMergeGroup(group OperationGroup, delta int) {
+	a.mergeMutex.Lock()
+	defer a.mergeMutex.Unlock()
+
+	session := a.merge
+	// Groups from before the session started were summarized already
+	if session == nil || group.Timestamp.Before(session.startedAt) {
+		return
+	}
+
+	origin := mergeOriginManual
+	relevant := false
+	added, removed := 0, 0
+	for _, op := range group.Operations {
+		if op.TargetFile != session.leftPath && op.TargetFile != session.rightPath {
+			continue
+		}
+		relevant = true
+		switch op.Type {
+		case OpCopy:
+			added++
+			if op.SourceFile == session.leftPath && op.TargetFile == session.rightPath {
+				origin = mergeOriginLeft
+			} else if op.SourceFile == session.rightPath && op.TargetFile == session.leftPath {
+				origin = mergeOriginRight
+			}
+		case OpRemove:
+			removed++
+		}
+	}
+	if !relevant {
+		return
+	}
+
+	switch origin {
+	case mergeOriginLeft:
+		session.summary.HunksFromLeft += delta
+	case mergeOriginRight:
+		session.summary.HunksFromRight += delta
+	default:
+		session.summary.ManualChanges += delta
+	}
+	session.summary.LinesAdded += added * delta
+	session.summary.LinesRemoved += removed * delta
+}
+
+// finishMergeSessionIfSaved completes the merge session once neither file of
+// the pair has unsaved changes, recording its summary and starting a new session
+func (a *App) finishMergeSessionIfSaved() {
+	a.mergeMutex.Lock()
+	session := a.merge
+	if session == nil || a.HasUnsavedChanges(session.leftPath) || a.HasUnsavedChanges(session.rightPath) {
+		a.mergeMutex.Unlock()
+		return
+	}
+	summary := session.summary
+	if summary.HunksFromLeft+summary.HunksFromRight+summary.ManualChanges == 0 {
+		a.mergeMutex.Unlock()
+		return
+	}
+
+	now := time.Now()
+	summary.LeftFile = session.leftPath
+	summary.RightFile = session.rightPath
+	summary.StartedAt = session.startedAt
+	summary.SavedAt = now
+	summary.DurationMs = now.Sub(session.startedAt).Milliseconds()
+	a.lastMergeSummary = &summary
+	a.merge = &mergeSession{leftPath: session.leftPath, rightPath: session.rightPath, startedAt: now}
+	a.mergeMutex.Unlock()
+
+	if err := a.appendMergeLog(summary); err != nil {
+		a.runtime().LogErrorf("Failed to write merge log: %v", err)
+	}
+	a.runtime().EventsEmit("merge-summary", summary)
+}
+
+// appendMergeLog appends a summary as one JSON line to the configured merge
+// log file, if any
+func (a *App) appendMergeLog(summary MergeSummary) error {
+	path := a.GetMergeLogPath()
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(summary)
+	if err != nil {
+		return fmt.Errorf("failed to encode merge summary: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create merge log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open merge log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write merge log: %w", err)
+	}
+	return nil
+}
+
+// GetMergeSummary returns the summary of the most recently saved merge
+// session, or nil if no merge has been saved yet
+func (a *App) GetMergeSummary() *MergeSummary {
+	a.mergeMutex.Lock()
+	defer a.mergeMutex.Unlock()
+	if a.lastMergeSummary == nil {
+		return nil
+	}
+	summary := *a.lastMergeSummary
+	return &summary
+}
+
+// GetMergeLogPath returns the file merge summaries are appended to, or "" if disabled
+func (a *App) GetMergeLogPath() string {
+	return a.settings.Get().MergeLogPath
+}
+
+// SetMergeLogPath sets the file merge summaries are appended to; "" disables the log
+func (a *App) SetMergeLogPath(path string) error {
+	return a.settings.Update(func(s *Settings) error {
+		s.MergeLogPath = path
+		return nil
+	})
+}
@@ -0,0 +1,93 @@
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_MergeSummary(t *testing.T) {
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+	logPath := filepath.Join(t.TempDir(), "audit", "merges.log")
+	if err := app.SetMergeLogPath(logPath); err != nil {
+		t.Fatalf("SetMergeLogPath returned error: %v", err)
+	}
+
+	leftPath, rightPath := compareTempFiles(t, app,
+		[]string{"a", "b", "c"},
+		[]string{"a", "x", "c", "d"})
+
+	if app.GetMergeSummary() != nil {
+		t.Fatal("Expected no summary before saving")
+	}
+
+	// Take line b from the left into the right
+	app.BeginOperationGroup("Copy to right")
+	if err := app.CopyToFile(leftPath, rightPath, 2, "b"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	app.CommitOperationGroup()
+
+	// Take line d from the right into the left
+	if err := app.CopyToFile(rightPath, leftPath, 4, "d"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+
+	// A manual removal that is then undone does not count
+	if err := app.RemoveLineFromFile(rightPath, 3); err != nil {
+		t.Fatalf("RemoveLineFromFile returned error: %v", err)
+	}
+	if err := app.UndoLastOperation(); err != nil {
+		t.Fatalf("UndoLastOperation returned error: %v", err)
+	}
+
+	// Removing the stale line on the right is a manual change
+	if err := app.RemoveLineFromFile(rightPath, 3); err != nil {
+		t.Fatalf("RemoveLineFromFile returned error: %v", err)
+	}
+
+	// Saving only one side does not finish the session
+	if err := app.SaveChanges(leftPath); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+	if app.GetMergeSummary() != nil {
+		t.Fatal("Expected no summary until both sides are saved")
+	}
+
+	if err := app.SaveChanges(rightPath); err != nil {
+		t.Fatalf("SaveChanges returned error: %v", err)
+	}
+	summary := app.GetMergeSummary()
+	if summary == nil {
+		t.Fatal("Expected a summary after the final save")
+	}
+	if summary.HunksFromLeft != 1 || summary.HunksFromRight != 1 || summary.ManualChanges != 1 {
+		t.Errorf("Unexpected hunk counts: %+v", summary)
+	}
+	if summary.LinesAdded != 2 || summary.LinesRemoved != 1 {
+		t.Errorf("Unexpected line counts: %+v", summary)
+	}
+	if summary.LeftFile != leftPath || summary.RightFile != rightPath || summary.DurationMs < 0 {
+		t.Errorf("Unexpected summary details: %+v", summary)
+	}
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatalf("Failed to read merge log: %v", err)
+	}
+	entries := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 log entry, got %d", len(entries))
+	}
+	var logged MergeSummary
+	if err := json.Unmarshal([]byte(entries[0]), &logged); err != nil {
+		t.Fatalf("Failed to parse log entry: %v", err)
+	}
+	if logged.HunksFromLeft != 1 {
+		t.Errorf("Expected logged summary to match, got %+v", logged)
+	}
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"fmt"
+)
+
+// maxMinimapBuckets is the most buckets GetMinimapData will aggregate into
+const maxMinimapBuckets = 10000
+
+// MinimapBucket describes a run of displayed lines by the share of them
+// that are of each change type, from 0 to 1
+type MinimapBucket struct {
+	Start    int     `json:"start"` // index of the first line in the bucket
+	Count    int     `json:"count"`
+	Added    float64 `json:"added"`
+	Removed  float64 `json:"removed"`
+	Modified float64 `json:"modified"`
+}
+
+// MinimapData is the current comparison aggregated for the minimap
+type MinimapData struct {
+	Total   int             `json:"total"` // number of lines as displayed
+	Buckets []MinimapBucket `json:"buckets"`
+}
+
+// GetMinimapData aggregates the current comparison as displayed into at most
+// the given number of equal runs of lines, so the minimap can be drawn
+// without fetching every line. Line indexes match those of GetDiffPage.
+func (a *App) GetMinimapData(buckets int) (*MinimapData, error) {
+	if buckets <= 0 {
+		return nil, fmt.Errorf("bucket count must be positive")
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	lines := a.displayResult(current.Result).Lines
+
+	return &MinimapData{
+		Total:   len(lines),
+		Buckets: minimapBuckets(lines, min(buckets, maxMinimapBuckets)),
+	}, nil
+}
+
+// minimapBuckets splits lines into n runs of as near equal length as
+// possible, or one per line when there are fewer lines than runs
+func minimapBuckets(lines []DiffLine, n int) []MinimapBucket {
+	n = min(n, len(lines))
+	buckets := make([]MinimapBucket, n)
+	for i := range buckets {
+		start, end := i*len(lines)/n, (i+1)*len(lines)/n
+		bucket := MinimapBucket{Start: start, Count: end - start}
+		var added, removed, modified int
+		for _, line := range lines[start:end] {
+			switch line.Type {
+			case "added":
+				added++
+			case "removed":
+				removed++
+			case "modified":
+				modified++
+			}
+		}
+		count := float64(bucket.Count)
+		bucket.Added = float64(added) / count
+		bucket.Removed = float64(removed) / count
+		bucket.Modified = float64(modified) / count
+		buckets[i] = bucket
+	}
+	return buckets
+}
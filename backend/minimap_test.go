@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestApp_GetMinimapData(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	if _, err := app.GetMinimapData(10); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+
+	var left, right []string
+	for i := 1; i <= 10; i++ {
+		left = append(left, fmt.Sprintf("line %d", i))
+	}
+	right = append(right, left...)
+	for i := 1; i <= 10; i++ {
+		right = append(right, fmt.Sprintf("appended %d", i))
+	}
+	compareTempFiles(t, app, left, right)
+
+	t.Run("buckets", func(t *testing.T) {
+		data, err := app.GetMinimapData(4)
+		if err != nil {
+			t.Fatalf("GetMinimapData returned error: %v", err)
+		}
+		if data.Total != 20 || len(data.Buckets) != 4 {
+			t.Fatalf("Expected 4 buckets of 20 lines, got %+v", data)
+		}
+		for i, bucket := range data.Buckets {
+			expected := 0.0
+			if i >= 2 {
+				expected = 1.0
+			}
+			if bucket.Start != i*5 || bucket.Count != 5 || bucket.Added != expected || bucket.Removed != 0 {
+				t.Errorf("Bucket %d: expected 5 lines from %d, %v added, got %+v", i, i*5, expected, bucket)
+			}
+		}
+	})
+
+	t.Run("more buckets than lines", func(t *testing.T) {
+		data, err := app.GetMinimapData(100)
+		if err != nil {
+			t.Fatalf("GetMinimapData returned error: %v", err)
+		}
+		if len(data.Buckets) != 20 || data.Buckets[19].Count != 1 {
+			t.Errorf("Expected one bucket per line, got %+v", data.Buckets)
+		}
+	})
+
+	t.Run("uneven buckets cover every line", func(t *testing.T) {
+		data, err := app.GetMinimapData(3)
+		if err != nil {
+			t.Fatalf("GetMinimapData returned error: %v", err)
+		}
+		next := 0
+		for _, bucket := range data.Buckets {
+			if bucket.Start != next {
+				t.Errorf("Expected a bucket starting at %d, got %+v", next, bucket)
+			}
+			next += bucket.Count
+		}
+		if next != 20 {
+			t.Errorf("Expected the buckets to cover 20 lines, got %d", next)
+		}
+	})
+
+	t.Run("invalid bucket count", func(t *testing.T) {
+		if _, err := app.GetMinimapData(0); err == nil {
+			t.Error("Expected error for zero buckets")
+		}
+	})
+}
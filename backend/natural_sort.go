@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/text/collate"
+	"golang.org/x/text/language"
+)
+
+// Orders for sorting file names and paths
+const (
+	// SortNatural compares names the way the user's locale orders words,
+	// ignoring case and comparing runs of digits by value, so file2 comes
+	// before file10
+	SortNatural = "natural"
+	// SortByteOrder compares names byte by byte
+	SortByteOrder = "byte"
+)
+
+// isSortOrder returns whether order is a known name sort order
+func isSortOrder(order string) bool {
+	return order == SortNatural || order == SortByteOrder
+}
+
+// systemLocale returns the collation locale from the environment, following
+// the POSIX precedence of LC_ALL, LC_COLLATE, then LANG
+func systemLocale() language.Tag {
+	for _, variable := range []string{"LC_ALL", "LC_COLLATE", "LANG"} {
+		value := os.Getenv(variable)
+		if value == "" {
+			continue
+		}
+		// Drop the encoding and modifier, as in de_DE.UTF-8@euro
+		value, _, _ = strings.Cut(value, ".")
+		value, _, _ = strings.Cut(value, "@")
+		if value == "C" || value == "POSIX" {
+			return language.Und
+		}
+		if tag, err := language.Parse(strings.ReplaceAll(value, "_", "-")); err == nil {
+			return tag
+		}
+		return language.Und
+	}
+	return language.Und
+}
+
+// nameComparer returns a function comparing names in the given order. Names
+// the locale considers equal, such as ones differing only in case, fall back
+// to byte order so sorting is deterministic.
+func nameComparer(order string) func(a, b string) int {
+	if order == SortByteOrder {
+		return strings.Compare
+	}
+	collator := collate.New(systemLocale(), collate.IgnoreCase, collate.Numeric)
+	return func(a, b string) int {
+		if result := collator.CompareString(a, b); result != 0 {
+			return result
+		}
+		return strings.Compare(a, b)
+	}
+}
+
+// pathComparer returns a function comparing slash-separated paths one
+// segment at a time, so a directory's contents sort together
+func pathComparer(order string) func(a, b string) int {
+	compareNames := nameComparer(order)
+	return func(a, b string) int {
+		aSegments, bSegments := strings.Split(a, "/"), strings.Split(b, "/")
+		for i := 0; i < len(aSegments) && i < len(bSegments); i++ {
+			if result := compareNames(aSegments[i], bSegments[i]); result != 0 {
+				return result
+			}
+		}
+		return len(aSegments) - len(bSegments)
+	}
+}
+
+// GetSortOrder returns how file names are ordered in directory listings,
+// "natural" or "byte"
+func (a *App) GetSortOrder() string {
+	return a.settings.Get().SortOrder
+}
+
+// SetSortOrder sets how file names are ordered in directory listings and
+// directory comparisons
+func (a *App) SetSortOrder(order string) error {
+	if !isSortOrder(order) {
+		return fmt.Errorf("unknown sort order: %s", order)
+	}
+	return a.settings.Update(func(s *Settings) error {
+		s.SortOrder = order
+		return nil
+	})
+}
@@ -0,0 +1,72 @@
+package backend
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestNameComparer(t *testing.T) {
+	t.Setenv("LC_ALL", "en_US.UTF-8")
+	names := []string{"file10.txt", "File2.txt", "file1.txt", "beta", "Alpha"}
+
+	natural := append([]string{}, names...)
+	compare := nameComparer(SortNatural)
+	sort.Slice(natural, func(i, j int) bool { return compare(natural[i], natural[j]) < 0 })
+	if want := []string{"Alpha", "beta", "file1.txt", "File2.txt", "file10.txt"}; !reflect.DeepEqual(natural, want) {
+		t.Errorf("Expected natural order %v, got %v", want, natural)
+	}
+
+	byteOrder := append([]string{}, names...)
+	compare = nameComparer(SortByteOrder)
+	sort.Slice(byteOrder, func(i, j int) bool { return compare(byteOrder[i], byteOrder[j]) < 0 })
+	if want := []string{"Alpha", "File2.txt", "beta", "file1.txt", "file10.txt"}; !reflect.DeepEqual(byteOrder, want) {
+		t.Errorf("Expected byte order %v, got %v", want, byteOrder)
+	}
+
+	paths := []string{"b.txt", "dir10/a.txt", "dir2/z.txt", "dir2/a.txt"}
+	comparePaths := pathComparer(SortNatural)
+	sort.Slice(paths, func(i, j int) bool { return comparePaths(paths[i], paths[j]) < 0 })
+	if want := []string{"b.txt", "dir2/a.txt", "dir2/z.txt", "dir10/a.txt"}; !reflect.DeepEqual(paths, want) {
+		t.Errorf("Expected paths %v, got %v", want, paths)
+	}
+}
+
+func TestSystemLocale(t *testing.T) {
+	tests := []struct {
+		lcAll, lang string
+		want        language.Tag
+	}{
+		{"", "de_DE.UTF-8", language.MustParse("de-DE")},
+		{"sv_SE@euro", "de_DE.UTF-8", language.MustParse("sv-SE")},
+		{"C", "de_DE.UTF-8", language.Und},
+		{"", "", language.Und},
+	}
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", tt.lcAll)
+		t.Setenv("LC_COLLATE", "")
+		t.Setenv("LANG", tt.lang)
+		if got := systemLocale(); got != tt.want {
+			t.Errorf("systemLocale() with LC_ALL=%q LANG=%q = %v, want %v", tt.lcAll, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestApp_SetSortOrder(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	if app.GetSortOrder() != SortNatural {
+		t.Errorf("Expected natural sorting by default, got %q", app.GetSortOrder())
+	}
+	if err := app.SetSortOrder(SortByteOrder); err != nil {
+		t.Fatalf("SetSortOrder returned error: %v", err)
+	}
+	if app.GetSortOrder() != SortByteOrder {
+		t.Errorf("Expected byte order, got %q", app.GetSortOrder())
+	}
+	if err := app.SetSortOrder("random"); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+}
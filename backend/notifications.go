@@ -0,0 +1,98 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	goruntime "runtime"
+	"time"
+)
+
+// longTaskThreshold is how long a task must run before its completion is
+// worth a notification
+const longTaskThreshold = 10 * time.Second
+
+// errNotificationsUnsupported is returned when the OS has no notification command
+var errNotificationsUnsupported = errors.New("notifications are not supported on this platform")
+
+// Notifier shows a notification outside the app window
+type Notifier interface {
+	Notify(title, message string) error
+}
+
+// systemNotifier shows notifications with the platform's command-line tools
+type systemNotifier struct{}
+
+// Notify shows a native notification
+func (systemNotifier) Notify(title, message string) error {
+	var cmd *exec.Cmd
+	switch goruntime.GOOS {
+	case "darwin":
+		// Pass text as arguments so it never needs escaping into the script
+		cmd = exec.Command("osascript",
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, message)
+	case "linux":
+		cmd = exec.Command("notify-send", "--app-name=Weld", title, message)
+	default:
+		return errNotificationsUnsupported
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to show notification: %w", err)
+	}
+	return nil
+}
+
+// notify shows a notification if they are enabled, falling back to a
+// "notification" event the frontend can display when the OS cannot show one
+func (a *App) notify(title, message string) {
+	if !a.GetNotificationsEnabled() {
+		return
+	}
+
+	var err error
+	if a.Notifier != nil {
+		err = a.Notifier.Notify(title, message)
+	} else {
+		err = errNotificationsUnsupported
+	}
+	if err != nil {
+		a.runtime().EventsEmit("notification", map[string]string{
+			"title":   title,
+			"message": message,
+		})
+	}
+}
+
+// notifyTaskDone notifies that a background task finished if it ran long
+// enough that the user has likely switched to something else
+func (a *App) notifyTaskDone(started time.Time, title, message string) {
+	if time.Since(started) < longTaskThreshold && a.windowFocused.Load() {
+		return
+	}
+	a.notify(title, message)
+}
+
+// SetWindowFocused records whether the app window has focus. The frontend
+// reports focus changes so notifications are only shown when they are useful.
+func (a *App) SetWindowFocused(focused bool) {
+	a.windowFocused.Store(focused)
+	if focused {
+		a.RecordActivity()
+	}
+}
+
+// GetNotificationsEnabled returns whether notifications are shown
+func (a *App) GetNotificationsEnabled() bool {
+	return a.settings.Get().NotificationsEnabled
+}
+
+// SetNotificationsEnabled sets whether notifications are shown
+func (a *App) SetNotificationsEnabled(enabled bool) error {
+	return a.settings.Update(func(s *Settings) error {
+		s.NotificationsEnabled = enabled
+		return nil
+	})
+}
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// recordingNotifier records notifications instead of showing them
+type recordingNotifier struct {
+	titles []string
+	err    error
+}
+
+func (n *recordingNotifier) Notify(title, message string) error {
+	n.titles = append(n.titles, title)
+	return n.err
+}
+
+func TestApp_NotifyTaskDone(t *testing.T) {
+	notifier := &recordingNotifier{}
+	app := NewApp()
+	app.Notifier = notifier
+
+	t.Run("short task while focused", func(t *testing.T) {
+		app.notifyTaskDone(time.Now(), "Done", "quick")
+		if len(notifier.titles) != 0 {
+			t.Errorf("Expected no notification, got %v", notifier.titles)
+		}
+	})
+
+	t.Run("long task", func(t *testing.T) {
+		app.notifyTaskDone(time.Now().Add(-2*longTaskThreshold), "Long", "slow")
+		if len(notifier.titles) != 1 || notifier.titles[0] != "Long" {
+			t.Errorf("Expected a notification, got %v", notifier.titles)
+		}
+	})
+
+	t.Run("short task while unfocused", func(t *testing.T) {
+		app.SetWindowFocused(false)
+		defer app.SetWindowFocused(true)
+		app.notifyTaskDone(time.Now(), "Background", "quick")
+		if len(notifier.titles) != 2 {
+			t.Errorf("Expected a notification, got %v", notifier.titles)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		if err := app.SetNotificationsEnabled(false); err != nil {
+			t.Fatalf("SetNotificationsEnabled returned error: %v", err)
+		}
+		defer app.SetNotificationsEnabled(true)
+		app.notifyTaskDone(time.Now().Add(-2*longTaskThreshold), "Muted", "slow")
+		if len(notifier.titles) != 2 {
+			t.Errorf("Expected no notification when disabled, got %v", notifier.titles)
+		}
+	})
+
+	t.Run("notifier failure without a window", func(t *testing.T) {
+		notifier.err = errors.New("no notification daemon")
+		app.notify("Failing", "message")
+		if len(notifier.titles) != 3 {
+			t.Errorf("Expected the notifier to be tried, got %v", notifier.titles)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+)
+
+// OperationRequest describes one line operation in a batch submitted by the frontend
+type OperationRequest struct {
+	Type        string `json:"type"` // "copy", "remove", or "update"
+	SourceFile  string `json:"sourceFile"`
+	TargetFile  string `json:"targetFile"`
+	LineNumber  int    `json:"lineNumber"`
+	LineContent string `json:"lineContent"`
+}
+
+// WithOperationGroup executes a list of line operations as a single undoable
+// group in one call. If any operation fails, the operations already applied
+// are rolled back and nothing is added to the undo history.
+func (a *App) WithOperationGroup(description string, ops []OperationRequest) error {
+	if len(ops) == 0 {
+		return fmt.Errorf("no operations to apply")
+	}
+
+	// Validate everything up front so bad requests never touch the cache
+	for i, op := range ops {
+		switch op.Type {
+		case "copy", "remove", "update":
+		default:
+			return fmt.Errorf("operation %d: unknown operation type %q", i+1, op.Type)
+		}
+		if op.TargetFile == "" {
+			return fmt.Errorf("operation %d: target file is required", i+1)
+		}
+	}
+
+	started := time.Now()
+	err := a.applyOperationGroup(description, func() error {
+		for i, op := range ops {
+			if err := a.applyOperationRequestLocked(op); err != nil {
+				return fmt.Errorf("operation %d (%s) failed: %w", i+1, op.Type, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.notifyTaskDone(started, "Batch complete", fmt.Sprintf("%s: %d operations applied", description, len(ops)))
+	return nil
+}
+
+// applyOperationGroup runs apply as one undoable operation group, rolling
+// the group back when apply fails. operationMutex is held throughout, so
+// operations from other calls cannot land in the group and be rolled back
+// with it.
+func (a *App) applyOperationGroup(description string, apply func() error) error {
+	a.operationMutex.Lock()
+	defer a.operationMutex.Unlock()
+
+	a.beginOperationGroupLocked(description)
+	if err := apply(); err != nil {
+		a.rollbackOperationGroupLocked()
+		return err
+	}
+	a.commitOperationGroupLocked()
+	return nil
+}
+
+// applyOperationRequestLocked performs a single requested operation (must
+// be called with operationMutex held)
+func (a *App) applyOperationRequestLocked(op OperationRequest) error {
+	switch op.Type {
+	case "copy":
+		return a.copyToFileLocked(op.SourceFile, op.TargetFile, op.LineNumber, op.LineContent)
+	case "remove":
+		return a.removeLineFromFileLocked(op.TargetFile, op.LineNumber)
+	case "update":
+		// Replace the line in place: remove the old content, insert the new
+		if err := a.removeLineFromFileLocked(op.TargetFile, op.LineNumber); err != nil {
+			return err
+		}
+		return a.copyToFileLocked(op.SourceFile, op.TargetFile, op.LineNumber, op.LineContent)
+	}
+	return fmt.Errorf("unknown operation type %q", op.Type)
+}
@@ -0,0 +1,134 @@
+package backend
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestApp_WithOperationGroup(t *testing.T) {
+	app := NewApp()
+
+	reset := func() {
+		clearHistory(app)
+		app.compare.DiscardAll()
+	}
+
+	t.Run("applies all operations as one group", func(t *testing.T) {
+		reset()
+		app.storeFileInMemory("target.txt", []string{"one", "two", "three"})
+
+		err := app.WithOperationGroup("Copy hunk", []OperationRequest{
+			{Type: "copy", SourceFile: "source.txt", TargetFile: "target.txt", LineNumber: 2, LineContent: "inserted"},
+			{Type: "remove", TargetFile: "target.txt", LineNumber: 4},
+			{Type: "update", TargetFile: "target.txt", LineNumber: 1, LineContent: "ONE"},
+		})
+		if err != nil {
+			t.Fatalf("WithOperationGroup returned error: %v", err)
+		}
+
+		lines, _ := app.compare.Edited("target.txt")
+		expected := []string{"ONE", "inserted", "two"}
+		if !reflect.DeepEqual(lines, expected) {
+			t.Errorf("Expected %v, got %v", expected, lines)
+		}
+
+		if len(undoStack(app)) != 1 {
+			t.Fatalf("Expected 1 operation group, got %d", len(undoStack(app)))
+		}
+		if undoStack(app)[0].Description != "Copy hunk" {
+			t.Errorf("Expected description 'Copy hunk', got %s", undoStack(app)[0].Description)
+		}
+
+		// A single undo reverts the whole batch
+		if err := app.UndoLastOperation(); err != nil {
+			t.Fatalf("UndoLastOperation returned error: %v", err)
+		}
+		lines, _ = app.compare.Edited("target.txt")
+		if !reflect.DeepEqual(lines, []string{"one", "two", "three"}) {
+			t.Errorf("Expected original content after undo, got %v", lines)
+		}
+	})
+
+	t.Run("rolls back on failure", func(t *testing.T) {
+		reset()
+		app.storeFileInMemory("target.txt", []string{"one", "two"})
+
+		err := app.WithOperationGroup("Broken batch", []OperationRequest{
+			{Type: "copy", SourceFile: "source.txt", TargetFile: "target.txt", LineNumber: 1, LineContent: "new"},
+			{Type: "remove", TargetFile: "target.txt", LineNumber: 99},
+		})
+		if err == nil {
+			t.Fatal("Expected error for out of range remove")
+		}
+
+		lines, _ := app.compare.Edited("target.txt")
+		if !reflect.DeepEqual(lines, []string{"one", "two"}) {
+			t.Errorf("Expected content to be rolled back, got %v", lines)
+		}
+		if len(undoStack(app)) != 0 {
+			t.Errorf("Expected no history after rollback, got %d groups", len(undoStack(app)))
+		}
+	})
+
+	t.Run("rollback leaves concurrent operations alone", func(t *testing.T) {
+		reset()
+		app.storeFileInMemory("target.txt", []string{"one"})
+		app.storeFileInMemory("other.txt", []string{"one"})
+
+		var wg sync.WaitGroup
+		err := app.applyOperationGroup("Broken batch", func() error {
+			if err := app.copyToFileLocked("", "target.txt", 1, "new"); err != nil {
+				return err
+			}
+			// A call arriving mid-batch waits for the batch to finish
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := app.CopyToFile("", "other.txt", 1, "concurrent"); err != nil {
+					t.Errorf("CopyToFile returned error: %v", err)
+				}
+			}()
+			time.Sleep(50 * time.Millisecond)
+			return errors.New("failed")
+		})
+		if err == nil {
+			t.Error("Expected the batch to fail")
+		}
+		wg.Wait()
+
+		if lines, _ := app.compare.Edited("other.txt"); !reflect.DeepEqual(lines, []string{"concurrent", "one"}) {
+			t.Errorf("Expected the concurrent copy to be kept, got %v", lines)
+		}
+		if lines, _ := app.compare.Edited("target.txt"); !reflect.DeepEqual(lines, []string{"one"}) {
+			t.Errorf("Expected the batch to be rolled back, got %v", lines)
+		}
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected only the concurrent copy in the history, got %d groups", len(undoStack(app)))
+		}
+	})
+
+	t.Run("rejects invalid requests before applying", func(t *testing.T) {
+		reset()
+		app.storeFileInMemory("target.txt", []string{"one"})
+
+		err := app.WithOperationGroup("Invalid", []OperationRequest{
+			{Type: "copy", TargetFile: "target.txt", LineNumber: 1, LineContent: "new"},
+			{Type: "rename", TargetFile: "target.txt"},
+		})
+		if err == nil {
+			t.Fatal("Expected error for unknown operation type")
+		}
+
+		lines, _ := app.compare.Edited("target.txt")
+		if !reflect.DeepEqual(lines, []string{"one"}) {
+			t.Errorf("Expected content to be untouched, got %v", lines)
+		}
+
+		if err := app.WithOperationGroup("Empty", nil); err == nil {
+			t.Error("Expected error for empty batch")
+		}
+	})
+}
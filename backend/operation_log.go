@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"weld/backend/history"
+)
+
+// Actions recorded in the operation log
+const (
+	OperationLogApply = history.LogApply
+	OperationLogUndo  = history.LogUndo
+	OperationLogRedo  = history.LogRedo
+)
+
+// OperationLogEntry records an operation group being applied, undone, or redone
+type OperationLogEntry = history.LogEntry
+
+var (
+	authorOnce sync.Once
+	author     string
+)
+
+// currentAuthor returns the name of the OS user running Weld
+func currentAuthor() string {
+	authorOnce.Do(func() {
+		if u, err := user.Current(); err == nil && u.Username != "" {
+			author = u.Username
+			return
+		}
+		author = os.Getenv("USER")
+		if author == "" {
+			author = os.Getenv("USERNAME")
+		}
+	})
+	return author
+}
+
+// ExportOperationLog writes an audit of every operation applied, undone, or
+// redone during the session. Paths ending in .csv get one row per line
+// operation; any other path gets JSON.
+func (a *App) ExportOperationLog(path string) error {
+	if path == "" {
+		return fmt.Errorf("export path cannot be empty")
+	}
+
+	entries := a.history.Log()
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		var err error
+		if data, err = operationLogCSV(entries); err != nil {
+			return fmt.Errorf("failed to encode operation log: %w", err)
+		}
+	} else {
+		var err error
+		if data, err = json.MarshalIndent(entries, "", "  "); err != nil {
+			return fmt.Errorf("failed to encode operation log: %w", err)
+		}
+	}
+
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write operation log: %w", err)
+	}
+	return nil
+}
+
+// operationLogCSV flattens the log to one CSV row per line operation
+func operationLogCSV(entries []OperationLogEntry) ([]byte, error) {
+	var builder strings.Builder
+	w := csv.NewWriter(&builder)
+	rows := [][]string{{
+		"time", "action", "group_id", "description", "author",
+		"operation_time", "operation", "source_file", "target_file", "line_number", "line_content",
+	}}
+	for _, entry := range entries {
+		for _, op := range entry.Group.Operations {
+			rows = append(rows, []string{
+				entry.Time.Format(time.RFC3339Nano),
+				entry.Action,
+				entry.Group.ID,
+				entry.Group.Description,
+				entry.Group.Author,
+				op.Timestamp.Format(time.RFC3339Nano),
+				string(op.Type),
+				op.SourceFile,
+				op.TargetFile,
+				strconv.Itoa(op.LineNumber),
+				op.LineContent,
+			})
+		}
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return nil, err
+	}
+	return []byte(builder.String()), nil
+}
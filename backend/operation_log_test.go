@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_ExportOperationLog(t *testing.T) {
+	app := NewApp()
+
+	app.storeFileInMemory("target.txt", []string{"one", "two"})
+	app.BeginOperationGroup("Copy hunk")
+	if err := app.CopyToFile("source.txt", "target.txt", 2, "new, \"quoted\""); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	app.CommitOperationGroup()
+	if err := app.UndoLastOperation(); err != nil {
+		t.Fatalf("UndoLastOperation returned error: %v", err)
+	}
+	if err := app.RedoLastOperation(); err != nil {
+		t.Fatalf("RedoLastOperation returned error: %v", err)
+	}
+
+	dir := t.TempDir()
+
+	t.Run("json", func(t *testing.T) {
+		path := filepath.Join(dir, "audit.json")
+		if err := app.ExportOperationLog(path); err != nil {
+			t.Fatalf("ExportOperationLog returned error: %v", err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read export: %v", err)
+		}
+		var entries []OperationLogEntry
+		if err := json.Unmarshal(data, &entries); err != nil {
+			t.Fatalf("Failed to parse export: %v", err)
+		}
+
+		var actions []string
+		for _, entry := range entries {
+			actions = append(actions, entry.Action)
+		}
+		if strings.Join(actions, ",") != "apply,undo,redo" {
+			t.Errorf("Expected apply,undo,redo, got %v", actions)
+		}
+		group := entries[0].Group
+		if group.Description != "Copy hunk" || group.Author != currentAuthor() {
+			t.Errorf("Unexpected group: %+v", group)
+		}
+		if group.Operations[0].Timestamp.IsZero() {
+			t.Error("Expected the operation to have a timestamp")
+		}
+	})
+
+	t.Run("csv", func(t *testing.T) {
+		path := filepath.Join(dir, "audit.CSV")
+		if err := app.ExportOperationLog(path); err != nil {
+			t.Fatalf("ExportOperationLog returned error: %v", err)
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			t.Fatalf("Failed to open export: %v", err)
+		}
+		defer file.Close()
+		rows, err := csv.NewReader(file).ReadAll()
+		if err != nil {
+			t.Fatalf("Failed to parse export: %v", err)
+		}
+		if len(rows) != 4 {
+			t.Fatalf("Expected a header and 3 rows, got %d", len(rows))
+		}
+		if rows[0][1] != "action" || rows[1][1] != "apply" || rows[1][10] != "new, \"quoted\"" {
+			t.Errorf("Unexpected rows: %v", rows[:2])
+		}
+	})
+
+	t.Run("empty path", func(t *testing.T) {
+		if err := app.ExportOperationLog(""); err == nil {
+			t.Error("Expected error for empty path")
+		}
+	})
+}
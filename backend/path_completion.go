@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"sort"
+	"strings"
+)
+
+// maxPathCompletions caps the number of completions returned for a single prefix
+const maxPathCompletions = 100
+
+// CompletePath returns filesystem entries that complete the given path prefix.
+// Directories are returned with a trailing separator so the frontend can keep
+// completing into them. Hidden entries are only included when the hidden-files
+// setting is enabled or the prefix itself starts with a dot.
+func (a *App) CompletePath(prefix string) ([]string, error) {
+	if prefix == "" {
+		return []string{}, nil
+	}
+
+	expanded, err := expandHomeDir(prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	// Split into the directory to list and the partial name to match
+	dir, partial := filepath.Split(expanded)
+	listDir := dir
+	if listDir == "" {
+		listDir = "."
+	}
+
+	entries, err := os.ReadDir(listDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory: %w", err)
+	}
+
+	showHidden := a.settings.Get().ShowHiddenFiles || strings.HasPrefix(partial, ".")
+
+	completions := []string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !showHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if !hasPathPrefix(name, partial) {
+			continue
+		}
+
+		// Keep the directory exactly as typed so completions extend the input
+		completion := dir + name
+		if isDirEntry(listDir, entry) {
+			completion += string(filepath.Separator)
+		}
+		completions = append(completions, completion)
+	}
+
+	sort.Strings(completions)
+	if len(completions) > maxPathCompletions {
+		completions = completions[:maxPathCompletions]
+	}
+	return completions, nil
+}
+
+// expandHomeDir replaces a leading "~" with the user's home directory
+func expandHomeDir(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") && !strings.HasPrefix(path, "~"+string(filepath.Separator)) {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("error resolving home directory: %w", err)
+	}
+	if path == "~" {
+		return homeDir + string(filepath.Separator), nil
+	}
+	return filepath.Join(homeDir, path[2:]) + trailingSeparator(path), nil
+}
+
+// trailingSeparator preserves a trailing separator that filepath.Join would strip
+func trailingSeparator(path string) string {
+	if strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator)) {
+		return string(filepath.Separator)
+	}
+	return ""
+}
+
+// hasPathPrefix reports whether name starts with prefix, ignoring case on
+// platforms whose filesystems are case-insensitive by default
+func hasPathPrefix(name, prefix string) bool {
+	if len(name) < len(prefix) {
+		return false
+	}
+	if goruntime.GOOS == "darwin" || goruntime.GOOS == "windows" {
+		return strings.EqualFold(name[:len(prefix)], prefix)
+	}
+	return strings.HasPrefix(name, prefix)
+}
+
+// isDirEntry reports whether entry is a directory, following symlinks
+func isDirEntry(dir string, entry os.DirEntry) bool {
+	if entry.IsDir() {
+		return true
+	}
+	if entry.Type()&os.ModeSymlink == 0 {
+		return false
+	}
+	info, err := os.Stat(filepath.Join(dir, entry.Name()))
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApp_CompletePath(t *testing.T) {
+	app := NewApp()
+
+	dir := t.TempDir()
+	for _, name := range []string{"alpha.txt", "alpine.go", "beta.txt", ".hidden"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "alps"), 0755); err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+
+	sep := string(filepath.Separator)
+
+	t.Run("empty prefix", func(t *testing.T) {
+		completions, err := app.CompletePath("")
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		if len(completions) != 0 {
+			t.Errorf("Expected no completions, got %v", completions)
+		}
+	})
+
+	t.Run("partial name", func(t *testing.T) {
+		completions, err := app.CompletePath(filepath.Join(dir, "alp"))
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		expected := []string{
+			filepath.Join(dir, "alpha.txt"),
+			filepath.Join(dir, "alpine.go"),
+			filepath.Join(dir, "alps") + sep,
+		}
+		if !reflect.DeepEqual(completions, expected) {
+			t.Errorf("CompletePath returned %v, expected %v", completions, expected)
+		}
+	})
+
+	t.Run("directory listing respects hidden setting", func(t *testing.T) {
+		app.SetShowHiddenFiles(false)
+		defer app.SetShowHiddenFiles(true)
+
+		completions, err := app.CompletePath(dir + sep)
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		if len(completions) != 4 {
+			t.Errorf("Expected 4 visible completions, got %v", completions)
+		}
+
+		// Typing a dot explicitly asks for hidden entries
+		completions, err = app.CompletePath(filepath.Join(dir, ".h"))
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		if !reflect.DeepEqual(completions, []string{filepath.Join(dir, ".hidden")}) {
+			t.Errorf("Expected hidden completion, got %v", completions)
+		}
+	})
+
+	t.Run("hidden files shown by default", func(t *testing.T) {
+		completions, err := app.CompletePath(dir + sep)
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		if len(completions) != 5 {
+			t.Errorf("Expected 5 completions including hidden, got %v", completions)
+		}
+	})
+
+	t.Run("missing directory", func(t *testing.T) {
+		completions, err := app.CompletePath(filepath.Join(dir, "nope", "x"))
+		if err != nil {
+			t.Fatalf("CompletePath returned error: %v", err)
+		}
+		if len(completions) != 0 {
+			t.Errorf("Expected no completions, got %v", completions)
+		}
+	})
+
+	t.Run("home directory expansion", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			t.Skip("no home directory available")
+		}
+		expanded, err := expandHomeDir("~" + sep)
+		if err != nil {
+			t.Fatalf("expandHomeDir returned error: %v", err)
+		}
+		if expanded != home+sep {
+			t.Errorf("expandHomeDir returned %s, expected %s", expanded, home+sep)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// portableMarkerName is the file beside the executable that turns on
+// portable mode without the --portable flag
+const portableMarkerName = "portable.marker"
+
+// portableDataDirName is the directory beside the executable that holds app
+// data in portable mode
+const portableDataDirName = "WeldData"
+
+// portableRoot returns the directory portable data lives in for an
+// executable: the directory of the binary, or on macOS the directory holding
+// the app bundle so data stays outside the signed bundle
+func portableRoot(executable string) string {
+	dir := filepath.Dir(executable)
+	if bundle, ok := strings.CutSuffix(dir, filepath.Join("Contents", "MacOS")); ok && strings.HasSuffix(filepath.Clean(bundle), ".app") {
+		return filepath.Dir(filepath.Clean(bundle))
+	}
+	return dir
+}
+
+// executablePath returns the resolved path of the running binary
+func executablePath() (string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	return executable, nil
+}
+
+// IsPortableInstall reports whether a portable.marker file sits beside the
+// executable
+func IsPortableInstall() bool {
+	executable, err := executablePath()
+	if err != nil {
+		return false
+	}
+	_, err = os.Stat(filepath.Join(portableRoot(executable), portableMarkerName))
+	return err == nil
+}
+
+// PortableDataDir returns the directory beside the executable that holds
+// settings, the session, and history in portable mode, creating it if needed
+func PortableDataDir() (string, error) {
+	executable, err := executablePath()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(portableRoot(executable), portableDataDirName)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create portable data directory: %w", err)
+	}
+	return dir, nil
+}
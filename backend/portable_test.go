@@ -0,0 +1,26 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestPortableRoot(t *testing.T) {
+	tests := []struct {
+		name       string
+		executable string
+		want       string
+	}{
+		{"binary", filepath.Join("/media", "usb", "weld"), filepath.Join("/media", "usb")},
+		{"windows binary", filepath.Join("/media", "usb", "Weld", "weld.exe"), filepath.Join("/media", "usb", "Weld")},
+		{"macOS bundle", filepath.Join("/Volumes", "USB", "Weld.app", "Contents", "MacOS", "Weld"), filepath.Join("/Volumes", "USB")},
+		{"MacOS directory outside a bundle", filepath.Join("/opt", "Contents", "MacOS", "weld"), filepath.Join("/opt", "Contents", "MacOS")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := portableRoot(tt.executable); got != tt.want {
+				t.Errorf("portableRoot(%q) = %q, want %q", tt.executable, got, tt.want)
+			}
+		})
+	}
+}
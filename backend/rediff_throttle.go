@@ -0,0 +1,189 @@
+package backend
+
+import (
+	"sync"
+	"time"
+
+	"weld/backend/diff"
+)
+
+// Bounds for the adaptive re-diff interval
+const (
+	minRediffInterval = 50 * time.Millisecond
+	maxRediffInterval = time.Second
+)
+
+// rediffThrottle coalesces bursts of re-diff requests into at most one run per
+// interval. The first request runs immediately; requests arriving while a run
+// is in progress or within the interval are folded into a single trailing run,
+// so the last request is always followed by an authoritative pass. The interval
+// adapts to how long each run takes so slow diffs are throttled harder.
+type rediffThrottle struct {
+	mu       sync.Mutex
+	run      func()
+	interval time.Duration
+	lastRun  time.Time
+	running  bool
+	pending  bool
+	timer    *time.Timer
+}
+
+// newRediffThrottle creates a throttle that calls run for each coalesced request
+func newRediffThrottle(run func()) *rediffThrottle {
+	return &rediffThrottle{
+		run:      run,
+		interval: minRediffInterval,
+	}
+}
+
+// Trigger requests a run, executing immediately when allowed or scheduling a trailing run
+func (t *rediffThrottle) Trigger() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.running || t.timer != nil {
+		t.pending = true
+		return
+	}
+
+	wait := t.interval - time.Since(t.lastRun)
+	if wait <= 0 {
+		t.startLocked()
+		return
+	}
+
+	t.pending = true
+	t.timer = time.AfterFunc(wait, t.fire)
+}
+
+// fire runs a scheduled trailing pass
+func (t *rediffThrottle) fire() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.timer = nil
+	if !t.pending || t.running {
+		return
+	}
+	t.startLocked()
+}
+
+// startLocked launches a run in the background (must be called with mu held)
+func (t *rediffThrottle) startLocked() {
+	t.pending = false
+	t.running = true
+	go t.execute()
+}
+
+// execute performs a run and schedules a trailing pass if more requests arrived meanwhile
+func (t *rediffThrottle) execute() {
+	started := time.Now()
+	t.run()
+	elapsed := time.Since(started)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.running = false
+	t.lastRun = time.Now()
+
+	// Allow roughly one run per two run-durations, within bounds
+	t.interval = min(max(2*elapsed, minRediffInterval), maxRediffInterval)
+
+	if t.pending && t.timer == nil {
+		t.timer = time.AfterFunc(t.interval, t.fire)
+	}
+}
+
+// Stop cancels any scheduled trailing run
+func (t *rediffThrottle) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+	t.pending = false
+}
+
+// RequestRediff asks the backend to recompute the diff for the given files after
+// an edit. Rapid successive requests (e.g. holding a key to remove many lines)
+// are coalesced. When the edited files are the current comparison, only the
+// region around the edits is compared again and delivered through the
+// "diff-patched" event; otherwise the whole result is delivered through
+// "diff-updated".
+func (a *App) RequestRediff(leftPath, rightPath string) {
+	a.requestRediff(leftPath, rightPath, false)
+}
+
+// requestRediff schedules a re-diff, comparing the files in full when full
+// is set, as when a setting changed how they are compared
+func (a *App) requestRediff(leftPath, rightPath string, full bool) {
+	a.rediffMutex.Lock()
+	a.rediffLeftPath = leftPath
+	a.rediffRightPath = rightPath
+	a.rediffFull = a.rediffFull || full
+	if a.rediffThrottle == nil {
+		a.rediffThrottle = newRediffThrottle(a.runRediff)
+	}
+	throttle := a.rediffThrottle
+	a.rediffMutex.Unlock()
+
+	throttle.Trigger()
+}
+
+// runRediff computes the diff for the most recently requested pair and emits the result
+func (a *App) runRediff() {
+	a.rediffMutex.Lock()
+	leftPath, rightPath, full := a.rediffLeftPath, a.rediffRightPath, a.rediffFull
+	a.rediffFull = false
+	a.rediffMutex.Unlock()
+
+	if !full {
+		if result, patch, ok := a.patchCurrentComparison(leftPath, rightPath); ok {
+			a.emitRediffPatch(leftPath, rightPath, result, patch)
+			return
+		}
+	}
+
+	options := a.comparisonOptions(leftPath, rightPath)
+	result, err := a.computeDiff(leftPath, rightPath, options)
+	if err == nil {
+		a.setCurrentComparison(leftPath, rightPath, options, result)
+	}
+
+	if err != nil {
+		a.runtime().EventsEmit("diff-error", map[string]string{
+			"leftPath":  leftPath,
+			"rightPath": rightPath,
+			"error":     err.Error(),
+		})
+		return
+	}
+	a.runtime().EventsEmit("diff-updated", map[string]interface{}{
+		"leftPath":  leftPath,
+		"rightPath": rightPath,
+		"result":    a.displayResult(result),
+	})
+}
+
+// emitRediffPatch delivers an incrementally updated comparison. A folded
+// view is re-indexed by every change, so it is sent whole instead.
+func (a *App) emitRediffPatch(leftPath, rightPath string, result *DiffResult, patch diff.Patch) {
+	if a.GetFoldUnchanged() {
+		a.runtime().EventsEmit("diff-updated", map[string]interface{}{
+			"leftPath":  leftPath,
+			"rightPath": rightPath,
+			"result":    a.displayResult(result),
+		})
+		return
+	}
+	a.runtime().EventsEmit("diff-patched", map[string]interface{}{
+		"leftPath":  leftPath,
+		"rightPath": rightPath,
+		"patch":     patch,
+		"chunks":    result.Chunks,
+		"identical": result.Identical,
+	})
+}
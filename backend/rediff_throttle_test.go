@@ -0,0 +1,83 @@
+package backend
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRediffThrottle(t *testing.T) {
+	t.Run("first trigger runs immediately", func(t *testing.T) {
+		var runs atomic.Int32
+		done := make(chan struct{}, 10)
+		throttle := newRediffThrottle(func() {
+			runs.Add(1)
+			done <- struct{}{}
+		})
+		defer throttle.Stop()
+
+		throttle.Trigger()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Expected immediate run")
+		}
+		if runs.Load() != 1 {
+			t.Errorf("Expected 1 run, got %d", runs.Load())
+		}
+	})
+
+	t.Run("burst is coalesced with a trailing run", func(t *testing.T) {
+		var runs atomic.Int32
+		var lastSeen atomic.Int32
+		var requested atomic.Int32
+		throttle := newRediffThrottle(func() {
+			runs.Add(1)
+			lastSeen.Store(requested.Load())
+			time.Sleep(5 * time.Millisecond)
+		})
+		defer throttle.Stop()
+
+		for i := 1; i <= 50; i++ {
+			requested.Store(int32(i))
+			throttle.Trigger()
+			time.Sleep(time.Millisecond)
+		}
+
+		// Wait for the trailing pass to settle
+		deadline := time.Now().Add(2 * time.Second)
+		for lastSeen.Load() != 50 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		if lastSeen.Load() != 50 {
+			t.Errorf("Expected the final run to observe the last request, saw %d", lastSeen.Load())
+		}
+		if runs.Load() >= 50 {
+			t.Errorf("Expected requests to be coalesced, got %d runs for 50 requests", runs.Load())
+		}
+		if runs.Load() < 2 {
+			t.Errorf("Expected a leading and trailing run, got %d", runs.Load())
+		}
+	})
+
+	t.Run("interval adapts to run duration", func(t *testing.T) {
+		done := make(chan struct{}, 1)
+		throttle := newRediffThrottle(func() {
+			time.Sleep(60 * time.Millisecond)
+			done <- struct{}{}
+		})
+		defer throttle.Stop()
+
+		throttle.Trigger()
+		<-done
+		time.Sleep(10 * time.Millisecond)
+
+		throttle.mu.Lock()
+		interval := throttle.interval
+		throttle.mu.Unlock()
+		if interval < 100*time.Millisecond {
+			t.Errorf("Expected interval to grow with run duration, got %v", interval)
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// resolutionScriptVersion is the format version written to resolution scripts
+const resolutionScriptVersion = 1
+
+// ResolutionScript is a replayable record of the decisions taken while
+// resolving the differences between two files. Files are referred to by side
+// so the script can be applied to fresh copies at other paths.
+type ResolutionScript struct {
+	Version   int                  `json:"version"`
+	LeftFile  string               `json:"leftFile"`
+	RightFile string               `json:"rightFile"`
+	CreatedAt time.Time            `json:"createdAt"`
+	Decisions []ResolutionDecision `json:"decisions"`
+}
+
+// ResolutionDecision is one applied operation group, such as copying a hunk
+// or typing a manual edit
+type ResolutionDecision struct {
+	Description string                `json:"description"`
+	Side        string                `json:"side"` // "left" or "right" when copied from that side, otherwise "manual"
+	Operations  []ResolutionOperation `json:"operations"`
+}
+
+// ResolutionOperation is a single line operation of a decision
+type ResolutionOperation struct {
+	Type        string `json:"type"`   // "copy" or "remove"
+	Target      string `json:"target"` // "left" or "right"
+	LineNumber  int    `json:"lineNumber"`
+	LineContent string `json:"lineContent"`
+}
+
+// ExportResolutionScript writes the decisions taken on the current comparison
+// to path as JSON. Undone groups are left out.
+func (a *App) ExportResolutionScript(path string) error {
+	if path == "" {
+		return fmt.Errorf("export path cannot be empty")
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return err
+	}
+
+	script := ResolutionScript{
+		Version:   resolutionScriptVersion,
+		LeftFile:  current.LeftPath,
+		RightFile: current.RightPath,
+		CreatedAt: time.Now(),
+		Decisions: []ResolutionDecision{},
+	}
+	for _, group := range a.appliedOperationGroups() {
+		if decision, ok := resolutionDecision(group, current.LeftPath, current.RightPath); ok {
+			script.Decisions = append(script.Decisions, decision)
+		}
+	}
+
+	data, err := json.MarshalIndent(script, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode resolution script: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write resolution script: %w", err)
+	}
+	return nil
+}
+
+// ApplyResolutionScript replays the decisions of a resolution script against
+// the files of the current comparison as a single undoable group. Removed
+// lines must match the script, so a script recorded against different
+// content is rejected and nothing is changed.
+func (a *App) ApplyResolutionScript(path string) error {
+	if path == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read resolution script: %w", err)
+	}
+	var script ResolutionScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		return fmt.Errorf("failed to parse resolution script: %w", err)
+	}
+	if script.Version != resolutionScriptVersion {
+		return fmt.Errorf("unsupported resolution script version %d", script.Version)
+	}
+
+	current, err := a.currentComparison()
+	if err != nil {
+		return err
+	}
+	paths := map[string]string{mergeOriginLeft: current.LeftPath, mergeOriginRight: current.RightPath}
+
+	err = a.applyOperationGroup("Apply resolution script", func() error {
+		for i, decision := range script.Decisions {
+			for _, op := range decision.Operations {
+				if err := a.applyResolutionOperationLocked(op, paths); err != nil {
+					return fmt.Errorf("decision %d (%s): %w", i+1, decision.Description, err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	a.RequestRediff(current.LeftPath, current.RightPath)
+	return nil
+}
+
+// applyResolutionOperationLocked performs one scripted operation on the file
+// of its target side (must be called with operationMutex held)
+func (a *App) applyResolutionOperationLocked(op ResolutionOperation, paths map[string]string) error {
+	target, ok := paths[op.Target]
+	if !ok {
+		return fmt.Errorf("unknown target side %q", op.Target)
+	}
+	source := ""
+	if op.Target == mergeOriginLeft {
+		source = paths[mergeOriginRight]
+	} else {
+		source = paths[mergeOriginLeft]
+	}
+
+	switch OperationType(op.Type) {
+	case OpCopy:
+		return a.copyToFileLocked(source, target, op.LineNumber, op.LineContent)
+	case OpRemove:
+		lines, err := a.ReadFileContentWithCache(target)
+		if err != nil {
+			return fmt.Errorf("failed to read target file: %w", err)
+		}
+		if op.LineNumber < 1 || op.LineNumber > len(lines) || lines[op.LineNumber-1] != op.LineContent {
+			return fmt.Errorf("resolution script does not match line %d of the %s file", op.LineNumber, op.Target)
+		}
+		return a.removeLineFromFileLocked(target, op.LineNumber)
+	}
+	return fmt.Errorf("unknown operation type %q", op.Type)
+}
+
+// appliedOperationGroups returns the groups from the operation log that are
+// currently applied, in the order they were applied
+func (a *App) appliedOperationGroups() []OperationGroup {
+	var groups []OperationGroup
+	for _, entry := range a.history.Log() {
+		switch entry.Action {
+		case OperationLogApply, OperationLogRedo:
+			groups = append(groups, entry.Group)
+		case OperationLogUndo:
+			for i := len(groups) - 1; i >= 0; i-- {
+				if groups[i].ID == entry.Group.ID {
+					groups = append(groups[:i], groups[i+1:]...)
+					break
+				}
+			}
+		}
+	}
+	return groups
+}
+
+// resolutionDecision converts an operation group on the compared pair into
+// a decision, reporting false for groups that touched neither file
+func resolutionDecision(group OperationGroup, leftPath, rightPath string) (ResolutionDecision, bool) {
+	decision := ResolutionDecision{Description: group.Description, Side: mergeOriginManual}
+	for _, op := range group.Operations {
+		var target string
+		switch op.TargetFile {
+		case leftPath:
+			target = mergeOriginLeft
+		case rightPath:
+			target = mergeOriginRight
+		default:
+			continue
+		}
+
+		scripted := ResolutionOperation{Type: string(op.Type), Target: target, LineContent: op.LineContent}
+		switch op.Type {
+		case OpCopy:
+			// Copies are replayed at the position the line actually landed
+			scripted.LineNumber = op.InsertIndex
+			if op.SourceFile == leftPath && target == mergeOriginRight {
+				decision.Side = mergeOriginLeft
+			} else if op.SourceFile == rightPath && target == mergeOriginLeft {
+				decision.Side = mergeOriginRight
+			}
+		case OpRemove:
+			scripted.LineNumber = op.LineNumber
+		}
+		decision.Operations = append(decision.Operations, scripted)
+	}
+	return decision, len(decision.Operations) > 0
+}
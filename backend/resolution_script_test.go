@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestApp_ResolutionScript(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"a", "b", "c"}
+	right := []string{"a", "x", "c"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+
+	// Take the left side of the change, then make a manual edit that is undone
+	if err := app.WithOperationGroup("Copy hunk to right", []OperationRequest{
+		{Type: "remove", TargetFile: rightPath, LineNumber: 2},
+		{Type: "copy", SourceFile: leftPath, TargetFile: rightPath, LineNumber: 2, LineContent: "b"},
+	}); err != nil {
+		t.Fatalf("WithOperationGroup returned error: %v", err)
+	}
+	if err := app.CopyToFile("", leftPath, 4, "scratch"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	if err := app.UndoLastOperation(); err != nil {
+		t.Fatalf("UndoLastOperation returned error: %v", err)
+	}
+	if err := app.CopyToFile("", leftPath, 4, "d"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	want := [][]string{{"a", "b", "c", "d"}, {"a", "b", "c"}}
+
+	scriptPath := filepath.Join(t.TempDir(), "resolution.json")
+	if err := app.ExportResolutionScript(scriptPath); err != nil {
+		t.Fatalf("ExportResolutionScript returned error: %v", err)
+	}
+	data, err := os.ReadFile(scriptPath)
+	if err != nil {
+		t.Fatalf("Failed to read script: %v", err)
+	}
+	var script ResolutionScript
+	if err := json.Unmarshal(data, &script); err != nil {
+		t.Fatalf("Failed to parse script: %v", err)
+	}
+	if len(script.Decisions) != 2 || script.Decisions[0].Side != "left" || script.Decisions[1].Side != "manual" {
+		t.Fatalf("Unexpected decisions: %+v", script.Decisions)
+	}
+
+	t.Run("replays on fresh copies", func(t *testing.T) {
+		if err := app.DiscardAllChanges(); err != nil {
+			t.Fatalf("DiscardAllChanges returned error: %v", err)
+		}
+		if err := app.ApplyResolutionScript(scriptPath); err != nil {
+			t.Fatalf("ApplyResolutionScript returned error: %v", err)
+		}
+		for i, path := range []string{leftPath, rightPath} {
+			lines, err := app.ReadFileContentWithCache(path)
+			if err != nil {
+				t.Fatalf("ReadFileContentWithCache returned error: %v", err)
+			}
+			if !reflect.DeepEqual(lines, want[i]) {
+				t.Errorf("Expected %v, got %v", want[i], lines)
+			}
+		}
+	})
+
+	t.Run("rejects mismatched content", func(t *testing.T) {
+		if err := app.DiscardAllChanges(); err != nil {
+			t.Fatalf("DiscardAllChanges returned error: %v", err)
+		}
+		if err := os.WriteFile(rightPath, []byte("a\ny\nc"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := app.ApplyResolutionScript(scriptPath); err == nil {
+			t.Fatal("Expected an error for a script recorded against other content")
+		}
+		lines, err := app.ReadFileContentWithCache(rightPath)
+		if err != nil {
+			t.Fatalf("ReadFileContentWithCache returned error: %v", err)
+		}
+		if !reflect.DeepEqual(lines, []string{"a", "y", "c"}) {
+			t.Errorf("Expected the file to be unchanged, got %v", lines)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if err := app.ExportResolutionScript(""); err == nil {
+			t.Error("Expected an error for an empty path")
+		}
+		if err := app.ApplyResolutionScript(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Error("Expected an error for a missing script")
+		}
+		if err := NewApp().ExportResolutionScript(scriptPath); err == nil {
+			t.Error("Expected an error when nothing has been compared")
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	goruntime "runtime"
+)
+
+// revealCommand returns the command that shows path in the file manager of
+// the given platform. File managers that cannot select a file open the
+// containing folder instead.
+func revealCommand(goos, path string) (string, []string, error) {
+	switch goos {
+	case "darwin":
+		return "open", []string{"-R", path}, nil
+	case "windows":
+		return "explorer", []string{"/select," + path}, nil
+	case "linux", "freebsd", "netbsd", "openbsd":
+		return "xdg-open", []string{filepath.Dir(path)}, nil
+	}
+	return "", nil, fmt.Errorf("revealing files is not supported on %s", goos)
+}
+
+// RevealInFileManager shows a file in the platform's file manager, selected
+// where the file manager supports it
+func (a *App) RevealInFileManager(path string) error {
+	if path == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+	if IsVirtualPath(path) {
+		return fmt.Errorf("virtual files have no location on disk")
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if _, err := os.Stat(absolute); err != nil {
+		return fmt.Errorf("file not found: %w", err)
+	}
+
+	name, args, err := revealCommand(goruntime.GOOS, absolute)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to open file manager: %w", err)
+	}
+	// Explorer exits with an error status even when it succeeds, so the
+	// outcome is not waited for beyond reaping the process
+	go cmd.Wait()
+	return nil
+}
+
+// RevealComparedFile shows the left or right file of the current comparison
+// in the file manager
+func (a *App) RevealComparedFile(side string) error {
+	current, err := a.currentComparison()
+	if err != nil {
+		return err
+	}
+	switch side {
+	case mergeOriginLeft:
+		return a.RevealInFileManager(current.LeftPath)
+	case mergeOriginRight:
+		return a.RevealInFileManager(current.RightPath)
+	}
+	return fmt.Errorf("invalid side: %s", side)
+}
@@ -0,0 +1,46 @@
+package backend
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRevealCommand(t *testing.T) {
+	path := filepath.Join("/projects", "weld", "main.go")
+	tests := []struct {
+		goos string
+		name string
+		args []string
+	}{
+		{"darwin", "open", []string{"-R", path}},
+		{"windows", "explorer", []string{"/select," + path}},
+		{"linux", "xdg-open", []string{filepath.Dir(path)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.goos, func(t *testing.T) {
+			name, args, err := revealCommand(tt.goos, path)
+			if err != nil || name != tt.name || !slices.Equal(args, tt.args) {
+				t.Errorf("revealCommand(%s) = %s %v, %v; expected %s %v", tt.goos, name, args, err, tt.name, tt.args)
+			}
+		})
+	}
+
+	if _, _, err := revealCommand("plan9", path); err == nil {
+		t.Error("Expected error for an unsupported platform")
+	}
+}
+
+func TestApp_RevealInFileManager_Errors(t *testing.T) {
+	app := NewApp()
+
+	if err := app.RevealInFileManager(""); err == nil {
+		t.Error("Expected error for empty path")
+	}
+	if err := app.RevealInFileManager(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+	if err := app.RevealComparedFile("left"); err == nil {
+		t.Error("Expected error when nothing has been compared")
+	}
+}
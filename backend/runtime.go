@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"context"
+	"errors"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// errNoWindow is returned by runtime calls that need a window when there is none
+var errNoWindow = errors.New("no window is available")
+
+// Runtime is the part of the Wails runtime the App calls: frontend events,
+// the application menu, the window, dialogs, and logging. Backend logic
+// goes through it so it can run without a live Wails context.
+type Runtime interface {
+	EventsEmit(name string, data ...interface{})
+	MenuUpdateApplicationMenu()
+	WindowFullscreen()
+	WindowUnfullscreen()
+	OpenFileDialog(options runtime.OpenDialogOptions) (string, error)
+	SaveFileDialog(options runtime.SaveDialogOptions) (string, error)
+	MessageDialog(options runtime.MessageDialogOptions) (string, error)
+	ClipboardGetText() (string, error)
+	Quit()
+	LogDebugf(format string, args ...interface{})
+	LogWarningf(format string, args ...interface{})
+	LogErrorf(format string, args ...interface{})
+}
+
+// wailsRuntime forwards runtime calls to Wails with the context it started
+// the App with
+type wailsRuntime struct {
+	ctx context.Context
+}
+
+// NewWailsRuntime returns the Runtime backed by a Wails application context
+func NewWailsRuntime(ctx context.Context) Runtime {
+	return wailsRuntime{ctx: ctx}
+}
+
+func (w wailsRuntime) EventsEmit(name string, data ...interface{}) {
+	runtime.EventsEmit(w.ctx, name, data...)
+}
+
+func (w wailsRuntime) MenuUpdateApplicationMenu() {
+	runtime.MenuUpdateApplicationMenu(w.ctx)
+}
+
+func (w wailsRuntime) WindowFullscreen() {
+	runtime.WindowFullscreen(w.ctx)
+}
+
+func (w wailsRuntime) WindowUnfullscreen() {
+	runtime.WindowUnfullscreen(w.ctx)
+}
+
+func (w wailsRuntime) OpenFileDialog(options runtime.OpenDialogOptions) (string, error) {
+	return runtime.OpenFileDialog(w.ctx, options)
+}
+
+func (w wailsRuntime) SaveFileDialog(options runtime.SaveDialogOptions) (string, error) {
+	return runtime.SaveFileDialog(w.ctx, options)
+}
+
+func (w wailsRuntime) MessageDialog(options runtime.MessageDialogOptions) (string, error) {
+	return runtime.MessageDialog(w.ctx, options)
+}
+
+func (w wailsRuntime) ClipboardGetText() (string, error) {
+	return runtime.ClipboardGetText(w.ctx)
+}
+
+func (w wailsRuntime) Quit() {
+	runtime.Quit(w.ctx)
+}
+
+func (w wailsRuntime) LogDebugf(format string, args ...interface{}) {
+	runtime.LogDebugf(w.ctx, format, args...)
+}
+
+func (w wailsRuntime) LogWarningf(format string, args ...interface{}) {
+	runtime.LogWarningf(w.ctx, format, args...)
+}
+
+func (w wailsRuntime) LogErrorf(format string, args ...interface{}) {
+	runtime.LogErrorf(w.ctx, format, args...)
+}
+
+// NoopRuntime is a Runtime for running without a window, as in tests and
+// headless modes. Events, menu updates, and logs are dropped, and dialogs
+// and the clipboard report that no window is available.
+type NoopRuntime struct{}
+
+func (NoopRuntime) EventsEmit(string, ...interface{})  {}
+func (NoopRuntime) MenuUpdateApplicationMenu()         {}
+func (NoopRuntime) WindowFullscreen()                  {}
+func (NoopRuntime) WindowUnfullscreen()                {}
+func (NoopRuntime) Quit()                              {}
+func (NoopRuntime) LogDebugf(string, ...interface{})   {}
+func (NoopRuntime) LogWarningf(string, ...interface{}) {}
+func (NoopRuntime) LogErrorf(string, ...interface{})   {}
+func (NoopRuntime) ClipboardGetText() (string, error)  { return "", errNoWindow }
+func (NoopRuntime) OpenFileDialog(runtime.OpenDialogOptions) (string, error) {
+	return "", errNoWindow
+}
+func (NoopRuntime) SaveFileDialog(runtime.SaveDialogOptions) (string, error) {
+	return "", errNoWindow
+}
+func (NoopRuntime) MessageDialog(runtime.MessageDialogOptions) (string, error) {
+	return "", errNoWindow
+}
+
+// runtime returns the Runtime the App was given or started with, or a
+// NoopRuntime before the App has started
+func (a *App) runtime() Runtime {
+	if a.Runtime == nil {
+		return NoopRuntime{}
+	}
+	return a.Runtime
+}
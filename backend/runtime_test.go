@@ -0,0 +1,144 @@
+package backend
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// recordingRuntime is a Runtime that records the calls made to it
+type recordingRuntime struct {
+	NoopRuntime
+	mu         sync.Mutex
+	calls      []string
+	events     map[string][]interface{}
+	clipboard  string
+	fullscreen bool
+}
+
+func (r *recordingRuntime) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *recordingRuntime) EventsEmit(name string, data ...interface{}) {
+	r.record("event:" + name)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.events == nil {
+		r.events = make(map[string][]interface{})
+	}
+	r.events[name] = data
+}
+
+func (r *recordingRuntime) MenuUpdateApplicationMenu() { r.record("menu") }
+
+func (r *recordingRuntime) WindowFullscreen() {
+	r.record("fullscreen")
+	r.mu.Lock()
+	r.fullscreen = true
+	r.mu.Unlock()
+}
+
+func (r *recordingRuntime) WindowUnfullscreen() {
+	r.record("unfullscreen")
+	r.mu.Lock()
+	r.fullscreen = false
+	r.mu.Unlock()
+}
+
+func (r *recordingRuntime) ClipboardGetText() (string, error) { return r.clipboard, nil }
+
+// event returns the data of the last event emitted with a name
+func (r *recordingRuntime) event(name string) ([]interface{}, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, ok := r.events[name]
+	return data, ok
+}
+
+func (r *recordingRuntime) called(call string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, c := range r.calls {
+		if c == call {
+			return true
+		}
+	}
+	return false
+}
+
+func TestApp_InjectedRuntime(t *testing.T) {
+	newApp := func() (*App, *recordingRuntime) {
+		rt := &recordingRuntime{}
+		app := NewApp()
+		app.Storage = NewMemoryStorage()
+		app.Runtime = rt
+		return app, rt
+	}
+
+	t.Run("emits events without a Wails context", func(t *testing.T) {
+		app, rt := newApp()
+		if err := app.SetZoomFactor(1.25); err != nil {
+			t.Fatalf("SetZoomFactor returned error: %v", err)
+		}
+		data, ok := rt.event("zoom-changed")
+		if !ok || !reflect.DeepEqual(data, []interface{}{1.25}) {
+			t.Errorf("Expected zoom-changed with 1.25, got %v", data)
+		}
+	})
+
+	t.Run("focus mode drives the window and menu", func(t *testing.T) {
+		app, rt := newApp()
+		if err := app.SetFocusMode(true); err != nil {
+			t.Fatalf("SetFocusMode returned error: %v", err)
+		}
+		if !rt.fullscreen || !rt.called("menu") || !rt.called("event:focus-mode-changed") {
+			t.Errorf("Expected fullscreen, a menu update, and an event, got %v", rt.calls)
+		}
+		if err := app.SetFocusMode(false); err != nil {
+			t.Fatalf("SetFocusMode returned error: %v", err)
+		}
+		if rt.fullscreen {
+			t.Error("Expected the window to leave fullscreen")
+		}
+	})
+
+	t.Run("reads the clipboard", func(t *testing.T) {
+		app, rt := newApp()
+		rt.clipboard = "one\ntwo"
+		app.compare.SetEdited("pane.txt", []string{"one", "two"})
+
+		result, err := app.CompareClipboardWithRange("pane.txt", 1, 2)
+		if err != nil {
+			t.Fatalf("CompareClipboardWithRange returned error: %v", err)
+		}
+		for _, line := range result.Lines {
+			if line.Type != "same" {
+				t.Errorf("Expected the clipboard to match the selection, got %+v", line)
+			}
+		}
+	})
+}
+
+func TestNoopRuntime(t *testing.T) {
+	var rt Runtime = NoopRuntime{}
+	if _, err := rt.OpenFileDialog(runtime.OpenDialogOptions{}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from OpenFileDialog, got %v", err)
+	}
+	if _, err := rt.SaveFileDialog(runtime.SaveDialogOptions{}); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from SaveFileDialog, got %v", err)
+	}
+	if _, err := rt.ClipboardGetText(); !errors.Is(err, errNoWindow) {
+		t.Errorf("Expected errNoWindow from ClipboardGetText, got %v", err)
+	}
+
+	// An App that has not started falls back to it
+	if _, ok := NewApp().runtime().(NoopRuntime); !ok {
+		t.Error("Expected an App without a Runtime to use NoopRuntime")
+	}
+}
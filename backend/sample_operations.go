@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// defaultSampleScenario is the sample pair opened for new users. It exercises
+// additions, deletions, modifications, and moved code in a single comparison.
+const defaultSampleScenario = "mixed"
+
+// OpenSampleComparison writes the embedded sample file pairs to a temporary
+// directory and returns the paths of the default pair so the frontend can
+// load it like any other comparison
+func (a *App) OpenSampleComparison() (InitialFiles, error) {
+	if a.SampleFiles == nil {
+		return InitialFiles{}, fmt.Errorf("sample files are not available in this build")
+	}
+
+	a.sampleMutex.Lock()
+	defer a.sampleMutex.Unlock()
+
+	// Reuse the extracted samples if they are still on disk, since the
+	// system may clean up temporary directories
+	if a.sampleDirectory != "" {
+		if _, err := os.Stat(a.sampleDirectory); err != nil {
+			a.sampleDirectory = ""
+		}
+	}
+	if a.sampleDirectory == "" {
+		dir, err := os.MkdirTemp("", "weld-samples-")
+		if err != nil {
+			return InitialFiles{}, fmt.Errorf("failed to create sample directory: %w", err)
+		}
+		if err := extractSampleFiles(a.SampleFiles, dir); err != nil {
+			os.RemoveAll(dir)
+			return InitialFiles{}, err
+		}
+		a.sampleDirectory = dir
+	}
+
+	left, right, err := findSamplePair(a.sampleDirectory, defaultSampleScenario)
+	if err != nil {
+		return InitialFiles{}, err
+	}
+
+	return InitialFiles{
+		LeftFile:  left,
+		RightFile: right,
+	}, nil
+}
+
+// extractSampleFiles copies every regular file from the sample filesystem into dir
+func extractSampleFiles(samples fs.FS, dir string) error {
+	return fs.WalkDir(samples, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		data, err := fs.ReadFile(samples, p)
+		if err != nil {
+			return fmt.Errorf("failed to read sample %s: %w", p, err)
+		}
+
+		// Flatten the embedded directory structure so pairs sit side by side
+		target := filepath.Join(dir, path.Base(p))
+		if err := os.WriteFile(target, data, 0644); err != nil {
+			return fmt.Errorf("failed to write sample %s: %w", target, err)
+		}
+		return nil
+	})
+}
+
+// findSamplePair locates the "<scenario>-1.ext" and "<scenario>-2.ext" files in dir
+func findSamplePair(dir, scenario string) (string, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read sample directory: %w", err)
+	}
+
+	var left, right string
+	for _, entry := range entries {
+		name := entry.Name()
+		switch {
+		case strings.HasPrefix(name, scenario+"-1."):
+			left = filepath.Join(dir, name)
+		case strings.HasPrefix(name, scenario+"-2."):
+			right = filepath.Join(dir, name)
+		}
+	}
+
+	if left == "" || right == "" {
+		return "", "", fmt.Errorf("sample comparison %q not found", scenario)
+	}
+	return left, right, nil
+}
+
+// removeSampleFiles deletes any sample files extracted during this session
+func (a *App) removeSampleFiles() {
+	a.sampleMutex.Lock()
+	defer a.sampleMutex.Unlock()
+	if a.sampleDirectory == "" {
+		return
+	}
+	os.RemoveAll(a.sampleDirectory)
+	a.sampleDirectory = ""
+}
@@ -0,0 +1,86 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestApp_OpenSampleComparison(t *testing.T) {
+	t.Run("no embedded samples", func(t *testing.T) {
+		app := NewApp()
+		if _, err := app.OpenSampleComparison(); err == nil {
+			t.Error("Expected error when sample files are not available")
+		}
+	})
+
+	t.Run("extracts samples and returns default pair", func(t *testing.T) {
+		app := NewApp()
+		app.SampleFiles = fstest.MapFS{
+			"resources/sample-files/mixed-1.rb":  {Data: []byte("puts 'a'\n")},
+			"resources/sample-files/mixed-2.rb":  {Data: []byte("puts 'b'\n")},
+			"resources/sample-files/addend-1.py": {Data: []byte("print(1)\n")},
+			"resources/sample-files/addend-2.py": {Data: []byte("print(1)\nprint(2)\n")},
+		}
+		t.Cleanup(app.removeSampleFiles)
+
+		files, err := app.OpenSampleComparison()
+		if err != nil {
+			t.Fatalf("OpenSampleComparison returned error: %v", err)
+		}
+
+		if filepath.Base(files.LeftFile) != "mixed-1.rb" {
+			t.Errorf("Expected left file mixed-1.rb, got %s", files.LeftFile)
+		}
+		if filepath.Base(files.RightFile) != "mixed-2.rb" {
+			t.Errorf("Expected right file mixed-2.rb, got %s", files.RightFile)
+		}
+
+		// All pairs should be extracted next to each other
+		if _, err := os.Stat(filepath.Join(filepath.Dir(files.LeftFile), "addend-2.py")); err != nil {
+			t.Errorf("Expected addend-2.py to be extracted: %v", err)
+		}
+
+		content, err := os.ReadFile(files.RightFile)
+		if err != nil {
+			t.Fatalf("Failed to read extracted sample: %v", err)
+		}
+		if string(content) != "puts 'b'\n" {
+			t.Errorf("Unexpected sample content: %q", string(content))
+		}
+
+		// A second call reuses the same directory
+		again, err := app.OpenSampleComparison()
+		if err != nil {
+			t.Fatalf("Second OpenSampleComparison returned error: %v", err)
+		}
+		if again.LeftFile != files.LeftFile {
+			t.Errorf("Expected extracted samples to be reused, got %s and %s", files.LeftFile, again.LeftFile)
+		}
+
+		// Samples cleaned up from disk are extracted again
+		if err := os.RemoveAll(filepath.Dir(files.LeftFile)); err != nil {
+			t.Fatalf("Failed to remove samples: %v", err)
+		}
+		restored, err := app.OpenSampleComparison()
+		if err != nil {
+			t.Fatalf("OpenSampleComparison after cleanup returned error: %v", err)
+		}
+		if _, err := os.Stat(restored.LeftFile); err != nil {
+			t.Errorf("Expected the samples to be extracted again: %v", err)
+		}
+	})
+
+	t.Run("missing default pair", func(t *testing.T) {
+		app := NewApp()
+		app.SampleFiles = fstest.MapFS{
+			"same-1.js": {Data: []byte("x")},
+		}
+		t.Cleanup(app.removeSampleFiles)
+
+		if _, err := app.OpenSampleComparison(); err == nil {
+			t.Error("Expected error when default sample pair is missing")
+		}
+	})
+}
@@ -3,42 +3,48 @@ package backend
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
-	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"weld/backend/compare"
 )
 
 // SaveChanges saves the in-memory changes to disk
 func (a *App) SaveChanges(filepath string) error {
-	fileCacheMutex.RLock()
-	cachedLines, exists := fileCache[filepath]
-	fileCacheMutex.RUnlock()
-
-	if !exists {
-		return fmt.Errorf("no unsaved changes for file: %s", filepath)
+	a.RecordActivity()
+	if IsVirtualPath(filepath) {
+		return fmt.Errorf("virtual files must be saved to a location with SaveFileAs")
 	}
 
-	// Write to file using buffered I/O for better performance
-	file, err := os.Create(filepath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
+	// The cached lines are dropped once written, and edits wait meanwhile
+	err := a.compare.Save(filepath, func(cachedLines []string) error {
+		// Write to file using buffered I/O for better performance
+		file, err := os.Create(filepath)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+		defer file.Close()
 
-	w := bufio.NewWriter(file)
-	if _, err := w.WriteString(strings.Join(cachedLines, "\n")); err != nil {
-		return fmt.Errorf("failed to write content: %w", err)
+		w := bufio.NewWriter(file)
+		if _, err := w.WriteString(strings.Join(cachedLines, "\n")); err != nil {
+			return fmt.Errorf("failed to write content: %w", err)
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to flush content: %w", err)
+		}
+		return nil
+	})
+	if errors.Is(err, compare.ErrNotEdited) {
+		return fmt.Errorf("no unsaved changes for file: %s", filepath)
 	}
-	if err := w.Flush(); err != nil {
-		return fmt.Errorf("failed to flush content: %w", err)
+	if err != nil {
+		return err
 	}
 
-	// Remove from cache after successful save
-	fileCacheMutex.Lock()
-	delete(fileCache, filepath)
-	fileCacheMutex.Unlock()
+	// Summarize the merge once both sides are saved
+	a.finishMergeSessionIfSaved()
 
 	return nil
 }
@@ -47,13 +53,9 @@ func (a *App) SaveChanges(filepath string) error {
 // Returns true to prevent closing, false to allow normal shutdown
 func (a *App) OnBeforeClose(ctx context.Context) (prevent bool) {
 	// Check if there are unsaved changes in memory cache
-	fileCacheMutex.RLock()
-	hasUnsaved := len(fileCache) > 0
-	fileCacheMutex.RUnlock()
-
-	if hasUnsaved {
+	if len(a.compare.EditedPaths()) > 0 {
 		// Emit event to frontend to show custom dialog
-		runtime.EventsEmit(ctx, "show-quit-dialog", a.GetUnsavedFilesList())
+		a.runtime().EventsEmit("show-quit-dialog", a.GetUnsavedFilesList())
 		// Always prevent closing initially - frontend will handle quit after user decision
 		return true
 	}
@@ -76,26 +78,18 @@ func (a *App) SaveSelectedFilesAndQuit(filesToSave []string) error {
 	}
 
 	// Clear any remaining unsaved files from cache if user chose not to save them
-	fileCacheMutex.Lock()
-	for filepath := range fileCache {
-		delete(fileCache, filepath)
-	}
-	fileCacheMutex.Unlock()
+	a.compare.DiscardAll()
 
 	// Quit the application
-	runtime.Quit(a.ctx)
+	a.runtime().Quit()
 	return nil
 }
 
 // QuitWithoutSaving clears the cache and quits without saving
 func (a *App) QuitWithoutSaving() {
 	// Clear all unsaved changes
-	fileCacheMutex.Lock()
-	for filepath := range fileCache {
-		delete(fileCache, filepath)
-	}
-	fileCacheMutex.Unlock()
+	a.compare.DiscardAll()
 
 	// Quit the application
-	runtime.Quit(a.ctx)
+	a.runtime().Quit()
 }
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"testing"
+)
+
+func TestApp_CompareRangesAcrossFiles(t *testing.T) {
+
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	app.compare.SetEdited("a.go", []string{"package a", "", "func f() int {", "\treturn 1", "}"})
+	app.compare.SetEdited("b.go", []string{"package b", "", "import \"fmt\"", "", "func g() int {", "\treturn 2", "}", "// end"})
+
+	result, err := app.CompareRangesAcrossFiles("a.go", 3, 5, "b.go", 5, 7)
+	if err != nil {
+		t.Fatalf("CompareRangesAcrossFiles returned error: %v", err)
+	}
+	if result.Scope == nil || *result.Scope != (DiffScope{LeftStart: 3, LeftEnd: 5, RightStart: 5, RightEnd: 7}) {
+		t.Errorf("Expected the scope of both selections, got %+v", result.Scope)
+	}
+	last := result.Lines[len(result.Lines)-1]
+	if last.Type != "same" || last.LeftNumber != 5 || last.RightNumber != 7 {
+		t.Errorf("Expected line numbers of the full files, got %+v", last)
+	}
+	if len(result.Chunks) == 0 || result.Chunks[0].StartIndex != 0 {
+		t.Errorf("Expected the selections to differ from their first line, got %+v", result.Chunks)
+	}
+
+	t.Run("same file", func(t *testing.T) {
+		result, err := app.CompareRangesAcrossFiles("b.go", 1, 1, "b.go", 1, 1)
+		if err != nil {
+			t.Fatalf("CompareRangesAcrossFiles returned error: %v", err)
+		}
+		if len(result.Lines) != 1 || result.Lines[0].Type != "same" {
+			t.Errorf("Expected one unchanged line, got %+v", result.Lines)
+		}
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		if _, err := app.CompareRangesAcrossFiles("a.go", 4, 3, "b.go", 1, 2); err == nil {
+			t.Error("Expected error for a reversed range")
+		}
+		if _, err := app.CompareRangesAcrossFiles("a.go", 1, 2, "b.go", 1, 9); err == nil {
+			t.Error("Expected error for a range past the end of the file")
+		}
+		if _, err := app.CompareRangesAcrossFiles("", 1, 1, "b.go", 1, 1); err == nil {
+			t.Error("Expected error for an empty path")
+		}
+	})
+}
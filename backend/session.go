@@ -0,0 +1,31 @@
+package backend
+
+import (
+	"weld/backend/session"
+)
+
+// sessionFileName is the storage key of the session
+const sessionFileName = "session.json"
+
+// Session holds working state that is restored the next time Weld starts,
+// as opposed to Settings which hold user preferences
+type Session struct {
+	Markers map[string][]LineMarker `json:"markers"`
+	// Undo and redo history kept when PersistUndoHistory is set, with the
+	// SHA-256 of each file it edits when it was stored
+	UndoHistory    []OperationGroup  `json:"undoHistory,omitempty"`
+	RedoHistory    []OperationGroup  `json:"redoHistory,omitempty"`
+	UndoFileHashes map[string]string `json:"undoFileHashes,omitempty"`
+}
+
+// newSession returns an empty session
+func newSession() Session {
+	return Session{
+		Markers: make(map[string][]LineMarker),
+	}
+}
+
+// newSessionStore returns the session store, kept in the app's storage
+func (a *App) newSessionStore() session.Store[Session] {
+	return session.NewStore(sessionFileName, newSession, func() Storage { return a.Storage })
+}
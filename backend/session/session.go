@@ -0,0 +1,104 @@
+// Package session keeps working state that is restored the next time the
+// app starts, such as line markers and undo history, in memory and in
+// storage
+package session
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"weld/backend/storage"
+)
+
+// Store holds the current session and saves every change to storage
+type Store[T any] interface {
+	// Load reads the stored session, keeping a new one when none is stored
+	Load() error
+	// View calls read with the current session. The session must not be
+	// modified or kept after read returns.
+	View(read func(*T))
+	// Update applies change to the session and saves it. Nothing is saved
+	// when change returns an error. A change that cannot be saved is still
+	// kept in memory.
+	Update(change func(*T) error) error
+}
+
+// store is a Store kept in the storage returned by storageOf, or only in
+// memory while that returns nil
+type store[T any] struct {
+	key        string
+	newSession func() T
+	storageOf  func() storage.Storage
+	mu         sync.Mutex
+	current    T
+}
+
+// NewStore returns a Store holding a new session, stored under key. The
+// storage is looked up on every load and save, so it can be chosen after the
+// store is created.
+func NewStore[T any](key string, newSession func() T, storageOf func() storage.Storage) Store[T] {
+	return &store[T]{key: key, newSession: newSession, storageOf: storageOf, current: newSession()}
+}
+
+// Load reads the stored session
+func (s *store[T]) Load() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.storageOf()
+	if st == nil {
+		return nil
+	}
+
+	data, err := st.Read(s.key)
+	if errors.Is(err, storage.ErrNotStored) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read session: %w", err)
+	}
+
+	session := s.newSession()
+	if err := json.Unmarshal(data, &session); err != nil {
+		return fmt.Errorf("failed to parse session: %w", err)
+	}
+	s.current = session
+	return nil
+}
+
+// View calls read with the current session
+func (s *store[T]) View(read func(*T)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	read(&s.current)
+}
+
+// Update changes and saves the session
+func (s *store[T]) Update(change func(*T) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := change(&s.current); err != nil {
+		return err
+	}
+	return s.saveLocked()
+}
+
+// saveLocked writes the current session to storage, if there is any (must
+// be called with mu held)
+func (s *store[T]) saveLocked() error {
+	st := s.storageOf()
+	if st == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.current, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if err := st.Write(s.key, data); err != nil {
+		return fmt.Errorf("failed to write session: %w", err)
+	}
+	return nil
+}
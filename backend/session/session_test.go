@@ -0,0 +1,90 @@
+package session
+
+import (
+	"errors"
+	"testing"
+
+	"weld/backend/storage"
+)
+
+type testSession struct {
+	Notes map[string]string `json:"notes"`
+}
+
+func newTestSession() testSession {
+	return testSession{Notes: make(map[string]string)}
+}
+
+func TestStore(t *testing.T) {
+	t.Run("new session when nothing is stored", func(t *testing.T) {
+		store := NewStore("session.json", newTestSession, func() storage.Storage { return storage.NewMemoryStorage() })
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		store.View(func(s *testSession) {
+			if s.Notes == nil || len(s.Notes) != 0 {
+				t.Errorf("Expected a new session, got %+v", s)
+			}
+		})
+	})
+
+	t.Run("updates are saved and reloaded", func(t *testing.T) {
+		st := storage.NewMemoryStorage()
+		store := NewStore("session.json", newTestSession, func() storage.Storage { return st })
+		err := store.Update(func(s *testSession) error {
+			s.Notes["a.txt"] = "check this"
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+
+		reloaded := NewStore("session.json", newTestSession, func() storage.Storage { return st })
+		if err := reloaded.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		reloaded.View(func(s *testSession) {
+			if s.Notes["a.txt"] != "check this" {
+				t.Errorf("Expected the saved session, got %+v", s)
+			}
+		})
+	})
+
+	t.Run("failed changes are not saved", func(t *testing.T) {
+		st := storage.NewMemoryStorage()
+		store := NewStore("session.json", newTestSession, func() storage.Storage { return st })
+		failure := errors.New("failed")
+		if err := store.Update(func(*testSession) error { return failure }); err != failure {
+			t.Errorf("Expected the change's error, got %v", err)
+		}
+		if _, err := st.Read("session.json"); !errors.Is(err, storage.ErrNotStored) {
+			t.Errorf("Expected nothing to be stored, got %v", err)
+		}
+	})
+
+	t.Run("unparsable session", func(t *testing.T) {
+		st := storage.NewMemoryStorage()
+		if err := st.Write("session.json", []byte("{")); err != nil {
+			t.Fatalf("Write returned error: %v", err)
+		}
+		store := NewStore("session.json", newTestSession, func() storage.Storage { return st })
+		if err := store.Load(); err == nil {
+			t.Error("Expected an error for an unparsable session")
+		}
+	})
+
+	t.Run("memory only without storage", func(t *testing.T) {
+		store := NewStore("session.json", newTestSession, func() storage.Storage { return nil })
+		if err := store.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if err := store.Update(func(s *testSession) error { s.Notes["a"] = "b"; return nil }); err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+		store.View(func(s *testSession) {
+			if s.Notes["a"] != "b" {
+				t.Errorf("Expected the change to be kept in memory, got %+v", s)
+			}
+		})
+	})
+}
@@ -0,0 +1,140 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+
+	"weld/backend/diff"
+	"weld/backend/settings"
+)
+
+// settingsFileName is the storage key of the settings
+const settingsFileName = "settings.json"
+
+// Settings holds user preferences that persist between sessions
+type Settings struct {
+	Version              int                `json:"version"`
+	FavoriteDirectories  []string           `json:"favoriteDirectories"`
+	ShowHiddenFiles      bool               `json:"showHiddenFiles"`
+	RecentComparisons    []RecentComparison `json:"recentComparisons"`
+	AlignImports         bool               `json:"alignImports"`
+	MergeLogPath         string             `json:"mergeLogPath"`
+	TabWidth             int                `json:"tabWidth"`
+	NotificationsEnabled bool               `json:"notificationsEnabled"`
+	IdleTimeoutMinutes   int                `json:"idleTimeoutMinutes"`
+	PollIntervalMs       int                `json:"pollIntervalMs"`
+	DiffAlgorithm        string             `json:"diffAlgorithm"`
+	IgnorePatterns       []string           `json:"ignorePatterns"`
+	SpellCheckLanguage   string             `json:"spellCheckLanguage"`
+	ShowLineAges         bool               `json:"showLineAges"`
+	FoldUnchanged        bool               `json:"foldUnchanged"`
+	FoldContextLines     int                `json:"foldContextLines"`
+	SimilarityThreshold  float64            `json:"similarityThreshold"` // 0 chooses by file type
+	MinLineLength        int                `json:"minLineLength"`       // 0 chooses by file type
+	DiffTimeBudgetMs     int                `json:"diffTimeBudgetMs"`    // 0 means no limit
+	SortOrder            string             `json:"sortOrder"`
+	FocusMode            bool               `json:"focusMode"`
+	EditorFont           EditorFont         `json:"editorFont"`
+	ZoomFactor           float64            `json:"zoomFactor"`
+	TailMode             bool               `json:"tailMode"`
+	UndoHistorySize      int                `json:"undoHistorySize"`
+	PersistUndoHistory   bool               `json:"persistUndoHistory"`
+}
+
+// defaultSettings returns the settings used when no settings file exists
+func defaultSettings() Settings {
+	return Settings{
+		Version:              currentSettingsVersion,
+		FavoriteDirectories:  []string{},
+		ShowHiddenFiles:      true,
+		RecentComparisons:    []RecentComparison{},
+		TabWidth:             4,
+		NotificationsEnabled: true,
+		IdleTimeoutMinutes:   defaultIdleTimeoutMinutes,
+		PollIntervalMs:       defaultPollIntervalMs,
+		DiffAlgorithm:        diff.AlgorithmLCS,
+		IgnorePatterns:       []string{},
+		FoldContextLines:     3,
+		DiffTimeBudgetMs:     defaultDiffTimeBudgetMs,
+		SortOrder:            SortNatural,
+		EditorFont:           defaultEditorFont(),
+		ZoomFactor:           defaultZoomFactor,
+		UndoHistorySize:      defaultUndoHistorySize,
+	}
+}
+
+// defaultDataDir returns the OS-specific directory used to store app data
+func defaultDataDir() string {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(configDir, "Weld")
+}
+
+// newSettingsStore returns the store of the app's settings, kept in app
+// storage, or in memory only when no storage is configured
+func (a *App) newSettingsStore() settings.Store[Settings] {
+	return settings.NewStore(settings.Schema[Settings]{
+		Key:        settingsFileName,
+		Version:    currentSettingsVersion,
+		Migrations: settingsMigrations,
+		Defaults:   defaultSettings,
+		Validate:   validateSettings,
+	}, func() Storage { return a.Storage })
+}
+
+// LoadSettings loads the persisted settings once, from the default data
+// directory unless Storage is set. Startup loads them, so it only needs to be
+// called first by code that uses settings before the window opens, such as
+// command line comparisons. A settings file that was quarantined is reported
+// through the "settings-quarantined" event on every call.
+func (a *App) LoadSettings() error {
+	a.settingsOnce.Do(func() {
+		if a.Storage == nil {
+			if dir := defaultDataDir(); dir != "" {
+				a.Storage = NewFileStorage(dir)
+			}
+		}
+		a.settingsErr = a.loadSettings()
+	})
+	if quarantine := a.settings.Quarantined(); quarantine != nil {
+		a.runtime().EventsEmit("settings-quarantined", *quarantine)
+	}
+	return a.settingsErr
+}
+
+// loadSettings reads settings from app storage, keeping the
+// defaults when the file does not exist yet. Older settings files are migrated
+// to the current version; files that cannot be parsed or fail validation are
+// quarantined so the app still starts with defaults.
+func (a *App) loadSettings() error {
+	_, err := a.settings.Load()
+	return err
+}
+
+// GetShowHiddenFiles returns whether hidden files are shown when browsing for files
+func (a *App) GetShowHiddenFiles() bool {
+	return a.settings.Get().ShowHiddenFiles
+}
+
+// SetShowHiddenFiles sets whether hidden files are shown when browsing for files
+func (a *App) SetShowHiddenFiles(show bool) error {
+	return a.settings.Update(func(s *Settings) error {
+		s.ShowHiddenFiles = show
+		return nil
+	})
+}
+
+// GetAlignImports returns whether import sections are compared as a set
+func (a *App) GetAlignImports() bool {
+	return a.settings.Get().AlignImports
+}
+
+// SetAlignImports sets whether import sections are compared as a set
+func (a *App) SetAlignImports(align bool) error {
+	return a.settings.Update(func(s *Settings) error {
+		s.AlignImports = align
+		return nil
+	})
+}
@@ -0,0 +1,222 @@
+// Package settings keeps user settings in memory and in storage, migrating
+// settings written by older versions and setting aside files that cannot be
+// used
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"weld/backend/storage"
+)
+
+// Migration upgrades raw settings from one version to the next
+type Migration func(raw map[string]interface{}) error
+
+// Schema describes settings of type T and how they are stored
+type Schema[T any] struct {
+	// Key is the storage key of the settings
+	Key string
+	// Version is the version of the settings written by this build. Settings
+	// written before versioning was introduced are version 0.
+	Version int
+	// Migrations holds the migration from version i to version i+1 at index i
+	Migrations []Migration
+	// Defaults returns the settings used when none are stored, which also
+	// fill in settings missing from stored ones
+	Defaults func() T
+	// Validate checks loaded settings for values the app cannot use
+	Validate func(T) error
+}
+
+// Quarantine describes a settings file that could not be loaded and was
+// renamed aside
+type Quarantine struct {
+	Path           string    `json:"path"`
+	QuarantinePath string    `json:"quarantinePath"`
+	Error          string    `json:"error"`
+	QuarantinedAt  time.Time `json:"quarantinedAt"`
+}
+
+// Store holds the current settings and saves every change to storage
+type Store[T any] interface {
+	// Load reads the stored settings, keeping the defaults when none are
+	// stored. Older settings are migrated and written back; settings that
+	// cannot be parsed or fail validation are renamed aside and replaced by
+	// the defaults, returning the quarantine along with the error.
+	Load() (*Quarantine, error)
+	// Get returns the current settings. Their slices are shared with the
+	// store, so they must not be modified.
+	Get() T
+	// Update applies change to the settings and saves them. Nothing is saved
+	// when change returns an error. Slices are replaced rather than modified,
+	// as Get shares them.
+	Update(change func(*T) error) error
+	// Quarantined returns the settings file set aside by Load, or nil
+	Quarantined() *Quarantine
+}
+
+// store is a Store kept in the storage returned by storageOf, or only in
+// memory while that returns nil
+type store[T any] struct {
+	schema     Schema[T]
+	storageOf  func() storage.Storage
+	mu         sync.Mutex
+	current    T
+	quarantine *Quarantine
+}
+
+// NewStore returns a Store holding the schema's defaults. The storage is
+// looked up on every load and save, so it can be chosen after the store is
+// created.
+func NewStore[T any](schema Schema[T], storageOf func() storage.Storage) Store[T] {
+	return &store[T]{schema: schema, storageOf: storageOf, current: schema.Defaults()}
+}
+
+// Load reads, migrates, and validates the stored settings
+func (s *store[T]) Load() (*Quarantine, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := s.storageOf()
+	if st == nil {
+		return nil, nil
+	}
+
+	data, err := st.Read(s.schema.Key)
+	if errors.Is(err, storage.ErrNotStored) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	settings, migrated, err := s.decode(data)
+	if err != nil {
+		return s.quarantineLocked(st, err)
+	}
+	s.current = settings
+
+	// Write migrated settings back so the migration only runs once
+	if migrated {
+		if err := s.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// Get returns the current settings
+func (s *store[T]) Get() T {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.current
+}
+
+// Update changes and saves the settings
+func (s *store[T]) Update(change func(*T) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := change(&s.current); err != nil {
+		return err
+	}
+	return s.saveLocked()
+}
+
+// Quarantined returns the quarantined settings file, if any
+func (s *store[T]) Quarantined() *Quarantine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.quarantine == nil {
+		return nil
+	}
+	quarantine := *s.quarantine
+	return &quarantine
+}
+
+// saveLocked writes the current settings to storage, if there is any
+// (must be called with mu held)
+func (s *store[T]) saveLocked() error {
+	st := s.storageOf()
+	if st == nil {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.current, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode settings: %w", err)
+	}
+	if err := st.Write(s.schema.Key, data); err != nil {
+		return fmt.Errorf("failed to write settings: %w", err)
+	}
+	return nil
+}
+
+// decode parses, migrates, and validates stored settings. It reports whether
+// any migration was applied.
+func (s *store[T]) decode(data []byte) (T, bool, error) {
+	var zero T
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return zero, false, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	if raw == nil {
+		return zero, false, fmt.Errorf("settings file is empty")
+	}
+
+	version := 0
+	if value, ok := raw["version"]; ok {
+		number, ok := value.(float64)
+		if !ok || number != float64(int(number)) {
+			return zero, false, fmt.Errorf("invalid settings version: %v", value)
+		}
+		version = int(number)
+	}
+	if version < 0 || version > s.schema.Version {
+		return zero, false, fmt.Errorf("unsupported settings version %d (expected at most %d)", version, s.schema.Version)
+	}
+
+	migrated := version < s.schema.Version
+	for ; version < s.schema.Version; version++ {
+		if err := s.schema.Migrations[version](raw); err != nil {
+			return zero, false, fmt.Errorf("failed to migrate settings from version %d: %w", version, err)
+		}
+	}
+	raw["version"] = s.schema.Version
+
+	migratedData, err := json.Marshal(raw)
+	if err != nil {
+		return zero, false, fmt.Errorf("failed to encode migrated settings: %w", err)
+	}
+	settings := s.schema.Defaults()
+	if err := json.Unmarshal(migratedData, &settings); err != nil {
+		return zero, false, fmt.Errorf("failed to parse settings: %w", err)
+	}
+	if err := s.schema.Validate(settings); err != nil {
+		return zero, false, err
+	}
+	return settings, migrated, nil
+}
+
+// quarantineLocked renames an unusable settings file aside and keeps the
+// defaults (must be called with mu held)
+func (s *store[T]) quarantineLocked(st storage.Storage, cause error) (*Quarantine, error) {
+	now := time.Now()
+	quarantineKey := fmt.Sprintf("%s.invalid-%s", s.schema.Key, now.Format("20060102-150405"))
+	if err := st.Rename(s.schema.Key, quarantineKey); err != nil {
+		return nil, fmt.Errorf("%v; failed to quarantine settings: %w", cause, err)
+	}
+
+	s.current = s.schema.Defaults()
+	s.quarantine = &Quarantine{
+		Path:           st.Location(s.schema.Key),
+		QuarantinePath: st.Location(quarantineKey),
+		Error:          cause.Error(),
+		QuarantinedAt:  now,
+	}
+	quarantine := *s.quarantine
+	return &quarantine, fmt.Errorf("settings quarantined to %s: %w", quarantineKey, cause)
+}
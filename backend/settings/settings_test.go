@@ -0,0 +1,134 @@
+package settings
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"weld/backend/storage"
+)
+
+type testSettings struct {
+	Version int      `json:"version"`
+	Width   int      `json:"width"`
+	Names   []string `json:"names"`
+}
+
+func newTestStore(st storage.Storage) Store[testSettings] {
+	return NewStore(Schema[testSettings]{
+		Key:     "settings.json",
+		Version: 1,
+		Migrations: []Migration{func(raw map[string]interface{}) error {
+			if raw["names"] == nil {
+				raw["names"] = []interface{}{}
+			}
+			return nil
+		}},
+		Defaults: func() testSettings {
+			return testSettings{Version: 1, Width: 4, Names: []string{}}
+		},
+		Validate: func(s testSettings) error {
+			if s.Width < 1 {
+				return errors.New("invalid width")
+			}
+			return nil
+		},
+	}, func() storage.Storage { return st })
+}
+
+func TestStore(t *testing.T) {
+	t.Run("defaults when nothing is stored", func(t *testing.T) {
+		store := newTestStore(storage.NewMemoryStorage())
+		if quarantine, err := store.Load(); quarantine != nil || err != nil {
+			t.Fatalf("Load returned %v, %v", quarantine, err)
+		}
+		if got := store.Get(); got.Width != 4 {
+			t.Errorf("Expected the defaults, got %+v", got)
+		}
+	})
+
+	t.Run("migrates and writes back", func(t *testing.T) {
+		st := storage.NewMemoryStorage()
+		st.Write("settings.json", []byte(`{"width": 8, "names": null}`))
+		store := newTestStore(st)
+		if _, err := store.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if got := store.Get(); got.Width != 8 || got.Names == nil || got.Version != 1 {
+			t.Errorf("Expected migrated settings, got %+v", got)
+		}
+		data, _ := st.Read("settings.json")
+		var saved testSettings
+		if err := json.Unmarshal(data, &saved); err != nil || saved.Version != 1 {
+			t.Errorf("Expected the migration to be written back, got %s", data)
+		}
+	})
+
+	for name, content := range map[string]string{
+		"unparseable":   `{"width": `,
+		"newer version": `{"version": 2}`,
+		"invalid value": `{"version": 1, "width": 0}`,
+	} {
+		t.Run("quarantines "+name, func(t *testing.T) {
+			st := storage.NewMemoryStorage()
+			st.Write("settings.json", []byte(content))
+			store := newTestStore(st)
+			quarantine, err := store.Load()
+			if err == nil || quarantine == nil {
+				t.Fatalf("Expected a quarantine, got %v, %v", quarantine, err)
+			}
+			if !strings.HasPrefix(quarantine.QuarantinePath, "memory:settings.json.invalid-") {
+				t.Errorf("Unexpected quarantine path %q", quarantine.QuarantinePath)
+			}
+			if *store.Quarantined() != *quarantine {
+				t.Errorf("Expected the quarantine to be remembered, got %+v", store.Quarantined())
+			}
+			if got := store.Get(); got.Width != 4 {
+				t.Errorf("Expected the defaults, got %+v", got)
+			}
+			if _, err := st.Read("settings.json"); !errors.Is(err, storage.ErrNotStored) {
+				t.Errorf("Expected the file to be moved aside, got %v", err)
+			}
+		})
+	}
+
+	t.Run("updates save unless they fail", func(t *testing.T) {
+		st := storage.NewMemoryStorage()
+		store := newTestStore(st)
+		if err := store.Update(func(s *testSettings) error {
+			s.Width = 2
+			return nil
+		}); err != nil {
+			t.Fatalf("Update returned error: %v", err)
+		}
+		failure := errors.New("rejected")
+		if err := store.Update(func(s *testSettings) error {
+			s.Width = 3
+			return failure
+		}); err != failure {
+			t.Errorf("Expected the change's error, got %v", err)
+		}
+
+		reloaded := newTestStore(st)
+		if _, err := reloaded.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		if got := reloaded.Get(); got.Width != 2 {
+			t.Errorf("Expected only the first update to be saved, got %+v", got)
+		}
+	})
+
+	t.Run("memory only without storage", func(t *testing.T) {
+		store := newTestStore(nil)
+		if quarantine, err := store.Load(); quarantine != nil || err != nil {
+			t.Fatalf("Load returned %v, %v", quarantine, err)
+		}
+		if err := store.Update(func(s *testSettings) error {
+			s.Width = 6
+			return nil
+		}); err != nil || store.Get().Width != 6 {
+			t.Errorf("Expected the update to be kept in memory, got %+v (%v)", store.Get(), err)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+	"weld/backend/settings"
+)
+
+// currentSettingsVersion is the schema version of settings written by this build.
+// Settings files written before versioning was introduced are version 0.
+const currentSettingsVersion = 1
+
+// settingsMigrations holds the migration from version i to version i+1 at index i
+var settingsMigrations = []settings.Migration{
+	migrateSettingsV0,
+}
+
+// SettingsQuarantine describes a settings file that could not be loaded and
+// was renamed aside
+type SettingsQuarantine = settings.Quarantine
+
+// migrateSettingsV0 replaces null lists left by unversioned settings files
+func migrateSettingsV0(raw map[string]interface{}) error {
+	for _, key := range []string{"favoriteDirectories", "recentComparisons"} {
+		if value, ok := raw[key]; ok && value == nil {
+			raw[key] = []interface{}{}
+		}
+	}
+	return nil
+}
+
+// validateSettings checks loaded settings for values the app cannot use
+func validateSettings(settings Settings) error {
+	for _, dir := range settings.FavoriteDirectories {
+		if dir == "" {
+			return fmt.Errorf("invalid settings: empty favorite directory")
+		}
+	}
+	if settings.TabWidth < 1 || settings.TabWidth > maxTabWidth {
+		return fmt.Errorf("invalid settings: tab width %d", settings.TabWidth)
+	}
+	if settings.FoldContextLines < 0 || settings.FoldContextLines > maxFoldContextLines {
+		return fmt.Errorf("invalid settings: fold context lines %d", settings.FoldContextLines)
+	}
+	if err := validateDiffConfig(DiffConfig{SimilarityThreshold: settings.SimilarityThreshold, MinLineLength: settings.MinLineLength}); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	if settings.DiffTimeBudgetMs < 0 || settings.DiffTimeBudgetMs > maxDiffTimeBudgetMs {
+		return fmt.Errorf("invalid settings: diff time budget %dms", settings.DiffTimeBudgetMs)
+	}
+	if err := validateEditorFont(settings.EditorFont); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	if settings.ZoomFactor < minZoomFactor || settings.ZoomFactor > maxZoomFactor {
+		return fmt.Errorf("invalid settings: zoom factor %v", settings.ZoomFactor)
+	}
+	if !isSortOrder(settings.SortOrder) {
+		return fmt.Errorf("invalid settings: unknown sort order %q", settings.SortOrder)
+	}
+	if !isAvailableAlgorithm(settings.DiffAlgorithm) {
+		return fmt.Errorf("invalid settings: unknown diff algorithm %q", settings.DiffAlgorithm)
+	}
+	if _, err := diff.CompileIgnorePatterns(settings.IgnorePatterns); err != nil {
+		return fmt.Errorf("invalid settings: %w", err)
+	}
+	if settings.PollIntervalMs < minPollIntervalMs {
+		return fmt.Errorf("invalid settings: poll interval %dms", settings.PollIntervalMs)
+	}
+	if settings.UndoHistorySize < 1 || settings.UndoHistorySize > maxUndoHistorySize {
+		return fmt.Errorf("invalid settings: undo history size %d", settings.UndoHistorySize)
+	}
+	if settings.IdleTimeoutMinutes < 0 {
+		return fmt.Errorf("invalid settings: idle timeout %d", settings.IdleTimeoutMinutes)
+	}
+	for _, pair := range settings.RecentComparisons {
+		if pair.LeftFile == "" || pair.RightFile == "" {
+			return fmt.Errorf("invalid settings: recent comparison with an empty path")
+		}
+	}
+	return nil
+}
+
+// GetSettingsQuarantine returns the settings file quarantined at startup, or
+// nil if settings loaded normally. The frontend uses this to show an error
+// that may have been emitted before it was listening.
+func (a *App) GetSettingsQuarantine() *SettingsQuarantine {
+	return a.settings.Quarantined()
+}
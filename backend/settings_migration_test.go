@@ -0,0 +1,106 @@
+package backend
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApp_LoadSettingsMigration(t *testing.T) {
+	t.Run("migrates unversioned settings", func(t *testing.T) {
+		app := NewApp()
+		dir := t.TempDir()
+		app.Storage = NewFileStorage(dir)
+		path := filepath.Join(dir, settingsFileName)
+		legacy := `{"favoriteDirectories": null, "showHiddenFiles": false}`
+		if err := os.WriteFile(path, []byte(legacy), 0644); err != nil {
+			t.Fatalf("Failed to write settings: %v", err)
+		}
+
+		if err := app.loadSettings(); err != nil {
+			t.Fatalf("loadSettings returned error: %v", err)
+		}
+		if app.GetShowHiddenFiles() {
+			t.Error("Expected showHiddenFiles to be kept from the legacy file")
+		}
+		if favorites := app.settings.Get().FavoriteDirectories; !reflect.DeepEqual(favorites, []string{}) {
+			t.Errorf("Expected null favorites to become empty, got %#v", favorites)
+		}
+		if !app.GetNotificationsEnabled() {
+			t.Error("Expected settings missing from the legacy file to use defaults")
+		}
+
+		// The migrated file is written back with the current version
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read settings: %v", err)
+		}
+		var saved map[string]interface{}
+		if err := json.Unmarshal(data, &saved); err != nil {
+			t.Fatalf("Failed to parse saved settings: %v", err)
+		}
+		if saved["version"] != float64(currentSettingsVersion) {
+			t.Errorf("Expected version %d, got %v", currentSettingsVersion, saved["version"])
+		}
+	})
+
+	quarantineCases := map[string]string{
+		"unparseable":   `{"favoriteDirectories": [`,
+		"newer version": `{"version": 99}`,
+		"invalid value": `{"version": 1, "favoriteDirectories": [""]}`,
+		"wrong type":    `{"version": 1, "showHiddenFiles": "yes"}`,
+		"bad pattern":   `{"version": 1, "ignorePatterns": ["("]}`,
+	}
+	for name, content := range quarantineCases {
+		t.Run("quarantines "+name, func(t *testing.T) {
+			app := NewApp()
+			dir := t.TempDir()
+			app.Storage = NewFileStorage(dir)
+			path := filepath.Join(dir, settingsFileName)
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("Failed to write settings: %v", err)
+			}
+
+			if err := app.loadSettings(); err == nil {
+				t.Fatal("Expected an error for a quarantined settings file")
+			}
+			if settings := app.settings.Get(); !reflect.DeepEqual(settings, defaultSettings()) {
+				t.Errorf("Expected default settings, got %+v", settings)
+			}
+			if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Error("Expected the settings file to be moved aside")
+			}
+
+			quarantine := app.GetSettingsQuarantine()
+			if quarantine == nil {
+				t.Fatal("Expected quarantine details")
+			}
+			if !strings.HasPrefix(quarantine.QuarantinePath, path+".invalid-") {
+				t.Errorf("Unexpected quarantine path: %s", quarantine.QuarantinePath)
+			}
+			data, err := os.ReadFile(quarantine.QuarantinePath)
+			if err != nil || string(data) != content {
+				t.Errorf("Expected the original content to be kept aside, got %q (%v)", data, err)
+			}
+		})
+	}
+
+	t.Run("no quarantine for valid settings", func(t *testing.T) {
+		app := NewApp()
+		app.Storage = NewMemoryStorage()
+		if err := app.SetShowHiddenFiles(false); err != nil {
+			t.Fatalf("SetShowHiddenFiles returned error: %v", err)
+		}
+		reloaded := NewApp()
+		reloaded.Storage = app.Storage
+		if err := reloaded.loadSettings(); err != nil {
+			t.Fatalf("loadSettings returned error: %v", err)
+		}
+		if reloaded.GetSettingsQuarantine() != nil || reloaded.GetShowHiddenFiles() {
+			t.Errorf("Expected settings to load normally, got %+v", reloaded.settings.Get())
+		}
+	})
+}
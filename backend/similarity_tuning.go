@@ -0,0 +1,154 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// maxMinLineLength is the largest accepted minimum line length for similarity
+const maxMinLineLength = 1000
+
+// Limits on the time spent finding modified lines in one comparison
+const (
+	defaultDiffTimeBudgetMs = 2000
+	maxDiffTimeBudgetMs     = 60000
+)
+
+// ComparisonMetadata is now imported from the diff package
+type ComparisonMetadata = diff.ComparisonMetadata
+
+// DiffConfig holds the tunable settings of modification detection. Zero
+// values are chosen by file type.
+type DiffConfig struct {
+	SimilarityThreshold float64 `json:"similarityThreshold"`
+	MinLineLength       int     `json:"minLineLength"`
+}
+
+// GetDiffConfig returns the modification detection settings
+func (a *App) GetDiffConfig() DiffConfig {
+	settings := a.settings.Get()
+	return DiffConfig{
+		SimilarityThreshold: settings.SimilarityThreshold,
+		MinLineLength:       settings.MinLineLength,
+	}
+}
+
+// SetDiffConfig sets the modification detection settings together, for a
+// settings panel, and re-compares the current files
+func (a *App) SetDiffConfig(config DiffConfig) error {
+	if err := validateDiffConfig(config); err != nil {
+		return err
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.SimilarityThreshold = config.SimilarityThreshold
+		s.MinLineLength = config.MinLineLength
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// validateDiffConfig checks modification detection settings
+func validateDiffConfig(config DiffConfig) error {
+	if config.SimilarityThreshold < 0 || config.SimilarityThreshold > 1 {
+		return fmt.Errorf("similarity threshold must be between 0 and 1")
+	}
+	if config.MinLineLength < 0 || config.MinLineLength > maxMinLineLength {
+		return fmt.Errorf("minimum line length must be between 0 and %d", maxMinLineLength)
+	}
+	return nil
+}
+
+// GetSimilarityThreshold returns the similarity ratio above which a changed
+// line counts as modified, or 0 when it is chosen by file type
+func (a *App) GetSimilarityThreshold() float64 {
+	return a.settings.Get().SimilarityThreshold
+}
+
+// SetSimilarityThreshold sets the similarity ratio above which a changed line
+// counts as modified, overriding the value chosen by file type. 0 restores
+// the automatic choice. The current files are re-compared.
+func (a *App) SetSimilarityThreshold(threshold float64) error {
+	if err := validateDiffConfig(DiffConfig{SimilarityThreshold: threshold}); err != nil {
+		return err
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.SimilarityThreshold = threshold
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// GetMinLineLength returns the length below which changed lines must match
+// exactly to count as modified, or 0 when it is chosen by file type
+func (a *App) GetMinLineLength() int {
+	return a.settings.Get().MinLineLength
+}
+
+// SetMinLineLength sets the length below which changed lines must match
+// exactly to count as modified, overriding the value chosen by file type.
+// 0 restores the automatic choice. The current files are re-compared.
+func (a *App) SetMinLineLength(length int) error {
+	if err := validateDiffConfig(DiffConfig{MinLineLength: length}); err != nil {
+		return err
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.MinLineLength = length
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// GetDiffTimeBudget returns the milliseconds a comparison may spend finding
+// modified lines before showing the rest as removed and added, or 0 for no
+// limit
+func (a *App) GetDiffTimeBudget() int {
+	return a.settings.Get().DiffTimeBudgetMs
+}
+
+// SetDiffTimeBudget sets the milliseconds a comparison may spend finding
+// modified lines, 0 for no limit, and re-compares the current files
+func (a *App) SetDiffTimeBudget(budgetMs int) error {
+	if budgetMs < 0 || budgetMs > maxDiffTimeBudgetMs {
+		return fmt.Errorf("diff time budget must be between 0 and %dms", maxDiffTimeBudgetMs)
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.DiffTimeBudgetMs = budgetMs
+		return nil
+	})
+
+	a.rediffCurrentComparison()
+	return err
+}
+
+// tuneAlgorithm adjusts modification detection for the type of the compared
+// file, then applies any overrides from the settings. Algorithms without a
+// configuration are returned unchanged with no metadata.
+func (a *App) tuneAlgorithm(algorithm diff.Algorithm, path string, lines []string) (diff.Algorithm, *ComparisonMetadata) {
+	configurable, ok := algorithm.(diff.Configurable)
+	if !ok {
+		return algorithm, nil
+	}
+
+	fileType := diff.DetectFileType(path, lines)
+	config := diff.TuneConfig(configurable.Config(), fileType)
+	if threshold := a.GetSimilarityThreshold(); threshold > 0 {
+		config.SimilarityThreshold = threshold
+	}
+	if length := a.GetMinLineLength(); length > 0 {
+		config.MinLineLength = length
+	}
+
+	metadata := &ComparisonMetadata{
+		FileType:            fileType,
+		SimilarityThreshold: config.SimilarityThreshold,
+		MinLineLength:       config.MinLineLength,
+	}
+	return configurable.WithConfig(config), metadata
+}
@@ -0,0 +1,113 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"weld/backend/diff"
+)
+
+func TestApp_SimilarityTuning(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.yml")
+	rightPath := filepath.Join(dir, "right.yml")
+	if err := os.WriteFile(leftPath, []byte("port: 80\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("port: 81\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if result.Metadata == nil || result.Metadata.FileType != "data" {
+		t.Fatalf("Expected data metadata, got %+v", result.Metadata)
+	}
+	// Short data lines are still compared by similarity
+	if result.Lines[0].Type != "modified" {
+		t.Errorf("Expected a modified line, got %+v", result.Lines)
+	}
+
+	if err := app.SetMinLineLength(50); err != nil {
+		t.Fatalf("SetMinLineLength returned error: %v", err)
+	}
+	if err := app.SetSimilarityThreshold(0.9); err != nil {
+		t.Fatalf("SetSimilarityThreshold returned error: %v", err)
+	}
+	result, err = app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if result.Metadata.MinLineLength != 50 || result.Metadata.SimilarityThreshold != 0.9 {
+		t.Errorf("Expected the overrides to be reported, got %+v", result.Metadata)
+	}
+	if result.Lines[0].Type == "modified" {
+		t.Errorf("Expected the override to require an exact match, got %+v", result.Lines)
+	}
+
+	if err := app.SetSimilarityThreshold(1.5); err == nil {
+		t.Error("Expected an error for a threshold above 1")
+	}
+	if err := app.SetMinLineLength(-1); err == nil {
+		t.Error("Expected an error for a negative length")
+	}
+}
+
+func TestApp_DiffConfig(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+
+	if config := app.GetDiffConfig(); config != (DiffConfig{}) {
+		t.Errorf("Expected automatic tuning by default, got %+v", config)
+	}
+	want := DiffConfig{SimilarityThreshold: 0.8, MinLineLength: 12}
+	if err := app.SetDiffConfig(want); err != nil {
+		t.Fatalf("SetDiffConfig returned error: %v", err)
+	}
+	if config := app.GetDiffConfig(); config != want {
+		t.Errorf("Expected %+v, got %+v", want, config)
+	}
+	if app.GetSimilarityThreshold() != 0.8 || app.GetMinLineLength() != 12 {
+		t.Error("Expected the individual settings to match")
+	}
+
+	if err := app.SetDiffConfig(DiffConfig{SimilarityThreshold: 0.5, MinLineLength: -3}); err == nil {
+		t.Error("Expected an error for a negative length")
+	}
+	if config := app.GetDiffConfig(); config != want {
+		t.Errorf("Expected an invalid config to change nothing, got %+v", config)
+	}
+}
+
+func TestApp_DiffTimeBudget(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+
+	if budget := app.GetDiffTimeBudget(); budget != defaultDiffTimeBudgetMs {
+		t.Errorf("Expected the default budget, got %d", budget)
+	}
+	if err := app.SetDiffTimeBudget(500); err != nil {
+		t.Fatalf("SetDiffTimeBudget returned error: %v", err)
+	}
+	config := app.algorithmFor(app.resolveCompareOptions(CompareOptions{})).(diff.Configurable).Config()
+	if config.TimeBudget != 500*time.Millisecond {
+		t.Errorf("Expected the budget to reach the algorithm, got %v", config.TimeBudget)
+	}
+
+	if err := app.SetDiffTimeBudget(0); err != nil {
+		t.Errorf("Expected no limit to be accepted, got %v", err)
+	}
+	for _, budget := range []int{-1, maxDiffTimeBudgetMs + 1} {
+		if err := app.SetDiffTimeBudget(budget); err == nil {
+			t.Errorf("Expected an error for a budget of %dms", budget)
+		}
+	}
+}
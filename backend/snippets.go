@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// snippetsFileName is the storage key of the snippets library
+const snippetsFileName = "snippets.json"
+
+// Snippet is a named block of text, such as a license header, that can be
+// inserted into a pane
+type Snippet struct {
+	Name      string    `json:"name"`
+	Content   string    `json:"content"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// snippetsLocked returns the snippets library, loading it from storage on
+// first use (must be called with snippetsMutex held)
+func (a *App) snippetsLocked() ([]Snippet, error) {
+	if a.snippets != nil || a.Storage == nil {
+		return a.snippets, nil
+	}
+
+	data, err := a.Storage.Read(snippetsFileName)
+	if errors.Is(err, ErrNotStored) {
+		a.snippets = []Snippet{}
+		return a.snippets, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snippets: %w", err)
+	}
+
+	snippets := []Snippet{}
+	if err := json.Unmarshal(data, &snippets); err != nil {
+		return nil, fmt.Errorf("failed to parse snippets: %w", err)
+	}
+	a.snippets = snippets
+	return a.snippets, nil
+}
+
+// saveSnippetsLocked writes the snippets library to app storage (must be
+// called with snippetsMutex held)
+func (a *App) saveSnippetsLocked() error {
+	if a.Storage == nil {
+		return nil
+	}
+	data, err := json.MarshalIndent(a.snippets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode snippets: %w", err)
+	}
+	if err := a.Storage.Write(snippetsFileName, data); err != nil {
+		return fmt.Errorf("failed to write snippets: %w", err)
+	}
+	return nil
+}
+
+// GetSnippets returns the saved snippets sorted by name
+func (a *App) GetSnippets() ([]Snippet, error) {
+	a.snippetsMutex.Lock()
+	defer a.snippetsMutex.Unlock()
+
+	snippets, err := a.snippetsLocked()
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]Snippet{}, snippets...)
+	compareNames := nameComparer(a.GetSortOrder())
+	sort.Slice(sorted, func(i, j int) bool {
+		return compareNames(sorted[i].Name, sorted[j].Name) < 0
+	})
+	return sorted, nil
+}
+
+// SaveSnippet stores a snippet under a name, replacing any snippet with the
+// same name
+func (a *App) SaveSnippet(name, content string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("snippet name cannot be empty")
+	}
+	if content == "" {
+		return fmt.Errorf("snippet content cannot be empty")
+	}
+
+	a.snippetsMutex.Lock()
+	defer a.snippetsMutex.Unlock()
+
+	snippets, err := a.snippetsLocked()
+	if err != nil {
+		return err
+	}
+	snippet := Snippet{Name: name, Content: content, UpdatedAt: time.Now()}
+	replaced := false
+	for i := range snippets {
+		if snippets[i].Name == name {
+			snippets[i] = snippet
+			replaced = true
+		}
+	}
+	if !replaced {
+		snippets = append(snippets, snippet)
+	}
+	a.snippets = snippets
+	return a.saveSnippetsLocked()
+}
+
+// DeleteSnippet removes a snippet from the library
+func (a *App) DeleteSnippet(name string) error {
+	a.snippetsMutex.Lock()
+	defer a.snippetsMutex.Unlock()
+
+	snippets, err := a.snippetsLocked()
+	if err != nil {
+		return err
+	}
+	for i := range snippets {
+		if snippets[i].Name == name {
+			a.snippets = append(snippets[:i], snippets[i+1:]...)
+			return a.saveSnippetsLocked()
+		}
+	}
+	return fmt.Errorf("snippet not found: %s", name)
+}
+
+// InsertSnippet inserts a snippet's lines into a file before the given
+// 1-based line as a single undoable operation
+func (a *App) InsertSnippet(name, targetFile string, lineNumber int) error {
+	if targetFile == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+
+	a.snippetsMutex.Lock()
+	snippets, err := a.snippetsLocked()
+	var content string
+	found := false
+	for _, snippet := range snippets {
+		if snippet.Name == name {
+			content, found = snippet.Content, true
+		}
+	}
+	a.snippetsMutex.Unlock()
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("snippet not found: %s", name)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n"), "\n")
+	return a.applyOperationGroup(fmt.Sprintf("Insert snippet %q", name), func() error {
+		for i, line := range lines {
+			if err := a.copyToFileLocked("", targetFile, lineNumber+i, line); err != nil {
+				return fmt.Errorf("failed to insert snippet: %w", err)
+			}
+		}
+		return nil
+	})
+}
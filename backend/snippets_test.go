@@ -0,0 +1,64 @@
+package backend
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestApp_Snippets(t *testing.T) {
+	storage := NewMemoryStorage()
+	app := NewApp()
+	app.Storage = storage
+
+	if err := app.SaveSnippet("license", "// Copyright\n// MIT License\n"); err != nil {
+		t.Fatalf("SaveSnippet returned error: %v", err)
+	}
+	if err := app.SaveSnippet("Config", "debug = false"); err != nil {
+		t.Fatalf("SaveSnippet returned error: %v", err)
+	}
+	if err := app.SaveSnippet("", "text"); err == nil {
+		t.Error("Expected an error for an empty name")
+	}
+
+	// A fresh app loads the library from storage
+	reloaded := NewApp()
+	reloaded.Storage = storage
+	snippets, err := reloaded.GetSnippets()
+	if err != nil {
+		t.Fatalf("GetSnippets returned error: %v", err)
+	}
+	if len(snippets) != 2 || snippets[0].Name != "Config" || snippets[1].Name != "license" {
+		t.Fatalf("Unexpected snippets: %+v", snippets)
+	}
+
+	t.Run("insert is undoable", func(t *testing.T) {
+		app.storeFileInMemory("target.txt", []string{"package main", "func main() {}"})
+		if err := app.InsertSnippet("license", "target.txt", 1); err != nil {
+			t.Fatalf("InsertSnippet returned error: %v", err)
+		}
+		want := []string{"// Copyright", "// MIT License", "package main", "func main() {}"}
+		if lines, _ := app.ReadFileContentWithCache("target.txt"); !reflect.DeepEqual(lines, want) {
+			t.Errorf("Expected %v, got %v", want, lines)
+		}
+
+		if err := app.UndoLastOperation(); err != nil {
+			t.Fatalf("UndoLastOperation returned error: %v", err)
+		}
+		want = []string{"package main", "func main() {}"}
+		if lines, _ := app.ReadFileContentWithCache("target.txt"); !reflect.DeepEqual(lines, want) {
+			t.Errorf("Expected the insert to be undone in one step, got %v", lines)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		if err := app.DeleteSnippet("Config"); err != nil {
+			t.Fatalf("DeleteSnippet returned error: %v", err)
+		}
+		if err := app.DeleteSnippet("Config"); err == nil {
+			t.Error("Expected an error deleting a missing snippet")
+		}
+		if err := app.InsertSnippet("Config", "target.txt", 1); err == nil {
+			t.Error("Expected an error inserting a missing snippet")
+		}
+	})
+}
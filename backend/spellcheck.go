@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"weld/backend/diff"
+)
+
+// SpellingIssue is imported from the diff package
+type SpellingIssue = diff.SpellingIssue
+
+// languageCodePattern matches dictionary language codes such as "en" or "en_US"
+var languageCodePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(?:[_-][A-Za-z0-9]+)*$`)
+
+// dictionaryKey returns the storage key of a language's dictionary
+func dictionaryKey(language string) string {
+	return "dictionaries/" + language + ".dic"
+}
+
+// ImportDictionary stores a word list, one word per line or in Hunspell .dic
+// format, as the spell-check dictionary for a language
+func (a *App) ImportDictionary(language, path string) error {
+	if !languageCodePattern.MatchString(language) {
+		return fmt.Errorf("invalid language code: %q", language)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading dictionary: %w", err)
+	}
+	dictionary := diff.ParseDictionary(strings.Split(string(data), "\n"))
+	if dictionary.Len() == 0 {
+		return fmt.Errorf("dictionary %s contains no words", path)
+	}
+	if a.Storage == nil {
+		return fmt.Errorf("no storage is configured for dictionaries")
+	}
+	if err := a.Storage.Write(dictionaryKey(language), data); err != nil {
+		return fmt.Errorf("failed to store dictionary: %w", err)
+	}
+
+	a.dictionaryMutex.Lock()
+	defer a.dictionaryMutex.Unlock()
+	if a.dictionaries == nil {
+		a.dictionaries = make(map[string]*diff.Dictionary)
+	}
+	a.dictionaries[language] = dictionary
+	return nil
+}
+
+// GetSpellCheckLanguage returns the language whose dictionary spell-checks
+// added words, or an empty string when spell-checking is off
+func (a *App) GetSpellCheckLanguage() string {
+	return a.settings.Get().SpellCheckLanguage
+}
+
+// SetSpellCheckLanguage selects the dictionary used to spell-check added
+// words. An empty language turns spell-checking off.
+func (a *App) SetSpellCheckLanguage(language string) error {
+	if language != "" {
+		if _, err := a.dictionary(language); err != nil {
+			return err
+		}
+	}
+	return a.settings.Update(func(s *Settings) error {
+		s.SpellCheckLanguage = language
+		return nil
+	})
+}
+
+// GetSpellingIssues returns the words added on the right side of the current
+// comparison that are not in the selected dictionary. It returns no issues
+// when spell-checking is off.
+func (a *App) GetSpellingIssues() ([]SpellingIssue, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	language := a.GetSpellCheckLanguage()
+	if language == "" {
+		return []SpellingIssue{}, nil
+	}
+	dictionary, err := a.dictionary(language)
+	if err != nil {
+		return nil, err
+	}
+	return diff.SpellCheck(current.Result, dictionary), nil
+}
+
+// dictionary returns the dictionary of a language, loading it from storage
+// on first use
+func (a *App) dictionary(language string) (*diff.Dictionary, error) {
+	if !languageCodePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid language code: %q", language)
+	}
+
+	a.dictionaryMutex.Lock()
+	defer a.dictionaryMutex.Unlock()
+	if dictionary, ok := a.dictionaries[language]; ok {
+		return dictionary, nil
+	}
+
+	if a.Storage == nil {
+		return nil, fmt.Errorf("no dictionary for language %q", language)
+	}
+	data, err := a.Storage.Read(dictionaryKey(language))
+	if errors.Is(err, ErrNotStored) {
+		return nil, fmt.Errorf("no dictionary for language %q", language)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dictionary: %w", err)
+	}
+
+	dictionary := diff.ParseDictionary(strings.Split(string(data), "\n"))
+	if a.dictionaries == nil {
+		a.dictionaries = make(map[string]*diff.Dictionary)
+	}
+	a.dictionaries[language] = dictionary
+	return dictionary, nil
+}
@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_SpellCheck(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	leftPath, rightPath := compareTempFiles(t, app,
+		[]string{"Install the package first."},
+		[]string{"Install the pakage first."})
+
+	issues, err := app.GetSpellingIssues()
+	if err != nil || len(issues) != 0 {
+		t.Errorf("Expected no issues while spell-checking is off, got %+v (%v)", issues, err)
+	}
+	if err := app.SetSpellCheckLanguage("en"); err == nil {
+		t.Error("Expected error for a language without a dictionary")
+	}
+
+	dicPath := filepath.Join(t.TempDir(), "en.dic")
+	if err := os.WriteFile(dicPath, []byte("4\ninstall/S\nthe\npackage\nfirst\n"), 0644); err != nil {
+		t.Fatalf("Failed to write dictionary: %v", err)
+	}
+	if err := app.ImportDictionary("../en", dicPath); err == nil {
+		t.Error("Expected error for an invalid language code")
+	}
+	if err := app.ImportDictionary("en", dicPath); err != nil {
+		t.Fatalf("ImportDictionary returned error: %v", err)
+	}
+	if err := app.SetSpellCheckLanguage("en"); err != nil {
+		t.Fatalf("SetSpellCheckLanguage returned error: %v", err)
+	}
+
+	if _, err := app.CompareFiles(leftPath, rightPath); err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	issues, err = app.GetSpellingIssues()
+	if err != nil {
+		t.Fatalf("GetSpellingIssues returned error: %v", err)
+	}
+	if len(issues) != 1 || issues[0].Word != "pakage" || issues[0].Line != 1 {
+		t.Errorf("Expected pakage to be flagged, got %+v", issues)
+	}
+
+	// Dictionaries are reloaded from storage
+	app.dictionaries = nil
+	if _, err := app.dictionary("en"); err != nil {
+		t.Errorf("Expected the stored dictionary to load, got %v", err)
+	}
+}
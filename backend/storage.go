@@ -0,0 +1,22 @@
+package backend
+
+import (
+	"weld/backend/storage"
+)
+
+// Storage persists app data such as settings, the session, and comparison
+// history. See storage.Storage.
+type Storage = storage.Storage
+
+// ErrNotStored is returned by Storage.Read when a key has never been written
+var ErrNotStored = storage.ErrNotStored
+
+// NewFileStorage returns a Storage that keeps each key as a file under dir
+func NewFileStorage(dir string) Storage {
+	return storage.NewFileStorage(dir)
+}
+
+// NewMemoryStorage returns a Storage that never touches the disk
+func NewMemoryStorage() Storage {
+	return storage.NewMemoryStorage()
+}
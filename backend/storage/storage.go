@@ -0,0 +1,128 @@
+// Package storage persists app data such as settings, the session, and
+// comparison history under named keys
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// ErrNotStored is returned by Storage.Read when a key has never been written
+var ErrNotStored = errors.New("not stored")
+
+// Storage persists app data such as settings, the session, and comparison
+// history. Keys are slash-separated names like "settings.json"; values are
+// encoded by the caller.
+type Storage interface {
+	// Read returns the data stored under key, or ErrNotStored
+	Read(key string) ([]byte, error)
+	// Write stores data under key, replacing any existing data
+	Write(key string, data []byte) error
+	// Rename moves the data stored under oldKey to newKey
+	Rename(oldKey, newKey string) error
+	// Location describes where a key is stored, for messages shown to the user
+	Location(key string) string
+}
+
+// fileStorage stores each key as a file inside a directory
+type fileStorage struct {
+	dir string
+}
+
+// NewFileStorage returns a Storage that keeps each key as a file under dir
+func NewFileStorage(dir string) Storage {
+	return &fileStorage{dir: dir}
+}
+
+// Read returns the contents of the key's file
+func (s *fileStorage) Read(key string) ([]byte, error) {
+	data, err := os.ReadFile(s.Location(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotStored
+	}
+	return data, err
+}
+
+// Write replaces the key's file, creating directories as needed
+func (s *fileStorage) Write(key string, data []byte) error {
+	path := s.Location(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Rename moves the key's file
+func (s *fileStorage) Rename(oldKey, newKey string) error {
+	err := os.Rename(s.Location(oldKey), s.Location(newKey))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotStored
+	}
+	return err
+}
+
+// Location returns the path of the key's file
+func (s *fileStorage) Location(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
+
+// memoryStorage keeps data in memory for the lifetime of the app
+type memoryStorage struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemoryStorage returns a Storage that never touches the disk
+func NewMemoryStorage() Storage {
+	return &memoryStorage{data: make(map[string][]byte)}
+}
+
+// Read returns a copy of the data stored under key
+func (s *memoryStorage) Read(key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.data[key]
+	if !ok {
+		return nil, ErrNotStored
+	}
+	return append([]byte{}, data...), nil
+}
+
+// Write stores a copy of data under key
+func (s *memoryStorage) Write(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = append([]byte{}, data...)
+	return nil
+}
+
+// Rename moves the data stored under oldKey to newKey
+func (s *memoryStorage) Rename(oldKey, newKey string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.data[oldKey]
+	if !ok {
+		return ErrNotStored
+	}
+	delete(s.data, oldKey)
+	s.data[newKey] = data
+	return nil
+}
+
+// Location returns a descriptive name for the key
+func (s *memoryStorage) Location(key string) string {
+	return "memory:" + key
+}
+
+// FilePath returns the path of the file a key is kept in, for storage that
+// keeps each key as a file
+func FilePath(s Storage, key string) (string, bool) {
+	files, ok := s.(*fileStorage)
+	if !ok {
+		return "", false
+	}
+	return files.Location(key), true
+}
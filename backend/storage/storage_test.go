@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestStorage(t *testing.T) {
+	backends := map[string]Storage{
+		"file":   NewFileStorage(t.TempDir()),
+		"memory": NewMemoryStorage(),
+	}
+
+	for name, storage := range backends {
+		t.Run(name, func(t *testing.T) {
+			if _, err := storage.Read("missing.json"); !errors.Is(err, ErrNotStored) {
+				t.Errorf("Expected ErrNotStored for a missing key, got %v", err)
+			}
+
+			if err := storage.Write("nested/key.json", []byte("one")); err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+			if err := storage.Write("nested/key.json", []byte("two")); err != nil {
+				t.Fatalf("Write returned error: %v", err)
+			}
+			data, err := storage.Read("nested/key.json")
+			if err != nil || string(data) != "two" {
+				t.Errorf("Expected %q, got %q (%v)", "two", data, err)
+			}
+
+			if err := storage.Rename("nested/key.json", "moved.json"); err != nil {
+				t.Fatalf("Rename returned error: %v", err)
+			}
+			if _, err := storage.Read("nested/key.json"); !errors.Is(err, ErrNotStored) {
+				t.Errorf("Expected the old key to be gone, got %v", err)
+			}
+			if data, err := storage.Read("moved.json"); err != nil || string(data) != "two" {
+				t.Errorf("Expected moved data, got %q (%v)", data, err)
+			}
+			if err := storage.Rename("missing.json", "other.json"); !errors.Is(err, ErrNotStored) {
+				t.Errorf("Expected ErrNotStored renaming a missing key, got %v", err)
+			}
+		})
+	}
+
+	t.Run("file location", func(t *testing.T) {
+		dir := t.TempDir()
+		if got := NewFileStorage(dir).Location("trends/a.json"); got != filepath.Join(dir, "trends", "a.json") {
+			t.Errorf("Unexpected location: %s", got)
+		}
+		if got, ok := FilePath(NewFileStorage(dir), "index.db"); !ok || got != filepath.Join(dir, "index.db") {
+			t.Errorf("Unexpected file path: %s", got)
+		}
+		if _, ok := FilePath(NewMemoryStorage(), "index.db"); ok {
+			t.Error("Expected memory storage to keep no files")
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/diff"
+)
+
+// Symbol is imported from the diff package
+type Symbol = diff.Symbol
+
+// GetSymbols lists the functions, types, or document sections of a file with
+// their line ranges. Files in unrecognized languages have no symbols.
+func (a *App) GetSymbols(path string) ([]Symbol, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file path cannot be empty")
+	}
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file: %w", err)
+	}
+	return diff.FindSymbols(lines, diff.LanguageForPath(path)), nil
+}
+
+// CompareSymbols diffs only the lines of one symbol in the left file against
+// one symbol in the right file, so a function can be compared even after it
+// moved. An empty rightSymbol compares the symbol of the same name. Line
+// numbers in the result refer to the full files.
+func (a *App) CompareSymbols(leftPath, rightPath, leftSymbol, rightSymbol string) (*DiffResult, error) {
+	if leftPath == "" || rightPath == "" {
+		return nil, fmt.Errorf("file paths cannot be empty")
+	}
+	if leftSymbol == "" {
+		return nil, fmt.Errorf("symbol name cannot be empty")
+	}
+	if rightSymbol == "" {
+		rightSymbol = leftSymbol
+	}
+
+	leftLines, left, err := a.findSymbolLines(leftPath, leftSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("error reading left file: %w", err)
+	}
+	rightLines, right, err := a.findSymbolLines(rightPath, rightSymbol)
+	if err != nil {
+		return nil, fmt.Errorf("error reading right file: %w", err)
+	}
+
+	algorithm := a.algorithmFor(a.resolveCompareOptions(CompareOptions{}))
+	result := algorithm.ComputeDiff(leftLines, rightLines)
+	diff.OffsetLineNumbers(result, left.StartLine-1, right.StartLine-1)
+	return result, nil
+}
+
+// findSymbolLines returns the lines of the named symbol in a file
+func (a *App) findSymbolLines(path, name string) ([]string, Symbol, error) {
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return nil, Symbol{}, err
+	}
+	symbol, ok := diff.FindSymbol(diff.FindSymbols(lines, diff.LanguageForPath(path)), name)
+	if !ok {
+		return nil, Symbol{}, fmt.Errorf("symbol %q not found in %s", name, path)
+	}
+	return lines[symbol.StartLine-1 : symbol.EndLine], symbol, nil
+}
+
+// OutlineNode is imported from the diff package
+type OutlineNode = diff.OutlineNode
+
+// GetOutline returns the symbols of a file nested under the symbols that
+// enclose them. When the file is part of the current comparison each entry
+// also reports whether its lines were changed, added, or removed.
+func (a *App) GetOutline(path string) ([]OutlineNode, error) {
+	symbols, err := a.GetSymbols(path)
+	if err != nil {
+		return nil, err
+	}
+
+	side := ""
+	if current := a.compare.Current(); current != nil {
+		switch path {
+		case current.LeftPath:
+			side = "left"
+		case current.RightPath:
+			side = "right"
+		}
+	}
+
+	var result *DiffResult
+	if side != "" {
+		current, err := a.currentComparison()
+		if err != nil {
+			return nil, err
+		}
+		result = current.Result
+	}
+	return diff.BuildOutline(symbols, result, side), nil
+}
@@ -0,0 +1,111 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApp_CompareSymbols(t *testing.T) {
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.go")
+	rightPath := filepath.Join(dir, "right.go")
+	left := "package main\n\nfunc helper() {\n}\n\nfunc run() {\n\tfmt.Println(1)\n}\n"
+	right := "package main\n\nfunc run() {\n\tfmt.Println(2)\n}\n\nfunc helper() {\n}\n"
+	if err := os.WriteFile(leftPath, []byte(left), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte(right), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	app := NewApp()
+
+	t.Run("lists symbols", func(t *testing.T) {
+		symbols, err := app.GetSymbols(leftPath)
+		if err != nil {
+			t.Fatalf("GetSymbols returned error: %v", err)
+		}
+		if len(symbols) != 2 || symbols[0].Name != "helper" || symbols[1].Name != "run" {
+			t.Errorf("Unexpected symbols: %+v", symbols)
+		}
+	})
+
+	t.Run("compares a moved function", func(t *testing.T) {
+		result, err := app.CompareSymbols(leftPath, rightPath, "run", "")
+		if err != nil {
+			t.Fatalf("CompareSymbols returned error: %v", err)
+		}
+		var removed, added int
+		for _, line := range result.Lines {
+			switch line.Type {
+			case "removed", "modified":
+				if line.LeftNumber != 7 || !strings.Contains(line.LeftLine, "1") {
+					t.Errorf("Unexpected left line: %+v", line)
+				}
+				removed++
+			}
+			if line.Type == "added" || line.Type == "modified" {
+				if line.RightNumber != 4 || !strings.Contains(line.RightLine, "2") {
+					t.Errorf("Unexpected right line: %+v", line)
+				}
+				added++
+			}
+		}
+		if removed == 0 || added == 0 {
+			t.Errorf("Expected the changed body line, got %+v", result.Lines)
+		}
+		if first := result.Lines[0]; first.LeftNumber != 6 || first.RightNumber != 3 {
+			t.Errorf("Expected line numbers from the full files, got %+v", first)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		if _, err := app.CompareSymbols(leftPath, rightPath, "missing", ""); err == nil {
+			t.Error("Expected an error for a missing symbol")
+		}
+		if _, err := app.CompareSymbols(leftPath, rightPath, "", ""); err == nil {
+			t.Error("Expected an error for an empty symbol name")
+		}
+		if _, err := app.GetSymbols(""); err == nil {
+			t.Error("Expected an error for an empty path")
+		}
+	})
+}
+
+func TestApp_GetOutline(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	left := []string{"# Title", "## Keep", "text", "## Edit", "old"}
+	right := []string{"# Title", "## Keep", "text", "## Edit", "new"}
+	leftPath, rightPath := compareTempFiles(t, app, left, right)
+	notesPath := filepath.Join(filepath.Dir(leftPath), "notes.md")
+	if err := os.WriteFile(notesPath, []byte(strings.Join(left, "\n")), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	// compareTempFiles writes .txt files, which have no symbols
+	outline, err := app.GetOutline(rightPath)
+	if err != nil {
+		t.Fatalf("GetOutline returned error: %v", err)
+	}
+	if len(outline) != 0 {
+		t.Errorf("Expected no outline for a text file, got %+v", outline)
+	}
+
+	if _, err := app.CompareFiles(notesPath, rightPath); err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	outline, err = app.GetOutline(notesPath)
+	if err != nil {
+		t.Fatalf("GetOutline returned error: %v", err)
+	}
+	if len(outline) != 1 || outline[0].Status != "modified" || len(outline[0].Children) != 2 {
+		t.Fatalf("Unexpected outline: %+v", outline)
+	}
+	if keep, edit := outline[0].Children[0], outline[0].Children[1]; keep.Status != "same" || edit.Status != "modified" {
+		t.Errorf("Unexpected section statuses: %+v, %+v", keep, edit)
+	}
+}
@@ -0,0 +1,266 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"weld/backend/diff"
+)
+
+// maxTailPendingLines is how many appended lines of a file are held back
+// waiting for the other file to catch up before they are reported as they
+// are, so a file the other never matches again does not pile up
+const maxTailPendingLines = 5000
+
+// TailUpdate is what a tail comparison settled since the previous update:
+// appended lines paired up as far as both files agree again, numbered as in
+// the files
+type TailUpdate struct {
+	LeftPath  string     `json:"leftPath"`
+	RightPath string     `json:"rightPath"`
+	Lines     []DiffLine `json:"lines"`
+	// Appended lines held back because the other file may still catch up
+	PendingLeft  int `json:"pendingLeft"`
+	PendingRight int `json:"pendingRight"`
+}
+
+// tailFile is one file followed by a tail comparison
+type tailFile struct {
+	path    string
+	offset  int64    // bytes read so far, always at the start of a line
+	settled int      // lines before the pending ones
+	pending []string // lines read but not yet reported
+}
+
+// tailSession follows two growing files, comparing only what is appended
+type tailSession struct {
+	left, right tailFile
+	algorithm   diff.Algorithm
+	stop        chan struct{}
+	mu          sync.Mutex // serializes advances
+}
+
+// StartTailComparison follows two growing files, such as the logs of two
+// services, from their current ends. On each poll interval the lines
+// appended to either file since the previous check are compared with those
+// appended to the other, and what settles is delivered through the
+// "tail-diff" event as a TailUpdate. Only appended lines are read, so files
+// of any size can be followed. Following replaces any tail comparison
+// already running.
+func (a *App) StartTailComparison(leftPath, rightPath string) error {
+	if leftPath == "" || rightPath == "" {
+		return fmt.Errorf("both files are required")
+	}
+	session := &tailSession{
+		algorithm: a.algorithmFor(a.resolveCompareOptions(CompareOptions{})),
+		stop:      make(chan struct{}),
+	}
+	for _, file := range []struct {
+		tail *tailFile
+		path string
+	}{{&session.left, leftPath}, {&session.right, rightPath}} {
+		if IsVirtualPath(file.path) {
+			return fmt.Errorf("cannot follow a virtual file: %s", file.path)
+		}
+		if isBinary, err := IsBinaryFile(file.path); err != nil {
+			return fmt.Errorf("error checking file type: %w", err)
+		} else if isBinary {
+			return fmt.Errorf("cannot follow binary file: %s", file.path)
+		}
+		offset, lines, err := tailStart(file.path)
+		if err != nil {
+			return err
+		}
+		*file.tail = tailFile{path: file.path, offset: offset, settled: lines}
+	}
+
+	interval := time.Duration(a.GetPollInterval()) * time.Millisecond
+	a.tailMutex.Lock()
+	if a.tail != nil {
+		close(a.tail.stop)
+	}
+	a.tail = session
+	a.tailMutex.Unlock()
+
+	go a.followTails(session, interval)
+	return nil
+}
+
+// StopTailComparison stops following the files of a tail comparison
+func (a *App) StopTailComparison() {
+	a.tailMutex.Lock()
+	defer a.tailMutex.Unlock()
+	if a.tail != nil {
+		close(a.tail.stop)
+		a.tail = nil
+	}
+}
+
+// followTails advances a tail comparison on every interval until it is
+// stopped. An error, such as a log rotated away, is reported once until the
+// file can be read again.
+func (a *App) followTails(session *tailSession, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	lastError := ""
+	for {
+		select {
+		case <-session.stop:
+			return
+		case <-ticker.C:
+			update, err := session.advance()
+			if err != nil {
+				if err.Error() != lastError {
+					lastError = err.Error()
+					a.runtime().EventsEmit("tail-error", map[string]string{
+						"leftPath":  session.left.path,
+						"rightPath": session.right.path,
+						"error":     lastError,
+					})
+				}
+				continue
+			}
+			lastError = ""
+			if update != nil {
+				a.runtime().EventsEmit("tail-diff", update)
+			}
+		}
+	}
+}
+
+// advance reads the lines appended to both files and compares them with
+// those still pending. Lines up to the last pair that match are settled,
+// since later appends cannot change how they pair up; the rest wait for the
+// next advance. It returns nil when nothing was appended.
+func (s *tailSession) advance() (*TailUpdate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	leftAppended, err := s.left.readAppended()
+	if err != nil {
+		return nil, err
+	}
+	rightAppended, err := s.right.readAppended()
+	if err != nil {
+		return nil, err
+	}
+	if !leftAppended && !rightAppended {
+		return nil, nil
+	}
+
+	result := s.algorithm.ComputeDiff(s.left.pending, s.right.pending)
+	settle := 0
+	if len(s.left.pending) > maxTailPendingLines || len(s.right.pending) > maxTailPendingLines {
+		settle = len(result.Lines)
+	} else {
+		for i, line := range result.Lines {
+			if line.Type == "same" {
+				settle = i + 1
+			}
+		}
+	}
+
+	settled := &DiffResult{Lines: result.Lines[:settle]}
+	diff.OffsetLineNumbers(settled, s.left.settled, s.right.settled)
+	var leftCount, rightCount int
+	for _, line := range settled.Lines {
+		if line.LeftNumber > 0 {
+			leftCount++
+		}
+		if line.RightNumber > 0 {
+			rightCount++
+		}
+	}
+	s.left.settle(leftCount)
+	s.right.settle(rightCount)
+
+	return &TailUpdate{
+		LeftPath:     s.left.path,
+		RightPath:    s.right.path,
+		Lines:        settled.Lines,
+		PendingLeft:  len(s.left.pending),
+		PendingRight: len(s.right.pending),
+	}, nil
+}
+
+// readAppended adds the complete lines appended to the file since the
+// previous read to its pending lines, reporting whether there were any. A
+// file that shrank was truncated or replaced, as when a log rotates, and is
+// followed again from its start.
+func (f *tailFile) readAppended() (bool, error) {
+	info, err := os.Stat(f.path)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < f.offset {
+		*f = tailFile{path: f.path}
+	}
+	if info.Size() == f.offset {
+		return false, nil
+	}
+
+	file, err := os.Open(f.path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	if _, err := file.Seek(f.offset, io.SeekStart); err != nil {
+		return false, err
+	}
+	data, err := io.ReadAll(io.LimitReader(file, info.Size()-f.offset))
+	if err != nil {
+		return false, err
+	}
+
+	// A line still being written is read once it is complete
+	end := bytes.LastIndexByte(data, '\n')
+	if end < 0 {
+		return false, nil
+	}
+	f.offset += int64(end + 1)
+	for _, line := range strings.Split(string(data[:end]), "\n") {
+		f.pending = append(f.pending, strings.TrimSuffix(line, "\r"))
+	}
+	return true, nil
+}
+
+// settle drops the first n pending lines, which have been reported
+func (f *tailFile) settle(n int) {
+	f.settled += n
+	f.pending = f.pending[n:]
+}
+
+// tailStart returns the offset just after the last complete line of a file
+// and the number of lines before it
+func tailStart(path string) (int64, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer file.Close()
+
+	var offset, read int64
+	lines := 0
+	buf := make([]byte, 64*1024)
+	for {
+		n, err := file.Read(buf)
+		chunk := buf[:n]
+		lines += bytes.Count(chunk, []byte{'\n'})
+		if last := bytes.LastIndexByte(chunk, '\n'); last >= 0 {
+			offset = read + int64(last) + 1
+		}
+		read += int64(n)
+		if err == io.EOF {
+			return offset, lines, nil
+		}
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// newTailComparison starts following two files with the given contents,
+// with a poll interval long enough that the test advances it by hand
+func newTailComparison(t *testing.T, left, right string) (*App, *tailSession, string, string) {
+	t.Helper()
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	if err := app.SetPollInterval(3600000); err != nil {
+		t.Fatalf("SetPollInterval returned error: %v", err)
+	}
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.log")
+	rightPath := filepath.Join(dir, "right.log")
+	if err := os.WriteFile(leftPath, []byte(left), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte(right), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := app.StartTailComparison(leftPath, rightPath); err != nil {
+		t.Fatalf("StartTailComparison returned error: %v", err)
+	}
+	t.Cleanup(app.StopTailComparison)
+	return app, app.tail, leftPath, rightPath
+}
+
+// appendText appends text to a file
+func appendText(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatalf("Failed to open file: %v", err)
+	}
+	defer file.Close()
+	if _, err := file.WriteString(text); err != nil {
+		t.Fatalf("Failed to append to file: %v", err)
+	}
+}
+
+func TestApp_StartTailComparison(t *testing.T) {
+	t.Run("requires both files", func(t *testing.T) {
+		if err := NewApp().StartTailComparison("", "right.log"); err == nil {
+			t.Error("Expected error without a left file")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if err := NewApp().StartTailComparison(filepath.Join(t.TempDir(), "missing.log"), filepath.Join(t.TempDir(), "missing.log")); err == nil {
+			t.Error("Expected error for missing files")
+		}
+	})
+
+	t.Run("starts at the last complete line", func(t *testing.T) {
+		_, session, _, _ := newTailComparison(t, "one\ntwo\npart", "one\n")
+		if session.left.offset != 8 || session.left.settled != 2 {
+			t.Errorf("Expected the left file followed from line 3 at byte 8, got %+v", session.left)
+		}
+		if session.right.offset != 4 || session.right.settled != 1 {
+			t.Errorf("Expected the right file followed from line 2 at byte 4, got %+v", session.right)
+		}
+	})
+
+	t.Run("restarting replaces the comparison", func(t *testing.T) {
+		app, session, leftPath, rightPath := newTailComparison(t, "", "")
+		if err := app.StartTailComparison(leftPath, rightPath); err != nil {
+			t.Fatalf("StartTailComparison returned error: %v", err)
+		}
+		select {
+		case <-session.stop:
+		default:
+			t.Error("Expected the previous comparison to be stopped")
+		}
+		app.StopTailComparison()
+		if app.tail != nil {
+			t.Error("Expected no tail comparison after stopping")
+		}
+	})
+}
+
+func TestTailSession_Advance(t *testing.T) {
+	_, session, leftPath, rightPath := newTailComparison(t, "boot\n", "boot\n")
+
+	update, err := session.advance()
+	if err != nil || update != nil {
+		t.Fatalf("Expected no update before anything is appended, got %+v, %v", update, err)
+	}
+
+	// The right log is behind, so the left line waits for it
+	appendText(t, leftPath, "request a\n")
+	update, err = session.advance()
+	if err != nil {
+		t.Fatalf("advance returned error: %v", err)
+	}
+	if len(update.Lines) != 0 || update.PendingLeft != 1 || update.PendingRight != 0 {
+		t.Errorf("Expected the appended line to be held back, got %+v", update)
+	}
+
+	appendText(t, rightPath, "request a\nrequest b\n")
+	appendText(t, leftPath, "request c\npart")
+	update, err = session.advance()
+	if err != nil {
+		t.Fatalf("advance returned error: %v", err)
+	}
+	if len(update.Lines) != 1 || update.Lines[0].Type != "same" || update.Lines[0].LeftNumber != 2 || update.Lines[0].RightNumber != 2 {
+		t.Errorf("Expected the matching line settled at line 2, got %+v", update.Lines)
+	}
+	if update.PendingLeft != 1 || update.PendingRight != 1 {
+		t.Errorf("Expected the diverging lines to be held back, got %+v", update)
+	}
+
+	// The partial line is read once it is complete
+	appendText(t, leftPath, "ial\n")
+	appendText(t, rightPath, "partial\n")
+	update, err = session.advance()
+	if err != nil {
+		t.Fatalf("advance returned error: %v", err)
+	}
+	if len(update.Lines) != 3 || update.PendingLeft != 0 || update.PendingRight != 0 {
+		t.Fatalf("Expected the divergence and the match to settle, got %+v", update)
+	}
+	if last := update.Lines[2]; last.Type != "same" || last.LeftLine != "partial" || last.LeftNumber != 4 || last.RightNumber != 4 {
+		t.Errorf("Expected the completed line to match at line 4, got %+v", last)
+	}
+
+	t.Run("truncated files are followed from the start", func(t *testing.T) {
+		if err := os.WriteFile(leftPath, []byte("rotated\n"), 0644); err != nil {
+			t.Fatalf("Failed to rewrite file: %v", err)
+		}
+		update, err := session.advance()
+		if err != nil {
+			t.Fatalf("advance returned error: %v", err)
+		}
+		if update.PendingLeft != 1 || session.left.settled != 0 {
+			t.Errorf("Expected the rewritten file to be read from its start, got %+v", update)
+		}
+	})
+
+	t.Run("removed files report an error", func(t *testing.T) {
+		if err := os.Remove(rightPath); err != nil {
+			t.Fatalf("Failed to remove file: %v", err)
+		}
+		if _, err := session.advance(); err == nil {
+			t.Error("Expected error for a removed file")
+		}
+	})
+}
@@ -0,0 +1,39 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"weld/backend/diff"
+)
+
+// ThreeWayResult is now imported from the diff package
+type ThreeWayResult = diff.ThreeWayResult
+
+// CompareThreeFiles compares the left and right files with their common
+// ancestor, classifying each change as left-only, right-only, made the same
+// way on both sides, or conflicting
+func (a *App) CompareThreeFiles(basePath, leftPath, rightPath string) (*ThreeWayResult, error) {
+	if basePath == "" || leftPath == "" || rightPath == "" {
+		return nil, fmt.Errorf("file paths cannot be empty")
+	}
+
+	files := make([][]string, 0, 3)
+	for _, file := range []struct{ side, path string }{{"base", basePath}, {"left", leftPath}, {"right", rightPath}} {
+		binary, err := IsBinaryFile(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("error checking %s file type: %w", file.side, err)
+		}
+		if binary {
+			return nil, fmt.Errorf("cannot compare binary file: %s", filepath.Base(file.path))
+		}
+		lines, err := a.ReadFileContentWithCache(file.path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s file: %w", file.side, err)
+		}
+		files = append(files, lines)
+	}
+
+	algorithm := algorithmForSize(a.algorithmFor(a.resolveCompareOptions(CompareOptions{})), files...)
+	return diff.CompareThree(files[0], files[1], files[2], algorithm), nil
+}
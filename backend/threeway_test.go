@@ -0,0 +1,47 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_CompareThreeFiles(t *testing.T) {
+	app := NewApp()
+
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		return path
+	}
+	base := write("base.txt", "one\ntwo\nthree\nfour")
+	left := write("left.txt", "one\nTWO\nthree\nfour\nleft")
+	right := write("right.txt", "one\ntwo\nthree\nFOUR\nright")
+
+	result, err := app.CompareThreeFiles(base, left, right)
+	if err != nil {
+		t.Fatalf("CompareThreeFiles returned error: %v", err)
+	}
+	var leftOnly, conflicts int
+	for _, chunk := range result.Chunks {
+		switch chunk.Type {
+		case "left-only":
+			leftOnly++
+		case "conflict":
+			conflicts++
+		}
+	}
+	if leftOnly != 1 || conflicts != 1 || result.Conflicts != 1 {
+		t.Errorf("Expected one left-only change and one conflict, got %+v", result.Chunks)
+	}
+
+	if _, err := app.CompareThreeFiles("", left, right); err == nil {
+		t.Error("Expected an error for an empty path")
+	}
+	if _, err := app.CompareThreeFiles(filepath.Join(dir, "missing.txt"), left, right); err == nil {
+		t.Error("Expected an error for a missing base file")
+	}
+}
@@ -0,0 +1,137 @@
+package backend
+
+import (
+	"fmt"
+
+	"weld/backend/fileio"
+)
+
+// Bounds and default of how many operation groups are kept for undo, and
+// as many again for redo
+const (
+	defaultUndoHistorySize = 50
+	maxUndoHistorySize     = 1000
+)
+
+// GetUndoHistorySize returns how many operation groups are kept for undo
+func (a *App) GetUndoHistorySize() int {
+	return a.settings.Get().UndoHistorySize
+}
+
+// SetUndoHistorySize sets how many operation groups are kept for undo and
+// for redo. The oldest groups beyond the new size are dropped right away.
+func (a *App) SetUndoHistorySize(size int) error {
+	if size < 1 || size > maxUndoHistorySize {
+		return fmt.Errorf("undo history size must be between 1 and %d", maxUndoHistorySize)
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.UndoHistorySize = size
+		return nil
+	})
+
+	a.history.Resize(size)
+	a.refreshMenu()
+	return err
+}
+
+// GetPersistUndoHistory returns whether undo and redo history is kept in
+// the session file between runs
+func (a *App) GetPersistUndoHistory() bool {
+	return a.settings.Get().PersistUndoHistory
+}
+
+// SetPersistUndoHistory sets whether undo and redo history is kept in the
+// session file between runs. Turning it off removes any history stored.
+func (a *App) SetPersistUndoHistory(persist bool) error {
+	err := a.settings.Update(func(s *Settings) error {
+		s.PersistUndoHistory = persist
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if persist {
+		return a.saveUndoHistory()
+	}
+
+	return a.session.Update(func(s *Session) error {
+		s.UndoHistory = nil
+		s.RedoHistory = nil
+		s.UndoFileHashes = nil
+		return nil
+	})
+}
+
+// saveUndoHistory stores the undo and redo history in the session when it
+// is persisted, with the hash of each file it edits. Undo replays edits by
+// line number, so history whose files have unsaved changes, which are lost
+// on exit, is not stored.
+func (a *App) saveUndoHistory() error {
+	if !a.GetPersistUndoHistory() {
+		return nil
+	}
+
+	undo, redo := a.history.Snapshot()
+
+	hashes := make(map[string]string)
+	for _, path := range historyFiles(undo, redo) {
+		hash, err := fileio.Hash(path)
+		if err != nil || a.HasUnsavedChanges(path) {
+			undo, redo, hashes = nil, nil, nil
+			break
+		}
+		hashes[path] = hash
+	}
+
+	return a.session.Update(func(s *Session) error {
+		s.UndoHistory = undo
+		s.RedoHistory = redo
+		s.UndoFileHashes = hashes
+		return nil
+	})
+}
+
+// restoreUndoHistory applies the configured history size and, when history
+// is persisted, restores the history stored in the session, as long as none
+// of its files changed since it was stored
+func (a *App) restoreUndoHistory() {
+	var undo, redo []OperationGroup
+	var hashes map[string]string
+	a.session.View(func(s *Session) {
+		undo, redo = s.UndoHistory, s.RedoHistory
+		hashes = s.UndoFileHashes
+	})
+
+	if !a.GetPersistUndoHistory() {
+		undo, redo = nil, nil
+	}
+	for _, path := range historyFiles(undo, redo) {
+		if hash, err := fileio.Hash(path); err != nil || hash != hashes[path] {
+			a.runtime().LogWarningf("Discarding stored undo history: %s changed since it was saved", path)
+			undo, redo = nil, nil
+			break
+		}
+	}
+
+	a.history.Resize(a.GetUndoHistorySize())
+	if len(undo) > 0 || len(redo) > 0 {
+		a.history.Restore(undo, redo)
+	}
+}
+
+// historyFiles returns the files edited by the operations of histories
+func historyFiles(histories ...[]OperationGroup) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, history := range histories {
+		for _, group := range history {
+			for _, op := range group.Operations {
+				if op.TargetFile != "" && !seen[op.TargetFile] {
+					seen[op.TargetFile] = true
+					files = append(files, op.TargetFile)
+				}
+			}
+		}
+	}
+	return files
+}
@@ -0,0 +1,171 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// clearHistory replaces the app's undo history with an empty one
+func clearHistory(app *App) {
+	app.history = app.newHistory()
+}
+
+// undoStack returns the app's undo history, oldest first
+func undoStack(app *App) []OperationGroup {
+	undo, _ := app.history.Snapshot()
+	return undo
+}
+
+// redoStack returns the app's redo history, oldest first
+func redoStack(app *App) []OperationGroup {
+	_, redo := app.history.Snapshot()
+	return redo
+}
+
+// undoGroups returns n operation groups copying lines into target
+func undoGroups(n int, target string) []OperationGroup {
+	groups := make([]OperationGroup, n)
+	for i := range groups {
+		groups[i] = OperationGroup{
+			ID:          fmt.Sprintf("group-%d", i),
+			Description: fmt.Sprintf("Copy %d", i),
+			Operations:  []SingleOperation{{Type: OpCopy, TargetFile: target, LineNumber: i + 1, InsertIndex: i + 1}},
+		}
+	}
+	return groups
+}
+
+func TestApp_SetUndoHistorySize(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+
+	if got := app.GetUndoHistorySize(); got != defaultUndoHistorySize {
+		t.Errorf("Expected default size %d, got %d", defaultUndoHistorySize, got)
+	}
+	for _, size := range []int{0, maxUndoHistorySize + 1} {
+		if err := app.SetUndoHistorySize(size); err == nil {
+			t.Errorf("Expected error for size %d", size)
+		}
+	}
+
+	app.history.Restore(undoGroups(5, "target.txt"), undoGroups(4, "target.txt"))
+
+	if err := app.SetUndoHistorySize(3); err != nil {
+		t.Fatalf("SetUndoHistorySize returned error: %v", err)
+	}
+	if got := app.GetUndoHistorySize(); got != 3 {
+		t.Errorf("Expected size 3, got %d", got)
+	}
+	undo, redo := app.history.Snapshot()
+	if len(undo) != 3 || undo[0].ID != "group-2" {
+		t.Errorf("Expected the three newest undo groups, got %+v", undo)
+	}
+	if len(redo) != 3 || redo[0].ID != "group-1" {
+		t.Errorf("Expected the three newest redo groups, got %+v", redo)
+	}
+
+	// New groups are kept within the new size
+	app.recordOperation(SingleOperation{Type: OpCopy, TargetFile: "target.txt", LineNumber: 1, InsertIndex: 1})
+	if count := len(undoStack(app)); count != 3 {
+		t.Errorf("Expected history to stay at 3 groups, got %d", count)
+	}
+}
+
+func TestApp_PersistUndoHistory(t *testing.T) {
+	storage := NewMemoryStorage()
+	app := NewApp()
+	app.Storage = storage
+
+	target := filepath.Join(t.TempDir(), "target.txt")
+	if err := os.WriteFile(target, []byte("one\ntwo\n"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	app.history.Restore(undoGroups(2, target), undoGroups(1, target))
+
+	// restart loads what app stored into a fresh app, as at startup
+	restart := func(t *testing.T) (undo, redo []OperationGroup) {
+		t.Helper()
+		next := NewApp()
+		next.Storage = storage
+		if err := next.loadSettings(); err != nil {
+			t.Fatalf("loadSettings returned error: %v", err)
+		}
+		if err := next.session.Load(); err != nil {
+			t.Fatalf("Load returned error: %v", err)
+		}
+		next.restoreUndoHistory()
+		return next.history.Snapshot()
+	}
+	// keep puts the history back for the next subtest
+	keep := func() {
+		app.history.Restore(undoGroups(2, target), undoGroups(1, target))
+	}
+
+	t.Run("off by default", func(t *testing.T) {
+		if app.GetPersistUndoHistory() {
+			t.Error("Expected undo history not to be persisted by default")
+		}
+		if err := app.saveUndoHistory(); err != nil {
+			t.Fatalf("saveUndoHistory returned error: %v", err)
+		}
+		if undo, redo := restart(t); len(undo) != 0 || len(redo) != 0 {
+			t.Errorf("Expected no history restored, got %d and %d groups", len(undo), len(redo))
+		}
+		keep()
+	})
+
+	t.Run("restored across runs", func(t *testing.T) {
+		if err := app.SetPersistUndoHistory(true); err != nil {
+			t.Fatalf("SetPersistUndoHistory returned error: %v", err)
+		}
+		undo, redo := restart(t)
+		if len(undo) != 2 || len(redo) != 1 || undo[1].Operations[0].TargetFile != target {
+			t.Errorf("Expected the history to be restored, got %+v and %+v", undo, redo)
+		}
+		keep()
+	})
+
+	t.Run("unsaved changes are not stored", func(t *testing.T) {
+		app.compare.SetEdited(target, []string{"one", "two", "three"})
+		if err := app.saveUndoHistory(); err != nil {
+			t.Fatalf("saveUndoHistory returned error: %v", err)
+		}
+		if undo, _ := restart(t); len(undo) != 0 {
+			t.Errorf("Expected no history for files with unsaved changes, got %+v", undo)
+		}
+		app.compare.DiscardAll()
+		keep()
+	})
+
+	t.Run("changed files discard the history", func(t *testing.T) {
+		if err := app.saveUndoHistory(); err != nil {
+			t.Fatalf("saveUndoHistory returned error: %v", err)
+		}
+		if err := os.WriteFile(target, []byte("edited elsewhere\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if undo, redo := restart(t); len(undo) != 0 || len(redo) != 0 {
+			t.Errorf("Expected stale history to be discarded, got %d and %d groups", len(undo), len(redo))
+		}
+		keep()
+	})
+
+	t.Run("turning it off removes the stored history", func(t *testing.T) {
+		if err := os.WriteFile(target, []byte("one\ntwo\n"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		if err := app.SetPersistUndoHistory(true); err != nil {
+			t.Fatalf("SetPersistUndoHistory returned error: %v", err)
+		}
+		if err := app.SetPersistUndoHistory(false); err != nil {
+			t.Fatalf("SetPersistUndoHistory returned error: %v", err)
+		}
+		var stored []OperationGroup
+		app.session.View(func(s *Session) { stored = s.UndoHistory })
+		if stored != nil {
+			t.Errorf("Expected the stored history to be removed, got %+v", stored)
+		}
+	})
+}
@@ -5,23 +5,19 @@ import (
 )
 
 func TestApp_UndoOperations(t *testing.T) {
-	app := &App{}
-
-	// Reset global state
-	operationHistory = []OperationGroup{}
-	currentTransaction = nil
-	isUndoing.Store(false)
+	app := NewApp()
 
 	t.Run("BeginOperationGroup", func(t *testing.T) {
 		id := app.BeginOperationGroup("Test operation")
 		if id == "" {
 			t.Error("Expected non-empty ID")
 		}
-		if currentTransaction == nil {
-			t.Error("Expected currentTransaction to be set")
+		group, ok := app.history.Pending()
+		if !ok {
+			t.Fatal("Expected an open operation group")
 		}
-		if currentTransaction.Description != "Test operation" {
-			t.Errorf("Expected description 'Test operation', got %s", currentTransaction.Description)
+		if group.Description != "Test operation" {
+			t.Errorf("Expected description 'Test operation', got %s", group.Description)
 		}
 	})
 
@@ -42,19 +38,19 @@ func TestApp_UndoOperations(t *testing.T) {
 		// Commit
 		app.CommitOperationGroup()
 
-		if currentTransaction != nil {
-			t.Error("Expected currentTransaction to be nil after commit")
+		if _, ok := app.history.Pending(); ok {
+			t.Error("Expected no open operation group after commit")
 		}
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in history, got %d", len(undoStack(app)))
 		}
-		if operationHistory[0].Description != "Test commit" {
-			t.Errorf("Expected description 'Test commit', got %s", operationHistory[0].Description)
+		if undoStack(app)[0].Description != "Test commit" {
+			t.Errorf("Expected description 'Test commit', got %s", undoStack(app)[0].Description)
 		}
 	})
 
 	t.Run("RollbackOperationGroup", func(t *testing.T) {
-		historyBefore := len(operationHistory)
+		historyBefore := len(undoStack(app))
 
 		app.BeginOperationGroup("Test rollback")
 		app.recordOperation(SingleOperation{
@@ -68,17 +64,17 @@ func TestApp_UndoOperations(t *testing.T) {
 
 		app.RollbackOperationGroup()
 
-		if currentTransaction != nil {
-			t.Error("Expected currentTransaction to be nil after rollback")
+		if _, ok := app.history.Pending(); ok {
+			t.Error("Expected no open operation group after rollback")
 		}
-		if len(operationHistory) != historyBefore {
+		if len(undoStack(app)) != historyBefore {
 			t.Error("Expected operation history to remain unchanged after rollback")
 		}
 	})
 
 	t.Run("CanUndo", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
+		clearHistory(app)
 
 		if app.CanUndo() {
 			t.Error("Expected CanUndo to return false with empty history")
@@ -103,7 +99,7 @@ func TestApp_UndoOperations(t *testing.T) {
 
 	t.Run("GetLastOperationDescription", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
+		clearHistory(app)
 
 		if app.GetLastOperationDescription() != "" {
 			t.Error("Expected empty description with no operations")
@@ -128,8 +124,7 @@ func TestApp_UndoOperations(t *testing.T) {
 
 	t.Run("recordOperation_SingleOperation", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
-		currentTransaction = nil
+		clearHistory(app)
 
 		// Record without transaction
 		app.recordOperation(SingleOperation{
@@ -141,42 +136,46 @@ func TestApp_UndoOperations(t *testing.T) {
 			InsertIndex: 1,
 		})
 
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in history, got %d", len(undoStack(app)))
 		}
-		if operationHistory[0].Description != "copy line" {
-			t.Errorf("Expected 'copy line', got %s", operationHistory[0].Description)
+		if undoStack(app)[0].Description != "copy line" {
+			t.Errorf("Expected 'copy line', got %s", undoStack(app)[0].Description)
 		}
 	})
 
 	t.Run("recordOperation_DuringUndo", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
-		isUndoing.Store(true)
+		clearHistory(app)
+		app.history.Restore(undoGroups(1, "target.txt"), nil)
 
 		// Try to record during undo
-		app.recordOperation(SingleOperation{
-			Type:        OpCopy,
-			SourceFile:  "source.txt",
-			TargetFile:  "target.txt",
-			LineNumber:  1,
-			LineContent: "test line",
-			InsertIndex: 1,
+		err := app.history.Undo(func(OperationGroup) error {
+			app.recordOperation(SingleOperation{
+				Type:        OpCopy,
+				SourceFile:  "source.txt",
+				TargetFile:  "target.txt",
+				LineNumber:  1,
+				LineContent: "test line",
+				InsertIndex: 1,
+			})
+			return nil
 		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
 
-		if len(operationHistory) != 0 {
+		if len(undoStack(app)) != 0 {
 			t.Error("Expected no operations to be recorded during undo")
 		}
-
-		isUndoing.Store(false)
 	})
 
 	t.Run("MaxHistorySize", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
+		clearHistory(app)
 
-		// Add more than maxHistorySize operations
-		for i := 0; i < maxHistorySize+10; i++ {
+		// Add more than defaultUndoHistorySize operations
+		for i := 0; i < defaultUndoHistorySize+10; i++ {
 			app.recordOperation(SingleOperation{
 				Type:        OpCopy,
 				SourceFile:  "source.txt",
@@ -187,18 +186,18 @@ func TestApp_UndoOperations(t *testing.T) {
 			})
 		}
 
-		if len(operationHistory) != maxHistorySize {
-			t.Errorf("Expected history size to be capped at %d, got %d", maxHistorySize, len(operationHistory))
+		if len(undoStack(app)) != defaultUndoHistorySize {
+			t.Errorf("Expected history size to be capped at %d, got %d", defaultUndoHistorySize, len(undoStack(app)))
 		}
 	})
 }
 
 func TestApp_UndoLastOperation(t *testing.T) {
-	app := &App{}
+	app := NewApp()
 
 	t.Run("UndoLastOperation_NoOperations", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
+		clearHistory(app)
 
 		err := app.UndoLastOperation()
 		if err == nil {
@@ -208,8 +207,8 @@ func TestApp_UndoLastOperation(t *testing.T) {
 
 	t.Run("UndoLastOperation_CopyOperation", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file state
 		targetLines := []string{"line1", "line2", "line3"}
@@ -234,15 +233,15 @@ func TestApp_UndoLastOperation(t *testing.T) {
 		}
 
 		// Check that the operation was removed from history
-		if len(operationHistory) != 0 {
+		if len(undoStack(app)) != 0 {
 			t.Error("Expected operation to be removed from history")
 		}
 	})
 
 	t.Run("UndoLastOperation_RemoveOperation", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file state (after a line was removed)
 		targetLines := []string{"line1", "line3"}
@@ -267,15 +266,15 @@ func TestApp_UndoLastOperation(t *testing.T) {
 		}
 
 		// Check that the operation was removed from history
-		if len(operationHistory) != 0 {
+		if len(undoStack(app)) != 0 {
 			t.Error("Expected operation to be removed from history")
 		}
 	})
 
 	t.Run("UndoLastOperation_MultipleOperations", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file states
 		leftLines := []string{"left1", "left2", "left3"}
@@ -310,16 +309,15 @@ func TestApp_UndoLastOperation(t *testing.T) {
 		}
 
 		// Check that the operations were removed from history
-		if len(operationHistory) != 0 {
+		if len(undoStack(app)) != 0 {
 			t.Error("Expected operations to be removed from history")
 		}
 	})
 
 	t.Run("UndoLastOperation_SetsUndoingFlag", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
-		isUndoing.Store(false)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Add a simple operation
 		app.recordOperation(SingleOperation{
@@ -340,20 +338,28 @@ func TestApp_UndoLastOperation(t *testing.T) {
 			t.Errorf("Unexpected error: %v", err)
 		}
 
-		// Check that isUndoing was reset
-		if isUndoing.Load() {
-			t.Error("Expected isUndoing to be reset to false after undo")
+		// Operations are recorded again once the undo is done
+		before := len(undoStack(app))
+		app.recordOperation(SingleOperation{
+			Type:        OpCopy,
+			SourceFile:  "source.txt",
+			TargetFile:  "target.txt",
+			LineNumber:  1,
+			LineContent: "test",
+			InsertIndex: 1,
+		})
+		if len(undoStack(app)) != before+1 {
+			t.Error("Expected operations to be recorded again after undo")
 		}
 	})
 }
 
 func TestApp_RedoOperations(t *testing.T) {
-	app := &App{}
+	app := NewApp()
 
 	t.Run("CanRedo", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
+		clearHistory(app)
 
 		if app.CanRedo() {
 			t.Error("Expected CanRedo to return false with empty redo history")
@@ -372,7 +378,7 @@ func TestApp_RedoOperations(t *testing.T) {
 		app.CommitOperationGroup()
 
 		// Set up file state for undo
-		fileCache = make(map[string][]string)
+		app.compare.DiscardAll()
 		app.storeFileInMemory("target.txt", []string{"test line"})
 
 		// Undo to populate redo history
@@ -388,8 +394,7 @@ func TestApp_RedoOperations(t *testing.T) {
 
 	t.Run("GetLastRedoOperationDescription", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
+		clearHistory(app)
 
 		if app.GetLastRedoOperationDescription() != "" {
 			t.Error("Expected empty description with no redo operations")
@@ -408,7 +413,7 @@ func TestApp_RedoOperations(t *testing.T) {
 		app.CommitOperationGroup()
 
 		// Set up file state for undo
-		fileCache = make(map[string][]string)
+		app.compare.DiscardAll()
 		app.storeFileInMemory("target.txt", []string{"test line"})
 
 		// Undo to populate redo history
@@ -424,7 +429,7 @@ func TestApp_RedoOperations(t *testing.T) {
 
 	t.Run("RedoLastOperation_NoOperations", func(t *testing.T) {
 		// Clear redo history
-		redoHistory = []OperationGroup{}
+		clearHistory(app)
 
 		err := app.RedoLastOperation()
 		if err == nil {
@@ -434,9 +439,8 @@ func TestApp_RedoOperations(t *testing.T) {
 
 	t.Run("RedoLastOperation_CopyOperation", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file state
 		targetLines := []string{"line1", "line2", "line3"}
@@ -467,19 +471,18 @@ func TestApp_RedoOperations(t *testing.T) {
 		}
 
 		// Check that the operation was moved back to undo history
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in undo history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in undo history, got %d", len(undoStack(app)))
 		}
-		if len(redoHistory) != 0 {
-			t.Errorf("Expected 0 operations in redo history, got %d", len(redoHistory))
+		if len(redoStack(app)) != 0 {
+			t.Errorf("Expected 0 operations in redo history, got %d", len(redoStack(app)))
 		}
 	})
 
 	t.Run("RedoLastOperation_RemoveOperation", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file state (after a line was removed)
 		targetLines := []string{"line1", "line3"}
@@ -510,20 +513,18 @@ func TestApp_RedoOperations(t *testing.T) {
 		}
 
 		// Check that the operation was moved back to undo history
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in undo history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in undo history, got %d", len(undoStack(app)))
 		}
-		if len(redoHistory) != 0 {
-			t.Errorf("Expected 0 operations in redo history, got %d", len(redoHistory))
+		if len(redoStack(app)) != 0 {
+			t.Errorf("Expected 0 operations in redo history, got %d", len(redoStack(app)))
 		}
 	})
 
 	t.Run("RedoLastOperation_SetsRedoingFlag", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
-		isRedoing.Store(false)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Add a simple operation
 		app.recordOperation(SingleOperation{
@@ -550,17 +551,25 @@ func TestApp_RedoOperations(t *testing.T) {
 			t.Errorf("Unexpected error during redo: %v", err)
 		}
 
-		// Check that isRedoing was reset
-		if isRedoing.Load() {
-			t.Error("Expected isRedoing to be reset to false after redo")
+		// Operations are recorded again once the redo is done
+		before := len(undoStack(app))
+		app.recordOperation(SingleOperation{
+			Type:        OpCopy,
+			SourceFile:  "source.txt",
+			TargetFile:  "target.txt",
+			LineNumber:  1,
+			LineContent: "test",
+			InsertIndex: 1,
+		})
+		if len(undoStack(app)) != before+1 {
+			t.Error("Expected operations to be recorded again after redo")
 		}
 	})
 
 	t.Run("RedoHistory_ClearedOnNewOperation", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Add an operation
 		app.recordOperation(SingleOperation{
@@ -581,7 +590,7 @@ func TestApp_RedoOperations(t *testing.T) {
 			t.Errorf("Unexpected error during undo: %v", err)
 		}
 
-		if len(redoHistory) == 0 {
+		if len(redoStack(app)) == 0 {
 			t.Error("Expected redo history to be populated after undo")
 		}
 
@@ -595,49 +604,51 @@ func TestApp_RedoOperations(t *testing.T) {
 			InsertIndex: 1,
 		})
 
-		if len(redoHistory) != 0 {
+		if len(redoStack(app)) != 0 {
 			t.Error("Expected redo history to be cleared after new operation")
 		}
 	})
 
 	t.Run("recordOperation_DuringRedo", func(t *testing.T) {
 		// Clear history
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		isRedoing.Store(true)
+		clearHistory(app)
+		app.history.Restore(nil, undoGroups(1, "target.txt"))
 
 		// Try to record during redo
-		app.recordOperation(SingleOperation{
-			Type:        OpCopy,
-			SourceFile:  "source.txt",
-			TargetFile:  "target.txt",
-			LineNumber:  1,
-			LineContent: "test line",
-			InsertIndex: 1,
+		err := app.history.Redo(func(OperationGroup) error {
+			app.recordOperation(SingleOperation{
+				Type:        OpCopy,
+				SourceFile:  "source.txt",
+				TargetFile:  "target.txt",
+				LineNumber:  1,
+				LineContent: "test line",
+				InsertIndex: 1,
+			})
+			return nil
 		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
 
-		if len(operationHistory) != 0 {
+		if len(undoStack(app)) != 1 {
 			t.Error("Expected no operations to be recorded during redo")
 		}
-
-		isRedoing.Store(false)
 	})
 
 	t.Run("MaxRedoHistorySize", func(t *testing.T) {
 		// Clear history and reset state
-		operationHistory = []OperationGroup{}
-		redoHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Create initial file with enough lines
-		initialLines := make([]string, maxHistorySize+10)
+		initialLines := make([]string, defaultUndoHistorySize+10)
 		for i := range initialLines {
 			initialLines[i] = "initial line"
 		}
 		app.storeFileInMemory("target.txt", initialLines)
 
-		// Add more than maxHistorySize operations
-		for i := 0; i < maxHistorySize+10; i++ {
+		// Add more than defaultUndoHistorySize operations
+		for i := 0; i < defaultUndoHistorySize+10; i++ {
 			app.recordOperation(SingleOperation{
 				Type:        OpCopy,
 				SourceFile:  "source.txt",
@@ -649,27 +660,26 @@ func TestApp_RedoOperations(t *testing.T) {
 		}
 
 		// Undo all operations to populate redo history
-		for i := 0; i < maxHistorySize+10; i++ {
+		for i := 0; i < defaultUndoHistorySize+10; i++ {
 			err := app.UndoLastOperation()
 			if err != nil {
 				break
 			}
 		}
 
-		if len(redoHistory) != maxHistorySize {
-			t.Errorf("Expected redo history size to be capped at %d, got %d", maxHistorySize, len(redoHistory))
+		if len(redoStack(app)) != defaultUndoHistorySize {
+			t.Errorf("Expected redo history size to be capped at %d, got %d", defaultUndoHistorySize, len(redoStack(app)))
 		}
 	})
 }
 
 func TestApp_IntegrationWithFileOperations(t *testing.T) {
-	app := &App{}
+	app := NewApp()
 
 	t.Run("CopyToFile_RecordsOperation", func(t *testing.T) {
 		// Clear history and cache
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
-		isUndoing.Store(false)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file
 		app.storeFileInMemory("target.txt", []string{"line1", "line2"})
@@ -681,19 +691,18 @@ func TestApp_IntegrationWithFileOperations(t *testing.T) {
 		}
 
 		// Check that operation was recorded
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in history, got %d", len(undoStack(app)))
 		}
-		if operationHistory[0].Operations[0].Type != OpCopy {
+		if undoStack(app)[0].Operations[0].Type != OpCopy {
 			t.Error("Expected copy operation to be recorded")
 		}
 	})
 
 	t.Run("RemoveLineFromFile_RecordsOperation", func(t *testing.T) {
 		// Clear history and cache
-		operationHistory = []OperationGroup{}
-		fileCache = make(map[string][]string)
-		isUndoing.Store(false)
+		clearHistory(app)
+		app.compare.DiscardAll()
 
 		// Set up initial file
 		app.storeFileInMemory("target.txt", []string{"line1", "line2", "line3"})
@@ -705,15 +714,15 @@ func TestApp_IntegrationWithFileOperations(t *testing.T) {
 		}
 
 		// Check that operation was recorded
-		if len(operationHistory) != 1 {
-			t.Errorf("Expected 1 operation in history, got %d", len(operationHistory))
+		if len(undoStack(app)) != 1 {
+			t.Errorf("Expected 1 operation in history, got %d", len(undoStack(app)))
 		}
-		if operationHistory[0].Operations[0].Type != OpRemove {
+		if undoStack(app)[0].Operations[0].Type != OpRemove {
 			t.Error("Expected remove operation to be recorded")
 		}
-		if operationHistory[0].Operations[0].LineContent != "line2" {
+		if undoStack(app)[0].Operations[0].LineContent != "line2" {
 			t.Errorf("Expected removed line content to be 'line2', got %s",
-				operationHistory[0].Operations[0].LineContent)
+				undoStack(app)[0].Operations[0].LineContent)
 		}
 	})
 }
@@ -0,0 +1,138 @@
+package backend
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// VirtualPathPrefix marks paths of in-memory files that do not exist on disk
+const VirtualPathPrefix = "weld-virtual://"
+
+// Registered virtual files and their original content. Edits are kept in the
+// file cache like edits to real files, so discarding changes restores this content.
+var (
+	virtualFiles      = make(map[string][]string)
+	virtualFilesMutex sync.RWMutex
+)
+
+// IsVirtualPath reports whether a path refers to an in-memory virtual file
+func IsVirtualPath(path string) bool {
+	return strings.HasPrefix(path, VirtualPathPrefix)
+}
+
+// readVirtualFile returns a copy of a virtual file's original content
+func readVirtualFile(path string) ([]string, error) {
+	virtualFilesMutex.RLock()
+	lines, exists := virtualFiles[path]
+	virtualFilesMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("virtual file not found: %s", path)
+	}
+	return append([]string{}, lines...), nil
+}
+
+// CreateVirtualFile creates an in-memory file and returns a weld-virtual://
+// path that can be compared, copied into, and edited like a real file. It is
+// never written to disk unless saved with SaveFileAs.
+func (a *App) CreateVirtualFile(name string, lines []string) (string, error) {
+	name = filepath.Base(strings.TrimSpace(name))
+	if name == "" || name == "." || name == string(filepath.Separator) {
+		name = "untitled"
+	}
+	if lines == nil {
+		lines = []string{}
+	}
+
+	path := VirtualPathPrefix + uuid.New().String()[:8] + "/" + name
+	virtualFilesMutex.Lock()
+	virtualFiles[path] = append([]string{}, lines...)
+	virtualFilesMutex.Unlock()
+	return path, nil
+}
+
+// CloseVirtualFile discards a virtual file and any unsaved edits to it
+func (a *App) CloseVirtualFile(path string) error {
+	if !IsVirtualPath(path) {
+		return fmt.Errorf("not a virtual file: %s", path)
+	}
+
+	virtualFilesMutex.Lock()
+	_, exists := virtualFiles[path]
+	delete(virtualFiles, path)
+	virtualFilesMutex.Unlock()
+	if !exists {
+		return fmt.Errorf("virtual file not found: %s", path)
+	}
+
+	a.compare.Discard(path)
+	return nil
+}
+
+// SaveFileAs writes the current content of a file, including unsaved edits,
+// to destination. This is the only way to persist a virtual file. When
+// destination is empty the user is prompted for a location; an empty path is
+// returned if they cancel.
+func (a *App) SaveFileAs(path, destination string) (string, error) {
+	lines, err := a.ReadFileContentWithCache(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if destination == "" {
+		defaultName := filepath.Base(path)
+		destination, err = a.dialogs().SaveFile(runtime.SaveDialogOptions{
+			Title:            "Save As",
+			DefaultDirectory: a.lastUsedDirectory,
+			DefaultFilename:  defaultName,
+		})
+		if err != nil || destination == "" {
+			return "", err
+		}
+	}
+	if IsVirtualPath(destination) {
+		return "", fmt.Errorf("cannot save to a virtual path: %s", destination)
+	}
+
+	if err := os.WriteFile(destination, []byte(strings.Join(lines, "\n")), 0644); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	a.lastUsedDirectory = filepath.Dir(destination)
+	return destination, nil
+}
+
+// CompareWithNewFile pairs an existing file with a new, empty virtual file so
+// the file reads as entirely added lines that can be selectively copied into
+// the new file. Side is the pane that gets the new file, "left" or "right";
+// the frontend then compares the returned paths.
+func (a *App) CompareWithNewFile(path, side string) (InitialFiles, error) {
+	if path == "" {
+		return InitialFiles{}, fmt.Errorf("file path cannot be empty")
+	}
+	isBinary, err := IsBinaryFile(path)
+	if err != nil {
+		return InitialFiles{}, fmt.Errorf("error checking file type: %w", err)
+	}
+	if isBinary {
+		return InitialFiles{}, fmt.Errorf("cannot compare binary file: %s", filepath.Base(path))
+	}
+
+	newPath, err := a.CreateVirtualFile(filepath.Base(path), nil)
+	if err != nil {
+		return InitialFiles{}, err
+	}
+
+	switch side {
+	case "left":
+		return InitialFiles{LeftFile: newPath, RightFile: path}, nil
+	case "right":
+		return InitialFiles{LeftFile: path, RightFile: newPath}, nil
+	}
+	a.CloseVirtualFile(newPath)
+	return InitialFiles{}, fmt.Errorf("invalid side: %s", side)
+}
@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApp_VirtualFiles(t *testing.T) {
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	path, err := app.CreateVirtualFile("scratch.txt", []string{"alpha", "beta"})
+	if err != nil {
+		t.Fatalf("CreateVirtualFile returned error: %v", err)
+	}
+	t.Cleanup(func() { app.CloseVirtualFile(path) })
+
+	if !IsVirtualPath(path) || !strings.HasSuffix(path, "/scratch.txt") {
+		t.Errorf("Unexpected virtual path: %s", path)
+	}
+
+	t.Run("compares like a real file", func(t *testing.T) {
+		realPath := filepath.Join(t.TempDir(), "real.txt")
+		if err := os.WriteFile(realPath, []byte("alpha\ngamma"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		result, err := app.CompareFiles(path, realPath)
+		if err != nil {
+			t.Fatalf("CompareFiles returned error: %v", err)
+		}
+		if len(result.Lines) < 2 || result.Lines[0].Type != "same" {
+			t.Errorf("Unexpected diff: %+v", result.Lines)
+		}
+		if len(app.GetRecentComparisons()) != 0 {
+			t.Error("Expected virtual comparisons to be left out of history")
+		}
+	})
+
+	t.Run("edits are unsaved until discarded", func(t *testing.T) {
+		if err := app.CopyToFile("", path, 3, "delta"); err != nil {
+			t.Fatalf("CopyToFile returned error: %v", err)
+		}
+		if !app.HasUnsavedChanges(path) {
+			t.Error("Expected the virtual file to have unsaved changes")
+		}
+		if err := app.SaveChanges(path); err == nil {
+			t.Error("Expected SaveChanges to refuse a virtual file")
+		}
+		if err := app.DiscardAllChanges(); err != nil {
+			t.Fatalf("DiscardAllChanges returned error: %v", err)
+		}
+		lines, err := app.ReadFileContentWithCache(path)
+		if err != nil || !reflect.DeepEqual(lines, []string{"alpha", "beta"}) {
+			t.Errorf("Expected original content after discard, got %v (%v)", lines, err)
+		}
+	})
+
+	t.Run("save as persists the content", func(t *testing.T) {
+		if err := app.CopyToFile("", path, 1, "first"); err != nil {
+			t.Fatalf("CopyToFile returned error: %v", err)
+		}
+		destination := filepath.Join(t.TempDir(), "saved.txt")
+		saved, err := app.SaveFileAs(path, destination)
+		if err != nil {
+			t.Fatalf("SaveFileAs returned error: %v", err)
+		}
+		data, err := os.ReadFile(saved)
+		if err != nil || string(data) != "first\nalpha\nbeta" {
+			t.Errorf("Unexpected saved content %q (%v)", data, err)
+		}
+		if _, err := app.SaveFileAs(path, ""); err == nil {
+			t.Error("Expected error without a destination or window")
+		}
+	})
+
+	t.Run("closed files are gone", func(t *testing.T) {
+		other, _ := app.CreateVirtualFile("", nil)
+		if !strings.HasSuffix(other, "/untitled") {
+			t.Errorf("Expected a default name, got %s", other)
+		}
+		if err := app.CloseVirtualFile(other); err != nil {
+			t.Fatalf("CloseVirtualFile returned error: %v", err)
+		}
+		if _, err := app.ReadFileContent(other); err == nil {
+			t.Error("Expected error reading a closed virtual file")
+		}
+		if err := app.CloseVirtualFile(other); err == nil {
+			t.Error("Expected error closing a file twice")
+		}
+	})
+}
+
+func TestApp_CompareWithNewFile(t *testing.T) {
+
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	realPath := filepath.Join(t.TempDir(), "notes.md")
+	if err := os.WriteFile(realPath, []byte("one\ntwo"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+
+	files, err := app.CompareWithNewFile(realPath, "left")
+	if err != nil {
+		t.Fatalf("CompareWithNewFile returned error: %v", err)
+	}
+	t.Cleanup(func() { app.CloseVirtualFile(files.LeftFile) })
+	if !IsVirtualPath(files.LeftFile) || files.RightFile != realPath {
+		t.Fatalf("Unexpected files: %+v", files)
+	}
+
+	result, err := app.CompareFiles(files.LeftFile, files.RightFile)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	for _, line := range result.Lines {
+		if line.Type != "added" {
+			t.Errorf("Expected every line to be added, got %+v", line)
+		}
+	}
+
+	// Copy a piece into the new file
+	if err := app.CopyToFile(realPath, files.LeftFile, 1, "two"); err != nil {
+		t.Fatalf("CopyToFile returned error: %v", err)
+	}
+	lines, _ := app.ReadFileContentWithCache(files.LeftFile)
+	if !reflect.DeepEqual(lines, []string{"two"}) {
+		t.Errorf("Expected the copied line in the new file, got %v", lines)
+	}
+
+	right, err := app.CompareWithNewFile(realPath, "right")
+	if err != nil || right.LeftFile != realPath || !IsVirtualPath(right.RightFile) {
+		t.Errorf("Expected the new file on the right, got %+v (%v)", right, err)
+	}
+	app.CloseVirtualFile(right.RightFile)
+
+	if _, err := app.CompareWithNewFile(realPath, "middle"); err == nil {
+		t.Error("Expected error for an invalid side")
+	}
+	if _, err := app.CompareWithNewFile(filepath.Join(t.TempDir(), "missing"), "left"); err == nil {
+		t.Error("Expected error for a missing file")
+	}
+}
@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"slices"
+	"time"
+
+	"weld/backend/fileio"
+)
+
+// poller checks the modification time and size of files on an interval,
+// standing in for the watcher when the OS cannot watch them
+type poller struct {
+	paths    []string
+	interval time.Duration
+	stop     chan struct{}
+}
+
+// startPollingLocked polls the given paths in place of the watcher (must be
+// called with mu held)
+func (w *watcher) startPollingLocked(paths []string) {
+	w.stopPollingLocked()
+
+	p := &poller{
+		paths:    paths,
+		interval: w.config.PollInterval(),
+		stop:     make(chan struct{}),
+	}
+	w.poller = p
+
+	// Stamp the files now so changes made before the first tick are noticed
+	stamps := make(map[string]fileio.Stamp)
+	for _, path := range paths {
+		stamps[path] = fileio.StampOf(path)
+	}
+	go w.poll(p, stamps)
+}
+
+// stopPollingLocked stops any poller (must be called with mu held)
+func (w *watcher) stopPollingLocked() {
+	if w.poller != nil {
+		close(w.poller.stop)
+		w.poller = nil
+	}
+}
+
+// isPolledLocked returns whether a path is polled rather than watched (must
+// be called with mu held)
+func (w *watcher) isPolledLocked(path string) bool {
+	return w.poller != nil && slices.Contains(w.poller.paths, path)
+}
+
+// poll reports a change whenever a polled file's stamp differs from the
+// previous check
+func (w *watcher) poll(p *poller, stamps map[string]fileio.Stamp) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			for _, path := range p.paths {
+				stamp := fileio.StampOf(path)
+				if stamp != stamps[path] {
+					stamps[path] = stamp
+					w.Changed(path)
+				}
+			}
+		}
+	}
+}
+
+// fallBack polls the paths the watcher could not watch
+func (w *watcher) fallBack(paths []string, cause error) {
+	if len(paths) == 0 {
+		return
+	}
+
+	w.mu.Lock()
+	// The watched files may have changed while the watcher was failing
+	for _, path := range paths {
+		if path != w.leftPath && path != w.rightPath {
+			w.mu.Unlock()
+			return
+		}
+	}
+	w.startPollingLocked(paths)
+	w.mu.Unlock()
+
+	if w.config.OnFallback != nil {
+		w.config.OnFallback(paths, cause)
+	}
+}
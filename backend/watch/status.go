@@ -0,0 +1,135 @@
+package watch
+
+import (
+	"errors"
+	"syscall"
+	"time"
+)
+
+// Watch states reported by Status
+const (
+	StateOff      = "off"      // no files are being watched
+	StateLive     = "live"     // every watched file is watched
+	StateDegraded = "degraded" // some watched files could not be watched
+	StateFailed   = "failed"   // no watched file is watched
+	StatePolling  = "polling"  // every watched file is watched, some by polling
+)
+
+// Path is the watch status of one watched file
+type Path struct {
+	Path      string     `json:"path"`
+	Side      string     `json:"side"`
+	Watching  bool       `json:"watching"`
+	Method    string     `json:"method,omitempty"` // "events", or "polling" when events are unavailable
+	LastEvent *time.Time `json:"lastEvent,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Status describes whether external changes to the watched files will be noticed
+type Status struct {
+	State string `json:"state"`
+	Paths []Path `json:"paths"`
+	// Error is a failure of the watcher itself rather than of a single path
+	Error string `json:"error,omitempty"`
+	// Hint suggests how to fix the failure, when a fix is known
+	Hint string `json:"hint,omitempty"`
+}
+
+// Status reports which files are watched, when each last changed, and why
+// watching failed
+func (w *watcher) Status() Status {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	status := Status{State: StateOff, Paths: []Path{}}
+	if w.leftPath == "" && w.rightPath == "" {
+		return status
+	}
+
+	var failures []error
+	if w.failure != nil {
+		failures = append(failures, w.failure)
+		status.Error = w.failure.Error()
+	} else if w.lastError != nil {
+		failures = append(failures, w.lastError)
+		status.Error = w.lastError.Error()
+	}
+
+	watched, polled := 0, 0
+	for _, entry := range []struct{ path, side string }{
+		{w.leftPath, "left"},
+		{w.rightPath, "right"},
+	} {
+		// Skipped files have nothing on disk to change
+		if len(w.watchable(entry.path)) == 0 {
+			continue
+		}
+		path := Path{Path: entry.path, Side: entry.side}
+		err := w.pathErrors[entry.path]
+		if err != nil {
+			failures = append(failures, err)
+			path.Error = err.Error()
+		}
+		switch {
+		case w.isPolledLocked(entry.path):
+			path.Watching, path.Method = true, "polling"
+			watched++
+			polled++
+		case err == nil && w.events != nil:
+			path.Watching, path.Method = true, "events"
+			watched++
+		}
+		if last, ok := w.lastEvents[entry.path]; ok {
+			path.LastEvent = &last
+		}
+		status.Paths = append(status.Paths, path)
+	}
+
+	switch {
+	case len(status.Paths) == 0:
+		// Only skipped files are watched, so there is nothing to watch
+	case watched == len(status.Paths) && polled > 0:
+		status.State = StatePolling
+	case watched == len(status.Paths):
+		status.State = StateLive
+	case watched == 0:
+		status.State = StateFailed
+	default:
+		status.State = StateDegraded
+	}
+	for _, err := range failures {
+		if hint := ErrorHint(err); hint != "" {
+			status.Hint = hint
+			break
+		}
+	}
+	return status
+}
+
+// recordErrorLocked records or clears the error of watching a path (must be
+// called with mu held)
+func (w *watcher) recordErrorLocked(path string, err error) {
+	if err == nil {
+		delete(w.pathErrors, path)
+		return
+	}
+	if w.pathErrors == nil {
+		w.pathErrors = make(map[string]error)
+	}
+	w.pathErrors[path] = err
+}
+
+// ErrorHint suggests a fix for watch errors with a known cause
+func ErrorHint(err error) string {
+	switch {
+	case errors.Is(err, syscall.ENOSPC):
+		return "The system limit on watched files was reached (inotify limit). Raise fs.inotify.max_user_watches or close other apps that watch many files."
+	case errors.Is(err, syscall.EMFILE):
+		return "The system limit on file watchers was reached (inotify limit). Raise fs.inotify.max_user_instances or close other apps that watch files."
+	case errors.Is(err, syscall.EACCES), errors.Is(err, syscall.EPERM):
+		return "Weld does not have permission to watch this file."
+	case errors.Is(err, syscall.ENOENT):
+		return "The file no longer exists."
+	}
+	return ""
+}
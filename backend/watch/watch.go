@@ -0,0 +1,302 @@
+// Package watch notices changes made outside the app to the compared files,
+// through file system events where the OS provides them and by polling
+// where it does not
+package watch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounceInterval is how long after a change further changes to the same
+// file are ignored
+const debounceInterval = 500 * time.Millisecond
+
+// Watcher watches the left and right files of a comparison for changes
+type Watcher interface {
+	// Start watches the given files, replacing any watched before. Files
+	// that cannot be watched are polled instead.
+	Start(leftPath, rightPath string)
+	// Stop stops watching and forgets the watch status
+	Stop()
+	// Paths returns the watched files, or empty strings when none are
+	Paths() (leftPath, rightPath string)
+	// Watching returns whether changes are being watched for or polled
+	Watching() bool
+	// Changed reports a change to a watched file as if it was noticed by
+	// the watcher. Changes to other files are ignored.
+	Changed(path string)
+	// Status reports which files are watched, when each last changed, and
+	// why watching failed
+	Status() Status
+}
+
+// Config configures a Watcher
+type Config struct {
+	// Skip, when set, reports files that have nothing on disk to watch
+	Skip func(path string) bool
+	// PollInterval returns the interval at which files that cannot be
+	// watched are polled. It is read whenever polling starts.
+	PollInterval func() time.Duration
+	// OnChange is called when a watched file changed, with its side, "left"
+	// or "right". Changes within a short interval of each other are
+	// reported once.
+	OnChange func(path, side string)
+	// OnFallback, when set, is called when files are polled because they
+	// could not be watched
+	OnFallback func(paths []string, cause error)
+	// OnStatus, when set, is called when the watch status changed
+	OnStatus func()
+	// OnRewatchError, when set, is called when a changed file could not be
+	// watched again
+	OnRewatchError func(path string, err error)
+}
+
+// watcher is the Watcher returned by New
+type watcher struct {
+	config Config
+
+	// mu guards the fields below
+	mu        sync.Mutex
+	events    *fsnotify.Watcher
+	leftPath  string
+	rightPath string
+	debouncer map[string]time.Time
+	poller    *poller
+
+	// Watch status of the watched files
+	failure    error // the watcher could not be created
+	lastError  error // the watcher reported an error while running
+	pathErrors map[string]error
+	lastEvents map[string]time.Time
+}
+
+// New returns a Watcher that is not watching anything yet
+func New(config Config) Watcher {
+	return &watcher{
+		config:     config,
+		debouncer:  make(map[string]time.Time),
+		lastEvents: make(map[string]time.Time),
+	}
+}
+
+// Start watches the given files
+func (w *watcher) Start(leftPath, rightPath string) {
+	w.mu.Lock()
+
+	// Get reference to old watcher before clearing
+	old := w.events
+	w.stopLocked()
+
+	// Create new watcher
+	events, err := fsnotify.NewWatcher()
+	if err != nil {
+		// Keep the paths so the status can say what is not being watched
+		w.leftPath = leftPath
+		w.rightPath = rightPath
+		w.failure = err
+		w.mu.Unlock()
+		// Close old watcher if exists (after releasing mutex)
+		if old != nil {
+			old.Close()
+		}
+		// Poll instead of failing the comparison
+		w.fallBack(w.watchable(leftPath, rightPath), err)
+		w.statusChanged()
+		return
+	}
+
+	w.events = events
+	w.leftPath = leftPath
+	w.rightPath = rightPath
+	w.mu.Unlock()
+
+	// Close old watcher after releasing mutex to avoid deadlock
+	if old != nil {
+		old.Close()
+	}
+
+	// Start watching in a goroutine with the watcher passed as parameter
+	go w.watch(events)
+
+	// Add paths to watcher
+	var failed []string
+	var cause error
+	errs := make(map[string]error)
+	for _, path := range w.watchable(leftPath, rightPath) {
+		if err := events.Add(path); err != nil {
+			failed = append(failed, path)
+			errs[path] = err
+			cause = err
+		}
+	}
+	if len(failed) > 0 {
+		w.mu.Lock()
+		current := w.events == events
+		if current {
+			for path, err := range errs {
+				w.recordErrorLocked(path, err)
+			}
+		}
+		w.mu.Unlock()
+		if current {
+			w.fallBack(failed, cause)
+			w.statusChanged()
+		}
+	}
+}
+
+// watchable returns the paths that exist on disk
+func (w *watcher) watchable(paths ...string) []string {
+	var watchable []string
+	for _, path := range paths {
+		if w.config.Skip == nil || !w.config.Skip(path) {
+			watchable = append(watchable, path)
+		}
+	}
+	return watchable
+}
+
+// Stop stops watching
+func (w *watcher) Stop() {
+	w.mu.Lock()
+	events := w.events
+	w.stopLocked()
+	w.mu.Unlock()
+
+	// Close watcher after releasing the mutex to avoid deadlock
+	if events != nil {
+		events.Close()
+	}
+}
+
+// stopLocked forgets the watcher and its status without closing it, which
+// is left to the caller after unlocking (must be called with mu held)
+func (w *watcher) stopLocked() {
+	w.events = nil
+	w.leftPath = ""
+	w.rightPath = ""
+	// Clear debouncer entries to free memory
+	clear(w.debouncer)
+	w.stopPollingLocked()
+	w.failure = nil
+	w.lastError = nil
+	w.pathErrors = nil
+	w.lastEvents = make(map[string]time.Time)
+}
+
+// Paths returns the watched files
+func (w *watcher) Paths() (string, string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.leftPath, w.rightPath
+}
+
+// Watching returns whether changes are being watched for or polled
+func (w *watcher) Watching() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.events != nil || w.poller != nil
+}
+
+// watch reports the changes the watcher notices until it is closed
+func (w *watcher) watch(events *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-events.Events:
+			if !ok {
+				return
+			}
+
+			// Handle write, create, rename, and remove events (atomic saves)
+			if event.Op&fsnotify.Write == fsnotify.Write ||
+				event.Op&fsnotify.Create == fsnotify.Create ||
+				event.Op&fsnotify.Rename == fsnotify.Rename ||
+				event.Op&fsnotify.Remove == fsnotify.Remove {
+				w.Changed(event.Name)
+			}
+
+		case err, ok := <-events.Errors:
+			if !ok {
+				return
+			}
+			w.mu.Lock()
+			current := w.events == events
+			if current {
+				w.lastError = err
+			}
+			w.mu.Unlock()
+			if current {
+				w.statusChanged()
+			}
+		}
+	}
+}
+
+// Changed reports a change to a watched file
+func (w *watcher) Changed(path string) {
+	// Debounce rapid changes
+	w.mu.Lock()
+	lastChange, exists := w.debouncer[path]
+	now := time.Now()
+
+	if exists && now.Sub(lastChange) < debounceInterval {
+		w.mu.Unlock()
+		return
+	}
+
+	w.debouncer[path] = now
+	w.lastEvents[path] = now
+
+	// Determine which side changed
+	var side string
+	if path == w.leftPath {
+		side = "left"
+	} else if path == w.rightPath {
+		side = "right"
+	} else {
+		w.mu.Unlock()
+		return
+	}
+
+	// Re-add the file to watcher in case it was recreated, unless the watcher
+	// could not watch it and it is polled instead
+	events := w.events
+	if w.isPolledLocked(path) {
+		events = nil
+	}
+	w.mu.Unlock()
+
+	if events != nil {
+		// Remove and re-add to handle atomic saves
+		// Note: We do this after unlocking to avoid deadlock on Windows
+		events.Remove(path)
+
+		// For atomic saves, the file might not exist immediately after rename
+		// Try to re-add with a small delay
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			w.mu.Lock()
+			defer w.mu.Unlock()
+
+			if w.events != nil {
+				err := w.events.Add(path)
+				w.recordErrorLocked(path, err)
+				if err != nil && w.config.OnRewatchError != nil {
+					w.config.OnRewatchError(path, err)
+				}
+			}
+		}()
+	}
+
+	w.config.OnChange(path, side)
+}
+
+// statusChanged reports that the watch status changed
+func (w *watcher) statusChanged() {
+	if w.config.OnStatus != nil {
+		w.config.OnStatus()
+	}
+}
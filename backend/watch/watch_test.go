@@ -0,0 +1,135 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// change is a change reported by a watcher
+type change struct {
+	path, side string
+}
+
+// newTestWatcher returns a watcher reporting changes on a channel, skipping
+// paths starting with "virtual:"
+func newTestWatcher(t *testing.T) (Watcher, chan change) {
+	changes := make(chan change, 16)
+	w := New(Config{
+		Skip:         func(path string) bool { return strings.HasPrefix(path, "virtual:") },
+		PollInterval: func() time.Duration { return 50 * time.Millisecond },
+		OnChange:     func(path, side string) { changes <- change{path, side} },
+	})
+	t.Cleanup(w.Stop)
+	return w, changes
+}
+
+// tempFile creates a file in a temporary directory
+func tempFile(t *testing.T, name string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(name), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	return path
+}
+
+func TestWatcher(t *testing.T) {
+	t.Run("reports changes by side, debounced", func(t *testing.T) {
+		w, changes := newTestWatcher(t)
+		leftPath, rightPath := tempFile(t, "left.txt"), tempFile(t, "right.txt")
+		w.Start(leftPath, rightPath)
+
+		w.Changed(rightPath)
+		w.Changed(rightPath)
+		w.Changed(filepath.Join(t.TempDir(), "other.txt"))
+		if got := <-changes; got != (change{rightPath, "right"}) {
+			t.Errorf("Unexpected change %+v", got)
+		}
+		select {
+		case got := <-changes:
+			t.Errorf("Expected one change to be reported, also got %+v", got)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("status", func(t *testing.T) {
+		w, _ := newTestWatcher(t)
+		if status := w.Status(); status.State != StateOff || len(status.Paths) != 0 {
+			t.Errorf("Expected watching to be off, got %+v", status)
+		}
+
+		leftPath, rightPath := tempFile(t, "left.txt"), tempFile(t, "right.txt")
+		w.Start(leftPath, rightPath)
+		status := w.Status()
+		if status.State != StateLive || len(status.Paths) != 2 || status.Paths[1].Method != "events" {
+			t.Fatalf("Expected both files to be watched, got %+v", status)
+		}
+
+		impl := w.(*watcher)
+		impl.mu.Lock()
+		impl.recordErrorLocked(rightPath, fmt.Errorf("watch %s: %w", rightPath, syscall.ENOSPC))
+		impl.mu.Unlock()
+		status = w.Status()
+		if status.State != StateDegraded {
+			t.Errorf("Expected state %q, got %q", StateDegraded, status.State)
+		}
+		if status.Paths[1].Watching || status.Paths[1].Error == "" {
+			t.Errorf("Expected the right file to report its error, got %+v", status.Paths[1])
+		}
+		if status.Hint == "" {
+			t.Error("Expected a hint for the inotify limit")
+		}
+
+		w.Stop()
+		if status := w.Status(); status.State != StateOff || w.Watching() {
+			t.Errorf("Expected watching to be off, got %+v", status)
+		}
+	})
+
+	t.Run("skipped files are not listed", func(t *testing.T) {
+		w, _ := newTestWatcher(t)
+		leftPath := tempFile(t, "left.txt")
+		w.Start(leftPath, "virtual:untitled")
+		if status := w.Status(); status.State != StateLive || len(status.Paths) != 1 {
+			t.Errorf("Expected only the file on disk to be listed, got %+v", status)
+		}
+	})
+
+	t.Run("missing files are polled", func(t *testing.T) {
+		var fallback []string
+		changes := make(chan change, 16)
+		w := New(Config{
+			PollInterval: func() time.Duration { return 50 * time.Millisecond },
+			OnChange:     func(path, side string) { changes <- change{path, side} },
+			OnFallback:   func(paths []string, cause error) { fallback = paths },
+		})
+		t.Cleanup(w.Stop)
+
+		leftPath := tempFile(t, "left.txt")
+		rightPath := filepath.Join(t.TempDir(), "right.txt")
+		w.Start(leftPath, rightPath)
+		if len(fallback) != 1 || fallback[0] != rightPath {
+			t.Errorf("Expected the missing file to fall back to polling, got %v", fallback)
+		}
+		status := w.Status()
+		if status.State != StatePolling || status.Paths[0].Method != "events" || status.Paths[1].Method != "polling" {
+			t.Errorf("Expected only the missing file to be polled, got %+v", status)
+		}
+
+		if err := os.WriteFile(rightPath, []byte("right"), 0644); err != nil {
+			t.Fatalf("Failed to create temp file: %v", err)
+		}
+		select {
+		case got := <-changes:
+			if got != (change{rightPath, "right"}) {
+				t.Errorf("Unexpected change %+v", got)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("Expected the poller to notice the new file")
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package backend
+
+import "weld/backend/watch"
+
+// Watch states reported by GetWatchStatus
+const (
+	WatchStateOff      = watch.StateOff      // no files are being compared
+	WatchStateLive     = watch.StateLive     // every compared file is watched
+	WatchStateDegraded = watch.StateDegraded // some compared files could not be watched
+	WatchStateFailed   = watch.StateFailed   // no compared file is watched
+	WatchStatePaused   = "paused"            // watching is paused while the app is idle
+	WatchStatePolling  = watch.StatePolling  // every compared file is watched, some by polling
+)
+
+// WatchedPath is the watch status of one compared file
+type WatchedPath = watch.Path
+
+// WatchStatus describes whether external changes to the compared files will be noticed
+type WatchStatus = watch.Status
+
+// GetWatchStatus reports which files are watched for external changes, when
+// each last changed, and why watching failed
+func (a *App) GetWatchStatus() WatchStatus {
+	status := a.watcher.Status()
+	if left, right := a.watcher.Paths(); left == "" && right == "" && a.watchPaused() {
+		status.State = WatchStatePaused
+	}
+	return status
+}
+
+// watchPaused returns whether watching was paused while idle. It does not
+// take idleMutex, as watching resumes with it held.
+func (a *App) watchPaused() bool {
+	return a.watchPausedFlag.Load()
+}
+
+// emitWatchStatus tells the frontend the watch status changed
+func (a *App) emitWatchStatus() {
+	a.runtime().EventsEmit("watch-status-changed", a.GetWatchStatus())
+}
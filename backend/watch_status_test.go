@@ -0,0 +1,59 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestApp_GetWatchStatus(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	t.Run("nothing compared", func(t *testing.T) {
+		if status := app.GetWatchStatus(); status.State != WatchStateOff {
+			t.Errorf("Expected state %q, got %q", WatchStateOff, status.State)
+		}
+	})
+
+	leftPath, rightPath := compareTempFiles(t, app, []string{"a"}, []string{"b"})
+
+	t.Run("live", func(t *testing.T) {
+		status := app.GetWatchStatus()
+		if status.State != WatchStateLive {
+			t.Fatalf("Expected state %q, got %+v", WatchStateLive, status)
+		}
+		if len(status.Paths) != 2 || status.Paths[0].Path != leftPath || status.Paths[1].Path != rightPath {
+			t.Errorf("Expected both files to be listed, got %+v", status.Paths)
+		}
+	})
+
+	t.Run("last event", func(t *testing.T) {
+		app.watcher.Changed(leftPath)
+		status := app.GetWatchStatus()
+		if status.Paths[0].LastEvent == nil || time.Since(*status.Paths[0].LastEvent) > time.Minute {
+			t.Errorf("Expected a recent event for the left file, got %+v", status.Paths[0])
+		}
+		if status.Paths[1].LastEvent != nil {
+			t.Errorf("Expected no event for the right file, got %+v", status.Paths[1])
+		}
+	})
+
+	t.Run("missing file is polled", func(t *testing.T) {
+		app.StartFileWatching(leftPath, filepath.Join(t.TempDir(), "missing.txt"))
+		status := app.GetWatchStatus()
+		if status.State != WatchStatePolling {
+			t.Errorf("Expected state %q, got %q", WatchStatePolling, status.State)
+		}
+		if status.Paths[0].Method != "events" || status.Paths[1].Method != "polling" || status.Paths[1].Error == "" {
+			t.Errorf("Expected only the missing file to be polled, got %+v", status.Paths)
+		}
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		app.StopFileWatching()
+		if status := app.GetWatchStatus(); status.State != WatchStateOff || len(status.Paths) != 0 {
+			t.Errorf("Expected watching to be off, got %+v", status)
+		}
+	})
+}
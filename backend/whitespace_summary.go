@@ -0,0 +1,84 @@
+package backend
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"weld/backend/diff"
+	"weld/backend/fileio"
+)
+
+// Line ending styles of a compared file
+const (
+	LineEndingLF    = fileio.LineEndingLF
+	LineEndingCRLF  = fileio.LineEndingCRLF
+	LineEndingMixed = fileio.LineEndingMixed
+)
+
+// summarizeWhitespace flags results whose differences are all whitespace, and
+// files whose line endings differ, which comparisons never show as changes
+func summarizeWhitespace(result *DiffResult, leftPath, rightPath string, leftLines, rightLines []string) {
+	result.WhitespaceOnly = !result.Identical && diff.IsWhitespaceOnly(leftLines, rightLines)
+
+	if IsVirtualPath(leftPath) || IsVirtualPath(rightPath) {
+		return
+	}
+	leftEnding, err := fileio.DetectLineEnding(leftPath)
+	if err != nil {
+		return
+	}
+	rightEnding, err := fileio.DetectLineEnding(rightPath)
+	if err != nil {
+		return
+	}
+	// A file with a single line has no line ending to disagree about
+	result.LineEndingsDiffer = leftEnding != "" && rightEnding != "" && leftEnding != rightEnding
+}
+
+// RecompareIgnoringWhitespace compares the current files again ignoring all
+// whitespace and blank lines, the follow-up for results flagged WhitespaceOnly
+func (a *App) RecompareIgnoringWhitespace() (*DiffResult, error) {
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	options := current.Options
+	options.Whitespace = diff.WhitespaceIgnoreAll
+	options.IgnoreBlankLines = true
+	return a.CompareFilesWithOptions(current.LeftPath, current.RightPath, options)
+}
+
+// NormalizeLineEndings rewrites a file with LF line endings, the follow-up for
+// results flagged LineEndingsDiffer. Files with unsaved changes are refused,
+// since saving them writes LF line endings anyway.
+func (a *App) NormalizeLineEndings(path string) error {
+	if path == "" {
+		return fmt.Errorf("file path cannot be empty")
+	}
+	if IsVirtualPath(path) {
+		return fmt.Errorf("virtual files have no line endings on disk")
+	}
+	if a.HasUnsavedChanges(path) {
+		return fmt.Errorf("save or discard the changes to %s first", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	normalized := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if bytes.Equal(normalized, data) {
+		return nil
+	}
+	if err := os.WriteFile(path, normalized, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	a.rediffCurrentComparison()
+	return nil
+}
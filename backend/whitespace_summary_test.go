@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestApp_WhitespaceOnlySummary(t *testing.T) {
+	app := NewApp()
+	app.Storage = NewMemoryStorage()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	dir := t.TempDir()
+	leftPath := filepath.Join(dir, "left.txt")
+	rightPath := filepath.Join(dir, "right.txt")
+	if err := os.WriteFile(leftPath, []byte("if x {\r\ny()\r\n}\r\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	if err := os.WriteFile(rightPath, []byte("if x {\n\ty()\n\n}\n"), 0644); err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+
+	result, err := app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if !result.WhitespaceOnly || !result.LineEndingsDiffer {
+		t.Errorf("Expected whitespace-only differences and differing line endings, got %v %v", result.WhitespaceOnly, result.LineEndingsDiffer)
+	}
+
+	result, err = app.RecompareIgnoringWhitespace()
+	if err != nil {
+		t.Fatalf("RecompareIgnoringWhitespace returned error: %v", err)
+	}
+	if !result.Identical || result.WhitespaceOnly {
+		t.Errorf("Expected no differences ignoring whitespace, got %+v", result.Lines)
+	}
+
+	if err := app.NormalizeLineEndings(leftPath); err != nil {
+		t.Fatalf("NormalizeLineEndings returned error: %v", err)
+	}
+	content, err := os.ReadFile(leftPath)
+	if err != nil || string(content) != "if x {\ny()\n}\n" {
+		t.Errorf("Expected LF line endings, got %q (err %v)", content, err)
+	}
+	result, err = app.CompareFiles(leftPath, rightPath)
+	if err != nil {
+		t.Fatalf("CompareFiles returned error: %v", err)
+	}
+	if result.LineEndingsDiffer {
+		t.Error("Expected line endings to match after normalizing")
+	}
+
+	if err := app.NormalizeLineEndings(""); err == nil {
+		t.Error("Expected error for empty path")
+	}
+}
@@ -0,0 +1,108 @@
+package backend
+
+import (
+	"fmt"
+
+	"golang.org/x/text/width"
+)
+
+// WrapLayoutOptions describes the pane viewport and font used to wrap lines
+type WrapLayoutOptions struct {
+	ViewportWidth float64 `json:"viewportWidth"` // text area width of one pane, in pixels
+	CharWidth     float64 `json:"charWidth"`     // advance of a regular character, in pixels
+	WideCharWidth float64 `json:"wideCharWidth"` // advance of an East Asian wide character; 0 means 2 * CharWidth
+	TabSize       int     `json:"tabSize"`       // columns per tab stop; 0 uses the comparison's tab width
+}
+
+// WrapLayout holds the number of visual rows each line of the current
+// comparison occupies when wrapped, indexed like DiffResult.Lines
+type WrapLayout struct {
+	LeftRows  []int `json:"leftRows"`
+	RightRows []int `json:"rightRows"`
+	Rows      []int `json:"rows"` // rows both panes reserve so the sides stay aligned
+	TotalRows int   `json:"totalRows"`
+}
+
+// ComputeWrapLayout computes wrapped row counts for both panes of the current
+// comparison, so the frontend can pad the shorter side of each line and keep
+// wrapped long lines vertically aligned
+func (a *App) ComputeWrapLayout(options WrapLayoutOptions) (*WrapLayout, error) {
+	if options.ViewportWidth <= 0 || options.CharWidth <= 0 {
+		return nil, fmt.Errorf("viewport and character widths must be positive")
+	}
+	if options.WideCharWidth <= 0 {
+		options.WideCharWidth = 2 * options.CharWidth
+	}
+	current, err := a.currentComparison()
+	if err != nil {
+		return nil, err
+	}
+	if options.TabSize <= 0 {
+		options.TabSize = a.resolveCompareOptions(current.Options).TabWidth
+	}
+
+	lines := current.Result.Lines
+	layout := &WrapLayout{
+		LeftRows:  make([]int, len(lines)),
+		RightRows: make([]int, len(lines)),
+		Rows:      make([]int, len(lines)),
+	}
+	for i, line := range lines {
+		left, right := 1, 1
+		if line.LeftNumber > 0 {
+			left = wrappedRows(line.LeftLine, options)
+		}
+		if line.RightNumber > 0 {
+			right = wrappedRows(line.RightLine, options)
+		}
+		layout.LeftRows[i] = left
+		layout.RightRows[i] = right
+		layout.Rows[i] = max(left, right)
+		layout.TotalRows += layout.Rows[i]
+	}
+	return layout, nil
+}
+
+// wrappedRows returns how many rows a line occupies when wrapped at
+// character boundaries to the viewport width
+func wrappedRows(line string, options WrapLayoutOptions) int {
+	rows := 1
+	x := 0.0
+	column := 0
+	for _, r := range line {
+		advance := options.CharWidth
+		columns := 1
+		switch {
+		case r == '\t':
+			columns = options.TabSize - column%options.TabSize
+			advance = float64(columns) * options.CharWidth
+		case isWideRune(r):
+			advance = options.WideCharWidth
+			columns = 2
+		}
+
+		// Start a new row when the character does not fit, unless it is the
+		// first on its row and could never fit
+		if x > 0 && x+advance > options.ViewportWidth+1e-9 {
+			rows++
+			x = 0
+			column = 0
+			if r == '\t' {
+				columns = options.TabSize
+				advance = float64(columns) * options.CharWidth
+			}
+		}
+		x += advance
+		column += columns
+	}
+	return rows
+}
+
+// isWideRune reports whether a rune takes two columns in a monospace font
+func isWideRune(r rune) bool {
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return true
+	}
+	return false
+}
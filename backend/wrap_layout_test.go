@@ -0,0 +1,76 @@
+package backend
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWrappedRows(t *testing.T) {
+	options := WrapLayoutOptions{ViewportWidth: 100, CharWidth: 10, WideCharWidth: 20, TabSize: 4}
+
+	tests := []struct {
+		name     string
+		line     string
+		expected int
+	}{
+		{"empty", "", 1},
+		{"fits exactly", strings.Repeat("a", 10), 1},
+		{"one over", strings.Repeat("a", 11), 2},
+		{"three rows", strings.Repeat("a", 25), 3},
+		{"wide characters", "漢字漢字漢字", 2},
+		{"tab stops", "\ta\tb", 1},
+		{"tab wraps", "abcdefgh\tx", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := wrappedRows(tt.line, options); got != tt.expected {
+				t.Errorf("wrappedRows(%q) = %d, expected %d", tt.line, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestApp_ComputeWrapLayout(t *testing.T) {
+	app := NewApp()
+	t.Cleanup(func() { app.StopFileWatching() })
+
+	t.Run("requires a comparison", func(t *testing.T) {
+		if _, err := NewApp().ComputeWrapLayout(WrapLayoutOptions{ViewportWidth: 100, CharWidth: 10}); err == nil {
+			t.Error("Expected error when nothing has been compared")
+		}
+	})
+
+	compareTempFiles(t, app,
+		[]string{"short", strings.Repeat("x", 25)},
+		[]string{"short", strings.Repeat("y", 12), "added line that wraps!"})
+
+	t.Run("aligns rows across panes", func(t *testing.T) {
+		layout, err := app.ComputeWrapLayout(WrapLayoutOptions{ViewportWidth: 100, CharWidth: 10})
+		if err != nil {
+			t.Fatalf("ComputeWrapLayout returned error: %v", err)
+		}
+		if len(layout.Rows) == 0 || layout.Rows[0] != 1 {
+			t.Fatalf("Expected the same short line to take one row, got %+v", layout)
+		}
+		total := 0
+		for i := range layout.Rows {
+			if layout.Rows[i] != max(layout.LeftRows[i], layout.RightRows[i]) {
+				t.Errorf("Row %d is not the larger side: %+v", i, layout)
+			}
+			total += layout.Rows[i]
+		}
+		if total != layout.TotalRows {
+			t.Errorf("Expected total %d, got %d", total, layout.TotalRows)
+		}
+		if !reflect.DeepEqual(layout.LeftRows[len(layout.LeftRows)-1:], []int{1}) {
+			t.Errorf("Expected an added line to reserve one left row, got %v", layout.LeftRows)
+		}
+	})
+
+	t.Run("invalid metrics", func(t *testing.T) {
+		if _, err := app.ComputeWrapLayout(WrapLayoutOptions{ViewportWidth: 0, CharWidth: 10}); err == nil {
+			t.Error("Expected error for a zero viewport width")
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package backend
+
+import (
+	"fmt"
+	"math"
+)
+
+// Bounds and step of the zoom factor
+const (
+	minZoomFactor     = 0.5
+	maxZoomFactor     = 3.0
+	zoomStep          = 0.1
+	defaultZoomFactor = 1.0
+)
+
+// GetZoomFactor returns the scale the panes and minimap are drawn at, which
+// the frontend applies at startup
+func (a *App) GetZoomFactor() float64 {
+	return a.settings.Get().ZoomFactor
+}
+
+// SetZoomFactor sets the scale the panes and minimap are drawn at and emits
+// "zoom-changed" so they all rescale together
+func (a *App) SetZoomFactor(factor float64) error {
+	if factor < minZoomFactor || factor > maxZoomFactor {
+		return fmt.Errorf("zoom factor must be between %.1f and %.1f", minZoomFactor, maxZoomFactor)
+	}
+	err := a.settings.Update(func(s *Settings) error {
+		s.ZoomFactor = factor
+		return nil
+	})
+
+	a.runtime().EventsEmit("zoom-changed", factor)
+	return err
+}
+
+// ZoomIn enlarges the panes by one step and returns the new zoom factor
+func (a *App) ZoomIn() (float64, error) {
+	return a.stepZoom(1)
+}
+
+// ZoomOut shrinks the panes by one step and returns the new zoom factor
+func (a *App) ZoomOut() (float64, error) {
+	return a.stepZoom(-1)
+}
+
+// ResetZoom restores the default size
+func (a *App) ResetZoom() error {
+	return a.SetZoomFactor(defaultZoomFactor)
+}
+
+// stepZoom moves the zoom factor by steps, staying within bounds
+func (a *App) stepZoom(steps int) (float64, error) {
+	// Round so repeated steps do not accumulate floating point error
+	factor := math.Round((a.GetZoomFactor()+float64(steps)*zoomStep)*10) / 10
+	factor = min(max(factor, minZoomFactor), maxZoomFactor)
+	return factor, a.SetZoomFactor(factor)
+}
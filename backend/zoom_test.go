@@ -0,0 +1,47 @@
+package backend
+
+import "testing"
+
+func TestApp_Zoom(t *testing.T) {
+	storage := NewMemoryStorage()
+	app := NewApp()
+	app.Storage = storage
+
+	if factor := app.GetZoomFactor(); factor != defaultZoomFactor {
+		t.Errorf("Expected the default zoom, got %v", factor)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := app.ZoomIn(); err != nil {
+			t.Fatalf("ZoomIn returned error: %v", err)
+		}
+	}
+	if factor := app.GetZoomFactor(); factor != 1.3 {
+		t.Errorf("Expected 1.3 after three steps in, got %v", factor)
+	}
+
+	reloaded := NewApp()
+	reloaded.Storage = storage
+	if err := reloaded.loadSettings(); err != nil {
+		t.Fatalf("loadSettings returned error: %v", err)
+	}
+	if factor := reloaded.GetZoomFactor(); factor != 1.3 {
+		t.Errorf("Expected the zoom to persist, got %v", factor)
+	}
+
+	// Zooming stops at the bounds
+	for i := 0; i < 20; i++ {
+		if _, err := app.ZoomOut(); err != nil {
+			t.Fatalf("ZoomOut returned error: %v", err)
+		}
+	}
+	if factor := app.GetZoomFactor(); factor != minZoomFactor {
+		t.Errorf("Expected the minimum zoom, got %v", factor)
+	}
+
+	if err := app.ResetZoom(); err != nil || app.GetZoomFactor() != defaultZoomFactor {
+		t.Errorf("Expected ResetZoom to restore the default, got %v (err %v)", app.GetZoomFactor(), err)
+	}
+	if err := app.SetZoomFactor(10); err == nil {
+		t.Error("Expected an error for a zoom factor out of range")
+	}
+}
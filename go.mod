@@ -6,6 +6,8 @@ require (
 	github.com/fsnotify/fsnotify v1.8.0
 	github.com/google/uuid v1.6.0
 	github.com/wailsapp/wails/v2 v2.10.1
+	go.etcd.io/bbolt v1.4.0
+	golang.org/x/text v0.27.0
 )
 
 require (
@@ -33,7 +35,6 @@ require (
 	golang.org/x/crypto v0.40.0 // indirect
 	golang.org/x/net v0.41.0 // indirect
 	golang.org/x/sys v0.34.0 // indirect
-	golang.org/x/text v0.27.0 // indirect
 )
 
 // replace github.com/wailsapp/wails/v2 v2.10.1 => /
@@ -7,6 +7,7 @@ import (
 	"os"
 	"path/filepath"
 	goruntime "runtime"
+	"strings"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/menu"
@@ -20,6 +21,9 @@ import (
 //go:embed all:frontend/dist
 var assets embed.FS
 
+//go:embed resources/sample-files/*-[12].*
+var sampleFiles embed.FS
+
 // BuildMenu creates the application menu
 func BuildMenu(app *backend.App) *menu.Menu {
 	appMenu := menu.NewMenu()
@@ -56,6 +60,24 @@ func BuildMenu(app *backend.App) *menu.Menu {
 	app.SetSaveAllMenuItem(saveAllItem)
 	saveAllItem.Disabled = true
 
+	// Compare against an empty new file
+	fileMenu.AddText("Compare Against New File", nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.GetContext(), "menu-compare-new-file")
+	})
+
+	// Show the compared files where they live on disk
+	fileMenu.AddSeparator()
+	fileMenu.AddText("Reveal Left File", nil, func(_ *menu.CallbackData) {
+		if err := app.RevealComparedFile("left"); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to reveal left file: %v", err)
+		}
+	})
+	fileMenu.AddText("Reveal Right File", nil, func(_ *menu.CallbackData) {
+		if err := app.RevealComparedFile("right"); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to reveal right file: %v", err)
+		}
+	})
+
 	// Only add Quit to File menu on non-macOS platforms
 	// macOS has Quit in the application menu (Weld > Quit Weld)
 	if goruntime.GOOS != "darwin" {
@@ -134,6 +156,48 @@ func BuildMenu(app *backend.App) *menu.Menu {
 		minimapItem.Checked = true
 	}
 
+	// Focus mode hides everything but the panes, for presentations and small screens
+	focusModeItem := viewMenu.AddCheckbox("Focus Mode", false, keys.Combo("f", keys.CmdOrCtrlKey, keys.ShiftKey), func(_ *menu.CallbackData) {
+		if err := app.SetFocusMode(!app.GetFocusMode()); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to save focus mode: %v", err)
+		}
+	})
+	app.SetFocusModeMenuItem(focusModeItem)
+
+	// Zoom scales the panes and minimap together
+	viewMenu.AddSeparator()
+	viewMenu.AddText("Zoom In", keys.CmdOrCtrl("+"), func(_ *menu.CallbackData) {
+		if _, err := app.ZoomIn(); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to save zoom: %v", err)
+		}
+	})
+	viewMenu.AddText("Zoom Out", keys.CmdOrCtrl("-"), func(_ *menu.CallbackData) {
+		if _, err := app.ZoomOut(); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to save zoom: %v", err)
+		}
+	})
+	viewMenu.AddText("Actual Size", keys.CmdOrCtrl("0"), func(_ *menu.CallbackData) {
+		if err := app.ResetZoom(); err != nil {
+			runtime.LogErrorf(app.GetContext(), "Failed to save zoom: %v", err)
+		}
+	})
+	viewMenu.AddSeparator()
+
+	// Diff algorithm radio group
+	algorithmMenu := viewMenu.AddSubmenu("Diff Algorithm")
+	algorithmItems := make(map[string]*menu.MenuItem)
+	for _, algorithm := range app.GetAvailableAlgorithms() {
+		name := algorithm.Name
+		algorithmItems[name] = algorithmMenu.AddRadio(algorithm.Label, name == app.GetDiffAlgorithm(), nil, func(_ *menu.CallbackData) {
+			if err := app.SetDiffAlgorithm(name); err != nil {
+				runtime.LogErrorf(app.GetContext(), "Failed to switch diff algorithm: %v", err)
+				return
+			}
+			runtime.EventsEmit(app.GetContext(), "diff-algorithm-changed", name)
+		})
+	}
+	app.SetDiffAlgorithmMenuItems(algorithmItems)
+
 	// Go menu
 	goMenu := appMenu.AddSubmenu("Go")
 
@@ -167,14 +231,106 @@ func BuildMenu(app *backend.App) *menu.Menu {
 	app.SetNextDiffMenuItem(nextDiffItem)
 	nextDiffItem.Disabled = true
 
+	goMenu.AddSeparator()
+
+	// Previous Marker
+	goMenu.AddText("Previous Marker", keys.Shift("f2"), func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.GetContext(), "menu-prev-marker")
+	})
+
+	// Next Marker
+	goMenu.AddText("Next Marker", keys.Key("f2"), func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.GetContext(), "menu-next-marker")
+	})
+
+	// Help menu
+	helpMenu := appMenu.AddSubmenu("Help")
+	helpMenu.AddText("Open Sample Comparison", nil, func(_ *menu.CallbackData) {
+		runtime.EventsEmit(app.GetContext(), "menu-open-sample")
+	})
+
 	return appMenu
 }
 
+// printUsage lists the command-line flags other than the developer flags
+// for generating fixtures
+func printUsage() {
+	output := flag.CommandLine.Output()
+	fmt.Fprintf(output, "Usage of %s:\n", os.Args[0])
+	visible := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visible.SetOutput(output)
+	flag.VisitAll(func(f *flag.Flag) {
+		if f.Name != "generate-fixtures" && !strings.HasPrefix(f.Name, "fixtures-") {
+			visible.Var(f.Value, f.Name, f.Usage)
+		}
+	})
+	visible.PrintDefaults()
+}
+
 func main() {
 	// Parse command line arguments
+	private := flag.Bool("private", false, "keep settings, session, and history in memory only")
+	pairsFile := flag.String("pairs-file", "", "compare the left,right pairs listed in a CSV or JSON `manifest` (- reads stdin)")
+	portableFlag := flag.Bool("portable", false, "keep settings, session, and history in a WeldData directory beside the executable")
+	doctor := flag.Bool("doctor", false, "check platform prerequisites, print a report, and exit")
+	quitIfIdentical := flag.Bool("quit-if-identical", false, "exit without opening a window when the two files are identical")
+
+	// Developer flags for producing diff test data, left out of the usage
+	fixtureDefaults := backend.DefaultFixtureOptions()
+	generateFixtures := flag.Int("generate-fixtures", 0, "write `N` synthetic file pairs for diff testing and exit")
+	fixturesDir := flag.String("fixtures-dir", "fixtures", "directory generated fixtures are written to")
+	fixturesLines := flag.Int("fixtures-lines", fixtureDefaults.Lines, "lines in each generated left file")
+	fixturesDensity := flag.Float64("fixtures-density", fixtureDefaults.Density, "changes per line in generated fixtures, from 0 to 1")
+	fixturesKinds := flag.String("fixtures-changes", strings.Join(fixtureDefaults.Kinds, ","), "comma-separated kinds of change in generated fixtures")
+	fixturesSeed := flag.Uint64("fixtures-seed", fixtureDefaults.Seed, "seed making generated fixtures reproducible")
+	flag.Usage = printUsage
 	flag.Parse()
 	args := flag.Args()
 
+	// A portable.marker file beside the executable makes every run portable
+	portable := *portableFlag || backend.IsPortableInstall()
+
+	// Write test data instead of starting the app
+	if *generateFixtures > 0 {
+		pairs, err := backend.GenerateFixtures(*fixturesDir, backend.FixtureOptions{
+			Count:   *generateFixtures,
+			Lines:   *fixturesLines,
+			Density: *fixturesDensity,
+			Kinds:   strings.Split(*fixturesKinds, ","),
+			Seed:    *fixturesSeed,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating fixtures: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Wrote %d fixture pairs to %s\n", len(pairs), *fixturesDir)
+		return
+	}
+
+	// Report on the platform instead of starting the app
+	if *doctor {
+		checks := backend.RunDoctor(portable)
+		if err := backend.WriteDoctorReport(os.Stdout, checks); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing report: %v\n", err)
+			os.Exit(1)
+		}
+		if backend.DoctorFailed(checks) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Load the pairs to work through one after another
+	var pairs []backend.ComparisonPair
+	if *pairsFile != "" {
+		var err error
+		pairs, err = backend.LoadPairsManifest(*pairsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading pairs: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
 	var leftFile, rightFile string
 
 	// Check if we have file arguments
@@ -230,8 +386,38 @@ func main() {
 
 	// Create an instance of the app structure
 	app := backend.NewApp()
+	if *private {
+		app.Storage = backend.NewMemoryStorage()
+	} else if portable {
+		dir, err := backend.PortableDataDir()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error setting up portable mode: %v\n", err)
+			os.Exit(1)
+		}
+		app.Storage = backend.NewFileStorage(dir)
+	}
+
+	// Nothing needs reviewing when the files have no differences
+	if *quitIfIdentical && leftFile != "" {
+		// Compare with the user's settings, as the window would
+		if err := app.LoadSettings(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading settings, using defaults: %v\n", err)
+		}
+		identical, err := app.FilesIdentical(leftFile, rightFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error comparing files: %v\n", err)
+			os.Exit(1)
+		}
+		if identical {
+			fmt.Println("Files are identical")
+			return
+		}
+	}
+
 	app.InitialLeftFile = leftFile
 	app.InitialRightFile = rightFile
+	app.InitialPairs = pairs
+	app.SampleFiles = sampleFiles
 
 	// Create application with options
 	err := wails.Run(&options.App{